@@ -43,7 +43,7 @@ var aliasSetFlags = []cli.Flag{
 	},
 	cli.StringFlag{
 		Name:  "api",
-		Usage: "API signature. Valid options are '[S3v4, S3v2]'",
+		Usage: "API signature. Valid options are '[S3v4, S3v2, sftp]'",
 	},
 }
 
@@ -95,6 +95,14 @@ EXAMPLES:
      {{.Prompt}} echo -e "BKIKJAA5BMMU2RHO6IBB\nV8f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12" | \
                  {{.HelpName}} mys3 https://s3.amazonaws.com --api "s3v4" --path "off"
      {{.EnableHistory}}
+
+  6. Add an SFTP server under "mysftp" alias, the keys are the SSH user name and password.
+     {{.DisableHistory}}
+     {{.Prompt}} {{.HelpName}} mysftp sftp://sftp.example.com:22 sftpuser sftppassword --api "sftp"
+     {{.EnableHistory}}
+
+  7. Add an SFTP server under "mysftp" alias authenticating with ssh-agent or the keys in ~/.ssh only.
+     {{.Prompt}} {{.HelpName}} mysftp sftp://sftp.example.com:22 sftpuser "" --api "sftp"
 `,
 }
 
@@ -121,19 +129,27 @@ func checkAliasSetSyntax(ctx *cli.Context, accessKey string, secretKey string, d
 		fatalIf(errInvalidURL(url), "Invalid URL.")
 	}
 
-	if !isValidAccessKey(accessKey) {
+	// SSH user names and passwords have no length constraints.
+	isSFTP := strings.EqualFold(api, sftpAPI)
+
+	if !isSFTP && !isValidAccessKey(accessKey) {
 		fatalIf(errInvalidArgument().Trace(accessKey),
 			"Invalid access key `"+accessKey+"`.")
 	}
 
-	if !isValidSecretKey(secretKey) {
+	if !isSFTP && !isValidSecretKey(secretKey) {
 		fatalIf(errInvalidArgument().Trace(secretKey),
 			"Invalid secret key `"+secretKey+"`.")
 	}
 
 	if api != "" && !isValidAPI(api) { // Empty value set to default "S3v4".
 		fatalIf(errInvalidArgument().Trace(api),
-			"Unrecognized API signature. Valid options are `[S3v4, S3v2]`.")
+			"Unrecognized API signature. Valid options are `["+strings.Join(validAPIs, ", ")+"]`.")
+	}
+
+	if (newClientURL(url).Scheme == sftpScheme) != isSFTP {
+		fatalIf(errInvalidArgument().Trace(url, api),
+			"SFTP servers require an `sftp://` URL along with `--api sftp`.")
 	}
 
 	if deprecated {
@@ -314,16 +330,30 @@ func mainAliasSet(cli *cli.Context, deprecated bool) error {
 	ctx, cancelAliasAdd := context.WithCancel(globalContext)
 	defer cancelAliasAdd()
 
-	s3Config, err := BuildS3Config(ctx, url, accessKey, secretKey, api, path)
-	fatalIf(err.Trace(cli.Args()...), "Unable to initialize new alias from the provided credentials.")
+	var aliasCfg aliasConfigV10
+	if strings.EqualFold(api, sftpAPI) {
+		// SFTP servers have no signature to probe.
+		aliasCfg = aliasConfigV10{
+			URL:       url,
+			AccessKey: accessKey,
+			SecretKey: secretKey,
+			API:       sftpAPI,
+			Path:      path,
+		}
+	} else {
+		s3Config, err := BuildS3Config(ctx, url, accessKey, secretKey, api, path)
+		fatalIf(err.Trace(cli.Args()...), "Unable to initialize new alias from the provided credentials.")
+
+		aliasCfg = aliasConfigV10{
+			URL:       s3Config.HostURL,
+			AccessKey: s3Config.AccessKey,
+			SecretKey: s3Config.SecretKey,
+			API:       s3Config.Signature,
+			Path:      path,
+		}
+	}
 
-	msg := setAlias(alias, aliasConfigV10{
-		URL:       s3Config.HostURL,
-		AccessKey: s3Config.AccessKey,
-		SecretKey: s3Config.SecretKey,
-		API:       s3Config.Signature,
-		Path:      path,
-	}) // Add an alias with specified credentials.
+	msg := setAlias(alias, aliasCfg) // Add an alias with specified credentials.
 
 	msg.op = "set"
 	if deprecated {
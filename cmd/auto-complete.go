@@ -219,6 +219,33 @@ func (al aliasComplete) Predict(a complete.Args) (prediction []string) {
 	return
 }
 
+// sessionComplete only completes session IDs
+type sessionComplete struct{}
+
+func (sc sessionComplete) Predict(a complete.Args) (prediction []string) {
+	defer func() {
+		sort.Strings(prediction)
+	}()
+
+	sessionDir, err := getSessionDir()
+	if err != nil {
+		return nil
+	}
+	sessionList, e := filepath.Glob(sessionDir + "/*.json")
+	if e != nil {
+		return nil
+	}
+
+	arg := a.Last
+	for _, path := range sessionList {
+		if sid := strings.TrimSuffix(filepath.Base(path), ".json"); strings.HasPrefix(sid, arg) {
+			prediction = append(prediction, sid)
+		}
+	}
+
+	return
+}
+
 var adminConfigCompleter = adminConfigComplete{}
 var s3Completer = s3Complete{}
 var aliasCompleter = aliasComplete{}
@@ -245,6 +272,8 @@ var completeCmds = map[string]complete.Predictor{
 	"/tree":   complete.PredictOr(s3Complete{deepLevel: 2}, fsCompleter),
 	"/du":     complete.PredictOr(s3Complete{deepLevel: 2}, fsCompleter),
 
+	"/inventory": complete.PredictOr(s3Complete{deepLevel: 2}, fsCompleter),
+
 	"/retention/set":   s3Completer,
 	"/retention/clear": s3Completer,
 	"/retention/info":  s3Completer,
@@ -297,6 +326,20 @@ var completeCmds = map[string]complete.Predictor{
 
 	"/undo": s3Completer,
 
+	"/compose": complete.PredictOr(s3Completer, fsCompleter),
+
+	"/cache/ls":    s3Completer,
+	"/cache/clear": s3Completer,
+	"/cache/du":    s3Completer,
+
+	"/session/list":   nil,
+	"/session/resume": sessionComplete{},
+	"/session/clear":  sessionComplete{},
+
+	"/replay": fsCompleter,
+
+	"/sync": complete.PredictOr(s3Completer, fsCompleter),
+
 	// Admin API commands MinIO only.
 	"/admin/heal": s3Completer,
 
@@ -0,0 +1,168 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// bandwidthLimiter - a token bucket capping the aggregate rate of all
+// the readers it limits, such as those of parallel copies.
+type bandwidthLimiter struct {
+	mutex sync.Mutex
+
+	// Bytes per second.
+	rate float64
+	// Most bytes going through at once, one second worth of bytes.
+	burst float64
+
+	tokens float64
+	last   time.Time
+
+	// Clock of the limiter, replaced in tests.
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// newBandwidthLimiter - returns a limiter of rate bytes per second.
+func newBandwidthLimiter(rate uint64) *bandwidthLimiter {
+	return &bandwidthLimiter{
+		rate:   float64(rate),
+		burst:  float64(rate),
+		tokens: float64(rate),
+		last:   time.Now(),
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+}
+
+// sleepContext - waits for d, or until ctx is canceled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// parseBandwidthLimit - returns the limiter of a limit such as 10MiB,
+// nil for an empty limit.
+func parseBandwidthLimit(limit string) (*bandwidthLimiter, *probe.Error) {
+	if limit == "" {
+		return nil, nil
+	}
+	rate, e := humanize.ParseBytes(limit)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(limit)
+	}
+	if rate == 0 {
+		return nil, errInvalidArgument().Trace(limit)
+	}
+	return newBandwidthLimiter(rate), nil
+}
+
+// maxRead - returns the most bytes a read may ask for at once.
+func (l *bandwidthLimiter) maxRead() int {
+	return int(l.burst)
+}
+
+// wait - takes n bytes worth of tokens and waits until they are
+// refilled if the bucket ran out. Tokens are taken before waiting so
+// that concurrent readers queue up behind each other.
+func (l *bandwidthLimiter) wait(ctx context.Context, n int) error {
+	l.mutex.Lock()
+	now := l.now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mutex.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	return l.sleep(ctx, delay)
+}
+
+// limitedReader - reads from reader no faster than its limiter allows.
+type limitedReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter *bandwidthLimiter
+}
+
+// Read - reads and waits for the bytes read to fit the limit, so that
+// progress hooked on the reader reports the limited rate.
+func (r *limitedReader) Read(p []byte) (n int, err error) {
+	if max := r.limiter.maxRead(); len(p) > max {
+		p = p[:max]
+	}
+	n, err = r.reader.Read(p)
+	if n > 0 {
+		if e := r.limiter.wait(r.ctx, n); e != nil && err == nil {
+			err = e
+		}
+	}
+	return n, err
+}
+
+// limitedReadCloser - a limited reader closing the reader it limits.
+type limitedReadCloser struct {
+	limitedReader
+	closer io.Closer
+}
+
+// Close - closes the limited reader.
+func (r *limitedReadCloser) Close() error {
+	return r.closer.Close()
+}
+
+// limitUpload - returns reader limited by --limit-upload when clnt
+// uploads to object storage.
+func limitUpload(ctx context.Context, clnt Client, reader io.Reader) io.Reader {
+	if globalLimitUpload == nil || clnt.GetURL().Type != objectStorage {
+		return reader
+	}
+	return &limitedReader{ctx: ctx, reader: reader, limiter: globalLimitUpload}
+}
+
+// limitDownload - returns reader limited by --limit-download when clnt
+// downloads from object storage.
+func limitDownload(ctx context.Context, clnt Client, reader io.ReadCloser) io.ReadCloser {
+	if globalLimitDownload == nil || clnt.GetURL().Type != objectStorage {
+		return reader
+	}
+	return &limitedReadCloser{
+		limitedReader: limitedReader{ctx: ctx, reader: reader, limiter: globalLimitDownload},
+		closer:        reader,
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// TestBandwidthLimit - tests readers share the limit.
+func (s *TestSuite) TestBandwidthLimit(c *C) {
+	_, err := parseBandwidthLimit("fast")
+	c.Assert(err, NotNil)
+	limiter, err := parseBandwidthLimit("1MiB")
+	c.Assert(err, IsNil)
+	c.Assert(limiter.rate, Equals, float64(1<<20))
+
+	// Sleeping moves the clock forward.
+	clock := limiter.last
+	var waited time.Duration
+	limiter.now = func() time.Time { return clock }
+	limiter.sleep = func(ctx context.Context, d time.Duration) error {
+		clock = clock.Add(d)
+		waited += d
+		return nil
+	}
+
+	// The first second worth of bytes goes through at once, the
+	// rest of the 1.5MiB read by the two readers takes half a second.
+	for i := 0; i < 2; i++ {
+		reader := &limitedReader{
+			ctx:     context.Background(),
+			reader:  bytes.NewReader(make([]byte, 768<<10)),
+			limiter: limiter,
+		}
+		n, e := io.Copy(ioutil.Discard, reader)
+		c.Assert(e, IsNil)
+		c.Assert(n, Equals, int64(768<<10))
+		if i == 0 {
+			c.Assert(waited, Equals, time.Duration(0))
+			c.Assert(limiter.tokens, Equals, float64(256<<10))
+		}
+	}
+	c.Assert(waited, Equals, 500*time.Millisecond)
+	c.Assert(limiter.tokens <= 0, Equals, true)
+
+	// Waiting stops with the context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.Assert(sleepContext(ctx, time.Hour), Equals, context.Canceled)
+}
@@ -0,0 +1,98 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio/pkg/console"
+)
+
+var cacheClearCmd = cli.Command{
+	Name:         "clear",
+	Usage:        "remove objects from the local read cache",
+	Action:       mainCacheClear,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] [TARGET]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Remove all cached objects.
+     {{.Prompt}} {{.HelpName}}
+
+  2. Remove cached objects of a bucket.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket
+`,
+}
+
+// cacheClearMessage container for cache clear message.
+type cacheClearMessage struct {
+	Status  string `json:"status"`
+	Objects int    `json:"objects"`
+	Size    int64  `json:"size"`
+}
+
+// String colorized cache clear message.
+func (c cacheClearMessage) String() string {
+	return console.Colorize("CacheClear", fmt.Sprintf("Removed %d cached object(s), %s freed.",
+		c.Objects, humanize.IBytes(uint64(c.Size))))
+}
+
+// JSON jsonified cache clear message.
+func (c cacheClearMessage) JSON() string {
+	msgBytes, e := json.MarshalIndent(c, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// mainCacheClear is the handle for "mc cache clear" command.
+func mainCacheClear(ctx *cli.Context) error {
+	if len(ctx.Args()) > 1 {
+		cli.ShowCommandHelpAndExit(ctx, "clear", 1) // last argument is exit code
+	}
+
+	console.SetColor("CacheClear", color.New(color.FgGreen))
+
+	store, err := getCacheStore()
+	fatalIf(err, "Unable to initialize the read cache.")
+
+	entries, err := cacheEntries(ctx.Args().First())
+	fatalIf(err, "Unable to list the read cache.")
+
+	msg := cacheClearMessage{Status: "success"}
+	for _, entry := range entries {
+		err = store.remove(entry)
+		fatalIf(err, "Unable to remove cached object `"+entry.URL+"`.")
+		msg.Objects++
+		msg.Size += entry.Size
+	}
+	printMsg(msg)
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio/pkg/console"
+)
+
+var cacheDiskUsageCmd = cli.Command{
+	Name:         "du",
+	Usage:        "summarize disk usage of the local read cache",
+	Action:       mainCacheDiskUsage,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] [TARGET]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+ENVIRONMENT VARIABLES:
+  MC_CACHE_QUOTA:  size cap of the read cache, least recently used objects are evicted above it (default 10GiB)
+
+EXAMPLES:
+  1. Summarize disk usage of the read cache.
+     {{.Prompt}} {{.HelpName}}
+
+  2. Summarize disk usage of cached objects of a bucket.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket
+`,
+}
+
+// cacheDiskUsageMessage container for cache disk usage.
+type cacheDiskUsageMessage struct {
+	Status  string `json:"status"`
+	Dir     string `json:"dir"`
+	Objects int    `json:"objects"`
+	Size    int64  `json:"size"`
+	Quota   int64  `json:"quota"`
+}
+
+// String colorized cache disk usage message.
+func (c cacheDiskUsageMessage) String() string {
+	return console.Colorize("CacheDu", fmt.Sprintf("%s\t%d object(s)\t%s of %s",
+		c.Dir, c.Objects, humanize.IBytes(uint64(c.Size)), humanize.IBytes(uint64(c.Quota))))
+}
+
+// JSON jsonified cache disk usage message.
+func (c cacheDiskUsageMessage) JSON() string {
+	msgBytes, e := json.MarshalIndent(c, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// mainCacheDiskUsage is the handle for "mc cache du" command.
+func mainCacheDiskUsage(ctx *cli.Context) error {
+	if len(ctx.Args()) > 1 {
+		cli.ShowCommandHelpAndExit(ctx, "du", 1) // last argument is exit code
+	}
+
+	console.SetColor("CacheDu", color.New(color.FgGreen))
+
+	store, err := getCacheStore()
+	fatalIf(err, "Unable to initialize the read cache.")
+
+	entries, err := cacheEntries(ctx.Args().First())
+	fatalIf(err, "Unable to list the read cache.")
+
+	msg := cacheDiskUsageMessage{
+		Status: "success",
+		Dir:    store.dir,
+		Quota:  store.quota,
+	}
+	for _, entry := range entries {
+		msg.Objects++
+		msg.Size += entry.Size
+	}
+	printMsg(msg)
+	return nil
+}
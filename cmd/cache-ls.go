@@ -0,0 +1,110 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio/pkg/console"
+)
+
+var cacheListCmd = cli.Command{
+	Name:         "ls",
+	Usage:        "list objects in the local read cache",
+	Action:       mainCacheList,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] [TARGET]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. List all cached objects, least recently used first.
+     {{.Prompt}} {{.HelpName}}
+
+  2. List cached objects of a bucket.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket
+`,
+}
+
+// cacheListMessage container for cached object.
+type cacheListMessage struct {
+	Status     string    `json:"status"`
+	URL        string    `json:"url"`
+	VersionID  string    `json:"versionId,omitempty"`
+	ETag       string    `json:"etag"`
+	Size       int64     `json:"size"`
+	LastAccess time.Time `json:"lastAccess"`
+}
+
+// String colorized cached object message.
+func (c cacheListMessage) String() string {
+	message := console.Colorize("Time", fmt.Sprintf("[%s] ", c.LastAccess.Format(printDate)))
+	message += console.Colorize("Size", fmt.Sprintf("%7s ", strings.Join(strings.Fields(humanize.IBytes(uint64(c.Size))), "")))
+	message += console.Colorize("URL", c.URL)
+	if c.VersionID != "" {
+		message += console.Colorize("VersionID", " ("+c.VersionID+")")
+	}
+	return message
+}
+
+// JSON jsonified cached object message.
+func (c cacheListMessage) JSON() string {
+	msgBytes, e := json.MarshalIndent(c, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// mainCacheList is the handle for "mc cache ls" command.
+func mainCacheList(ctx *cli.Context) error {
+	if len(ctx.Args()) > 1 {
+		cli.ShowCommandHelpAndExit(ctx, "ls", 1) // last argument is exit code
+	}
+
+	console.SetColor("Time", color.New(color.FgGreen))
+	console.SetColor("Size", color.New(color.FgYellow))
+	console.SetColor("URL", color.New(color.Bold))
+	console.SetColor("VersionID", color.New(color.FgHiBlue))
+
+	entries, err := cacheEntries(ctx.Args().First())
+	fatalIf(err, "Unable to list the read cache.")
+
+	for _, entry := range entries {
+		printMsg(cacheListMessage{
+			Status:     "success",
+			URL:        entry.URL,
+			VersionID:  entry.VersionID,
+			ETag:       entry.ETag,
+			Size:       entry.Size,
+			LastAccess: entry.LastAccess,
+		})
+	}
+	return nil
+}
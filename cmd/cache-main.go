@@ -0,0 +1,70 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+var cacheSubcommands = []cli.Command{
+	cacheListCmd,
+	cacheClearCmd,
+	cacheDiskUsageCmd,
+}
+
+var cacheCmd = cli.Command{
+	Name:            "cache",
+	Usage:           "manage local read cache of downloaded objects",
+	Action:          mainCache,
+	Before:          setGlobalsFromContext,
+	Flags:           globalFlags,
+	HideHelpCommand: true,
+	Subcommands:     cacheSubcommands,
+}
+
+func mainCache(ctx *cli.Context) error {
+	commandNotFound(ctx, cacheSubcommands)
+	return nil
+}
+
+// cacheEntries - returns cached entries whose URL starts with the
+// expanded aliasedURL, all entries for an empty URL.
+func cacheEntries(aliasedURL string) ([]cacheEntry, *probe.Error) {
+	store, err := getCacheStore()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	entries, err := store.entries()
+	if err != nil {
+		return nil, err.Trace(store.dir)
+	}
+	if aliasedURL == "" {
+		return entries, nil
+	}
+
+	_, prefix, _ := mustExpandAlias(aliasedURL)
+	var matched []cacheEntry
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.URL, prefix) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched, nil
+}
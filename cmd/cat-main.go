@@ -64,7 +64,8 @@ FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}{{end}}
 ENVIRONMENT VARIABLES:
-  MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+  MC_ENCRYPT_KEY:        list of comma delimited prefix=secret values
+  MC_ENCRYPT_CLIENT_KEY: list of comma delimited prefix=secret values for client-side encryption
 
 EXAMPLES:
   1. Stream an object from Amazon S3 cloud storage to mplayer standard input.
@@ -181,6 +182,8 @@ func catURL(ctx context.Context, sourceURL, sourceVersion string, timeRef time.T
 		// are ignored since some of them have zero size though they
 		// have contents like files under /proc.
 		// 2. extract the version ID if rewind flag is passed
+		// 3. find out if the object was compressed on upload
+		var codec string
 		if client, content, err := url2Stat(ctx, sourceURL, sourceVersion, false, encKeyDB, timeRef); err == nil {
 			if sourceVersion == "" {
 				versionID = content.VersionID
@@ -188,12 +191,24 @@ func catURL(ctx context.Context, sourceURL, sourceVersion string, timeRef time.T
 			if client.GetURL().Type == objectStorage {
 				size = content.Size
 			}
+			var origSize int64
+			if codec, origSize = contentCompression(content); codec != "" {
+				size = origSize
+			}
 		} else {
 			return err.Trace(sourceURL)
 		}
 		if reader, err = getSourceStreamFromURL(ctx, sourceURL, versionID, encKeyDB); err != nil {
 			return err.Trace(sourceURL)
 		}
+		if codec != "" {
+			decReader, err := decompressStream(reader, codec)
+			if err != nil {
+				reader.Close()
+				return err.Trace(sourceURL)
+			}
+			reader = decReader
+		}
 		defer reader.Close()
 	}
 	return catOut(reader, size).Trace(sourceURL)
@@ -0,0 +1,1053 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/minio/mc/pkg/hookreader"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+const archiveBackendName = "archive"
+
+// archiveFormat - container and compression of an archive.
+type archiveFormat int
+
+const (
+	archiveTar archiveFormat = iota
+	archiveTarGzip
+	archiveTarZstd
+	archiveZip
+)
+
+// archiveExtensions - file extensions of supported archives.
+var archiveExtensions = []struct {
+	ext    string
+	format archiveFormat
+}{
+	{".tar", archiveTar},
+	{".tar.gz", archiveTarGzip},
+	{".tgz", archiveTarGzip},
+	{".tar.zst", archiveTarZstd},
+	{".tzst", archiveTarZstd},
+	{".zip", archiveZip},
+}
+
+// archiveFormatOf - returns the archive format of a file name.
+func archiveFormatOf(name string) (archiveFormat, bool) {
+	name = strings.ToLower(name)
+	for _, e := range archiveExtensions {
+		if strings.HasSuffix(name, e.ext) && len(name) > len(e.ext) {
+			return e.format, true
+		}
+	}
+	return archiveTar, false
+}
+
+// archiveWriteExtensions - file extensions of archives which can be
+// written, zip archives need seeking and are only read.
+func archiveWriteExtensions() (exts []string) {
+	for _, e := range archiveExtensions {
+		if e.format != archiveZip {
+			exts = append(exts, e.ext)
+		}
+	}
+	return exts
+}
+
+// isArchiveWriteTarget - returns true if an archive can be written at aliasedURL.
+func isArchiveWriteTarget(aliasedURL string) bool {
+	format, ok := archiveFormatOf(strings.TrimRight(aliasedURL, "/\\"))
+	return ok && format != archiveZip
+}
+
+// archiveRootURL - returns the URL addressing the entries of the
+// archive at aliasedURL.
+func archiveRootURL(aliasedURL string) string {
+	_, urlStrFull, _ := mustExpandAlias(aliasedURL)
+	sep := string(newClientURL(urlStrFull).Separator)
+	if strings.HasSuffix(aliasedURL, sep) {
+		return aliasedURL
+	}
+	return aliasedURL + sep
+}
+
+// archiveLocation - an entry, or a prefix of entries, inside an archive.
+type archiveLocation struct {
+	// Full URL of the entry.
+	url *ClientURL
+	// Path of the archive itself.
+	archivePath string
+	// Slash separated path inside the archive.
+	entry  string
+	format archiveFormat
+}
+
+// archiveURL - returns the URL of the archive itself.
+func (l archiveLocation) archiveURL() string {
+	u := l.url.Clone()
+	u.Path = l.archivePath
+	return u.String()
+}
+
+// parseArchiveLocation - looks for an archive in the path of urlStr,
+// an archive is only browsed when its name is followed by a separator.
+// On object storage prefixes may be named like archives, a name is
+// only an archive once its object is found through aliasCfg.
+func parseArchiveLocation(urlStr string, aliasCfg *aliasConfigV10) (archiveLocation, bool) {
+	u := newClientURL(urlStr)
+	sep := string(u.Separator)
+	p := u.Path
+
+	start := 0
+	if u.Type == objectStorage {
+		// Bucket names may look like archives, skip them.
+		bucketPath := strings.TrimPrefix(p, sep)
+		i := strings.Index(bucketPath, sep)
+		if i < 0 {
+			return archiveLocation{}, false
+		}
+		start = len(p) - len(bucketPath) + i + 1
+	}
+
+	for i := start; i < len(p); {
+		j := strings.Index(p[i:], sep)
+		if j < 0 {
+			break
+		}
+		name := p[i : i+j]
+		archivePath := p[:i+j]
+		i += j + 1
+
+		format, ok := archiveFormatOf(name)
+		if !ok {
+			continue
+		}
+		loc := archiveLocation{
+			url:         u,
+			archivePath: archivePath,
+			entry:       strings.Replace(p[i:], sep, "/", -1),
+			format:      format,
+		}
+		if u.Type == fileSystem {
+			// Local folders named like archives are left untouched.
+			if fi, e := os.Stat(archivePath); e == nil && fi.IsDir() {
+				continue
+			}
+		} else if !isArchiveObject(loc.archiveURL(), aliasCfg) {
+			continue
+		}
+		return loc, true
+	}
+	return archiveLocation{}, false
+}
+
+// archiveObjects - whether the objects looked up so far are archives,
+// keyed by URL, an object is only looked up once.
+var archiveObjects = struct {
+	sync.Mutex
+	m map[string]bool
+}{m: make(map[string]bool)}
+
+// isArchiveObject - returns true if archiveURL is an existing object,
+// or an archive being written, rather than a prefix.
+func isArchiveObject(archiveURL string, aliasCfg *aliasConfigV10) bool {
+	archiveWriters.Lock()
+	_, ok := archiveWriters.m[archiveURL]
+	archiveWriters.Unlock()
+	if ok {
+		return true
+	}
+
+	archiveObjects.Lock()
+	isArchive, ok := archiveObjects.m[archiveURL]
+	archiveObjects.Unlock()
+	if ok {
+		return isArchive
+	}
+
+	clnt, err := getStorageBackend(archiveURL, aliasCfg).New(archiveURL, aliasCfg)
+	if err != nil {
+		return false
+	}
+	st, err := clnt.Stat(context.Background(), StatOptions{})
+	if err != nil {
+		switch err.ToGoError().(type) {
+		case ObjectMissing, PathNotFound:
+		default:
+			// Unknown yet, looked up again next time.
+			return false
+		}
+	}
+	isArchive = err == nil && !st.Type.IsDir()
+	archiveObjects.Lock()
+	archiveObjects.m[archiveURL] = isArchive
+	archiveObjects.Unlock()
+	return isArchive
+}
+
+// isArchiveURL - returns true if urlStr addresses entries of an archive.
+func isArchiveURL(urlStr string, aliasCfg *aliasConfigV10) bool {
+	_, ok := parseArchiveLocation(urlStr, aliasCfg)
+	return ok
+}
+
+// isAliasArchiveURL - returns true if urlStr, expanded from alias,
+// addresses entries of an archive.
+func isAliasArchiveURL(alias, urlStr string) bool {
+	_, _, aliasCfg, err := expandAlias(alias)
+	if err != nil {
+		return false
+	}
+	return isArchiveURL(urlStr, aliasCfg)
+}
+
+// archiveEntryName - returns the cleaned path of an archive entry.
+func archiveEntryName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// archiveEntry - a regular file stored in an archive.
+type archiveEntry struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+	Mode    os.FileMode
+	// Position among the regular files of the archive.
+	Pos int
+}
+
+// archiveIndexCache - entries of the archives read so far, keyed
+// by the archive URL and its version.
+var archiveIndexCache = struct {
+	sync.Mutex
+	m map[string][]archiveEntry
+}{m: make(map[string][]archiveEntry)}
+
+// archiveCloser - closes several closers in order.
+type archiveCloser []io.Closer
+
+// Close - close all, returns the first error.
+func (cs archiveCloser) Close() (err error) {
+	for _, c := range cs {
+		if e := c.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// archiveCloseFunc - adapts a function to io.Closer.
+type archiveCloseFunc func() error
+
+// Close - calls the function.
+func (f archiveCloseFunc) Close() error {
+	return f()
+}
+
+// archiveEntryReader - reader of an archive entry which releases
+// the archive stream once closed.
+type archiveEntryReader struct {
+	io.Reader
+	io.Closer
+}
+
+// Archive client, entries of tar and zip archives are served read
+// only through the client of the archive itself. Entries are written
+// while an archive writer streams a new archive to the same URL.
+type archiveClient struct {
+	unsupportedClient
+	targetURL   *ClientURL
+	archive     Client
+	archivePath string
+	entry       string
+	format      archiveFormat
+}
+
+// archiveNew - instantiates a client browsing the archive served by archive.
+func archiveNew(archive Client, loc archiveLocation) Client {
+	return &archiveClient{
+		unsupportedClient: unsupportedClient{backend: archiveBackendName},
+		targetURL:         loc.url,
+		archive:           archive,
+		archivePath:       loc.archivePath,
+		entry:             loc.entry,
+		format:            loc.format,
+	}
+}
+
+// archiveBackend - serves entries of archives stored on any other
+// backend. Archives support none of the optional operations.
+var archiveBackend = clientBackend{
+	Name:  archiveBackendName,
+	Match: isArchiveURL,
+	New: func(urlStr string, aliasCfg *aliasConfigV10) (Client, *probe.Error) {
+		loc, ok := parseArchiveLocation(urlStr, aliasCfg)
+		if !ok {
+			return nil, probe.NewError(errors.New("not an archive URL")).Trace(urlStr)
+		}
+		archive, err := getStorageBackend(urlStr, aliasCfg).New(loc.archiveURL(), aliasCfg)
+		if err != nil {
+			return nil, err.Trace(urlStr)
+		}
+		return archiveNew(archive, loc), nil
+	},
+}
+
+// The archive backend opens archives through the registry itself,
+// so it is registered once the registry is initialized.
+func init() {
+	clientBackends = append(clientBackends, archiveBackend)
+}
+
+// GetURL get url.
+func (c *archiveClient) GetURL() ClientURL {
+	return c.targetURL.Clone()
+}
+
+// AddUserAgent - set app name and version of the archive client.
+func (c *archiveClient) AddUserAgent(app, version string) {
+	c.archive.AddUserAgent(app, version)
+}
+
+// writer - returns the archive writer of this archive, if any.
+func (c *archiveClient) writer() *archiveWriter {
+	archiveWriters.Lock()
+	defer archiveWriters.Unlock()
+	return archiveWriters.m[c.archive.GetURL().String()]
+}
+
+// extractor - returns the single pass extraction of this archive, if any.
+func (c *archiveClient) extractor() *archiveExtractor {
+	archiveExtractors.Lock()
+	defer archiveExtractors.Unlock()
+	return archiveExtractors.m[c.archive.GetURL().String()]
+}
+
+// entryURL - returns the URL of an entry name.
+func (c *archiveClient) entryURL(name string) ClientURL {
+	u := c.targetURL.Clone()
+	sep := string(u.Separator)
+	u.Path = c.archivePath + sep + strings.Replace(name, "/", sep, -1)
+	return u
+}
+
+// entry2ClientContent - converts an archive entry into ClientContent.
+func (c *archiveClient) entry2ClientContent(e archiveEntry) *ClientContent {
+	return &ClientContent{
+		URL:  c.entryURL(e.Name),
+		Time: e.ModTime,
+		Size: e.Size,
+		Type: e.Mode,
+		Metadata: map[string]string{
+			"Content-Type": guessURLContentType(e.Name),
+		},
+	}
+}
+
+// dir2ClientContent - converts a folder of the archive into ClientContent.
+func (c *archiveClient) dir2ClientContent(dir string) *ClientContent {
+	return &ClientContent{
+		URL:  c.entryURL(dir),
+		Type: os.ModeDir,
+	}
+}
+
+// openTar - returns a reader of the tar stream.
+func (c *archiveClient) openTar(ctx context.Context) (*tar.Reader, io.Closer, *probe.Error) {
+	rc, err := c.archive.Get(ctx, GetOptions{})
+	if err != nil {
+		return nil, nil, err.Trace(c.archivePath)
+	}
+
+	var r io.Reader = rc
+	closer := archiveCloser{rc}
+	switch c.format {
+	case archiveTarGzip:
+		gr, e := gzip.NewReader(rc)
+		if e != nil {
+			rc.Close()
+			return nil, nil, probe.NewError(e).Trace(c.archivePath)
+		}
+		r, closer = gr, archiveCloser{gr, rc}
+	case archiveTarZstd:
+		zr, e := zstd.NewReader(rc)
+		if e != nil {
+			rc.Close()
+			return nil, nil, probe.NewError(e).Trace(c.archivePath)
+		}
+		dr := zr.IOReadCloser()
+		r, closer = dr, archiveCloser{dr, rc}
+	}
+	return tar.NewReader(r), closer, nil
+}
+
+// openZip - returns a reader of the zip archive. Entries are read
+// with ranged reads when the archive reader supports them, the
+// archive is spooled to a temporary file otherwise.
+func (c *archiveClient) openZip(ctx context.Context) (*zip.Reader, io.Closer, *probe.Error) {
+	st, err := c.archive.Stat(ctx, StatOptions{})
+	if err != nil {
+		return nil, nil, err.Trace(c.archivePath)
+	}
+	rc, err := c.archive.Get(ctx, GetOptions{})
+	if err != nil {
+		return nil, nil, err.Trace(c.archivePath)
+	}
+
+	size := st.Size
+	ra, ok := rc.(io.ReaderAt)
+	var closer io.Closer = rc
+	if !ok {
+		f, e := ioutil.TempFile("", "mc-archive-")
+		if e != nil {
+			rc.Close()
+			return nil, nil, probe.NewError(e)
+		}
+		closer = archiveCloser{f, archiveCloseFunc(func() error {
+			return os.Remove(f.Name())
+		})}
+		size, e = io.Copy(f, rc)
+		rc.Close()
+		if e != nil {
+			closer.Close()
+			return nil, nil, probe.NewError(e).Trace(c.archivePath)
+		}
+		ra = f
+	}
+
+	zr, e := zip.NewReader(ra, size)
+	if e != nil {
+		closer.Close()
+		return nil, nil, probe.NewError(e).Trace(c.archivePath)
+	}
+	return zr, closer, nil
+}
+
+// readIndex - reads all regular file entries of the archive.
+func (c *archiveClient) readIndex(ctx context.Context) (entries []archiveEntry, err *probe.Error) {
+	if c.format == archiveZip {
+		zr, closer, err := c.openZip(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer closer.Close()
+		for _, f := range zr.File {
+			if name := archiveEntryName(f.Name); name != "" && f.Mode().IsRegular() {
+				entries = append(entries, archiveEntry{
+					Name:    name,
+					Size:    int64(f.UncompressedSize64),
+					ModTime: f.Modified,
+					Mode:    f.Mode(),
+					Pos:     len(entries),
+				})
+			}
+		}
+		return entries, nil
+	}
+
+	tr, closer, err := c.openTar(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	for {
+		hdr, e := tr.Next()
+		if e == io.EOF {
+			return entries, nil
+		}
+		if e != nil {
+			return nil, probe.NewError(e).Trace(c.archivePath)
+		}
+		fi := hdr.FileInfo()
+		if name := archiveEntryName(hdr.Name); name != "" && fi.Mode().IsRegular() {
+			entries = append(entries, archiveEntry{
+				Name:    name,
+				Size:    hdr.Size,
+				ModTime: hdr.ModTime,
+				Mode:    fi.Mode(),
+				Pos:     len(entries),
+			})
+		}
+	}
+}
+
+// index - returns the entries of the archive sorted by name, the
+// archive is only read once per version.
+func (c *archiveClient) index(ctx context.Context) ([]archiveEntry, *probe.Error) {
+	if w := c.writer(); w != nil {
+		return w.index(), nil
+	}
+
+	st, err := c.archive.Stat(ctx, StatOptions{})
+	if err != nil {
+		return nil, err.Trace(c.archivePath)
+	}
+	if st.Type.IsDir() {
+		return nil, probe.NewError(PathNotFound{Path: c.archivePath})
+	}
+	key := fmt.Sprintf("%s|%s|%d|%d", c.archive.GetURL().String(), st.ETag, st.Size, st.Time.UnixNano())
+
+	archiveIndexCache.Lock()
+	entries, ok := archiveIndexCache.m[key]
+	archiveIndexCache.Unlock()
+	if ok {
+		return entries, nil
+	}
+
+	entries, err = c.readIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	archiveIndexCache.Lock()
+	archiveIndexCache.m[key] = entries
+	archiveIndexCache.Unlock()
+	return entries, nil
+}
+
+// Stat - get metadata of an archive entry or folder.
+func (c *archiveClient) Stat(ctx context.Context, opts StatOptions) (*ClientContent, *probe.Error) {
+	entries, err := c.index(ctx)
+	if err != nil {
+		return nil, err.Trace(c.targetURL.String())
+	}
+	if c.entry == "" {
+		return c.dir2ClientContent(""), nil
+	}
+
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].Name >= c.entry })
+	if i < len(entries) && entries[i].Name == c.entry {
+		return c.entry2ClientContent(entries[i]), nil
+	}
+	dir := strings.TrimSuffix(c.entry, "/") + "/"
+	i = sort.Search(len(entries), func(i int) bool { return entries[i].Name >= dir })
+	if i < len(entries) && strings.HasPrefix(entries[i].Name, dir) {
+		return c.dir2ClientContent(dir), nil
+	}
+	return nil, probe.NewError(ObjectMissing{}).Trace(c.targetURL.String())
+}
+
+// List - list entries of the archive starting with the entry prefix.
+func (c *archiveClient) List(ctx context.Context, opts ListOptions) <-chan *ClientContent {
+	contentCh := make(chan *ClientContent)
+	go func() {
+		defer close(contentCh)
+		// Archives hold neither incomplete uploads nor versions.
+		if opts.Incomplete {
+			return
+		}
+		entries, err := c.index(ctx)
+		if err != nil {
+			contentCh <- &ClientContent{Err: err.Trace(c.targetURL.String())}
+			return
+		}
+		for _, content := range c.list(entries, opts) {
+			select {
+			case <-ctx.Done():
+				return
+			case contentCh <- content:
+			}
+		}
+	}()
+	return contentCh
+}
+
+// list - returns entries matching the entry prefix, sub-folders are
+// returned instead of their entries in non recursive mode.
+func (c *archiveClient) list(entries []archiveEntry, opts ListOptions) (contents []*ClientContent) {
+	prefix := c.entry
+	seenDirs := make(map[string]bool)
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name, prefix) {
+			continue
+		}
+		if !opts.Recursive {
+			if i := strings.Index(e.Name[len(prefix):], "/"); i >= 0 {
+				dir := e.Name[:len(prefix)+i+1]
+				if !seenDirs[dir] {
+					seenDirs[dir] = true
+					contents = append(contents, c.dir2ClientContent(dir))
+				}
+				continue
+			}
+		}
+		contents = append(contents, c.entry2ClientContent(e))
+	}
+	return contents
+}
+
+// Get - returns a reader of an archive entry.
+func (c *archiveClient) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *probe.Error) {
+	if c.writer() != nil {
+		return nil, c.notImplemented("Get")
+	}
+
+	if c.format == archiveZip {
+		zr, closer, err := c.openZip(ctx)
+		if err != nil {
+			return nil, err.Trace(c.targetURL.String())
+		}
+		for _, f := range zr.File {
+			if archiveEntryName(f.Name) != c.entry || !f.Mode().IsRegular() {
+				continue
+			}
+			rc, e := f.Open()
+			if e != nil {
+				closer.Close()
+				return nil, probe.NewError(e).Trace(c.targetURL.String())
+			}
+			return archiveEntryReader{rc, archiveCloser{rc, closer}}, nil
+		}
+		closer.Close()
+		return nil, probe.NewError(ObjectMissing{}).Trace(c.targetURL.String())
+	}
+
+	// Entries are read from the single pass extraction of the
+	// archive, if any and not already past the entry.
+	if x := c.extractor(); x != nil {
+		if reader, ok := x.get(c.entry); ok {
+			return reader, nil
+		}
+	}
+
+	// Tar archives are streamed up to the entry.
+	tr, closer, err := c.openTar(ctx)
+	if err != nil {
+		return nil, err.Trace(c.targetURL.String())
+	}
+	for {
+		hdr, e := tr.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			closer.Close()
+			return nil, probe.NewError(e).Trace(c.targetURL.String())
+		}
+		if archiveEntryName(hdr.Name) == c.entry && hdr.FileInfo().Mode().IsRegular() {
+			return archiveEntryReader{tr, closer}, nil
+		}
+	}
+	closer.Close()
+	return nil, probe.NewError(ObjectMissing{}).Trace(c.targetURL.String())
+}
+
+// Put - add an entry to the archive being written, archives are
+// otherwise read only.
+func (c *archiveClient) Put(ctx context.Context, reader io.Reader, size int64, metadata map[string]string, progress io.Reader, sse encrypt.ServerSide, md5, disableMultipart, isPreserve bool) (int64, *probe.Error) {
+	w := c.writer()
+	if w == nil {
+		return 0, c.notImplemented("Put")
+	}
+	if c.entry == "" || strings.HasSuffix(c.entry, "/") {
+		return 0, probe.NewError(ObjectNameEmpty{})
+	}
+	if size < 0 {
+		return 0, errArchiveEntrySize(c.entry).Trace(c.targetURL.String())
+	}
+
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     c.entry,
+		Size:     size,
+		Mode:     0644,
+		ModTime:  time.Now().UTC(),
+	}
+	if isPreserve {
+		if attr, e := parseAttribute(metadata); e == nil {
+			if _, mtime, err := parseAtimeMtime(attr); err == nil && !mtime.IsZero() {
+				hdr.ModTime = mtime
+			}
+			if mode, e := strconv.ParseUint(attr["mode"], 0, 32); e == nil {
+				hdr.Mode = int64(os.FileMode(mode).Perm())
+			}
+		}
+	}
+
+	n, err := w.add(hdr, hookreader.NewHook(reader, progress))
+	if err != nil {
+		return n, err.Trace(c.targetURL.String())
+	}
+	return n, nil
+}
+
+// Copy - not supported by archives, entries are streamed through Put.
+func (c *archiveClient) Copy(ctx context.Context, source string, opts CopyOptions, progress io.Reader) *probe.Error {
+	return c.notImplemented("Copy")
+}
+
+// MakeBucket - folders are implied by the entries of an archive.
+func (c *archiveClient) MakeBucket(ctx context.Context, region string, ignoreExisting, withLock bool) *probe.Error {
+	return nil
+}
+
+// Remove - not supported by archives.
+func (c *archiveClient) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isBypass bool, contentCh <-chan *ClientContent) <-chan *probe.Error {
+	errorCh := make(chan *probe.Error, 1)
+	errorCh <- c.notImplemented("Remove")
+	close(errorCh)
+	return errorCh
+}
+
+// archiveExtractor - reads the entries of a tar archive in a single
+// sequential pass. Recursive copies of an archive register one so that
+// their entries are not each streamed from the start of the archive.
+type archiveExtractor struct {
+	sync.Mutex
+	cond *sync.Cond
+	ctx  context.Context
+	c    *archiveClient
+	key  string
+	// Position of the regular files of the archive by name.
+	pos map[string]int
+	// Positions of the entries waiting for the stream.
+	waiting map[int]bool
+	tr      *tar.Reader
+	closer  io.Closer
+	// Position of the next regular file of the stream.
+	next int
+	// An entry of the stream is being read.
+	busy bool
+	err  error
+}
+
+// archiveExtractors - archives being extracted, keyed by archive URL.
+var archiveExtractors = struct {
+	sync.Mutex
+	m map[string]*archiveExtractor
+}{m: make(map[string]*archiveExtractor)}
+
+// errArchiveExtractorClosed - ends the extraction of an archive.
+var errArchiveExtractorClosed = errors.New("archive extraction is closed")
+
+// openArchiveExtractor - starts the single pass extraction of the tar
+// archive addressed by aliasedURL. Nothing is extracted for any other
+// URL, zip archives are read at random offsets already.
+func openArchiveExtractor(ctx context.Context, aliasedURL string) (*archiveExtractor, *probe.Error) {
+	clnt, err := newClient(aliasedURL)
+	if err != nil {
+		return nil, err.Trace(aliasedURL)
+	}
+	c, ok := clnt.(*archiveClient)
+	if !ok || c.format == archiveZip || c.writer() != nil {
+		return nil, nil
+	}
+	entries, err := c.index(ctx)
+	if err != nil {
+		return nil, err.Trace(aliasedURL)
+	}
+
+	x := &archiveExtractor{
+		ctx:     ctx,
+		c:       c,
+		key:     c.archive.GetURL().String(),
+		pos:     make(map[string]int, len(entries)),
+		waiting: make(map[int]bool),
+	}
+	x.cond = sync.NewCond(x)
+	for _, e := range entries {
+		// Streaming reads return the first entry of a name.
+		if pos, ok := x.pos[e.Name]; !ok || e.Pos < pos {
+			x.pos[e.Name] = e.Pos
+		}
+	}
+
+	archiveExtractors.Lock()
+	defer archiveExtractors.Unlock()
+	// Several sources of the same archive share one extraction.
+	if _, ok := archiveExtractors.m[x.key]; ok {
+		return nil, nil
+	}
+	archiveExtractors.m[x.key] = x
+	return x, nil
+}
+
+// firstWaiting - returns the lowest position waiting for the stream.
+func (x *archiveExtractor) firstWaiting() int {
+	first := -1
+	for pos := range x.waiting {
+		if first < 0 || pos < first {
+			first = pos
+		}
+	}
+	return first
+}
+
+// get - returns a reader of the entry name once the stream reaches it,
+// entries are served in archive order. Entries the stream is already
+// past are not served and have to be streamed on their own.
+func (x *archiveExtractor) get(name string) (io.ReadCloser, bool) {
+	x.Lock()
+	defer x.Unlock()
+	pos, ok := x.pos[name]
+	if !ok {
+		return nil, false
+	}
+	x.waiting[pos] = true
+	defer func() {
+		delete(x.waiting, pos)
+		x.cond.Broadcast()
+	}()
+	for x.err == nil && pos >= x.next && (x.busy || pos != x.firstWaiting()) {
+		x.cond.Wait()
+	}
+	if x.err != nil || pos < x.next {
+		return nil, false
+	}
+
+	if x.tr == nil {
+		tr, closer, err := x.c.openTar(x.ctx)
+		if err != nil {
+			x.err = err.ToGoError()
+			return nil, false
+		}
+		x.tr, x.closer = tr, closer
+	}
+	for {
+		hdr, e := x.tr.Next()
+		if e != nil {
+			x.err = e
+			return nil, false
+		}
+		if archiveEntryName(hdr.Name) == "" || !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		x.next++
+		if x.next-1 == pos {
+			x.busy = true
+			return &archiveExtractorReader{Reader: x.tr, x: x}, true
+		}
+	}
+}
+
+// Close - ends the extraction, entries not read yet are streamed on
+// their own.
+func (x *archiveExtractor) Close() error {
+	archiveExtractors.Lock()
+	delete(archiveExtractors.m, x.key)
+	archiveExtractors.Unlock()
+
+	x.Lock()
+	defer x.Unlock()
+	x.err = errArchiveExtractorClosed
+	x.cond.Broadcast()
+	if x.closer == nil {
+		return nil
+	}
+	return x.closer.Close()
+}
+
+// archiveExtractorReader - reader of the entry being extracted, the
+// stream moves on to the next entries once closed.
+type archiveExtractorReader struct {
+	io.Reader
+	x      *archiveExtractor
+	closed bool
+}
+
+// Close - releases the stream.
+func (r *archiveExtractorReader) Close() error {
+	r.x.Lock()
+	defer r.x.Unlock()
+	if !r.closed {
+		r.closed = true
+		r.x.busy = false
+		r.x.cond.Broadcast()
+	}
+	return nil
+}
+
+// errArchiveAborted - reported to the upload of an aborted archive.
+var errArchiveAborted = errors.New("archive was aborted")
+
+// archiveWriter - streams a tar archive to the Put of the archive's
+// own client, entries are added by archive clients of the same URL.
+type archiveWriter struct {
+	sync.Mutex
+	key     string
+	pw      *io.PipeWriter
+	zw      io.WriteCloser
+	tw      *tar.Writer
+	entries []archiveEntry
+	err     error
+	doneCh  chan *probe.Error
+}
+
+// archiveWriters - archives being written, keyed by archive URL.
+var archiveWriters = struct {
+	sync.Mutex
+	m map[string]*archiveWriter
+}{m: make(map[string]*archiveWriter)}
+
+// openArchiveWriter - starts writing a new tar archive at aliasedURL,
+// the archive replaces any existing object once closed.
+func openArchiveWriter(ctx context.Context, aliasedURL string) (*archiveWriter, *probe.Error) {
+	if !isArchiveWriteTarget(aliasedURL) {
+		return nil, errInvalidArchiveTarget(aliasedURL).Trace(aliasedURL)
+	}
+	clnt, err := newClient(strings.TrimRight(aliasedURL, "/\\"))
+	if err != nil {
+		return nil, err.Trace(aliasedURL)
+	}
+	format, _ := archiveFormatOf(clnt.GetURL().Path)
+
+	pr, pw := io.Pipe()
+	w := &archiveWriter{
+		key:    clnt.GetURL().String(),
+		pw:     pw,
+		doneCh: make(chan *probe.Error, 1),
+	}
+	contentType := "application/x-tar"
+	switch format {
+	case archiveTarGzip:
+		w.zw = gzip.NewWriter(pw)
+		contentType = "application/gzip"
+	case archiveTarZstd:
+		zw, e := zstd.NewWriter(pw)
+		if e != nil {
+			return nil, probe.NewError(e).Trace(aliasedURL)
+		}
+		w.zw = zw
+		contentType = "application/zstd"
+	}
+	if w.zw != nil {
+		w.tw = tar.NewWriter(w.zw)
+	} else {
+		w.tw = tar.NewWriter(pw)
+	}
+
+	archiveWriters.Lock()
+	defer archiveWriters.Unlock()
+	if _, ok := archiveWriters.m[w.key]; ok {
+		return nil, errInvalidTarget(aliasedURL).Trace(aliasedURL)
+	}
+	archiveWriters.m[w.key] = w
+
+	go func() {
+		_, err := clnt.Put(ctx, pr, -1, map[string]string{"Content-Type": contentType}, nil, nil, false, false, false)
+		if err != nil {
+			pr.CloseWithError(err.ToGoError())
+		} else {
+			pr.Close()
+		}
+		w.doneCh <- err
+	}()
+	return w, nil
+}
+
+// add - writes an entry of known size.
+func (w *archiveWriter) add(hdr *tar.Header, reader io.Reader) (int64, *probe.Error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.err != nil {
+		return 0, probe.NewError(w.err)
+	}
+
+	if e := w.tw.WriteHeader(hdr); e != nil {
+		w.err = e
+		return 0, probe.NewError(e)
+	}
+	n, e := io.Copy(w.tw, io.LimitReader(reader, hdr.Size))
+	if e == nil && n < hdr.Size {
+		e = UnexpectedEOF{TotalSize: hdr.Size, TotalWritten: n}
+	}
+	if e != nil {
+		// A truncated entry corrupts the rest of the stream.
+		w.err = e
+		return n, probe.NewError(e)
+	}
+
+	w.entries = append(w.entries, archiveEntry{
+		Name:    hdr.Name,
+		Size:    hdr.Size,
+		ModTime: hdr.ModTime,
+		Mode:    os.FileMode(hdr.Mode).Perm(),
+		Pos:     len(w.entries),
+	})
+	return n, nil
+}
+
+// index - returns the entries written so far sorted by name.
+func (w *archiveWriter) index() []archiveEntry {
+	w.Lock()
+	defer w.Unlock()
+	entries := make([]archiveEntry, len(w.entries))
+	copy(entries, w.entries)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// Close - completes the archive and waits for its upload, an aborted
+// archive is discarded.
+func (w *archiveWriter) Close(abort bool) *probe.Error {
+	archiveWriters.Lock()
+	delete(archiveWriters.m, w.key)
+	archiveWriters.Unlock()
+
+	w.Lock()
+	e := w.err
+	if e == nil && abort {
+		e = errArchiveAborted
+	}
+	if e == nil {
+		e = w.tw.Close()
+	}
+	if e == nil && w.zw != nil {
+		e = w.zw.Close()
+	}
+	if e != nil {
+		w.pw.CloseWithError(e)
+	} else {
+		w.pw.Close()
+	}
+	w.Unlock()
+
+	err := <-w.doneCh
+	if abort {
+		return nil
+	}
+	if err != nil {
+		return err.Trace(w.key)
+	}
+	if e != nil {
+		return probe.NewError(e).Trace(w.key)
+	}
+	return nil
+}
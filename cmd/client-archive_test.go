@@ -0,0 +1,234 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// archiveGet - reads an entry of an archive.
+func archiveGet(c *C, urlStr string) string {
+	clnt, err := newClient(urlStr)
+	c.Assert(err, IsNil)
+	reader, err := clnt.Get(context.Background(), GetOptions{})
+	c.Assert(err, IsNil)
+	defer reader.Close()
+	data, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	return string(data)
+}
+
+// archiveListURLs - lists the URLs of an archive prefix.
+func archiveListURLs(c *C, urlStr string, recursive bool) (urls []string) {
+	for _, content := range memList(c, urlStr, ListOptions{Recursive: recursive}) {
+		urls = append(urls, content.URL.String())
+	}
+	return urls
+}
+
+// TestArchiveURL - tests locating archives in URLs.
+func (s *TestSuite) TestArchiveURL(c *C) {
+	clnt, err := newClient("mem://archive-url")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+	memPut(c, "mem://archive-url/dir/backup.tar.gz", "archive")
+	memPut(c, "mem://archive-url/backup.tar", "archive")
+	// A prefix named like an archive.
+	memPut(c, "mem://archive-url/logs.tar/file", "file")
+
+	loc, ok := parseArchiveLocation("mem://archive-url/dir/backup.tar.gz/sub/file", nil)
+	c.Assert(ok, Equals, true)
+	c.Assert(loc.archiveURL(), Equals, "mem://archive-url/dir/backup.tar.gz")
+	c.Assert(loc.entry, Equals, "sub/file")
+	c.Assert(loc.format, Equals, archiveTarGzip)
+
+	// Archives are only browsed with a trailing separator.
+	c.Assert(isArchiveURL("mem://archive-url/backup.tar", nil), Equals, false)
+	c.Assert(isArchiveURL("mem://archive-url/backup.tar/", nil), Equals, true)
+	// Buckets are never archives.
+	c.Assert(isArchiveURL("mem://backup.zip/file", nil), Equals, false)
+	c.Assert(isArchiveURL("mem://archive-url/.tar/file", nil), Equals, false)
+	// Only existing objects are archives.
+	c.Assert(isArchiveURL("mem://archive-url/missing.tar/file", nil), Equals, false)
+	c.Assert(isArchiveURL("mem://archive-url/logs.tar/file", nil), Equals, false)
+	st, err := memStat(c, "mem://archive-url/logs.tar/file")
+	c.Assert(err, IsNil)
+	c.Assert(st.Type.IsRegular(), Equals, true)
+
+	c.Assert(isArchiveWriteTarget("play/bucket/backup.tar.zst"), Equals, true)
+	c.Assert(isArchiveWriteTarget("play/bucket/backup.zip"), Equals, false)
+}
+
+// TestArchiveTar - tests browsing and reading a compressed tar archive.
+func (s *TestSuite) TestArchiveTar(c *C) {
+	clnt, err := newClient("mem://archive-tar")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, name := range []string{"./dir/a.txt", "b.txt", "dir/sub/c.txt"} {
+		c.Assert(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(name))}), IsNil)
+		_, e := tw.Write([]byte(name))
+		c.Assert(e, IsNil)
+	}
+	c.Assert(tw.Close(), IsNil)
+	c.Assert(gw.Close(), IsNil)
+	memPut(c, "mem://archive-tar/backup.tgz", buf.String())
+
+	c.Assert(archiveListURLs(c, "mem://archive-tar/backup.tgz/", false), DeepEquals, []string{
+		"mem://archive-tar/backup.tgz/b.txt",
+		"mem://archive-tar/backup.tgz/dir/",
+	})
+	c.Assert(archiveListURLs(c, "mem://archive-tar/backup.tgz/dir/", true), DeepEquals, []string{
+		"mem://archive-tar/backup.tgz/dir/a.txt",
+		"mem://archive-tar/backup.tgz/dir/sub/c.txt",
+	})
+
+	st, err := memStat(c, "mem://archive-tar/backup.tgz/dir")
+	c.Assert(err, IsNil)
+	c.Assert(st.Type.IsDir(), Equals, true)
+	st, err = memStat(c, "mem://archive-tar/backup.tgz/dir/sub/c.txt")
+	c.Assert(err, IsNil)
+	c.Assert(st.Size, Equals, int64(len("dir/sub/c.txt")))
+	_, err = memStat(c, "mem://archive-tar/backup.tgz/missing")
+	c.Assert(err, NotNil)
+
+	c.Assert(archiveGet(c, "mem://archive-tar/backup.tgz/dir/a.txt"), Equals, "./dir/a.txt")
+	c.Assert(archiveGet(c, "mem://archive-tar/backup.tgz/dir/sub/c.txt"), Equals, "dir/sub/c.txt")
+
+	// Archives are read only.
+	clnt, err = newClient("mem://archive-tar/backup.tgz/new.txt")
+	c.Assert(err, IsNil)
+	_, err = clnt.Put(context.Background(), strings.NewReader("new"), 3, nil, nil, nil, false, false, false)
+	c.Assert(err, NotNil)
+}
+
+// TestArchiveExtractor - tests reading the entries of a tar archive
+// in a single pass.
+func (s *TestSuite) TestArchiveExtractor(c *C) {
+	clnt, err := newClient("mem://archive-extract")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range []string{"a.txt", "b.txt", "dir/c.txt"} {
+		c.Assert(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(name))}), IsNil)
+		_, e := tw.Write([]byte(name))
+		c.Assert(e, IsNil)
+	}
+	c.Assert(tw.Close(), IsNil)
+	memPut(c, "mem://archive-extract/backup.tar", buf.String())
+
+	x, err := openArchiveExtractor(context.Background(), "mem://archive-extract/backup.tar/")
+	c.Assert(err, IsNil)
+	c.Assert(x, NotNil)
+	// Sources of the same archive share the extraction.
+	other, err := openArchiveExtractor(context.Background(), "mem://archive-extract/backup.tar/dir/")
+	c.Assert(err, IsNil)
+	c.Assert(other, IsNil)
+
+	c.Assert(archiveGet(c, "mem://archive-extract/backup.tar/b.txt"), Equals, "b.txt")
+	c.Assert(x.next, Equals, 2)
+	c.Assert(archiveGet(c, "mem://archive-extract/backup.tar/dir/c.txt"), Equals, "dir/c.txt")
+	c.Assert(x.next, Equals, 3)
+	// Entries already passed are streamed on their own.
+	c.Assert(archiveGet(c, "mem://archive-extract/backup.tar/a.txt"), Equals, "a.txt")
+	c.Assert(x.next, Equals, 3)
+
+	c.Assert(x.Close(), IsNil)
+	clnt, err = newClient("mem://archive-extract/backup.tar/b.txt")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.(*archiveClient).extractor(), IsNil)
+	c.Assert(archiveGet(c, "mem://archive-extract/backup.tar/b.txt"), Equals, "b.txt")
+
+	// Objects other than tar archives are not extracted.
+	x, err = openArchiveExtractor(context.Background(), "mem://archive-extract/")
+	c.Assert(err, IsNil)
+	c.Assert(x, IsNil)
+}
+
+// TestArchiveZip - tests reading a zip archive.
+func (s *TestSuite) TestArchiveZip(c *C) {
+	clnt, err := newClient("mem://archive-zip")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"photos/1.jpg", "photos/2.jpg"} {
+		w, e := zw.Create(name)
+		c.Assert(e, IsNil)
+		_, e = w.Write([]byte(strings.Repeat(name, 100)))
+		c.Assert(e, IsNil)
+	}
+	c.Assert(zw.Close(), IsNil)
+	memPut(c, "mem://archive-zip/photos.zip", buf.String())
+
+	c.Assert(archiveListURLs(c, "mem://archive-zip/photos.zip/photos/", false), DeepEquals, []string{
+		"mem://archive-zip/photos.zip/photos/1.jpg",
+		"mem://archive-zip/photos.zip/photos/2.jpg",
+	})
+	c.Assert(archiveGet(c, "mem://archive-zip/photos.zip/photos/2.jpg"), Equals, strings.Repeat("photos/2.jpg", 100))
+}
+
+// TestArchiveWriter - tests streaming entries into a new archive.
+func (s *TestSuite) TestArchiveWriter(c *C) {
+	clnt, err := newClient("mem://archive-writer")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+
+	_, err = openArchiveWriter(context.Background(), "mem://archive-writer/out.zip")
+	c.Assert(err, NotNil)
+
+	w, err := openArchiveWriter(context.Background(), "mem://archive-writer/out.tar.zst")
+	c.Assert(err, IsNil)
+	c.Assert(archiveRootURL("mem://archive-writer/out.tar.zst"), Equals, "mem://archive-writer/out.tar.zst/")
+
+	for _, name := range []string{"b/file", "a"} {
+		clnt, err = newClient("mem://archive-writer/out.tar.zst/" + name)
+		c.Assert(err, IsNil)
+		n, err := clnt.Put(context.Background(), strings.NewReader(name), int64(len(name)), nil, nil, nil, false, false, false)
+		c.Assert(err, IsNil)
+		c.Assert(n, Equals, int64(len(name)))
+	}
+	// Entries written so far are listed.
+	c.Assert(archiveListURLs(c, "mem://archive-writer/out.tar.zst/", true), DeepEquals, []string{
+		"mem://archive-writer/out.tar.zst/a",
+		"mem://archive-writer/out.tar.zst/b/file",
+	})
+	c.Assert(w.Close(false), IsNil)
+
+	c.Assert(archiveGet(c, "mem://archive-writer/out.tar.zst/b/file"), Equals, "b/file")
+
+	// Aborted archives are not stored.
+	w, err = openArchiveWriter(context.Background(), "mem://archive-writer/aborted.tar")
+	c.Assert(err, IsNil)
+	c.Assert(w.Close(true), IsNil)
+	_, err = memStat(c, "mem://archive-writer/aborted.tar")
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,254 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// clientCapability - optional Client operations, a backend lists
+// the ones it supports.
+type clientCapability string
+
+const (
+	capSelect       clientCapability = "select"
+	capObjectLock   clientCapability = "object lock"
+	capRetention    clientCapability = "retention"
+	capLegalHold    clientCapability = "legal hold"
+	capTagging      clientCapability = "tagging"
+	capLifecycle    clientCapability = "lifecycle"
+	capVersioning   clientCapability = "versioning"
+	capReplication  clientCapability = "replication"
+	capEncryption   clientCapability = "encryption"
+	capAccessPolicy clientCapability = "access policy"
+	capShare        clientCapability = "share"
+	capWatch        clientCapability = "watch"
+	capBucketInfo   clientCapability = "bucket info"
+)
+
+// allClientCapabilities - every optional capability, used by
+// backends implementing the full Client interface.
+var allClientCapabilities = []clientCapability{
+	capSelect, capObjectLock, capRetention, capLegalHold, capTagging,
+	capLifecycle, capVersioning, capReplication, capEncryption,
+	capAccessPolicy, capShare, capWatch, capBucketInfo,
+}
+
+// clientFactory - instantiates a new Client for an expanded URL,
+// aliasCfg is nil when the URL does not belong to any alias.
+type clientFactory func(urlStr string, aliasCfg *aliasConfigV10) (Client, *probe.Error)
+
+// clientBackend - a storage backend which can serve URLs either
+// through its URL schemes or through alias `api` values.
+type clientBackend struct {
+	// Name of the backend as reported in errors.
+	Name string
+	// URL schemes served for URLs without a matching alias.
+	Schemes []string
+	// Alias `api` values served, matched case insensitively.
+	APIs []string
+	// Match, if set, serves URLs regardless of their alias or scheme,
+	// used by backends layered over the other backends.
+	Match func(urlStr string, aliasCfg *aliasConfigV10) bool
+	// Optional operations supported by this backend.
+	Capabilities []clientCapability
+	// New instantiates a client for this backend.
+	New clientFactory
+}
+
+// Supports - returns true if the backend supports the capability.
+func (b clientBackend) Supports(c clientCapability) bool {
+	for _, capability := range b.Capabilities {
+		if capability == c {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	fsBackendName = "filesystem"
+	s3BackendName = "S3"
+)
+
+// clientBackends - list of all registered backends, a new backend
+// is added here and is then reachable through newClient.
+var clientBackends = []clientBackend{
+	{
+		Name:         fsBackendName,
+		Capabilities: []clientCapability{capAccessPolicy, capWatch},
+		New: func(urlStr string, _ *aliasConfigV10) (Client, *probe.Error) {
+			return fsNew(urlStr)
+		},
+	},
+	{
+		Name:         s3BackendName,
+		APIs:         []string{"S3v4", "S3v2"},
+		Capabilities: allClientCapabilities,
+		New: func(urlStr string, aliasCfg *aliasConfigV10) (Client, *probe.Error) {
+			return S3New(NewS3Config(urlStr, aliasCfg))
+		},
+	},
+	{
+		Name:    memBackendName,
+		Schemes: []string{memScheme},
+		Capabilities: []clientCapability{
+			capObjectLock, capRetention, capLegalHold, capTagging, capLifecycle,
+			capVersioning, capReplication, capEncryption, capAccessPolicy, capBucketInfo,
+		},
+		New: func(urlStr string, _ *aliasConfigV10) (Client, *probe.Error) {
+			return memNew(urlStr)
+		},
+	},
+	{
+		Name:    httpBackendName,
+		Schemes: []string{"http", "https"},
+		New: func(urlStr string, _ *aliasConfigV10) (Client, *probe.Error) {
+			return httpNew(urlStr)
+		},
+	},
+	{
+		Name: sftpBackendName,
+		APIs: []string{sftpAPI},
+		New:  sftpNew,
+	},
+}
+
+// getClientBackendByName - returns the backend registered with name.
+func getClientBackendByName(name string) (clientBackend, bool) {
+	for _, b := range clientBackends {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return clientBackend{}, false
+}
+
+// getClientBackendByScheme - returns the backend serving URL scheme.
+func getClientBackendByScheme(scheme string) (clientBackend, bool) {
+	if scheme == "" {
+		return clientBackend{}, false
+	}
+	for _, b := range clientBackends {
+		for _, s := range b.Schemes {
+			if strings.EqualFold(s, scheme) {
+				return b, true
+			}
+		}
+	}
+	return clientBackend{}, false
+}
+
+// getClientBackendByAPI - returns the backend serving alias api value,
+// empty api defaults to S3.
+func getClientBackendByAPI(api string) (clientBackend, bool) {
+	if api == "" {
+		return getClientBackendByName(s3BackendName)
+	}
+	for _, b := range clientBackends {
+		for _, a := range b.APIs {
+			if strings.EqualFold(a, api) {
+				return b, true
+			}
+		}
+	}
+	return clientBackend{}, false
+}
+
+// getClientBackend - returns the backend serving an expanded URL.
+func getClientBackend(urlStr string, aliasCfg *aliasConfigV10) clientBackend {
+	for _, b := range clientBackends {
+		if b.Match != nil && b.Match(urlStr, aliasCfg) {
+			return b
+		}
+	}
+	return getStorageBackend(urlStr, aliasCfg)
+}
+
+// getStorageBackend - returns the backend serving an expanded URL
+// through its alias `api` value or its scheme.
+func getStorageBackend(urlStr string, aliasCfg *aliasConfigV10) clientBackend {
+	if aliasCfg != nil {
+		if b, ok := getClientBackendByAPI(aliasCfg.API); ok {
+			return b
+		}
+		// Unrecognized api values were always served by S3.
+		b, _ := getClientBackendByName(s3BackendName)
+		return b
+	}
+	scheme, _ := getScheme(urlStr)
+	if b, ok := getClientBackendByScheme(scheme); ok {
+		return b
+	}
+	// No matching alias or scheme. So we treat it like a filesystem.
+	b, _ := getClientBackendByName(fsBackendName)
+	return b
+}
+
+// clientBackendAPIs - all alias api values served by registered backends.
+func clientBackendAPIs() (apis []string) {
+	for _, b := range clientBackends {
+		apis = append(apis, b.APIs...)
+	}
+	return apis
+}
+
+// checkClientCapability - verifies that the backend serving aliasedURL
+// supports the capability, returns APINotImplemented otherwise.
+func checkClientCapability(aliasedURL string, c clientCapability) *probe.Error {
+	_, urlStrFull, aliasCfg, err := expandAlias(aliasedURL)
+	if err != nil {
+		return err.Trace(aliasedURL)
+	}
+	b := getClientBackend(urlStrFull, aliasCfg)
+	if !b.Supports(c) {
+		return probe.NewError(APINotImplemented{
+			API:     string(c),
+			APIType: b.Name,
+		})
+	}
+	return nil
+}
+
+// newClientWithCapability - instantiates a new client for aliasedURL,
+// failing early if its backend does not support the capability.
+func newClientWithCapability(aliasedURL string, c clientCapability) (Client, *probe.Error) {
+	if err := checkClientCapability(aliasedURL, c); err != nil {
+		return nil, err.Trace(aliasedURL)
+	}
+	return newClient(aliasedURL)
+}
+
+// clientWrapper - a client layered over another client, such as the
+// read cache or client-side encryption.
+type clientWrapper interface {
+	unwrap() Client
+}
+
+// unwrapClient - returns the backend client under all layers of clnt,
+// used to reach backend specific operations.
+func unwrapClient(clnt Client) Client {
+	for {
+		w, ok := clnt.(clientWrapper)
+		if !ok {
+			return clnt
+		}
+		clnt = w.unwrap()
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"testing"
+)
+
+// Tests that URLs are served by the expected backend.
+func TestGetClientBackend(t *testing.T) {
+	testCases := []struct {
+		urlStr   string
+		aliasCfg *aliasConfigV10
+		backend  string
+	}{
+		{"/tmp/dir", nil, fsBackendName},
+		{"mem://bucket/object", nil, memBackendName},
+		{"https://example.com/file.tar.gz", nil, httpBackendName},
+		{"https://play.min.io/bucket", &aliasConfigV10{API: "S3v4"}, s3BackendName},
+		{"https://play.min.io/bucket", &aliasConfigV10{API: "s3v2"}, s3BackendName},
+		{"https://play.min.io/bucket", &aliasConfigV10{}, s3BackendName},
+		{"mem://bucket/backup.tar/file", nil, archiveBackendName},
+		{"mem://bucket/backup.tar", nil, memBackendName},
+	}
+
+	for i, testCase := range testCases {
+		b := getClientBackend(testCase.urlStr, testCase.aliasCfg)
+		if b.Name != testCase.backend {
+			t.Fatalf("Test %d: expected backend %s, got %s", i+1, testCase.backend, b.Name)
+		}
+	}
+}
+
+// Tests backend capabilities.
+func TestClientBackendSupports(t *testing.T) {
+	fs, _ := getClientBackendByName(fsBackendName)
+	equalAssert(fs.Supports(capWatch), true, t)
+	equalAssert(fs.Supports(capLifecycle), false, t)
+
+	s3, _ := getClientBackendByName(s3BackendName)
+	equalAssert(s3.Supports(capLifecycle), true, t)
+	equalAssert(s3.Supports(capReplication), true, t)
+
+	archive, _ := getClientBackendByName(archiveBackendName)
+	equalAssert(archive.Supports(capTagging), false, t)
+}
+
+// Tests unsupported operations report the backend.
+func TestUnsupportedClient(t *testing.T) {
+	clnt, err := httpNew("https://example.com/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = clnt.GetTags(context.Background(), "")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	e, ok := err.ToGoError().(APINotImplemented)
+	if !ok {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if e.API != "GetTags" || e.APIType != httpBackendName {
+		t.Fatalf("unexpected error %v", e)
+	}
+}
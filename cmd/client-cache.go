@@ -0,0 +1,420 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v7"
+)
+
+const (
+	// Default size cap of the read cache.
+	defaultCacheQuota = 10 * humanize.GiByte
+
+	cacheDataExt = ".data"
+	cacheMetaExt = ".json"
+)
+
+// getCacheDir - get read cache directory.
+func getCacheDir() (string, *probe.Error) {
+	configDir, err := getMcConfigDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+	return filepath.Join(configDir, globalCacheDir), nil
+}
+
+// getCacheQuota - returns the size cap of the read cache, set with
+// MC_CACHE_QUOTA.
+func getCacheQuota() (int64, *probe.Error) {
+	quotaStr := os.Getenv("MC_CACHE_QUOTA")
+	if quotaStr == "" {
+		return defaultCacheQuota, nil
+	}
+	quota, e := humanize.ParseBytes(quotaStr)
+	if e != nil {
+		return 0, probe.NewError(e).Trace(quotaStr)
+	}
+	return int64(quota), nil
+}
+
+// globalCacheStore - read cache shared by all clients of this process.
+var globalCacheStore struct {
+	sync.Once
+	store *cacheStore
+	err   *probe.Error
+}
+
+// getCacheStore - returns the read cache configured for this process.
+func getCacheStore() (*cacheStore, *probe.Error) {
+	globalCacheStore.Do(func() {
+		dir, err := getCacheDir()
+		if err != nil {
+			globalCacheStore.err = err.Trace()
+			return
+		}
+		quota, err := getCacheQuota()
+		if err != nil {
+			globalCacheStore.err = err.Trace()
+			return
+		}
+		globalCacheStore.store = newCacheStore(dir, quota)
+	})
+	return globalCacheStore.store, globalCacheStore.err
+}
+
+// cacheEntry - metadata of a cached object, saved next to its data.
+type cacheEntry struct {
+	URL       string `json:"url"`
+	VersionID string `json:"versionId,omitempty"`
+	ETag      string `json:"etag"`
+	Size      int64  `json:"size"`
+
+	// Last access of the entry, tracked through the data file mtime.
+	LastAccess time.Time `json:"-"`
+	// Path of the data file.
+	dataPath string
+}
+
+// cacheKey - returns the content key of an object version.
+func cacheKey(urlStr, versionID, etag string) string {
+	sum := sha256.Sum256([]byte(urlStr + "\x00" + versionID + "\x00" + etag))
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheStore - on-disk store of cached objects, evicted in least
+// recently used order once over quota.
+type cacheStore struct {
+	// Serializes evictions of this process.
+	sync.Mutex
+	dir   string
+	quota int64
+	// Running total size of the entries, the store is only
+	// walked to size it on first commit and to evict.
+	total int64
+	sized bool
+}
+
+// newCacheStore - returns a store of cached objects rooted at dir.
+func newCacheStore(dir string, quota int64) *cacheStore {
+	return &cacheStore{dir: dir, quota: quota}
+}
+
+// path - returns the path of a cache key without extension.
+func (s *cacheStore) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key)
+}
+
+// open - returns the data of a cached entry, its access time is
+// refreshed on every hit.
+func (s *cacheStore) open(key string, size int64) (*os.File, bool) {
+	dataPath := s.path(key) + cacheDataExt
+	f, e := os.Open(dataPath)
+	if e != nil {
+		return nil, false
+	}
+	if fi, e := f.Stat(); e != nil || fi.Size() != size {
+		f.Close()
+		return nil, false
+	}
+	now := time.Now()
+	os.Chtimes(dataPath, now, now)
+	return f, true
+}
+
+// create - returns a temporary file receiving the data of a new entry.
+func (s *cacheStore) create(key string) (*os.File, *probe.Error) {
+	dir := filepath.Dir(s.path(key))
+	if e := os.MkdirAll(dir, 0700); e != nil {
+		return nil, probe.NewError(e)
+	}
+	f, e := ioutil.TempFile(dir, key+".tmp-")
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return f, nil
+}
+
+// commit - moves a complete temporary file in place and evicts
+// older entries when over quota.
+func (s *cacheStore) commit(key string, tmp *os.File, entry cacheEntry) *probe.Error {
+	defer os.Remove(tmp.Name())
+	if e := tmp.Close(); e != nil {
+		return probe.NewError(e)
+	}
+	metaBytes, e := json.Marshal(entry)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	p := s.path(key)
+	var replaced int64
+	if fi, e := os.Stat(p + cacheDataExt); e == nil {
+		replaced = fi.Size()
+	}
+	if e = ioutil.WriteFile(p+cacheMetaExt, metaBytes, 0600); e != nil {
+		return probe.NewError(e)
+	}
+	if e = os.Rename(tmp.Name(), p+cacheDataExt); e != nil {
+		os.Remove(p + cacheMetaExt)
+		return probe.NewError(e)
+	}
+	return s.add(entry.Size - replaced)
+}
+
+// add - accounts for size added to the store and evicts older
+// entries once the running total is over quota.
+func (s *cacheStore) add(size int64) *probe.Error {
+	s.Lock()
+	defer s.Unlock()
+
+	if !s.sized {
+		entries, err := s.entries()
+		if err != nil {
+			return err.Trace(s.dir)
+		}
+		s.total = 0
+		for _, entry := range entries {
+			s.total += entry.Size
+		}
+		s.sized = true
+	} else {
+		s.total += size
+	}
+	if s.total <= s.quota {
+		return nil
+	}
+	return s.evict()
+}
+
+// entries - returns all complete entries of the store, least
+// recently used first.
+func (s *cacheStore) entries() (entries []cacheEntry, err *probe.Error) {
+	e := filepath.Walk(s.dir, func(p string, fi os.FileInfo, e error) error {
+		if e != nil {
+			if os.IsNotExist(e) {
+				return nil
+			}
+			return e
+		}
+		if fi.IsDir() || !strings.HasSuffix(p, cacheDataExt) {
+			return nil
+		}
+		metaBytes, e := ioutil.ReadFile(strings.TrimSuffix(p, cacheDataExt) + cacheMetaExt)
+		if e != nil {
+			// Entries being written or removed are skipped.
+			return nil
+		}
+		var entry cacheEntry
+		if json.Unmarshal(metaBytes, &entry) != nil {
+			return nil
+		}
+		entry.Size = fi.Size()
+		entry.LastAccess = fi.ModTime()
+		entry.dataPath = p
+		entries = append(entries, entry)
+		return nil
+	})
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastAccess.Before(entries[j].LastAccess)
+	})
+	return entries, nil
+}
+
+// remove - removes an entry from the store.
+func (s *cacheStore) remove(entry cacheEntry) *probe.Error {
+	if e := os.Remove(entry.dataPath); e != nil && !os.IsNotExist(e) {
+		return probe.NewError(e)
+	}
+	os.Remove(strings.TrimSuffix(entry.dataPath, cacheDataExt) + cacheMetaExt)
+	return nil
+}
+
+// evict - removes least recently used entries until the store fits
+// its quota, the running total is updated with the entries found since
+// other processes share the store. Callers must hold the store lock.
+func (s *cacheStore) evict() *probe.Error {
+	entries, err := s.entries()
+	if err != nil {
+		return err.Trace(s.dir)
+	}
+	s.total = 0
+	for _, entry := range entries {
+		s.total += entry.Size
+	}
+	for _, entry := range entries {
+		if s.total <= s.quota {
+			break
+		}
+		if err = s.remove(entry); err != nil {
+			return err.Trace(entry.dataPath)
+		}
+		s.total -= entry.Size
+	}
+	return nil
+}
+
+// cacheFillReader - reads an object from its backend while filling
+// the cache, the entry is only kept once read completely.
+type cacheFillReader struct {
+	io.ReadCloser
+	ctx   context.Context
+	clnt  Client
+	store *cacheStore
+	key   string
+	tmp   *os.File
+	entry cacheEntry
+	n     int64
+}
+
+// Read - reads from the backend and copies data into the cache.
+func (r *cacheFillReader) Read(p []byte) (n int, e error) {
+	n, e = r.ReadCloser.Read(p)
+	if r.tmp == nil {
+		return n, e
+	}
+	if n > 0 {
+		if _, we := r.tmp.Write(p[:n]); we != nil {
+			r.discard()
+			return n, e
+		}
+		r.n += int64(n)
+	}
+	if e == io.EOF {
+		if r.n == r.entry.Size && r.validate() {
+			tmp := r.tmp
+			r.tmp = nil
+			// Failing to cache does not fail the read.
+			r.store.commit(r.key, tmp, r.entry)
+		} else {
+			r.discard()
+		}
+	}
+	return n, e
+}
+
+// objectStatReader - reader reporting the object it serves, such as
+// the readers of S3 objects.
+type objectStatReader interface {
+	Stat() (minio.ObjectInfo, error)
+}
+
+// validate - returns true if the object read has the ETag of the
+// entry, the object may have been overwritten between the Stat and
+// the Get of the entry.
+func (r *cacheFillReader) validate() bool {
+	if sr, ok := r.ReadCloser.(objectStatReader); ok {
+		info, e := sr.Stat()
+		return e == nil && strings.Trim(info.ETag, "\"") == r.entry.ETag
+	}
+	// The object is looked up again otherwise.
+	st, err := r.clnt.Stat(r.ctx, StatOptions{versionID: r.entry.VersionID})
+	return err == nil && st.ETag == r.entry.ETag
+}
+
+// discard - drops a partially read entry.
+func (r *cacheFillReader) discard() {
+	if r.tmp != nil {
+		r.tmp.Close()
+		os.Remove(r.tmp.Name())
+		r.tmp = nil
+	}
+}
+
+// Close - closes the backend reader.
+func (r *cacheFillReader) Close() error {
+	r.discard()
+	return r.ReadCloser.Close()
+}
+
+// Cache client, objects read through Get are saved on disk and served
+// again while their ETag is unchanged. All other operations go to the
+// wrapped client.
+type cacheClient struct {
+	Client
+	store *cacheStore
+}
+
+// cacheNew - wraps clnt into a client caching its reads in store.
+func cacheNew(clnt Client, store *cacheStore) Client {
+	return &cacheClient{Client: clnt, store: store}
+}
+
+// unwrap - returns the cached client.
+func (c *cacheClient) unwrap() Client {
+	return c.Client
+}
+
+// Get - returns a reader of the cached object if its ETag still
+// matches, the object is read and cached otherwise.
+func (c *cacheClient) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *probe.Error) {
+	// Objects encrypted with customer keys are never stored in clear.
+	if opts.SSE != nil {
+		return c.Client.Get(ctx, opts)
+	}
+
+	st, err := c.Client.Stat(ctx, StatOptions{versionID: opts.VersionID})
+	if err != nil || st.ETag == "" || !st.Type.IsRegular() {
+		return c.Client.Get(ctx, opts)
+	}
+
+	urlStr := c.Client.GetURL().String()
+	key := cacheKey(urlStr, opts.VersionID, st.ETag)
+	if f, ok := c.store.open(key, st.Size); ok {
+		return f, nil
+	}
+
+	reader, err := c.Client.Get(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := c.store.create(key)
+	if err != nil {
+		// Reads never fail because of the cache.
+		return reader, nil
+	}
+	return &cacheFillReader{
+		ReadCloser: reader,
+		ctx:        ctx,
+		clnt:       c.Client,
+		store:      c.store,
+		key:        key,
+		tmp:        tmp,
+		entry: cacheEntry{
+			URL:       urlStr,
+			VersionID: opts.VersionID,
+			ETag:      st.ETag,
+			Size:      st.Size,
+		},
+	}, nil
+}
@@ -0,0 +1,169 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+// cacheGet - reads an object through the cache, returns whether it
+// was served from the cache.
+func cacheGet(c *C, store *cacheStore, urlStr string) (string, bool) {
+	clnt, err := newClient(urlStr)
+	c.Assert(err, IsNil)
+	reader, err := cacheNew(clnt, store).Get(context.Background(), GetOptions{})
+	c.Assert(err, IsNil)
+	defer reader.Close()
+	data, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	_, hit := reader.(*os.File)
+	return string(data), hit
+}
+
+// TestCacheGet - tests serving and revalidating cached objects.
+func (s *TestSuite) TestCacheGet(c *C) {
+	dir, e := ioutil.TempDir("", "mc-cache-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(dir)
+	store := newCacheStore(dir, 1<<20)
+
+	clnt, err := newClient("mem://cache-get")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+	memPut(c, "mem://cache-get/object", "first")
+
+	data, hit := cacheGet(c, store, "mem://cache-get/object")
+	c.Assert(data, Equals, "first")
+	c.Assert(hit, Equals, false)
+	data, hit = cacheGet(c, store, "mem://cache-get/object")
+	c.Assert(data, Equals, "first")
+	c.Assert(hit, Equals, true)
+
+	// A new ETag invalidates the cached object.
+	memPut(c, "mem://cache-get/object", "second")
+	data, hit = cacheGet(c, store, "mem://cache-get/object")
+	c.Assert(data, Equals, "second")
+	c.Assert(hit, Equals, false)
+
+	entries, err := store.entries()
+	c.Assert(err, IsNil)
+	c.Assert(len(entries), Equals, 2)
+	c.Assert(entries[0].URL, Equals, "mem://cache-get/object")
+}
+
+// TestCacheEvict - tests evicting least recently used objects.
+func (s *TestSuite) TestCacheEvict(c *C) {
+	dir, e := ioutil.TempDir("", "mc-cache-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(dir)
+	store := newCacheStore(dir, 10)
+
+	clnt, err := newClient("mem://cache-evict")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+	memPut(c, "mem://cache-evict/a", "aaaaa")
+	memPut(c, "mem://cache-evict/b", "bbbbb")
+	memPut(c, "mem://cache-evict/c", "ccccc")
+
+	cacheGet(c, store, "mem://cache-evict/a")
+	cacheGet(c, store, "mem://cache-evict/b")
+	// Make sure access times differ on coarse filesystems.
+	past := time.Now().Add(-time.Hour)
+	entries, err := store.entries()
+	c.Assert(err, IsNil)
+	for _, entry := range entries {
+		if entry.URL == "mem://cache-evict/b" {
+			c.Assert(os.Chtimes(entry.dataPath, past, past), IsNil)
+		}
+	}
+	cacheGet(c, store, "mem://cache-evict/c")
+
+	entries, err = store.entries()
+	c.Assert(err, IsNil)
+	var urls []string
+	for _, entry := range entries {
+		urls = append(urls, entry.URL)
+	}
+	c.Assert(len(urls), Equals, 2)
+	for _, u := range urls {
+		c.Assert(u, Not(Equals), "mem://cache-evict/b")
+	}
+	c.Assert(store.total, Equals, int64(10))
+}
+
+// racingClient - overwrites the object between the Stat and the Get
+// of a cache miss.
+type racingClient struct {
+	Client
+	overwrite func()
+}
+
+// Get - overwrites the object then reads it.
+func (r racingClient) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *probe.Error) {
+	r.overwrite()
+	return r.Client.Get(ctx, opts)
+}
+
+// TestCacheGetRace - tests objects overwritten while read are not
+// cached under the ETag of their previous content.
+func (s *TestSuite) TestCacheGetRace(c *C) {
+	dir, e := ioutil.TempDir("", "mc-cache-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(dir)
+	store := newCacheStore(dir, 1<<20)
+
+	clnt, err := newClient("mem://cache-race")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+	memPut(c, "mem://cache-race/object", "first")
+
+	clnt, err = newClient("mem://cache-race/object")
+	c.Assert(err, IsNil)
+	reader, err := cacheNew(racingClient{clnt, func() {
+		memPut(c, "mem://cache-race/object", "second")
+	}}, store).Get(context.Background(), GetOptions{})
+	c.Assert(err, IsNil)
+	data, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	reader.Close()
+	c.Assert(string(data), Equals, "second")
+
+	entries, err := store.entries()
+	c.Assert(err, IsNil)
+	c.Assert(len(entries), Equals, 0)
+
+	data2, hit := cacheGet(c, store, "mem://cache-race/object")
+	c.Assert(data2, Equals, "second")
+	c.Assert(hit, Equals, false)
+}
+
+// TestUnwrapClient - tests reaching the backend client under the cache
+// and client-side encryption.
+func (s *TestSuite) TestUnwrapClient(c *C) {
+	clnt, err := newClient("mem://cache-unwrap")
+	c.Assert(err, IsNil)
+	wrapped := cseNew(cacheNew(clnt, newCacheStore(c.MkDir(), 1<<20)), "", []byte("32byteslongsecretkeymustbegiven1"))
+	_, ok := unwrapClient(wrapped).(*memClient)
+	c.Assert(ok, Equals, true)
+}
@@ -0,0 +1,198 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/minio/mc/pkg/probe"
+)
+
+const (
+	// Metadata marking objects compressed by mc, its value names
+	// the codec.
+	compressMetaKey = "X-Amz-Meta-Mc-Compression"
+	// Metadata holding the size of the object before compression,
+	// absent when it was not known at upload time.
+	compressSizeMetaKey = "X-Amz-Meta-Mc-Compression-Size"
+)
+
+// Supported compression codecs.
+const (
+	compressZstd = "zstd"
+	compressGzip = "gzip"
+	compressS2   = "s2"
+)
+
+// compressCodecs - returns the names of the supported codecs.
+func compressCodecs() []string {
+	return []string{compressZstd, compressGzip, compressS2}
+}
+
+// isValidCompression - returns true if codec is supported.
+func isValidCompression(codec string) bool {
+	for _, c := range compressCodecs() {
+		if c == codec {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCompressFlag - exits when --compress names an unsupported codec.
+func checkCompressFlag(codec string) {
+	if codec != "" && !isValidCompression(codec) {
+		fatalIf(errInvalidArgument().Trace(codec), "`--compress` should be one of `"+strings.Join(compressCodecs(), "`, `")+"`.")
+	}
+}
+
+// metadataValue - returns the value of key in metadata, keys of user
+// metadata being matched with or without their X-Amz-Meta- prefix.
+func metadataValue(metadata map[string]string, key string) string {
+	for k, v := range metadata {
+		k = http.CanonicalHeaderKey(k)
+		if k == key || "X-Amz-Meta-"+k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+// streamCompression - returns the codec and original size recorded
+// in the metadata of a compressed object, an empty codec for objects
+// stored as is and a size of -1 when it is unknown.
+func streamCompression(metadata map[string]string) (codec string, size int64) {
+	codec = metadataValue(metadata, compressMetaKey)
+	if !isValidCompression(codec) {
+		return "", -1
+	}
+	size, e := strconv.ParseInt(metadataValue(metadata, compressSizeMetaKey), 10, 64)
+	if e != nil {
+		size = -1
+	}
+	return codec, size
+}
+
+// contentCompression - returns the codec and original size of a
+// listed or stat'ed object, see streamCompression.
+func contentCompression(content *ClientContent) (codec string, size int64) {
+	if codec, size = streamCompression(content.Metadata); codec != "" {
+		return codec, size
+	}
+	return streamCompression(content.UserMetadata)
+}
+
+// contentSize - returns the size of content before compression when
+// it was listed with its compression metadata, its size otherwise.
+func contentSize(content *ClientContent) int64 {
+	if codec, size := contentCompression(content); codec != "" && size >= 0 {
+		return size
+	}
+	return content.Size
+}
+
+// filterCompressionMetadata - drops the compression metadata of a
+// decompressed object before it is written elsewhere.
+func filterCompressionMetadata(metadata map[string]string) {
+	for k := range metadata {
+		switch strings.TrimPrefix(http.CanonicalHeaderKey(k), "X-Amz-Meta-") {
+		case "Mc-Compression", "Mc-Compression-Size":
+			delete(metadata, k)
+		}
+	}
+}
+
+// compressStream - returns a reader of reader compressed with codec
+// and records the codec in metadata, along with size when known.
+// Closing the returned reader stops the compression.
+func compressStream(reader io.Reader, codec string, size int64, metadata map[string]string) io.ReadCloser {
+	metadata[compressMetaKey] = codec
+	if size >= 0 {
+		metadata[compressSizeMetaKey] = strconv.FormatInt(size, 10)
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		var w io.WriteCloser
+		var e error
+		switch codec {
+		case compressZstd:
+			w, e = zstd.NewWriter(pw)
+		case compressGzip:
+			w = gzip.NewWriter(pw)
+		case compressS2:
+			w = s2.NewWriter(pw)
+		default:
+			e = errors.New("unsupported compression `" + codec + "`")
+		}
+		if e == nil {
+			_, e = io.Copy(w, reader)
+			if ce := w.Close(); e == nil {
+				e = ce
+			}
+		}
+		pw.CloseWithError(e)
+	}()
+	return pr
+}
+
+// decompressReader - reads a decompressed stream, closing it
+// releases the decoder and closes the compressed stream.
+type decompressReader struct {
+	io.Reader
+	release func()
+	source  io.Closer
+}
+
+// Close - closes the compressed stream.
+func (d *decompressReader) Close() error {
+	if d.release != nil {
+		d.release()
+	}
+	return d.source.Close()
+}
+
+// decompressStream - returns a reader of reader decompressed with
+// codec, closing it closes reader.
+func decompressStream(reader io.ReadCloser, codec string) (io.ReadCloser, *probe.Error) {
+	d := &decompressReader{source: reader}
+	switch codec {
+	case compressZstd:
+		dec, e := zstd.NewReader(reader)
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		d.Reader, d.release = dec, dec.Close
+	case compressGzip:
+		dec, e := gzip.NewReader(reader)
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		d.Reader = dec
+	case compressS2:
+		d.Reader = s2.NewReader(reader)
+	default:
+		return nil, probe.NewError(errors.New("unsupported compression `" + codec + "`"))
+	}
+	return d, nil
+}
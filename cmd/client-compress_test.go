@@ -0,0 +1,106 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// TestCompressStream - tests streams of every codec are read back
+// as written.
+func (s *TestSuite) TestCompressStream(c *C) {
+	plain := strings.Repeat("GET /index.html 200\n", 1000)
+	for _, codec := range compressCodecs() {
+		metadata := map[string]string{}
+		compReader := compressStream(strings.NewReader(plain), codec, int64(len(plain)), metadata)
+		data, e := ioutil.ReadAll(compReader)
+		c.Assert(e, IsNil)
+		c.Assert(len(data) < len(plain), Equals, true)
+
+		gotCodec, size := streamCompression(metadata)
+		c.Assert(gotCodec, Equals, codec)
+		c.Assert(size, Equals, int64(len(plain)))
+
+		decReader, err := decompressStream(ioutil.NopCloser(bytes.NewReader(data)), codec)
+		c.Assert(err, IsNil)
+		data, e = ioutil.ReadAll(decReader)
+		c.Assert(e, IsNil)
+		c.Assert(decReader.Close(), IsNil)
+		c.Assert(string(data), Equals, plain)
+
+		filterCompressionMetadata(metadata)
+		c.Assert(metadata, DeepEquals, map[string]string{})
+	}
+	c.Assert(isValidCompression("lz4"), Equals, false)
+}
+
+// TestCompressCopy - tests objects are compressed on upload and
+// decompressed when copied to the filesystem.
+func (s *TestSuite) TestCompressCopy(c *C) {
+	ctx := context.Background()
+	plain := strings.Repeat("level=info msg=\"request served\"\n", 1000)
+
+	clnt, err := newClient("mem://compress")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(ctx, "", false, false), IsNil)
+	memPut(c, "mem://compress/app.log", plain)
+
+	src, err := memStat(c, "mem://compress/app.log")
+	c.Assert(err, IsNil)
+	urls := uploadSourceToTargetURL(ctx, URLs{
+		SourceContent: src,
+		TargetContent: &ClientContent{URL: *newClientURL("mem://compress/app.log.zst")},
+		Compress:      compressZstd,
+	}, nil, nil, false)
+	c.Assert(urls.Error, IsNil)
+
+	st, err := memStat(c, "mem://compress/app.log.zst")
+	c.Assert(err, IsNil)
+	c.Assert(st.Size < int64(len(plain)), Equals, true)
+	c.Assert(st.UserMetadata["Mc-Compression"], Equals, compressZstd)
+	c.Assert(st.UserMetadata["Mc-Compression-Size"], Equals, strconv.Itoa(len(plain)))
+	c.Assert(contentSize(st), Equals, int64(len(plain)))
+
+	// Compressed objects are not compressed twice.
+	urls = uploadSourceToTargetURL(ctx, URLs{
+		SourceContent: st,
+		TargetContent: &ClientContent{URL: *newClientURL("mem://compress/copy.zst")},
+		Compress:      compressGzip,
+	}, nil, nil, false)
+	c.Assert(urls.Error, IsNil)
+	cp, err := memStat(c, "mem://compress/copy.zst")
+	c.Assert(err, IsNil)
+	c.Assert(cp.UserMetadata["Mc-Compression"], Equals, compressZstd)
+	c.Assert(cp.Size, Equals, st.Size)
+
+	target := filepath.Join(c.MkDir(), "app.log")
+	urls = uploadSourceToTargetURL(ctx, URLs{
+		SourceContent: st,
+		TargetContent: &ClientContent{URL: *newClientURL(target)},
+	}, nil, nil, false)
+	c.Assert(urls.Error, IsNil)
+	data, e := ioutil.ReadFile(target)
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, plain)
+}
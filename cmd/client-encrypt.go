@@ -0,0 +1,454 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/hookreader"
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/minio/pkg/s3select"
+	"github.com/minio/sio"
+)
+
+const (
+	// Metadata marking objects encrypted on the client side, its
+	// value names the encryption scheme.
+	clientEncryptionMetaKey = "X-Amz-Meta-Mc-Client-Encryption"
+	// Metadata holding the random IV the object key is derived from.
+	clientEncryptionIVMetaKey = "X-Amz-Meta-Mc-Client-Encryption-Iv"
+
+	// Objects are sealed with DARE 2.0, keyed by HMAC-SHA256(key, IV).
+	clientEncryptionScheme = "DARE-2.0-HMAC-SHA256"
+	clientEncryptionIVLen  = 32
+	clientEncryptionKeyLen = 32
+)
+
+// parseClientEncryptionKeys - parses comma separated alias/prefix=key
+// values, keys being 32 bytes long, into pairs of alias to prefix and
+// client-side encryption key.
+func parseClientEncryptionKeys(cseKeys string) (encMap map[string][]prefixSSEPair, err *probe.Error) {
+	encMap = make(map[string][]prefixSSEPair)
+	index := 0 // start index of prefix
+	k := len(cseKeys)
+	for index < k {
+		i := strings.Index(cseKeys[index:], "=")
+		if i == -1 {
+			return nil, probe.NewError(errors.New("Client-side encryption prefix should be of the form prefix1=key1,... "))
+		}
+		prefix := cseKeys[index : index+i]
+		vs := index + i + 1
+		if vs+clientEncryptionKeyLen > k {
+			return nil, probe.NewError(errors.New("Client-side encryption key should be 32 bytes long"))
+		}
+		if vs+clientEncryptionKeyLen < k && cseKeys[vs+clientEncryptionKeyLen] != ',' {
+			return nil, probe.NewError(errors.New("Client-side encryption prefix=secret should be delimited by , and secret should be 32 bytes long"))
+		}
+		alias, _ := url2Alias(prefix)
+		encMap[alias] = append(encMap[alias], prefixSSEPair{
+			Prefix:    prefix,
+			ClientKey: []byte(cseKeys[vs : vs+clientEncryptionKeyLen]),
+		})
+		// advance past the key and its delimiter
+		index = vs + clientEncryptionKeyLen + 1
+	}
+	return encMap, nil
+}
+
+// getClientEncryptionKeys - returns the client-side encryption keys of
+// --encrypt-client-key, or MC_ENCRYPT_CLIENT_KEY when unset.
+func getClientEncryptionKeys(ctx *cli.Context) string {
+	if cseKeys := ctx.String("encrypt-client-key"); cseKeys != "" {
+		return cseKeys
+	}
+	return os.Getenv("MC_ENCRYPT_CLIENT_KEY")
+}
+
+// addClientEncryptionKeys - adds the client-side encryption keys given
+// as prefix=key values, like --encrypt-key, to encKeyDB.
+func addClientEncryptionKeys(encKeyDB map[string][]prefixSSEPair, cseKeys string) *probe.Error {
+	if cseKeys == "" {
+		return nil
+	}
+	cseKeys, err := getDecodedKey(cseKeys)
+	if err != nil {
+		return err.Trace()
+	}
+	encMap, err := parseClientEncryptionKeys(cseKeys)
+	if err != nil {
+		return err.Trace()
+	}
+	for alias, ps := range encMap {
+		if hostCfg := mustGetHostConfig(alias); hostCfg == nil {
+			return probe.NewError(errors.New("Client-side encryption prefix " + ps[0].Prefix + " has invalid alias"))
+		}
+		encKeyDB[alias] = append(encKeyDB[alias], ps...)
+		sort.Sort(byPrefixLength(encKeyDB[alias]))
+	}
+	return nil
+}
+
+// getClientEncKey - returns the client-side encryption key of an
+// aliased resource, nil if objects under it are stored as is.
+func getClientEncKey(resource string, encKeys []prefixSSEPair) []byte {
+	for _, k := range encKeys {
+		if k.ClientKey != nil && strings.HasPrefix(resource, k.Prefix) {
+			return k.ClientKey
+		}
+	}
+	return nil
+}
+
+// getClientEncKeyFromAlias - returns the client-side encryption key
+// of urlStr of alias.
+func getClientEncKeyFromAlias(alias, urlStr string, encKeyDB map[string][]prefixSSEPair) []byte {
+	if len(encKeyDB[alias]) == 0 {
+		return nil
+	}
+	resource := filepath.ToSlash(filepath.Join(alias, newClientURL(urlStr).Path))
+	return getClientEncKey(resource, encKeyDB[alias])
+}
+
+// withClientEncryption - wraps clnt of alias into a client-side
+// encryption client when key is set.
+func withClientEncryption(clnt Client, alias string, key []byte) Client {
+	if key == nil {
+		return clnt
+	}
+	return cseNew(clnt, alias, key)
+}
+
+// newClientFromAliasWithKeys - returns the client of urlStr of alias,
+// encrypting on the client side when encKeyDB has a key for it.
+func newClientFromAliasWithKeys(alias, urlStr string, encKeyDB map[string][]prefixSSEPair) (Client, *probe.Error) {
+	clnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		return nil, err
+	}
+	return withClientEncryption(clnt, alias, getClientEncKeyFromAlias(alias, urlStr, encKeyDB)), nil
+}
+
+// newClientWithKeys - returns the client of an aliased URL, encrypting
+// on the client side when encKeyDB has a key for it.
+func newClientWithKeys(aliasedURL string, encKeyDB map[string][]prefixSSEPair) (Client, *probe.Error) {
+	clnt, err := newClient(aliasedURL)
+	if err != nil {
+		return nil, err
+	}
+	alias, urlStrFull, _, err := expandAlias(aliasedURL)
+	if err != nil {
+		return nil, err.Trace(aliasedURL)
+	}
+	return withClientEncryption(clnt, alias, getClientEncKeyFromAlias(alias, urlStrFull, encKeyDB)), nil
+}
+
+// clientEncryptionIV - returns the IV of an object encrypted on the
+// client side, false for objects stored as is.
+func clientEncryptionIV(content *ClientContent) ([]byte, bool) {
+	lookup := func(key string) string {
+		if v, ok := content.Metadata[key]; ok {
+			return v
+		}
+		if v, ok := content.UserMetadata[key]; ok {
+			return v
+		}
+		return content.UserMetadata[strings.TrimPrefix(key, "X-Amz-Meta-")]
+	}
+	if lookup(clientEncryptionMetaKey) != clientEncryptionScheme {
+		return nil, false
+	}
+	iv, e := base64.StdEncoding.DecodeString(lookup(clientEncryptionIVMetaKey))
+	if e != nil || len(iv) != clientEncryptionIVLen {
+		return nil, false
+	}
+	return iv, true
+}
+
+// isClientEncryptionMetaKey - returns true for metadata keys of the
+// client-side encryption.
+func isClientEncryptionMetaKey(key string) bool {
+	switch strings.TrimPrefix(http.CanonicalHeaderKey(key), "X-Amz-Meta-") {
+	case "Mc-Client-Encryption", "Mc-Client-Encryption-Iv":
+		return true
+	}
+	return false
+}
+
+// filterClientEncryptionMetadata - drops the client-side encryption
+// metadata of a decrypted object before it is written elsewhere.
+func filterClientEncryptionMetadata(metadata map[string]string) {
+	for k := range metadata {
+		if isClientEncryptionMetaKey(k) {
+			delete(metadata, k)
+		}
+	}
+}
+
+// Client-side encryption client, objects are encrypted before Put and
+// decrypted after Get so that the server never sees plaintext nor keys.
+// Objects without the client-side encryption marker cannot be read,
+// they are copied from a prefix without a key to be encrypted.
+type cseClient struct {
+	Client
+	alias string
+	key   []byte
+}
+
+// cseNew - wraps clnt of alias into a client encrypting objects with key.
+func cseNew(clnt Client, alias string, key []byte) Client {
+	return &cseClient{Client: clnt, alias: alias, key: key}
+}
+
+// unwrap - returns the client storing the encrypted objects.
+func (c *cseClient) unwrap() Client {
+	return c.Client
+}
+
+// config - returns the DARE configuration of an object IV.
+func (c *cseClient) config(iv []byte) sio.Config {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(iv)
+	return sio.Config{
+		MinVersion: sio.Version20,
+		MaxVersion: sio.Version20,
+		Key:        mac.Sum(nil),
+	}
+}
+
+// decryptContent - reports the plaintext size of objects whose
+// metadata marks them as encrypted.
+func decryptContent(content *ClientContent) {
+	if content.Err != nil || !content.Type.IsRegular() {
+		return
+	}
+	if _, ok := clientEncryptionIV(content); !ok {
+		return
+	}
+	if size, e := sio.DecryptedSize(uint64(content.Size)); e == nil {
+		content.Size = int64(size)
+	}
+}
+
+// Stat - returns the object metadata, sizes of encrypted objects
+// are plaintext sizes.
+func (c *cseClient) Stat(ctx context.Context, opts StatOptions) (*ClientContent, *probe.Error) {
+	content, err := c.Client.Stat(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	decryptContent(content)
+	return content, nil
+}
+
+// List - lists objects with their metadata, sizes of encrypted objects
+// are plaintext sizes. Objects listed without metadata by backends not
+// supporting it are looked up one by one.
+func (c *cseClient) List(ctx context.Context, opts ListOptions) <-chan *ClientContent {
+	opts.WithMetadata = true
+	contentCh := make(chan *ClientContent)
+	go func() {
+		defer close(contentCh)
+		for content := range c.Client.List(ctx, opts) {
+			if content.Err == nil && content.Type.IsRegular() &&
+				len(content.Metadata) == 0 && len(content.UserMetadata) == 0 {
+				c.statContent(ctx, content)
+			}
+			decryptContent(content)
+			select {
+			case contentCh <- content:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return contentCh
+}
+
+// statContent - fills the metadata of a listed object.
+func (c *cseClient) statContent(ctx context.Context, content *ClientContent) {
+	clnt, err := newClientFromAlias(c.alias, content.URL.String())
+	if err != nil {
+		return
+	}
+	st, err := clnt.Stat(ctx, StatOptions{versionID: content.VersionID})
+	if err != nil {
+		return
+	}
+	content.Metadata = st.Metadata
+	content.UserMetadata = st.UserMetadata
+}
+
+// Get - returns a reader of the decrypted object.
+func (c *cseClient) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *probe.Error) {
+	st, err := c.Client.Stat(ctx, StatOptions{versionID: opts.VersionID, sse: opts.SSE})
+	if err != nil {
+		return nil, err
+	}
+	// Objects stored as is could have been written by anyone with
+	// access to the bucket, they are never taken for decrypted ones.
+	iv, ok := clientEncryptionIV(st)
+	if !ok {
+		return nil, probe.NewError(ObjectNotClientEncrypted{Object: c.GetURL().String()})
+	}
+	reader, err := c.Client.Get(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	decReader, e := sio.DecryptReader(reader, c.config(iv))
+	if e != nil {
+		reader.Close()
+		return nil, probe.NewError(e)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{decReader, reader}, nil
+}
+
+// Put - encrypts reader under a new object key before upload.
+func (c *cseClient) Put(ctx context.Context, reader io.Reader, size int64, metadata map[string]string, progress io.Reader, sse encrypt.ServerSide, md5, disableMultipart, isPreserve bool) (int64, *probe.Error) {
+	iv := make([]byte, clientEncryptionIVLen)
+	if _, e := io.ReadFull(rand.Reader, iv); e != nil {
+		return 0, probe.NewError(e)
+	}
+	encMetadata := make(map[string]string, len(metadata)+2)
+	for k, v := range metadata {
+		encMetadata[k] = v
+	}
+	encMetadata[clientEncryptionMetaKey] = clientEncryptionScheme
+	encMetadata[clientEncryptionIVMetaKey] = base64.StdEncoding.EncodeToString(iv)
+
+	encSize := int64(-1)
+	if size >= 0 {
+		n, e := sio.EncryptedSize(uint64(size))
+		if e != nil {
+			return 0, probe.NewError(e)
+		}
+		encSize = int64(n)
+	}
+	// Progress is reported on plaintext.
+	encReader, e := sio.EncryptReader(hookreader.NewHook(reader, progress), c.config(iv))
+	if e != nil {
+		return 0, probe.NewError(e)
+	}
+	n, err := c.Client.Put(ctx, encReader, encSize, encMetadata, nil, sse, md5, disableMultipart, isPreserve)
+	if err != nil {
+		return 0, err
+	}
+	if decSize, e := sio.DecryptedSize(uint64(n)); e == nil {
+		n = int64(decSize)
+	}
+	return n, nil
+}
+
+// Compose - encrypted objects cannot be concatenated on the server.
+func (c *cseClient) Compose(ctx context.Context, sources []string, opts CopyOptions, progress io.Reader) *probe.Error {
+	return probe.NewError(APINotImplemented{
+		API:     "Compose",
+		APIType: "client-side encrypted " + c.GetURL().String(),
+	})
+}
+
+// Select - encrypted objects are decrypted and queried locally.
+func (c *cseClient) Select(ctx context.Context, expression string, sse encrypt.ServerSide, selOpts SelectObjectOpts) (io.ReadCloser, *probe.Error) {
+	st, err := c.Client.Stat(ctx, StatOptions{sse: sse})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := clientEncryptionIV(st); !ok {
+		return nil, probe.NewError(ObjectNotClientEncrypted{Object: c.GetURL().String()})
+	}
+
+	object := path.Base(filepath.ToSlash(c.GetURL().Path))
+	opts := minio.SelectObjectOptions{
+		Expression:     expression,
+		ExpressionType: minio.QueryExpressionTypeSQL,
+	}
+	opts.InputSerialization = selectObjectInputOpts(selOpts, object)
+	opts.OutputSerialization = selectObjectOutputOpts(selOpts, opts.InputSerialization)
+	reqBytes, e := xml.Marshal(opts)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	s3Select, e := s3select.NewS3Select(bytes.NewReader(reqBytes))
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	e = s3Select.Open(func(offset, length int64) (io.ReadCloser, error) {
+		reader, err := c.Get(ctx, GetOptions{SSE: sse})
+		if err != nil {
+			return nil, err.ToGoError()
+		}
+		if _, e := io.CopyN(ioutil.Discard, reader, offset); e != nil {
+			reader.Close()
+			return nil, e
+		}
+		if length < 0 {
+			return reader, nil
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(reader, length), reader}, nil
+	})
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+
+	// Results are encoded as the server would, and decoded by the
+	// same reader as server side queries.
+	pr, pw := io.Pipe()
+	go func() {
+		s3Select.Evaluate(&selectResponseWriter{PipeWriter: pw, header: http.Header{}})
+		s3Select.Close()
+		pw.Close()
+	}()
+	results, e := minio.NewSelectResults(&http.Response{StatusCode: http.StatusOK, Body: pr}, "")
+	if e != nil {
+		pr.Close()
+		return nil, probe.NewError(e)
+	}
+	return results, nil
+}
+
+// selectResponseWriter - collects the event stream of a local query.
+type selectResponseWriter struct {
+	*io.PipeWriter
+	header http.Header
+}
+
+func (w *selectResponseWriter) Header() http.Header { return w.header }
+func (w *selectResponseWriter) WriteHeader(int)     {}
+func (w *selectResponseWriter) Flush()              {}
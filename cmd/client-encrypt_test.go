@@ -0,0 +1,115 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+// TestClientEncryption - tests objects are stored encrypted and read
+// back decrypted.
+func (s *TestSuite) TestClientEncryption(c *C) {
+	ctx := context.Background()
+	key := []byte("32byteslongsecretkeymustbegiven1")
+	plain := "end-to-end encrypted content"
+
+	clnt, err := newClient("mem://cse")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(ctx, "", false, false), IsNil)
+
+	raw, err := newClient("mem://cse/object")
+	c.Assert(err, IsNil)
+	n, err := cseNew(raw, "", key).Put(ctx, bytes.NewReader([]byte(plain)), int64(len(plain)),
+		map[string]string{}, nil, nil, false, false, false)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, int64(len(plain)))
+
+	// The server only sees ciphertext.
+	reader, err := raw.Get(ctx, GetOptions{})
+	c.Assert(err, IsNil)
+	data, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	reader.Close()
+	c.Assert(bytes.Contains(data, []byte(plain)), Equals, false)
+
+	st, err := cseNew(raw, "", key).Stat(ctx, StatOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(st.Size, Equals, int64(len(plain)))
+	c.Assert(st.UserMetadata["Mc-Client-Encryption"], Equals, clientEncryptionScheme)
+
+	reader, err = cseNew(raw, "", key).Get(ctx, GetOptions{})
+	c.Assert(err, IsNil)
+	data, e = ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	reader.Close()
+	c.Assert(string(data), Equals, plain)
+
+	// Objects stored as is are refused.
+	memPut(c, "mem://cse/plain", plain)
+	raw, err = newClient("mem://cse/plain")
+	c.Assert(err, IsNil)
+	_, err = cseNew(raw, "", key).Get(ctx, GetOptions{})
+	c.Assert(err, NotNil)
+	_, ok := err.ToGoError().(ObjectNotClientEncrypted)
+	c.Assert(ok, Equals, true)
+
+	// Only objects marked as encrypted report plaintext sizes.
+	sizes := map[string]int64{}
+	for content := range cseNew(clnt, "", key).List(ctx, ListOptions{Recursive: true}) {
+		c.Assert(content.Err, IsNil)
+		sizes[content.URL.Path] = content.Size
+	}
+	c.Assert(sizes, DeepEquals, map[string]int64{
+		"/cse/object": int64(len(plain)),
+		"/cse/plain":  int64(len(plain)),
+	})
+	c.Assert(parseStat(st).ClientEncryption, Equals, clientEncryptionScheme)
+
+	// Reading with another key fails.
+	raw, err = newClient("mem://cse/object")
+	c.Assert(err, IsNil)
+	reader, err = cseNew(raw, "", []byte("32byteslongsecretkeymustbegiven2")).Get(ctx, GetOptions{})
+	c.Assert(err, IsNil)
+	_, e = ioutil.ReadAll(reader)
+	c.Assert(e, NotNil)
+	reader.Close()
+}
+
+// TestParseClientEncryptionKeys - tests malformed client-side
+// encryption keys are rejected.
+func (s *TestSuite) TestParseClientEncryptionKeys(c *C) {
+	for _, keys := range []string{
+		"myminio/bucket",
+		"myminio/bucket=tooshort",
+		"myminio/bucket=32byteslongsecretkeymustbegiven1;myminio/other=32byteslongsecretkeymustbegiven2",
+	} {
+		_, err := parseClientEncryptionKeys(keys)
+		c.Assert(err, NotNil)
+	}
+
+	encMap, err := parseClientEncryptionKeys("myminio/bucket/=32byteslongsecretkeymustbegiven1")
+	c.Assert(err, IsNil)
+	c.Assert(len(encMap["myminio"]), Equals, 1)
+	// Client keys are never returned as SSE keys.
+	c.Assert(getSSE("myminio/bucket/object", encMap["myminio"]), IsNil)
+	c.Assert(string(getClientEncKey("myminio/bucket/object", encMap["myminio"])), Equals, "32byteslongsecretkeymustbegiven1")
+	c.Assert(getClientEncKey("myminio/other/object", encMap["myminio"]), IsNil)
+}
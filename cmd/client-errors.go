@@ -102,6 +102,16 @@ func (e ObjectOnGlacier) Error() string {
 	return "Object `" + e.Object + "` is on Glacier storage."
 }
 
+// ObjectNotClientEncrypted - object under a client-side encrypted
+// prefix stored without encryption.
+type ObjectNotClientEncrypted struct {
+	Object string
+}
+
+func (e ObjectNotClientEncrypted) Error() string {
+	return "Object `" + e.Object + "` is not encrypted on the client side."
+}
+
 // BucketNameTopLevel - generic error
 type BucketNameTopLevel struct{}
 
@@ -206,6 +216,23 @@ func (e UnexpectedExcessRead) Error() string {
 	return msg
 }
 
+// ObjectRemoveFailed - removal of an object or of one of its versions
+// failed with Err.
+type ObjectRemoveFailed struct {
+	Bucket    string
+	Object    string
+	VersionID string
+	Err       error
+}
+
+func (e ObjectRemoveFailed) Error() string {
+	return e.Err.Error()
+}
+
+func (e ObjectRemoveFailed) Unwrap() error {
+	return e.Err
+}
+
 // SameFile - source and destination are same files.
 type SameFile struct {
 	Source, Destination string
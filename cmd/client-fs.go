@@ -29,7 +29,6 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
-	"time"
 
 	"github.com/pkg/xattr"
 	"github.com/rjeczalik/notify"
@@ -38,16 +37,14 @@ import (
 	"github.com/minio/mc/pkg/hookreader"
 	"github.com/minio/mc/pkg/ioutils"
 	"github.com/minio/mc/pkg/probe"
-	minio "github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/encrypt"
-	"github.com/minio/minio-go/v7/pkg/lifecycle"
 	"github.com/minio/minio-go/v7/pkg/notification"
-	"github.com/minio/minio-go/v7/pkg/replication"
 	"github.com/minio/minio/pkg/console"
 )
 
 // filesystem client
 type fsClient struct {
+	unsupportedClient
 	PathURL *ClientURL
 }
 
@@ -71,7 +68,8 @@ func fsNew(path string) (Client, *probe.Error) {
 		return nil, probe.NewError(EmptyPath{})
 	}
 	return &fsClient{
-		PathURL: newClientURL(normalizePath(path)),
+		unsupportedClient: unsupportedClient{backend: fsBackendName},
+		PathURL:           newClientURL(normalizePath(path)),
 	}, nil
 }
 
@@ -122,14 +120,6 @@ func (f *fsClient) GetURL() ClientURL {
 	return *f.PathURL
 }
 
-// Select replies a stream of query results.
-func (f *fsClient) Select(ctx context.Context, expression string, sse encrypt.ServerSide, opts SelectObjectOpts) (io.ReadCloser, *probe.Error) {
-	return nil, probe.NewError(APINotImplemented{
-		API:     "Select",
-		APIType: "filesystem",
-	})
-}
-
 // Watches for all fs events on an input path.
 func (f *fsClient) Watch(ctx context.Context, options WatchOptions) (*WatchObject, *probe.Error) {
 	eventChan := make(chan []EventInfo)
@@ -378,22 +368,6 @@ func (f *fsClient) Put(ctx context.Context, reader io.Reader, size int64, metada
 	return f.put(ctx, reader, size, metadata, progress, preserve)
 }
 
-// ShareDownload - share download not implemented for filesystem.
-func (f *fsClient) ShareDownload(ctx context.Context, versionID string, expires time.Duration) (string, *probe.Error) {
-	return "", probe.NewError(APINotImplemented{
-		API:     "ShareDownload",
-		APIType: "filesystem",
-	})
-}
-
-// ShareUpload - share upload not implemented for filesystem.
-func (f *fsClient) ShareUpload(ctx context.Context, startsWith bool, expires time.Duration, contentType string) (string, map[string]string, *probe.Error) {
-	return "", nil, probe.NewError(APINotImplemented{
-		API:     "ShareUpload",
-		APIType: "filesystem",
-	})
-}
-
 // Copy - copy data from source to destination
 func (f *fsClient) Copy(ctx context.Context, source string, opts CopyOptions, progress io.Reader) *probe.Error {
 	rc, e := os.Open(source)
@@ -410,6 +384,26 @@ func (f *fsClient) Copy(ctx context.Context, source string, opts CopyOptions, pr
 	return nil
 }
 
+// Compose concatenates local source files into the target file.
+func (f *fsClient) Compose(ctx context.Context, sources []string, opts CopyOptions, progress io.Reader) *probe.Error {
+	readers := make([]io.Reader, 0, len(sources))
+	for _, source := range sources {
+		rc, e := os.Open(source)
+		if e != nil {
+			err := f.toClientError(e, source)
+			return err.Trace(source)
+		}
+		defer rc.Close()
+		readers = append(readers, rc)
+	}
+
+	destination := f.PathURL.Path
+	if _, err := f.put(ctx, io.MultiReader(readers...), opts.size, opts.metadata, progress, false); err != nil {
+		return err.Trace(append([]string{destination}, sources...)...)
+	}
+	return nil
+}
+
 // Get returns reader and any additional metadata.
 func (f *fsClient) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *probe.Error) {
 	fileData, e := os.Open(f.PathURL.Path)
@@ -888,73 +882,18 @@ func (f *fsClient) MakeBucket(ctx context.Context, region string, ignoreExisting
 	return nil
 }
 
-// Set object lock configuration of bucket.
-func (f *fsClient) SetObjectLockConfig(ctx context.Context, mode minio.RetentionMode, validity uint64, unit minio.ValidityUnit) *probe.Error {
-	return probe.NewError(APINotImplemented{
-		API:     "SetObjectLockConfig",
-		APIType: "filesystem",
-	})
-}
-
-// Get object lock configuration of bucket.
-func (f *fsClient) GetObjectLockConfig(ctx context.Context) (status string, mode minio.RetentionMode, validity uint64, unit minio.ValidityUnit, err *probe.Error) {
-	return "", "", 0, "", probe.NewError(APINotImplemented{
-		API:     "GetObjectLockConfig",
-		APIType: "filesystem",
-	})
-}
-
-// GetAccessRules - unsupported API
-func (f *fsClient) GetAccessRules(ctx context.Context) (map[string]string, *probe.Error) {
-	return map[string]string{}, probe.NewError(APINotImplemented{
-		API:     "GetBucketPolicy",
-		APIType: "filesystem",
-	})
-}
-
-// Set object retention for a given object.
-func (f *fsClient) PutObjectRetention(ctx context.Context, versionID string, mode minio.RetentionMode, retainUntilDate time.Time, bypassGovernance bool) *probe.Error {
-	return probe.NewError(APINotImplemented{
-		API:     "PutObjectRetention",
-		APIType: "filesystem",
-	})
-}
-
-func (f *fsClient) GetObjectRetention(ctx context.Context, versionID string) (minio.RetentionMode, time.Time, *probe.Error) {
-	return "", time.Time{}, probe.NewError(APINotImplemented{
-		API:     "GetObjectRetention",
-		APIType: "filesystem",
-	})
-}
-
-// Set object legal hold for a given object.
-func (f *fsClient) PutObjectLegalHold(ctx context.Context, versionID string, lhold minio.LegalHoldStatus) *probe.Error {
-	return probe.NewError(APINotImplemented{
-		API:     "PutObjectLegalHold",
-		APIType: "filesystem",
-	})
-}
-
-// Get object legal hold for a given object.
-func (f *fsClient) GetObjectLegalHold(ctx context.Context, versionID string) (minio.LegalHoldStatus, *probe.Error) {
-	return "", probe.NewError(APINotImplemented{
-		API:     "GetObjectLegalHold",
-		APIType: "filesystem",
-	})
-}
-
 // GetAccess - get access policy permissions.
 func (f *fsClient) GetAccess(ctx context.Context) (access string, policyJSON string, err *probe.Error) {
 	// For windows this feature is not implemented.
 	if runtime.GOOS == "windows" {
-		return "", "", probe.NewError(APINotImplemented{API: "GetAccess", APIType: "filesystem"})
+		return "", "", f.notImplemented("GetAccess")
 	}
 	st, err := f.fsStat(false)
 	if err != nil {
 		return "", "", err.Trace(f.PathURL.String())
 	}
 	if !st.Mode().IsDir() {
-		return "", "", probe.NewError(APINotImplemented{API: "GetAccess", APIType: "filesystem"})
+		return "", "", f.notImplemented("GetAccess")
 	}
 	// Mask with os.ModePerm to get only inode permissions
 	switch st.Mode() & os.ModePerm {
@@ -973,14 +912,14 @@ func (f *fsClient) SetAccess(ctx context.Context, access string, isJSON bool) *p
 	// For windows this feature is not implemented.
 	// JSON policy for fs is not yet implemented.
 	if runtime.GOOS == "windows" || isJSON {
-		return probe.NewError(APINotImplemented{API: "SetAccess", APIType: "filesystem"})
+		return f.notImplemented("SetAccess")
 	}
 	st, err := f.fsStat(false)
 	if err != nil {
 		return err.Trace(f.PathURL.String())
 	}
 	if !st.Mode().IsDir() {
-		return probe.NewError(APINotImplemented{API: "SetAccess", APIType: "filesystem"})
+		return f.notImplemented("SetAccess")
 	}
 	var mode os.FileMode
 	switch access {
@@ -1075,116 +1014,3 @@ func (f *fsClient) fsStat(isIncomplete bool) (os.FileInfo, *probe.Error) {
 
 func (f *fsClient) AddUserAgent(_, _ string) {
 }
-
-// Get Object Tags
-func (f *fsClient) GetTags(ctx context.Context, _ string) (map[string]string, *probe.Error) {
-	return nil, probe.NewError(APINotImplemented{
-		API:     "GetObjectTagging",
-		APIType: "filesystem",
-	})
-}
-
-// Set Object tags
-func (f *fsClient) SetTags(ctx context.Context, versionID, tags string) *probe.Error {
-	return probe.NewError(APINotImplemented{
-		API:     "SetObjectTagging",
-		APIType: "filesystem",
-	})
-}
-
-// Delete object tags
-func (f *fsClient) DeleteTags(ctx context.Context, versionID string) *probe.Error {
-	return probe.NewError(APINotImplemented{
-		API:     "DeleteObjectTagging",
-		APIType: "filesystem",
-	})
-}
-
-// Get lifecycle configuration for a given bucket, not implemented.
-func (f *fsClient) GetLifecycle(ctx context.Context) (*lifecycle.Configuration, *probe.Error) {
-	return nil, probe.NewError(APINotImplemented{
-		API:     "GetLifecycle",
-		APIType: "filesystem",
-	})
-}
-
-// Set lifecycle configuration for a given bucket, not implemented.
-func (f *fsClient) SetLifecycle(ctx context.Context, _ *lifecycle.Configuration) *probe.Error {
-	return probe.NewError(APINotImplemented{
-		API:     "SetLifecycle",
-		APIType: "filesystem",
-	})
-}
-
-// Get version info for a bucket, not implemented.
-func (f *fsClient) GetVersion(ctx context.Context) (minio.BucketVersioningConfiguration, *probe.Error) {
-	return minio.BucketVersioningConfiguration{}, probe.NewError(APINotImplemented{
-		API:     "GetVersion",
-		APIType: "filesystem",
-	})
-}
-
-// SetVersion - Set version configuration on a bucket, not implemented
-func (f *fsClient) SetVersion(ctx context.Context, status string) *probe.Error {
-	return probe.NewError(APINotImplemented{
-		API:     "SetVersion",
-		APIType: "filesystem",
-	})
-}
-
-// Get replication configuration for a given bucket, not implemented.
-func (f *fsClient) GetReplication(ctx context.Context) (replication.Config, *probe.Error) {
-	return replication.Config{}, probe.NewError(APINotImplemented{
-		API:     "GetReplication",
-		APIType: "filesystem",
-	})
-}
-
-// Set replication configuration for a given bucket, not implemented.
-func (f *fsClient) SetReplication(ctx context.Context, cfg *replication.Config, opts replication.Options) *probe.Error {
-	return probe.NewError(APINotImplemented{
-		API:     "SetReplication",
-		APIType: "filesystem",
-	})
-}
-
-// Remove replication configuration for a given bucket. Not implemented
-func (f *fsClient) RemoveReplication(ctx context.Context) *probe.Error {
-	return probe.NewError(APINotImplemented{
-		API:     "RemoveReplication",
-		APIType: "filesystem",
-	})
-
-}
-
-// Get encryption info for a bucket, not implemented.
-func (f *fsClient) GetEncryption(ctx context.Context) (string, string, *probe.Error) {
-	return "", "", probe.NewError(APINotImplemented{
-		API:     "GetEncryption",
-		APIType: "filesystem",
-	})
-}
-
-// SetEncryption - Set encryption configuration on a bucket, not implemented
-func (f *fsClient) SetEncryption(ctx context.Context, algorithm, keyID string) *probe.Error {
-	return probe.NewError(APINotImplemented{
-		API:     "SetEncryption",
-		APIType: "filesystem",
-	})
-}
-
-// DeleteEncryption - removes encryption configuration on a bucket, not implemented
-func (f *fsClient) DeleteEncryption(ctx context.Context) *probe.Error {
-	return probe.NewError(APINotImplemented{
-		API:     "DeleteEncryption",
-		APIType: "filesystem",
-	})
-}
-
-// Gets bucket info
-func (f *fsClient) GetBucketInfo(ctx context.Context) (BucketInfo, *probe.Error) {
-	return BucketInfo{}, probe.NewError(APINotImplemented{
-		API:     "GetBucketInfo",
-		APIType: "filesystem",
-	})
-}
@@ -0,0 +1,339 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+const (
+	httpBackendName = "HTTP"
+
+	// httpMaxResumes - maximum number of times an interrupted
+	// download is resumed.
+	httpMaxResumes = 5
+)
+
+// HTTP client, serves plain HTTP(S) URLs without any alias as
+// read-only objects.
+type httpClient struct {
+	unsupportedClient
+	targetURL *ClientURL
+	client    *http.Client
+}
+
+var (
+	httpClientOnce   sync.Once
+	globalHTTPClient *http.Client
+)
+
+// getHTTPClient - returns the HTTP client shared by all HTTP URLs, it is
+// created on first use once the global flags are known.
+func getHTTPClient() *http.Client {
+	httpClientOnce.Do(func() {
+		globalHTTPClient = &http.Client{
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   10 * time.Second,
+					KeepAlive: 15 * time.Second,
+				}).DialContext,
+				MaxIdleConnsPerHost:   256,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ExpectContinueTimeout: 10 * time.Second,
+				// Objects are downloaded as is.
+				DisableCompression: true,
+				TLSClientConfig: &tls.Config{
+					RootCAs:            globalRootCAs,
+					MinVersion:         tls.VersionTLS12,
+					InsecureSkipVerify: globalInsecure,
+				},
+			},
+		}
+	})
+	return globalHTTPClient
+}
+
+// httpNew - instantiate a new HTTP client.
+func httpNew(urlStr string) (Client, *probe.Error) {
+	return &httpClient{
+		unsupportedClient: unsupportedClient{backend: httpBackendName},
+		targetURL:         newClientURL(urlStr),
+		client:            getHTTPClient(),
+	}, nil
+}
+
+// GetURL get url.
+func (h *httpClient) GetURL() ClientURL {
+	return h.targetURL.Clone()
+}
+
+// AddUserAgent - not applicable for HTTP.
+func (h *httpClient) AddUserAgent(_, _ string) {
+}
+
+// do - sends a request for the target URL.
+func (h *httpClient) do(ctx context.Context, method string, header http.Header) (*http.Response, *probe.Error) {
+	urlStr := h.targetURL.String()
+	req, e := http.NewRequest(method, urlStr, nil)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	req = req.WithContext(ctx)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, e := h.client.Do(req)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+		return resp, nil
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return nil, probe.NewError(ObjectMissing{})
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, probe.NewError(PathInsufficientPermission{Path: urlStr})
+	}
+	return nil, probe.NewError(fmt.Errorf("Unexpected response `%s`", resp.Status))
+}
+
+// resp2ClientContent converts response headers into ClientContent.
+func (h *httpClient) resp2ClientContent(resp *http.Response) *ClientContent {
+	content := &ClientContent{
+		URL:          h.targetURL.Clone(),
+		Size:         resp.ContentLength,
+		Type:         os.FileMode(0664),
+		ETag:         strings.Trim(resp.Header.Get("ETag"), "\""),
+		Metadata:     map[string]string{},
+		UserMetadata: map[string]string{},
+	}
+	if t, e := http.ParseTime(resp.Header.Get("Last-Modified")); e == nil {
+		content.Time = t.UTC()
+	}
+	for _, k := range []string{"Content-Type", "Cache-Control", "Content-Encoding", "Content-Disposition", "Content-Language", "Expires"} {
+		if v := resp.Header.Get(k); v != "" {
+			content.Metadata[k] = v
+		}
+	}
+	if _, ok := content.Metadata["Content-Type"]; !ok {
+		content.Metadata["Content-Type"] = guessURLContentType(h.targetURL.Path)
+	}
+	return content
+}
+
+// Stat - get metadata of the URL through a HEAD request.
+func (h *httpClient) Stat(ctx context.Context, opts StatOptions) (*ClientContent, *probe.Error) {
+	resp, err := h.do(ctx, http.MethodHead, nil)
+	if err != nil {
+		return nil, err.Trace(h.targetURL.String())
+	}
+	resp.Body.Close()
+	return h.resp2ClientContent(resp), nil
+}
+
+// List - HTTP URLs can not be listed, the URL itself is returned.
+func (h *httpClient) List(ctx context.Context, opts ListOptions) <-chan *ClientContent {
+	contentCh := make(chan *ClientContent)
+	go func() {
+		defer close(contentCh)
+		if opts.Incomplete {
+			return
+		}
+		content, err := h.Stat(ctx, StatOptions{})
+		if err != nil {
+			content = &ClientContent{Err: err}
+		}
+		select {
+		case <-ctx.Done():
+		case contentCh <- content:
+		}
+	}()
+	return contentCh
+}
+
+// Get - returns a reader of the URL, interrupted downloads are resumed
+// with range requests when the server supports them.
+func (h *httpClient) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *probe.Error) {
+	r := &httpReader{ctx: ctx, h: h, size: -1}
+	if err := r.open(); err != nil {
+		return nil, err.Trace(h.targetURL.String())
+	}
+	if r.resumable {
+		return httpRangeReader{r}, nil
+	}
+	return r, nil
+}
+
+// httpReader - reader of an HTTP URL resuming interrupted downloads.
+type httpReader struct {
+	ctx    context.Context
+	h      *httpClient
+	body   io.ReadCloser
+	offset int64
+	size   int64
+	// validator sent as If-Range to make sure a resumed
+	// download continues with the same content.
+	validator string
+	resumable bool
+	resumes   int
+}
+
+// open - sends a GET request starting at the current offset.
+func (r *httpReader) open() *probe.Error {
+	header := http.Header{}
+	if r.offset > 0 {
+		header.Set("Range", "bytes="+strconv.FormatInt(r.offset, 10)+"-")
+		header.Set("If-Range", r.validator)
+	}
+	resp, err := r.h.do(r.ctx, http.MethodGet, header)
+	if err != nil {
+		return err
+	}
+	if r.offset > 0 {
+		if resp.StatusCode != http.StatusPartialContent {
+			resp.Body.Close()
+			return probe.NewError(fmt.Errorf("Unable to resume download of `%s`, content has changed", r.h.targetURL))
+		}
+	} else {
+		r.size = resp.ContentLength
+		// Weak ETags can not be used with If-Range.
+		etag := resp.Header.Get("ETag")
+		if etag != "" && !strings.HasPrefix(etag, "W/") {
+			r.validator = etag
+		} else {
+			r.validator = resp.Header.Get("Last-Modified")
+		}
+		r.resumable = resp.Header.Get("Accept-Ranges") == "bytes" && r.validator != "" && r.size > 0
+	}
+	r.body = resp.Body
+	return nil
+}
+
+// Read - reads from the response body, reopening it on failure.
+func (r *httpReader) Read(p []byte) (int, error) {
+	for {
+		if r.body == nil {
+			if err := r.open(); err != nil {
+				return 0, err.ToGoError()
+			}
+		}
+		n, e := r.body.Read(p)
+		r.offset += int64(n)
+		if e == nil || e == io.EOF && (r.size < 0 || r.offset >= r.size) {
+			return n, e
+		}
+		if e == io.EOF {
+			e = io.ErrUnexpectedEOF
+		}
+		r.body.Close()
+		r.body = nil
+		if !r.resumable || r.resumes >= httpMaxResumes || r.ctx.Err() != nil {
+			return n, e
+		}
+		r.resumes++
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// Close - closes the current response body.
+func (r *httpReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
+
+// httpRangeReader - reader of an HTTP URL served with range support,
+// it can be read at any offset, e.g. by zip archives.
+type httpRangeReader struct {
+	*httpReader
+}
+
+// ReadAt - reads len(p) bytes at offset off with a range request.
+func (r httpRangeReader) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	end := off + int64(len(p))
+	if end > r.size {
+		end = r.size
+	}
+	header := http.Header{}
+	header.Set("Range", "bytes="+strconv.FormatInt(off, 10)+"-"+strconv.FormatInt(end-1, 10))
+	header.Set("If-Range", r.validator)
+	resp, err := r.h.do(r.ctx, http.MethodGet, header)
+	if err != nil {
+		return 0, err.ToGoError()
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, fmt.Errorf("Unable to read `%s` at offset %d, content has changed", r.h.targetURL, off)
+	}
+	n, e := io.ReadFull(resp.Body, p[:end-off])
+	if e == nil && end-off < int64(len(p)) {
+		e = io.EOF
+	}
+	return n, e
+}
+
+// MakeBucket - not supported, HTTP URLs are read-only.
+func (h *httpClient) MakeBucket(ctx context.Context, region string, ignoreExisting, withLock bool) *probe.Error {
+	return h.notImplemented("MakeBucket")
+}
+
+// Put - not supported, HTTP URLs are read-only.
+func (h *httpClient) Put(ctx context.Context, reader io.Reader, size int64, metadata map[string]string, progress io.Reader, sse encrypt.ServerSide, md5, disableMultipart, isPreserve bool) (int64, *probe.Error) {
+	return 0, h.notImplemented("Put")
+}
+
+// Copy - not supported, HTTP URLs are read-only.
+func (h *httpClient) Copy(ctx context.Context, source string, opts CopyOptions, progress io.Reader) *probe.Error {
+	return h.notImplemented("Copy")
+}
+
+// Remove - not supported, HTTP URLs are read-only.
+func (h *httpClient) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isBypass bool, contentCh <-chan *ClientContent) <-chan *probe.Error {
+	errorCh := make(chan *probe.Error, 1)
+	errorCh <- h.notImplemented("Remove")
+	close(errorCh)
+	return errorCh
+}
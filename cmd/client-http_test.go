@@ -0,0 +1,174 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Tests stat and resumed downloads of HTTP URLs.
+func TestHTTPClient(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+	modTime := time.Date(2021, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/file.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", `"etag"`)
+		// Drop the connection half way through the first download.
+		if r.Method == http.MethodGet && atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Last-Modified", modTime.Format(http.TimeFormat))
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			w.Write(data[:len(data)/2])
+			return
+		}
+		http.ServeContent(w, r, "file.txt", modTime, bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	clnt, err := httpNew(server.URL + "/file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := clnt.Stat(context.Background(), StatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Size != int64(len(data)) || st.ETag != "etag" || !st.Time.Equal(modTime) {
+		t.Fatalf("unexpected stat %d %s %s", st.Size, st.ETag, st.Time)
+	}
+
+	reader, err := clnt.Get(context.Background(), GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	got, e := ioutil.ReadAll(reader)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("expected %d bytes, got %d", len(data), len(got))
+	}
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Fatalf("expected the download to be resumed once, got %d requests", requests)
+	}
+
+	if _, err = clnt.Put(context.Background(), bytes.NewReader(data), int64(len(data)), nil, nil, nil, false, false, false); err == nil {
+		t.Fatal("expected writes to fail")
+	}
+
+	clnt, err = httpNew(server.URL + "/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = clnt.Stat(context.Background(), StatOptions{}); err == nil {
+		t.Fatal("expected missing URL to fail")
+	}
+}
+
+// Tests zip archives served over HTTP are read with range requests.
+func TestHTTPZipArchive(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"a/file1.txt", "a/file2.txt"} {
+		w, e := zw.Create(name)
+		if e != nil {
+			t.Fatal(e)
+		}
+		w.Write([]byte("content of " + name))
+	}
+	if e := zw.Close(); e != nil {
+		t.Fatal(e)
+	}
+	data := buf.Bytes()
+	modTime := time.Date(2021, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	var ranged int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			atomic.AddInt32(&ranged, 1)
+		}
+		w.Header().Set("ETag", `"etag"`)
+		http.ServeContent(w, r, "backup.zip", modTime, bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	clnt, err := httpNew(server.URL + "/dir/backup.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := clnt.Get(context.Background(), GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ra, ok := reader.(io.ReaderAt)
+	reader.Close()
+	if !ok {
+		t.Fatal("expected a reader supporting ReadAt")
+	}
+	p := make([]byte, 4)
+	if n, e := ra.ReadAt(p, int64(len(data)-2)); n != 2 || e != io.EOF {
+		t.Fatalf("expected 2 bytes and EOF, got %d %v", n, e)
+	}
+
+	loc, ok := parseArchiveLocation(server.URL+"/dir/backup.zip/a/file2.txt", nil)
+	if !ok {
+		t.Fatal("expected an archive URL")
+	}
+	var contents []string
+	for content := range archiveNew(clnt, loc).List(context.Background(), ListOptions{Recursive: true}) {
+		if content.Err != nil {
+			t.Fatal(content.Err)
+		}
+		contents = append(contents, content.URL.Path)
+	}
+	if len(contents) != 1 || contents[0] != "/dir/backup.zip/a/file2.txt" {
+		t.Fatalf("unexpected entries %v", contents)
+	}
+
+	reader, err = archiveNew(clnt, loc).Get(context.Background(), GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	got, e := ioutil.ReadAll(reader)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if string(got) != "content of a/file2.txt" {
+		t.Fatalf("unexpected content %q", got)
+	}
+	if atomic.LoadInt32(&ranged) < 2 {
+		t.Fatalf("expected the archive to be read with range requests")
+	}
+}
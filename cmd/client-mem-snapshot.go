@@ -0,0 +1,231 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+)
+
+// memSnapshotEnv - names the snapshot file of the in-memory store.
+// Without it mem:// contents only live as long as the process, with
+// it every mc invocation loads the snapshot and saves its changes
+// back, so that scripts can chain commands against mem:// URLs. The
+// snapshot is not locked, invocations must not run concurrently.
+const memSnapshotEnv = "MC_MEM_SNAPSHOT"
+
+const memSnapshotVersion = "1"
+
+// memSnapshot - on-disk format of the in-memory store.
+type memSnapshot struct {
+	Version string                       `json:"version"`
+	Buckets map[string]memBucketSnapshot `json:"buckets"`
+}
+
+// memBucketSnapshot - on-disk format of a bucket, lifecycle and
+// replication configurations are kept in their XML form.
+type memBucketSnapshot struct {
+	Created      time.Time                       `json:"created"`
+	Versioning   string                          `json:"versioning,omitempty"`
+	Objects      map[string][]memVersionSnapshot `json:"objects"`
+	Tags         map[string]string               `json:"tags,omitempty"`
+	Policy       string                          `json:"policy,omitempty"`
+	PolicyJSON   string                          `json:"policyJSON,omitempty"`
+	Lifecycle    string                          `json:"lifecycle,omitempty"`
+	Replication  string                          `json:"replication,omitempty"`
+	EncAlgorithm string                          `json:"encAlgorithm,omitempty"`
+	EncKeyID     string                          `json:"encKeyID,omitempty"`
+	LockEnabled  bool                            `json:"lockEnabled,omitempty"`
+	LockMode     minio.RetentionMode             `json:"lockMode,omitempty"`
+	LockValidity uint64                          `json:"lockValidity,omitempty"`
+	LockUnit     minio.ValidityUnit              `json:"lockUnit,omitempty"`
+}
+
+// memVersionSnapshot - on-disk format of an object version.
+type memVersionSnapshot struct {
+	VersionID      string                `json:"versionID,omitempty"`
+	Data           []byte                `json:"data"`
+	ModTime        time.Time             `json:"modTime"`
+	ETag           string                `json:"etag"`
+	Metadata       map[string]string     `json:"metadata,omitempty"`
+	StorageClass   string                `json:"storageClass,omitempty"`
+	IsDeleteMarker bool                  `json:"isDeleteMarker,omitempty"`
+	Tags           map[string]string     `json:"tags,omitempty"`
+	RetentionMode  minio.RetentionMode   `json:"retentionMode,omitempty"`
+	RetainUntil    time.Time             `json:"retainUntil,omitempty"`
+	LegalHold      minio.LegalHoldStatus `json:"legalHold,omitempty"`
+}
+
+// loadMemStore - returns the store saved in the snapshot file, a
+// missing file is an empty store. An empty path is a process only store.
+func loadMemStore(snapshotPath string) (*memStore, *probe.Error) {
+	s := &memStore{
+		buckets:  make(map[string]*memBucket),
+		snapshot: snapshotPath,
+	}
+	if snapshotPath == "" {
+		return s, nil
+	}
+	data, e := ioutil.ReadFile(snapshotPath)
+	if os.IsNotExist(e) {
+		return s, nil
+	}
+	if e != nil {
+		return nil, probe.NewError(e).Trace(snapshotPath)
+	}
+	var snap memSnapshot
+	if e = json.Unmarshal(data, &snap); e != nil {
+		return nil, probe.NewError(e).Trace(snapshotPath)
+	}
+	for name, bs := range snap.Buckets {
+		b := &memBucket{
+			created:      bs.Created,
+			versioning:   bs.Versioning,
+			objects:      make(map[string][]*memObjectVersion),
+			tags:         bs.Tags,
+			policy:       bs.Policy,
+			policyJSON:   bs.PolicyJSON,
+			encAlgorithm: bs.EncAlgorithm,
+			encKeyID:     bs.EncKeyID,
+			lockEnabled:  bs.LockEnabled,
+			lockMode:     bs.LockMode,
+			lockValidity: bs.LockValidity,
+			lockUnit:     bs.LockUnit,
+		}
+		if bs.Lifecycle != "" {
+			b.lifecycle = lifecycle.NewConfiguration()
+			if e = xml.Unmarshal([]byte(bs.Lifecycle), b.lifecycle); e != nil {
+				return nil, probe.NewError(e).Trace(snapshotPath, name)
+			}
+		}
+		if bs.Replication != "" {
+			if e = xml.Unmarshal([]byte(bs.Replication), &b.replication); e != nil {
+				return nil, probe.NewError(e).Trace(snapshotPath, name)
+			}
+		}
+		for object, versions := range bs.Objects {
+			for _, vs := range versions {
+				b.objects[object] = append(b.objects[object], &memObjectVersion{
+					versionID:      vs.VersionID,
+					data:           vs.Data,
+					modTime:        vs.ModTime,
+					etag:           vs.ETag,
+					metadata:       vs.Metadata,
+					storageClass:   vs.StorageClass,
+					isDeleteMarker: vs.IsDeleteMarker,
+					tags:           vs.Tags,
+					retentionMode:  vs.RetentionMode,
+					retainUntil:    vs.RetainUntil,
+					legalHold:      vs.LegalHold,
+				})
+			}
+		}
+		s.buckets[name] = b
+	}
+	return s, nil
+}
+
+// save - writes the store to its snapshot file, if any. Callers
+// must hold the store lock.
+func (s *memStore) save() *probe.Error {
+	if s.snapshot == "" {
+		return nil
+	}
+	snap := memSnapshot{
+		Version: memSnapshotVersion,
+		Buckets: make(map[string]memBucketSnapshot, len(s.buckets)),
+	}
+	for name, b := range s.buckets {
+		bs := memBucketSnapshot{
+			Created:      b.created,
+			Versioning:   b.versioning,
+			Objects:      make(map[string][]memVersionSnapshot, len(b.objects)),
+			Tags:         b.tags,
+			Policy:       b.policy,
+			PolicyJSON:   b.policyJSON,
+			EncAlgorithm: b.encAlgorithm,
+			EncKeyID:     b.encKeyID,
+			LockEnabled:  b.lockEnabled,
+			LockMode:     b.lockMode,
+			LockValidity: b.lockValidity,
+			LockUnit:     b.lockUnit,
+		}
+		if b.lifecycle != nil && !b.lifecycle.Empty() {
+			data, e := xml.Marshal(b.lifecycle)
+			if e != nil {
+				return probe.NewError(e).Trace(name)
+			}
+			bs.Lifecycle = string(data)
+		}
+		if !b.replication.Empty() {
+			data, e := xml.Marshal(b.replication)
+			if e != nil {
+				return probe.NewError(e).Trace(name)
+			}
+			bs.Replication = string(data)
+		}
+		for object, versions := range b.objects {
+			for _, v := range versions {
+				bs.Objects[object] = append(bs.Objects[object], memVersionSnapshot{
+					VersionID:      v.versionID,
+					Data:           v.data,
+					ModTime:        v.modTime,
+					ETag:           v.etag,
+					Metadata:       v.metadata,
+					StorageClass:   v.storageClass,
+					IsDeleteMarker: v.isDeleteMarker,
+					Tags:           v.tags,
+					RetentionMode:  v.retentionMode,
+					RetainUntil:    v.retainUntil,
+					LegalHold:      v.legalHold,
+				})
+			}
+		}
+		snap.Buckets[name] = bs
+	}
+	data, e := json.Marshal(snap)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	// Write aside and rename, an interrupted save never
+	// leaves a truncated snapshot behind.
+	tmpFile, e := ioutil.TempFile(filepath.Dir(s.snapshot), ".mem-snapshot-")
+	if e != nil {
+		return probe.NewError(e).Trace(s.snapshot)
+	}
+	if _, e = tmpFile.Write(data); e == nil {
+		e = tmpFile.Close()
+	} else {
+		tmpFile.Close()
+	}
+	if e == nil {
+		e = os.Rename(tmpFile.Name(), s.snapshot)
+	}
+	if e != nil {
+		os.Remove(tmpFile.Name())
+		return probe.NewError(e).Trace(s.snapshot)
+	}
+	return nil
+}
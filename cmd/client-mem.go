@@ -0,0 +1,1267 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/hookreader"
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/minio/minio-go/v7/pkg/replication"
+	"github.com/minio/minio-go/v7/pkg/tags"
+	"github.com/rs/xid"
+)
+
+const (
+	// memScheme - URL scheme of the in-memory backend, URLs are
+	// of the form mem://bucket/object.
+	memScheme      = "mem"
+	memBackendName = "in-memory"
+
+	// nullVersionID - version id of objects written while
+	// versioning is suspended.
+	nullVersionID = "null"
+)
+
+// memObjectVersion - a single version of an object or a delete marker.
+type memObjectVersion struct {
+	versionID      string
+	data           []byte
+	modTime        time.Time
+	etag           string
+	metadata       map[string]string
+	storageClass   string
+	isDeleteMarker bool
+	tags           map[string]string
+	retentionMode  minio.RetentionMode
+	retainUntil    time.Time
+	legalHold      minio.LegalHoldStatus
+}
+
+// memBucket - bucket with all its objects versions and configuration.
+type memBucket struct {
+	created time.Time
+	// versioning is empty if never enabled, "Enabled" or "Suspended".
+	versioning string
+	// objects versions, newest first.
+	objects map[string][]*memObjectVersion
+
+	tags        map[string]string
+	policy      string
+	policyJSON  string
+	lifecycle   *lifecycle.Configuration
+	replication replication.Config
+
+	encAlgorithm string
+	encKeyID     string
+
+	lockEnabled  bool
+	lockMode     minio.RetentionMode
+	lockValidity uint64
+	lockUnit     minio.ValidityUnit
+}
+
+// memStore - process wide storage shared by all in-memory clients.
+type memStore struct {
+	sync.RWMutex
+	buckets map[string]*memBucket
+	// snapshot file the store is persisted to, if any.
+	snapshot string
+}
+
+var (
+	globalMemStore     *memStore
+	globalMemStoreErr  *probe.Error
+	globalMemStoreOnce sync.Once
+)
+
+// getMemStore - returns the store shared by all in-memory clients,
+// loaded from the snapshot named by MC_MEM_SNAPSHOT on first use.
+func getMemStore() (*memStore, *probe.Error) {
+	globalMemStoreOnce.Do(func() {
+		globalMemStore, globalMemStoreErr = loadMemStore(os.Getenv(memSnapshotEnv))
+	})
+	return globalMemStore, globalMemStoreErr
+}
+
+// in-memory client
+type memClient struct {
+	unsupportedClient
+	targetURL *ClientURL
+	store     *memStore
+}
+
+// memNew - instantiate a new in-memory client.
+func memNew(urlStr string) (Client, *probe.Error) {
+	store, err := getMemStore()
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	return &memClient{
+		unsupportedClient: unsupportedClient{backend: memBackendName},
+		targetURL:         newClientURL(urlStr),
+		store:             store,
+	}, nil
+}
+
+// newMemClientURL - returns the URL of an in-memory path, the bucket
+// name takes the place of the host so that mem://bucket/object maps
+// to the path /bucket/object.
+func newMemClientURL(uri string) *ClientURL {
+	return &ClientURL{
+		Type:            objectStorage,
+		Scheme:          memScheme,
+		Path:            "/" + strings.TrimPrefix(uri, "/"),
+		SchemeSeparator: "://",
+		Separator:       '/',
+	}
+}
+
+// url2BucketAndObject - returns bucket and object of the client URL.
+func (m *memClient) url2BucketAndObject() (bucketName, objectName string) {
+	return m.splitPath(m.targetURL.Path)
+}
+
+// splitPath split path into bucket and object.
+func (m *memClient) splitPath(path string) (bucketName, objectName string) {
+	tokens := splitStr(strings.TrimPrefix(path, "/"), "/", 2)
+	return tokens[0], tokens[1]
+}
+
+// getBucket returns the bucket, callers must hold the store lock.
+func (m *memClient) getBucket(bucket string) (*memBucket, *probe.Error) {
+	if bucket == "" {
+		return nil, probe.NewError(BucketNameEmpty{})
+	}
+	b, ok := m.store.buckets[bucket]
+	if !ok {
+		return nil, probe.NewError(BucketDoesNotExist{Bucket: bucket})
+	}
+	return b, nil
+}
+
+// getVersion returns the requested version of an object or the latest
+// version if versionID is empty, callers must hold the store lock.
+func (b *memBucket) getVersion(object, versionID string) (*memObjectVersion, *probe.Error) {
+	versions := b.objects[object]
+	if len(versions) == 0 {
+		return nil, probe.NewError(ObjectMissing{})
+	}
+	if versionID == "" {
+		if versions[0].isDeleteMarker {
+			return nil, probe.NewError(ObjectIsDeleteMarker{})
+		}
+		return versions[0], nil
+	}
+	for _, v := range versions {
+		if v.versionID == versionID {
+			return v, nil
+		}
+	}
+	return nil, probe.NewError(ObjectMissing{})
+}
+
+// versionAt returns the version which was current at timeRef.
+func (b *memBucket) versionAt(object string, timeRef time.Time) *memObjectVersion {
+	for _, v := range b.objects[object] {
+		if timeRef.IsZero() || v.modTime.Before(timeRef) {
+			return v
+		}
+	}
+	return nil
+}
+
+// addVersion stores a new version of an object according to the
+// bucket versioning status.
+func (b *memBucket) addVersion(object string, v *memObjectVersion) {
+	switch b.versioning {
+	case "Enabled":
+		v.versionID = xid.New().String()
+	case "Suspended":
+		v.versionID = nullVersionID
+	default:
+		b.objects[object] = []*memObjectVersion{v}
+		return
+	}
+	versions := []*memObjectVersion{v}
+	for _, old := range b.objects[object] {
+		// A null version is always overwritten.
+		if v.versionID == nullVersionID && old.versionID == nullVersionID {
+			continue
+		}
+		versions = append(versions, old)
+	}
+	b.objects[object] = versions
+}
+
+// isProtected returns an error if object lock prevents removing the version.
+func (v *memObjectVersion) isProtected(bypassGovernance bool) *probe.Error {
+	if v.legalHold == minio.LegalHoldEnabled {
+		return probe.NewError(errors.New("Object is WORM protected and cannot be overwritten"))
+	}
+	if v.retainUntil.After(UTCNow()) {
+		if v.retentionMode == minio.Compliance || !bypassGovernance {
+			return probe.NewError(errors.New("Object is WORM protected and cannot be overwritten"))
+		}
+	}
+	return nil
+}
+
+// removeVersion removes an object version, a delete marker is added
+// instead when versionID is empty and versioning is enabled.
+func (b *memBucket) removeVersion(object, versionID string, bypassGovernance bool) *probe.Error {
+	versions := b.objects[object]
+	if versionID == "" && b.versioning != "" {
+		if len(versions) == 0 {
+			return nil
+		}
+		b.addVersion(object, &memObjectVersion{
+			modTime:        UTCNow(),
+			isDeleteMarker: true,
+		})
+		return nil
+	}
+	for i, v := range versions {
+		if versionID != "" && v.versionID != versionID {
+			continue
+		}
+		if err := v.isProtected(bypassGovernance); err != nil {
+			return err.Trace(object, versionID)
+		}
+		versions = append(versions[:i], versions[i+1:]...)
+		break
+	}
+	if len(versions) == 0 {
+		delete(b.objects, object)
+	} else {
+		b.objects[object] = versions
+	}
+	return nil
+}
+
+// sortedKeys returns all object names of the bucket in lexical order.
+func (b *memBucket) sortedKeys() []string {
+	keys := make([]string, 0, len(b.objects))
+	for k := range b.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedBuckets returns all bucket names in lexical order.
+func (s *memStore) sortedBuckets() []string {
+	names := make([]string, 0, len(s.buckets))
+	for name := range s.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetURL get url.
+func (m *memClient) GetURL() ClientURL {
+	return *m.targetURL
+}
+
+// AddUserAgent - not applicable for in-memory.
+func (m *memClient) AddUserAgent(_, _ string) {
+}
+
+// Returns new path by joining path segments with URL path separator.
+func (m *memClient) joinPath(bucket string, objects ...string) string {
+	p := "/" + bucket
+	for _, o := range objects {
+		p += "/" + o
+	}
+	return p
+}
+
+// bucket2ClientContent converts a bucket into ClientContent.
+func (m *memClient) bucket2ClientContent(bucket string, b *memBucket) *ClientContent {
+	url := m.targetURL.Clone()
+	url.Path = m.joinPath(bucket)
+	return &ClientContent{
+		URL:  url,
+		Time: b.created,
+		Type: os.ModeDir,
+	}
+}
+
+// prefix2ClientContent converts a common prefix into ClientContent.
+func (m *memClient) prefix2ClientContent(bucket, prefix string) *ClientContent {
+	url := m.targetURL.Clone()
+	url.Path = m.joinPath(bucket, prefix)
+	return &ClientContent{
+		URL:  url,
+		Time: time.Now(),
+		Type: os.ModeDir,
+	}
+}
+
+// version2ClientContent converts an object version into ClientContent.
+func (m *memClient) version2ClientContent(bucket, object string, v *memObjectVersion, isLatest bool) *ClientContent {
+	url := m.targetURL.Clone()
+	url.Path = m.joinPath(bucket, object)
+	content := &ClientContent{
+		URL:            url,
+		Time:           v.modTime,
+		Size:           int64(len(v.data)),
+		Type:           os.FileMode(0664),
+		StorageClass:   v.storageClass,
+		ETag:           v.etag,
+		VersionID:      v.versionID,
+		IsDeleteMarker: v.isDeleteMarker,
+		IsLatest:       isLatest,
+		Metadata:       map[string]string{},
+		UserMetadata:   map[string]string{},
+	}
+	for k, val := range v.metadata {
+		content.Metadata[k] = val
+		if strings.HasPrefix(k, "X-Amz-Meta-") {
+			content.UserMetadata[strings.TrimPrefix(k, "X-Amz-Meta-")] = val
+		}
+	}
+	if v.retentionMode != "" {
+		content.RetentionEnabled = true
+		content.RetentionMode = string(v.retentionMode)
+		content.RetentionDuration = v.retainUntil.Format(time.RFC3339)
+	}
+	if v.legalHold != "" {
+		content.LegalHoldEnabled = true
+		content.LegalHold = string(v.legalHold)
+	}
+	if strings.HasSuffix(object, "/") {
+		content.Type = os.ModeDir
+	}
+	return content
+}
+
+// Stat - get metadata of a bucket, an object or a prefix.
+func (m *memClient) Stat(ctx context.Context, opts StatOptions) (*ClientContent, *probe.Error) {
+	m.store.RLock()
+	defer m.store.RUnlock()
+
+	bucket, object := m.url2BucketAndObject()
+	if bucket == "" {
+		url := m.targetURL.Clone()
+		url.Path = "/"
+		return &ClientContent{URL: url, Type: os.ModeDir}, nil
+	}
+
+	b, err := m.getBucket(bucket)
+	if err != nil {
+		return nil, err.Trace(bucket)
+	}
+	if object == "" {
+		return m.bucket2ClientContent(bucket, b), nil
+	}
+
+	// Incomplete uploads never exist in memory.
+	if opts.incomplete {
+		return nil, probe.NewError(ObjectMissing{})
+	}
+
+	if !strings.HasSuffix(object, "/") {
+		var v *memObjectVersion
+		if opts.timeRef.IsZero() {
+			v, err = b.getVersion(object, opts.versionID)
+		} else if v = b.versionAt(object, opts.timeRef); v == nil || v.isDeleteMarker {
+			err = probe.NewError(ObjectMissing{opts.timeRef})
+		}
+		if err == nil {
+			return m.version2ClientContent(bucket, object, v, v == b.objects[object][0]), nil
+		}
+		if !errors.As(err.ToGoError(), &ObjectMissing{}) && !errors.As(err.ToGoError(), &ObjectIsDeleteMarker{}) {
+			return nil, err.Trace(bucket, object)
+		}
+	}
+
+	// Verify if a prefix exists.
+	prefix := strings.TrimRight(object, "/") + "/"
+	for key := range b.objects {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if v := b.versionAt(key, opts.timeRef); v != nil && !v.isDeleteMarker {
+			return m.prefix2ClientContent(bucket, prefix), nil
+		}
+	}
+	return nil, probe.NewError(ObjectMissing{opts.timeRef})
+}
+
+// List - list at delimited path, if not recursive.
+func (m *memClient) List(ctx context.Context, opts ListOptions) <-chan *ClientContent {
+	contentCh := make(chan *ClientContent)
+	go func() {
+		defer close(contentCh)
+
+		// Collect all entries under the lock, then send them
+		// without holding it to avoid blocking writers.
+		m.store.RLock()
+		contents := m.list(opts)
+		m.store.RUnlock()
+
+		for _, content := range contents {
+			select {
+			case <-ctx.Done():
+				return
+			case contentCh <- content:
+			}
+		}
+	}()
+	return contentCh
+}
+
+// list returns all entries matching the listing options, callers
+// must hold the store lock.
+func (m *memClient) list(opts ListOptions) (contents []*ClientContent) {
+	// Incomplete uploads never exist in memory.
+	if opts.Incomplete {
+		return nil
+	}
+
+	bucket, object := m.url2BucketAndObject()
+	if bucket == "" {
+		for _, name := range m.store.sortedBuckets() {
+			b := m.store.buckets[name]
+			if !opts.Recursive {
+				contents = append(contents, m.bucket2ClientContent(name, b))
+				continue
+			}
+			if opts.ShowDir == DirFirst {
+				contents = append(contents, m.bucket2ClientContent(name, b))
+			}
+			contents = append(contents, m.listBucket(name, b, "", opts)...)
+			if opts.ShowDir == DirLast {
+				contents = append(contents, m.bucket2ClientContent(name, b))
+			}
+		}
+		return contents
+	}
+
+	b, err := m.getBucket(bucket)
+	if err != nil {
+		return []*ClientContent{{Err: err.Trace(bucket)}}
+	}
+	if !opts.Recursive && object == "" && !strings.HasSuffix(m.targetURL.Path, "/") {
+		return []*ClientContent{m.bucket2ClientContent(bucket, b)}
+	}
+	return m.listBucket(bucket, b, object, opts)
+}
+
+// listBucket lists objects with prefix in a bucket, versions are listed
+// when asked to with the same semantics as the S3 versioned listing.
+func (m *memClient) listBucket(bucket string, b *memBucket, prefix string, opts ListOptions) (contents []*ClientContent) {
+	versioned := !opts.TimeRef.IsZero() || opts.WithOlderVersions
+	timeRef := opts.TimeRef
+	if timeRef.IsZero() {
+		timeRef = time.Now().UTC().Add(time.Second)
+	}
+
+	seenPrefixes := make(map[string]bool)
+	for _, key := range b.sortedKeys() {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if !opts.Recursive {
+			// Avoid sending an empty directory when we are specifically listing it
+			if key == prefix && strings.HasSuffix(key, "/") {
+				continue
+			}
+			if i := strings.Index(key[len(prefix):], "/"); i >= 0 {
+				dir := key[:len(prefix)+i+1]
+				if !seenPrefixes[dir] && m.hasVisibleVersion(b, key, timeRef, versioned) {
+					seenPrefixes[dir] = true
+					contents = append(contents, m.prefix2ClientContent(bucket, dir))
+				}
+				continue
+			}
+		}
+		versions := b.objects[key]
+		if !versioned {
+			if versions[0].isDeleteMarker {
+				continue
+			}
+			contents = append(contents, m.version2ClientContent(bucket, key, versions[0], true))
+			continue
+		}
+		listed := false
+		for i, v := range versions {
+			if listed && !opts.WithOlderVersions {
+				break
+			}
+			if !v.modTime.Before(timeRef) {
+				continue
+			}
+			listed = true
+			if v.isDeleteMarker && !opts.WithDeleteMarkers {
+				continue
+			}
+			contents = append(contents, m.version2ClientContent(bucket, key, v, i == 0))
+		}
+	}
+	return contents
+}
+
+// hasVisibleVersion returns true if the object shows up in the listing.
+func (m *memClient) hasVisibleVersion(b *memBucket, key string, timeRef time.Time, versioned bool) bool {
+	if !versioned {
+		return !b.objects[key][0].isDeleteMarker
+	}
+	return b.versionAt(key, timeRef) != nil
+}
+
+// MakeBucket - make a new bucket, or an empty prefix if the URL has one.
+func (m *memClient) MakeBucket(ctx context.Context, region string, ignoreExisting, withLock bool) *probe.Error {
+	m.store.Lock()
+	defer m.store.Unlock()
+
+	bucket, object := m.url2BucketAndObject()
+	if bucket == "" {
+		return probe.NewError(BucketNameEmpty{})
+	}
+	b, ok := m.store.buckets[bucket]
+	if object != "" {
+		if !ok {
+			b = m.newBucket(bucket, withLock)
+		}
+		if !strings.HasSuffix(object, "/") {
+			object += "/"
+		}
+		b.addVersion(object, &memObjectVersion{
+			modTime:  UTCNow(),
+			etag:     m.etag(nil),
+			metadata: map[string]string{},
+		})
+		return m.store.save()
+	}
+	if ok {
+		if ignoreExisting {
+			return nil
+		}
+		return probe.NewError(BucketExists{Bucket: bucket})
+	}
+	m.newBucket(bucket, withLock)
+	return m.store.save()
+}
+
+// newBucket creates a bucket, callers must hold the store lock.
+func (m *memClient) newBucket(bucket string, withLock bool) *memBucket {
+	b := &memBucket{
+		created:     UTCNow(),
+		objects:     make(map[string][]*memObjectVersion),
+		policy:      "none",
+		lockEnabled: withLock,
+	}
+	// Object locking requires versioning.
+	if withLock {
+		b.versioning = "Enabled"
+	}
+	m.store.buckets[bucket] = b
+	return b
+}
+
+// etag computes the ETag of data.
+func (m *memClient) etag(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// newVersion builds a new object version from data and metadata,
+// applying the default retention of the bucket if any.
+func (m *memClient) newVersion(b *memBucket, data []byte, metadata map[string]string) (*memObjectVersion, *probe.Error) {
+	v := &memObjectVersion{
+		data:     data,
+		modTime:  UTCNow(),
+		etag:     m.etag(data),
+		metadata: map[string]string{},
+		tags:     map[string]string{},
+	}
+	for k, val := range metadata {
+		switch k = http.CanonicalHeaderKey(k); k {
+		case "X-Amz-Storage-Class":
+			v.storageClass = strings.ToUpper(val)
+		case AmzObjectLockMode:
+			v.retentionMode = minio.RetentionMode(strings.ToUpper(val))
+		case AmzObjectLockRetainUntilDate:
+			if t, e := time.Parse(time.RFC3339, val); e == nil {
+				v.retainUntil = t.UTC()
+			}
+		case AmzObjectLockLegalHold:
+			v.legalHold = minio.LegalHoldStatus(strings.ToUpper(val))
+		case "X-Amz-Tagging":
+			t, e := tags.Parse(val, true)
+			if e != nil {
+				return nil, probe.NewError(e)
+			}
+			v.tags = t.ToMap()
+		case "Content-Type", "Cache-Control", "Content-Encoding", "Content-Disposition", "Content-Language", "Expires":
+			v.metadata[k] = val
+		default:
+			if !strings.HasPrefix(k, "X-Amz-Meta-") {
+				k = "X-Amz-Meta-" + k
+			}
+			v.metadata[k] = val
+		}
+	}
+	if _, ok := v.metadata["Content-Type"]; !ok {
+		v.metadata["Content-Type"] = "application/octet-stream"
+	}
+	if v.storageClass == "" {
+		v.storageClass = "STANDARD"
+	}
+	if v.retentionMode != "" || v.legalHold != "" || b.lockMode != "" {
+		if !b.lockEnabled {
+			return nil, probe.NewError(errors.New("Bucket is missing ObjectLockConfiguration"))
+		}
+	}
+	if v.retentionMode == "" && b.lockMode != "" {
+		v.retentionMode = b.lockMode
+		switch b.lockUnit {
+		case minio.Years:
+			v.retainUntil = v.modTime.AddDate(int(b.lockValidity), 0, 0)
+		default:
+			v.retainUntil = v.modTime.AddDate(0, 0, int(b.lockValidity))
+		}
+	}
+	return v, nil
+}
+
+// Put - store a new object version with custom metadata.
+func (m *memClient) Put(ctx context.Context, reader io.Reader, size int64, metadata map[string]string, progress io.Reader, sse encrypt.ServerSide, md5, disableMultipart, isPreserve bool) (int64, *probe.Error) {
+	bucket, object := m.url2BucketAndObject()
+	if bucket == "" {
+		return 0, probe.NewError(BucketNameEmpty{})
+	}
+	if object == "" {
+		return 0, probe.NewError(ObjectNameEmpty{})
+	}
+
+	data, e := ioutil.ReadAll(hookreader.NewHook(reader, progress))
+	if e != nil {
+		return int64(len(data)), probe.NewError(e)
+	}
+	n := int64(len(data))
+	if size >= 0 && n < size {
+		return n, probe.NewError(UnexpectedEOF{TotalSize: size, TotalWritten: n})
+	}
+	if size >= 0 && n > size {
+		return n, probe.NewError(UnexpectedExcessRead{TotalSize: size, TotalWritten: n})
+	}
+
+	m.store.Lock()
+	defer m.store.Unlock()
+
+	b, err := m.getBucket(bucket)
+	if err != nil {
+		return 0, err.Trace(bucket)
+	}
+	v, err := m.newVersion(b, data, metadata)
+	if err != nil {
+		return 0, err.Trace(bucket, object)
+	}
+	b.addVersion(object, v)
+	if err = m.store.save(); err != nil {
+		return n, err.Trace(bucket, object)
+	}
+	return n, nil
+}
+
+// Get - get a reader of an object version.
+func (m *memClient) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *probe.Error) {
+	m.store.RLock()
+	defer m.store.RUnlock()
+
+	bucket, object := m.url2BucketAndObject()
+	b, err := m.getBucket(bucket)
+	if err != nil {
+		return nil, err.Trace(bucket)
+	}
+	v, err := b.getVersion(object, opts.VersionID)
+	if err != nil {
+		return nil, err.Trace(bucket, object)
+	}
+	if v.isDeleteMarker {
+		return nil, probe.NewError(errors.New("The specified method is not allowed against this resource"))
+	}
+	return memObjectReader{bytes.NewReader(v.data), v.etag}, nil
+}
+
+// memObjectReader - seekable reader of an in-memory object.
+type memObjectReader struct {
+	*bytes.Reader
+	etag string
+}
+
+// Stat - returns the object read, like the readers of S3 objects.
+func (r memObjectReader) Stat() (minio.ObjectInfo, error) {
+	return minio.ObjectInfo{ETag: r.etag, Size: r.Size()}, nil
+}
+
+// Close - nothing to release.
+func (r memObjectReader) Close() error {
+	return nil
+}
+
+// Copy - copy an object within the in-memory store, source is of
+// the form /bucket/object.
+func (m *memClient) Copy(ctx context.Context, source string, opts CopyOptions, progress io.Reader) *probe.Error {
+	dstBucket, dstObject := m.url2BucketAndObject()
+	if dstBucket == "" {
+		return probe.NewError(BucketNameEmpty{})
+	}
+	srcBucket, srcObject := m.splitPath(source)
+
+	m.store.Lock()
+	defer m.store.Unlock()
+
+	sb, err := m.getBucket(srcBucket)
+	if err != nil {
+		return err.Trace(srcBucket)
+	}
+	src, err := sb.getVersion(srcObject, opts.versionID)
+	if err != nil {
+		return err.Trace(source)
+	}
+	db, err := m.getBucket(dstBucket)
+	if err != nil {
+		return err.Trace(dstBucket)
+	}
+
+	metadata := src.metadata
+	if len(opts.metadata) > 0 {
+		metadata = opts.metadata
+	}
+	v, err := m.newVersion(db, src.data, metadata)
+	if err != nil {
+		return err.Trace(m.targetURL.String())
+	}
+	if progress != nil {
+		if _, e := io.CopyN(ioutil.Discard, progress, int64(len(src.data))); e != nil && e != io.EOF {
+			return probe.NewError(e)
+		}
+	}
+	db.addVersion(dstObject, v)
+	return m.store.save()
+}
+
+// Compose - concatenate source objects into the target object.
+func (m *memClient) Compose(ctx context.Context, sources []string, opts CopyOptions, progress io.Reader) *probe.Error {
+	dstBucket, dstObject := m.url2BucketAndObject()
+	if dstBucket == "" {
+		return probe.NewError(BucketNameEmpty{})
+	}
+
+	m.store.Lock()
+	defer m.store.Unlock()
+
+	var data []byte
+	for _, source := range sources {
+		srcBucket, srcObject := m.splitPath(source)
+		sb, err := m.getBucket(srcBucket)
+		if err != nil {
+			return err.Trace(srcBucket)
+		}
+		src, err := sb.getVersion(srcObject, "")
+		if err != nil {
+			return err.Trace(source)
+		}
+		data = append(data, src.data...)
+	}
+	db, err := m.getBucket(dstBucket)
+	if err != nil {
+		return err.Trace(dstBucket)
+	}
+
+	v, err := m.newVersion(db, data, opts.metadata)
+	if err != nil {
+		return err.Trace(m.targetURL.String())
+	}
+	if progress != nil {
+		if _, e := io.CopyN(ioutil.Discard, progress, int64(len(data))); e != nil && e != io.EOF {
+			return probe.NewError(e)
+		}
+	}
+	db.addVersion(dstObject, v)
+	return m.store.save()
+}
+
+// Remove - remove objects versions or buckets.
+func (m *memClient) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isBypass bool, contentCh <-chan *ClientContent) <-chan *probe.Error {
+	errorCh := make(chan *probe.Error)
+	go func() {
+		defer close(errorCh)
+		if isRemoveBucket {
+			if _, object := m.url2BucketAndObject(); object != "" {
+				errorCh <- probe.NewError(errors.New("cannot delete prefixes with `mc rb` command - Use `mc rm` instead"))
+				return
+			}
+		}
+
+		var buckets []string
+		for content := range contentCh {
+			bucket, object := m.splitPath(content.URL.Path)
+			// We don't treat path when bucket is
+			// empty, just skip it when it happens.
+			if bucket == "" {
+				continue
+			}
+			if len(buckets) == 0 || buckets[len(buckets)-1] != bucket {
+				buckets = append(buckets, bucket)
+			}
+			// Incomplete uploads never exist in memory.
+			if object == "" || isIncomplete {
+				continue
+			}
+
+			m.store.Lock()
+			b, err := m.getBucket(bucket)
+			if err == nil {
+				err = b.removeVersion(object, content.VersionID, isBypass)
+			}
+			if err == nil {
+				err = m.store.save()
+			}
+			m.store.Unlock()
+			if err != nil {
+				errorCh <- err.Trace(content.URL.String())
+			}
+		}
+
+		if !isRemoveBucket || isIncomplete {
+			return
+		}
+		m.store.Lock()
+		defer m.store.Unlock()
+		for _, bucket := range buckets {
+			b, err := m.getBucket(bucket)
+			if err != nil {
+				errorCh <- err.Trace(bucket)
+				continue
+			}
+			if len(b.objects) > 0 {
+				errorCh <- probe.NewError(fmt.Errorf("The bucket `%s` you tried to delete is not empty", bucket))
+				continue
+			}
+			delete(m.store.buckets, bucket)
+			if err = m.store.save(); err != nil {
+				errorCh <- err.Trace(bucket)
+			}
+		}
+	}()
+	return errorCh
+}
+
+// withBucket runs fn on the bucket of the client URL under the store lock.
+func (m *memClient) withBucket(fn func(b *memBucket, object string) *probe.Error) *probe.Error {
+	m.store.Lock()
+	defer m.store.Unlock()
+
+	bucket, object := m.url2BucketAndObject()
+	b, err := m.getBucket(bucket)
+	if err != nil {
+		return err.Trace(bucket)
+	}
+	return fn(b, object)
+}
+
+// updateBucket runs fn like withBucket, saving the store if fn succeeds.
+func (m *memClient) updateBucket(fn func(b *memBucket, object string) *probe.Error) *probe.Error {
+	return m.withBucket(func(b *memBucket, object string) *probe.Error {
+		if err := fn(b, object); err != nil {
+			return err
+		}
+		return m.store.save()
+	})
+}
+
+// withVersion runs fn on an object version of the client URL under the store lock.
+func (m *memClient) withVersion(versionID string, fn func(v *memObjectVersion) *probe.Error) *probe.Error {
+	return m.withBucket(func(b *memBucket, object string) *probe.Error {
+		if object == "" {
+			return probe.NewError(ObjectNameEmpty{})
+		}
+		v, err := b.getVersion(object, versionID)
+		if err != nil {
+			return err.Trace(object, versionID)
+		}
+		return fn(v)
+	})
+}
+
+// updateVersion runs fn like withVersion, saving the store if fn succeeds.
+func (m *memClient) updateVersion(versionID string, fn func(v *memObjectVersion) *probe.Error) *probe.Error {
+	return m.withVersion(versionID, func(v *memObjectVersion) *probe.Error {
+		if err := fn(v); err != nil {
+			return err
+		}
+		return m.store.save()
+	})
+}
+
+// SetObjectLockConfig - set the default retention of a bucket.
+func (m *memClient) SetObjectLockConfig(ctx context.Context, mode minio.RetentionMode, validity uint64, unit minio.ValidityUnit) *probe.Error {
+	return m.updateBucket(func(b *memBucket, _ string) *probe.Error {
+		if !b.lockEnabled {
+			return probe.NewError(errors.New("Bucket is missing ObjectLockConfiguration"))
+		}
+		switch {
+		case mode != "" && validity > 0 && unit != "":
+		case mode == "" && validity == 0 && unit == "":
+		default:
+			return errInvalidArgument().Trace(m.targetURL.String())
+		}
+		b.lockMode, b.lockValidity, b.lockUnit = mode, validity, unit
+		return nil
+	})
+}
+
+// GetObjectLockConfig - get the object lock configuration of a bucket.
+func (m *memClient) GetObjectLockConfig(ctx context.Context) (status string, mode minio.RetentionMode, validity uint64, unit minio.ValidityUnit, perr *probe.Error) {
+	perr = m.withBucket(func(b *memBucket, _ string) *probe.Error {
+		if !b.lockEnabled {
+			return probe.NewError(errors.New("Object Lock configuration does not exist for this bucket"))
+		}
+		status, mode, validity, unit = "Enabled", b.lockMode, b.lockValidity, b.lockUnit
+		return nil
+	})
+	return status, mode, validity, unit, perr
+}
+
+// PutObjectRetention - set object retention for a given object.
+func (m *memClient) PutObjectRetention(ctx context.Context, versionID string, mode minio.RetentionMode, retainUntilDate time.Time, bypassGovernance bool) *probe.Error {
+	if mode != "" && retainUntilDate.IsZero() {
+		return errInvalidArgument().Trace(m.targetURL.String())
+	}
+	return m.updateBucket(func(b *memBucket, object string) *probe.Error {
+		if !b.lockEnabled {
+			return probe.NewError(errors.New("Bucket is missing ObjectLockConfiguration"))
+		}
+		v, err := b.getVersion(object, versionID)
+		if err != nil {
+			return err.Trace(object, versionID)
+		}
+		// Shortening or removing an active retention is only
+		// allowed in governance mode with bypass.
+		if v.retainUntil.After(UTCNow()) && (mode == "" || retainUntilDate.Before(v.retainUntil)) {
+			if v.retentionMode == minio.Compliance || !bypassGovernance {
+				return probe.NewError(errors.New("Object is WORM protected and cannot be overwritten"))
+			}
+		}
+		v.retentionMode, v.retainUntil = mode, retainUntilDate
+		return nil
+	})
+}
+
+// GetObjectRetention - get object retention for a given object.
+func (m *memClient) GetObjectRetention(ctx context.Context, versionID string) (mode minio.RetentionMode, until time.Time, err *probe.Error) {
+	err = m.withVersion(versionID, func(v *memObjectVersion) *probe.Error {
+		mode, until = v.retentionMode, v.retainUntil
+		return nil
+	})
+	return mode, until, err
+}
+
+// PutObjectLegalHold - set object legal hold for a given object.
+func (m *memClient) PutObjectLegalHold(ctx context.Context, versionID string, lhold minio.LegalHoldStatus) *probe.Error {
+	if !lhold.IsValid() {
+		return errInvalidArgument().Trace(m.targetURL.String())
+	}
+	return m.updateVersion(versionID, func(v *memObjectVersion) *probe.Error {
+		v.legalHold = lhold
+		return nil
+	})
+}
+
+// GetObjectLegalHold - get object legal hold for a given object.
+func (m *memClient) GetObjectLegalHold(ctx context.Context, versionID string) (lhold minio.LegalHoldStatus, err *probe.Error) {
+	err = m.withVersion(versionID, func(v *memObjectVersion) *probe.Error {
+		lhold = v.legalHold
+		return nil
+	})
+	return lhold, err
+}
+
+// GetAccess - get canned access policy of a bucket.
+func (m *memClient) GetAccess(ctx context.Context) (access string, policyJSON string, err *probe.Error) {
+	err = m.withBucket(func(b *memBucket, _ string) *probe.Error {
+		access, policyJSON = b.policy, b.policyJSON
+		return nil
+	})
+	return access, policyJSON, err
+}
+
+// GetAccessRules - get access rules of a bucket.
+func (m *memClient) GetAccessRules(ctx context.Context) (map[string]string, *probe.Error) {
+	policies := map[string]string{}
+	err := m.withBucket(func(b *memBucket, _ string) *probe.Error {
+		bucket, _ := m.url2BucketAndObject()
+		if b.policy != "none" {
+			policies[bucket+"/*"] = b.policy
+		}
+		return nil
+	})
+	return policies, err
+}
+
+// SetAccess - set canned or JSON access policy of a bucket.
+func (m *memClient) SetAccess(ctx context.Context, access string, isJSON bool) *probe.Error {
+	return m.updateBucket(func(b *memBucket, _ string) *probe.Error {
+		if isJSON {
+			b.policy, b.policyJSON = "custom", access
+			return nil
+		}
+		b.policy, b.policyJSON = access, ""
+		return nil
+	})
+}
+
+// GetTags - get tags of bucket or object.
+func (m *memClient) GetTags(ctx context.Context, versionID string) (map[string]string, *probe.Error) {
+	result := map[string]string{}
+	err := m.withBucket(func(b *memBucket, object string) *probe.Error {
+		t := b.tags
+		if object != "" {
+			v, err := b.getVersion(object, versionID)
+			if err != nil {
+				return err.Trace(object, versionID)
+			}
+			t = v.tags
+		}
+		for k, val := range t {
+			result[k] = val
+		}
+		return nil
+	})
+	return result, err
+}
+
+// SetTags - set tags of bucket or object.
+func (m *memClient) SetTags(ctx context.Context, versionID, tagString string) *probe.Error {
+	return m.updateBucket(func(b *memBucket, object string) *probe.Error {
+		t, e := tags.Parse(tagString, object != "")
+		if e != nil {
+			return probe.NewError(e)
+		}
+		if object == "" {
+			if versionID != "" {
+				return probe.NewError(errors.New("setting bucket tags does not support versioning parameters"))
+			}
+			b.tags = t.ToMap()
+			return nil
+		}
+		v, err := b.getVersion(object, versionID)
+		if err != nil {
+			return err.Trace(object, versionID)
+		}
+		v.tags = t.ToMap()
+		return nil
+	})
+}
+
+// DeleteTags - delete tags of bucket or object.
+func (m *memClient) DeleteTags(ctx context.Context, versionID string) *probe.Error {
+	return m.updateBucket(func(b *memBucket, object string) *probe.Error {
+		if object == "" {
+			if versionID != "" {
+				return probe.NewError(errors.New("setting bucket tags does not support versioning parameters"))
+			}
+			b.tags = nil
+			return nil
+		}
+		v, err := b.getVersion(object, versionID)
+		if err != nil {
+			return err.Trace(object, versionID)
+		}
+		v.tags = map[string]string{}
+		return nil
+	})
+}
+
+// GetLifecycle - get current lifecycle configuration.
+func (m *memClient) GetLifecycle(ctx context.Context) (config *lifecycle.Configuration, err *probe.Error) {
+	err = m.withBucket(func(b *memBucket, _ string) *probe.Error {
+		config = b.lifecycle
+		if config == nil {
+			config = lifecycle.NewConfiguration()
+		}
+		return nil
+	})
+	return config, err
+}
+
+// SetLifecycle - set lifecycle configuration on a bucket.
+func (m *memClient) SetLifecycle(ctx context.Context, config *lifecycle.Configuration) *probe.Error {
+	return m.updateBucket(func(b *memBucket, _ string) *probe.Error {
+		b.lifecycle = config
+		return nil
+	})
+}
+
+// GetVersion - gets bucket version info.
+func (m *memClient) GetVersion(ctx context.Context) (config minio.BucketVersioningConfiguration, err *probe.Error) {
+	err = m.withBucket(func(b *memBucket, _ string) *probe.Error {
+		config.Status = b.versioning
+		return nil
+	})
+	return config, err
+}
+
+// SetVersion - set version configuration on a bucket.
+func (m *memClient) SetVersion(ctx context.Context, status string) *probe.Error {
+	return m.updateBucket(func(b *memBucket, _ string) *probe.Error {
+		switch status {
+		case "enable":
+			b.versioning = "Enabled"
+		case "suspend":
+			if b.lockEnabled {
+				return probe.NewError(errors.New("An Object Lock configuration is present on this bucket, so the versioning state cannot be changed"))
+			}
+			b.versioning = "Suspended"
+		default:
+			return probe.NewError(fmt.Errorf("Invalid versioning status"))
+		}
+		return nil
+	})
+}
+
+// GetReplication - gets replication configuration for a given bucket.
+func (m *memClient) GetReplication(ctx context.Context) (cfg replication.Config, err *probe.Error) {
+	err = m.withBucket(func(b *memBucket, _ string) *probe.Error {
+		cfg = b.replication
+		return nil
+	})
+	return cfg, err
+}
+
+// SetReplication - sets replication configuration for a given bucket.
+func (m *memClient) SetReplication(ctx context.Context, cfg *replication.Config, opts replication.Options) *probe.Error {
+	return m.updateBucket(func(b *memBucket, objectPrefix string) *probe.Error {
+		if b.versioning != "Enabled" {
+			return probe.NewError(errors.New("Versioning must be 'Enabled' on the bucket to apply a replication configuration"))
+		}
+		opts.Prefix = objectPrefix
+		switch opts.Op {
+		case replication.AddOption:
+			if e := cfg.AddRule(opts); e != nil {
+				return probe.NewError(e)
+			}
+		case replication.SetOption:
+			if e := cfg.EditRule(opts); e != nil {
+				return probe.NewError(e)
+			}
+		case replication.RemoveOption:
+			if e := cfg.RemoveRule(opts); e != nil {
+				return probe.NewError(e)
+			}
+		case replication.ImportOption:
+		default:
+			return probe.NewError(fmt.Errorf("Invalid replication option"))
+		}
+		b.replication = *cfg
+		return nil
+	})
+}
+
+// RemoveReplication - removes replication configuration for a given bucket.
+func (m *memClient) RemoveReplication(ctx context.Context) *probe.Error {
+	return m.updateBucket(func(b *memBucket, _ string) *probe.Error {
+		b.replication = replication.Config{}
+		return nil
+	})
+}
+
+// GetEncryption - gets bucket encryption info.
+func (m *memClient) GetEncryption(ctx context.Context) (algorithm, keyID string, err *probe.Error) {
+	err = m.withBucket(func(b *memBucket, _ string) *probe.Error {
+		if b.encAlgorithm == "" {
+			return probe.NewError(errors.New("The server side encryption configuration was not found"))
+		}
+		algorithm, keyID = b.encAlgorithm, b.encKeyID
+		return nil
+	})
+	return algorithm, keyID, err
+}
+
+// SetEncryption - set encryption configuration on a bucket.
+func (m *memClient) SetEncryption(ctx context.Context, algorithm, kmsKeyID string) *probe.Error {
+	return m.updateBucket(func(b *memBucket, _ string) *probe.Error {
+		switch strings.ToLower(algorithm) {
+		case "sse-kms":
+			b.encAlgorithm, b.encKeyID = "aws:kms", kmsKeyID
+		case "sse-s3":
+			b.encAlgorithm, b.encKeyID = "AES256", ""
+		default:
+			return probe.NewError(fmt.Errorf("Invalid encryption algorithm %s", algorithm))
+		}
+		return nil
+	})
+}
+
+// DeleteEncryption - removes encryption configuration on a bucket.
+func (m *memClient) DeleteEncryption(ctx context.Context) *probe.Error {
+	return m.updateBucket(func(b *memBucket, _ string) *probe.Error {
+		b.encAlgorithm, b.encKeyID = "", ""
+		return nil
+	})
+}
+
+// GetBucketInfo - gets info about a bucket.
+func (m *memClient) GetBucketInfo(ctx context.Context) (info BucketInfo, err *probe.Error) {
+	err = m.withBucket(func(b *memBucket, _ string) *probe.Error {
+		bucket, _ := m.url2BucketAndObject()
+		content := m.bucket2ClientContent(bucket, b)
+		info.URL = content.URL
+		info.Type = content.Type
+		info.Date = content.Time
+		info.Versioning.Status = b.versioning
+		if b.lockEnabled {
+			info.Locking.Enabled = "Enabled"
+			info.Locking.Mode = b.lockMode
+			if b.lockMode != "" {
+				info.Locking.Validity = fmt.Sprintf("%d%s", b.lockValidity, b.lockUnit)
+			}
+		}
+		info.Replication.Enabled = !b.replication.Empty()
+		info.Replication.Config = b.replication
+		info.Encryption.Algorithm = b.encAlgorithm
+		info.Encryption.KeyID = b.encKeyID
+		info.Policy.Type = b.policy
+		info.Policy.Text = b.policyJSON
+		info.Tagging = b.tags
+		info.ILM.Config = b.lifecycle
+		return nil
+	})
+	return info, err
+}
@@ -0,0 +1,339 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v7"
+	. "gopkg.in/check.v1"
+)
+
+// memPut - uploads data to an in-memory URL.
+func memPut(c *C, urlStr, data string) {
+	clnt, err := newClient(urlStr)
+	c.Assert(err, IsNil)
+	_, err = clnt.Put(context.Background(), bytes.NewReader([]byte(data)), int64(len(data)),
+		map[string]string{}, nil, nil, false, false, false)
+	c.Assert(err, IsNil)
+}
+
+// memList - lists all entries of an in-memory URL.
+func memList(c *C, urlStr string, opts ListOptions) (contents []*ClientContent) {
+	clnt, err := newClient(urlStr)
+	c.Assert(err, IsNil)
+	for content := range clnt.List(context.Background(), opts) {
+		c.Assert(content.Err, IsNil)
+		contents = append(contents, content)
+	}
+	return contents
+}
+
+// memStat - stats an in-memory URL.
+func memStat(c *C, urlStr string) (*ClientContent, *probe.Error) {
+	clnt, err := newClient(urlStr)
+	c.Assert(err, IsNil)
+	return clnt.Stat(context.Background(), StatOptions{})
+}
+
+// TestMemURL - tests parsing in-memory URLs.
+func (s *TestSuite) TestMemURL(c *C) {
+	url := newClientURL("mem://bucket/dir/object")
+	c.Assert(url.Type, Equals, ClientURLType(objectStorage))
+	c.Assert(url.Path, Equals, "/bucket/dir/object")
+	c.Assert(url.String(), Equals, "mem://bucket/dir/object")
+
+	c.Assert(urlJoinPath("mem://bucket", "object"), Equals, "mem://bucket/object")
+	c.Assert(newClientURL("mem://").Path, Equals, "/")
+}
+
+// TestMemPutGetList - tests basic operations of the in-memory client.
+func (s *TestSuite) TestMemPutGetList(c *C) {
+	clnt, err := newClient("mem://mem-basic")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", true, false), IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), NotNil)
+
+	memPut(c, "mem://mem-basic/object1", "hello")
+	memPut(c, "mem://mem-basic/dir/object2", "world")
+
+	clnt, err = newClient("mem://mem-basic/object1")
+	c.Assert(err, IsNil)
+	reader, err := clnt.Get(context.Background(), GetOptions{})
+	c.Assert(err, IsNil)
+	data, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "hello")
+
+	st, err := clnt.Stat(context.Background(), StatOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(st.Size, Equals, int64(5))
+	c.Assert(st.ETag, Equals, "5d41402abc4b2a76b9719d911017c592")
+
+	st, err = memStat(c, "mem://mem-basic/dir")
+	c.Assert(err, IsNil)
+	c.Assert(st.Type.IsDir(), Equals, true)
+
+	contents := memList(c, "mem://mem-basic/", ListOptions{})
+	c.Assert(len(contents), Equals, 2)
+	c.Assert(contents[0].URL.String(), Equals, "mem://mem-basic/dir/")
+	c.Assert(contents[0].Type.IsDir(), Equals, true)
+	c.Assert(contents[1].URL.String(), Equals, "mem://mem-basic/object1")
+
+	contents = memList(c, "mem://mem-basic/", ListOptions{Recursive: true})
+	c.Assert(len(contents), Equals, 2)
+	c.Assert(contents[0].URL.String(), Equals, "mem://mem-basic/dir/object2")
+}
+
+// TestMemVersions - tests versioning, delete markers and rewind.
+func (s *TestSuite) TestMemVersions(c *C) {
+	clnt, err := newClient("mem://mem-versions")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+	c.Assert(clnt.SetVersion(context.Background(), "enable"), IsNil)
+
+	memPut(c, "mem://mem-versions/object", "v1")
+	time.Sleep(10 * time.Millisecond)
+	rewind := time.Now().UTC()
+	time.Sleep(10 * time.Millisecond)
+	memPut(c, "mem://mem-versions/object", "v22")
+
+	objClnt, err := newClient("mem://mem-versions/object")
+	c.Assert(err, IsNil)
+	contentCh := make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: objClnt.GetURL()}
+	close(contentCh)
+	for err := range objClnt.Remove(context.Background(), false, false, false, contentCh) {
+		c.Assert(err, IsNil)
+	}
+
+	_, err = memStat(c, "mem://mem-versions/object")
+	c.Assert(err, NotNil)
+
+	contents := memList(c, "mem://mem-versions/", ListOptions{Recursive: true})
+	c.Assert(len(contents), Equals, 0)
+
+	contents = memList(c, "mem://mem-versions/", ListOptions{Recursive: true, WithOlderVersions: true, WithDeleteMarkers: true})
+	c.Assert(len(contents), Equals, 3)
+	c.Assert(contents[0].IsDeleteMarker, Equals, true)
+	c.Assert(contents[0].IsLatest, Equals, true)
+	c.Assert(contents[2].Size, Equals, int64(2))
+
+	contents = memList(c, "mem://mem-versions/", ListOptions{Recursive: true, TimeRef: rewind})
+	c.Assert(len(contents), Equals, 1)
+	c.Assert(contents[0].Size, Equals, int64(2))
+}
+
+// TestMemLocking - tests that retention and legal hold protect versions.
+func (s *TestSuite) TestMemLocking(c *C) {
+	clnt, err := newClient("mem://mem-locking")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, true), IsNil)
+
+	memPut(c, "mem://mem-locking/object", "data")
+	objClnt, err := newClient("mem://mem-locking/object")
+	c.Assert(err, IsNil)
+	st, err := objClnt.Stat(context.Background(), StatOptions{})
+	c.Assert(err, IsNil)
+
+	c.Assert(objClnt.PutObjectLegalHold(context.Background(), st.VersionID, minio.LegalHoldEnabled), IsNil)
+	lhold, err := objClnt.GetObjectLegalHold(context.Background(), st.VersionID)
+	c.Assert(err, IsNil)
+	c.Assert(lhold, Equals, minio.LegalHoldEnabled)
+
+	remove := func(bypass bool) *probe.Error {
+		contentCh := make(chan *ClientContent, 1)
+		contentCh <- &ClientContent{URL: objClnt.GetURL(), VersionID: st.VersionID}
+		close(contentCh)
+		return <-objClnt.Remove(context.Background(), false, false, bypass, contentCh)
+	}
+	c.Assert(remove(false), NotNil)
+
+	c.Assert(objClnt.PutObjectLegalHold(context.Background(), st.VersionID, minio.LegalHoldDisabled), IsNil)
+	until := time.Now().UTC().Add(time.Hour)
+	c.Assert(objClnt.PutObjectRetention(context.Background(), st.VersionID, minio.Governance, until, false), IsNil)
+	c.Assert(remove(false), NotNil)
+	c.Assert(remove(true), IsNil)
+}
+
+// TestMemDifference - tests diffing two in-memory buckets.
+func (s *TestSuite) TestMemDifference(c *C) {
+	for _, bucket := range []string{"mem://mem-diff-src", "mem://mem-diff-dst"} {
+		clnt, err := newClient(bucket)
+		c.Assert(err, IsNil)
+		c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+	}
+	memPut(c, "mem://mem-diff-src/same", "data")
+	memPut(c, "mem://mem-diff-dst/same", "data")
+	memPut(c, "mem://mem-diff-src/only-source", "data")
+	memPut(c, "mem://mem-diff-src/size", "data")
+	memPut(c, "mem://mem-diff-dst/size", "bigger data")
+
+	srcClnt, err := newClient("mem://mem-diff-src/")
+	c.Assert(err, IsNil)
+	dstClnt, err := newClient("mem://mem-diff-dst/")
+	c.Assert(err, IsNil)
+
+	diffs := map[string]differType{}
+	for diff := range difference(context.Background(), srcClnt, dstClnt, "mem://mem-diff-src/", "mem://mem-diff-dst/", false, nil, true, false, DirNone, time.Time{}) {
+		c.Assert(diff.Error, IsNil)
+		diffs[diff.FirstURL] = diff.Diff
+	}
+	c.Assert(len(diffs), Equals, 2)
+	c.Assert(diffs["mem://mem-diff-src/only-source"], Equals, differInFirst)
+	c.Assert(diffs["mem://mem-diff-src/size"], Equals, differInSize)
+}
+
+// TestMemSnapshot - tests the store is saved to and loaded from its snapshot.
+func (s *TestSuite) TestMemSnapshot(c *C) {
+	ctx := context.Background()
+	dir, e := ioutil.TempDir("", "mc-mem-snapshot-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(dir)
+	snapshot := filepath.Join(dir, "store.json")
+
+	store, err := loadMemStore(snapshot)
+	c.Assert(err, IsNil)
+	bucketClnt := &memClient{targetURL: newClientURL("mem://mem-snapshot"), store: store}
+	c.Assert(bucketClnt.MakeBucket(ctx, "", false, false), IsNil)
+	c.Assert(bucketClnt.SetVersion(ctx, "enable"), IsNil)
+	objClnt := &memClient{targetURL: newClientURL("mem://mem-snapshot/object"), store: store}
+	for _, data := range []string{"v1", "v22"} {
+		_, err = objClnt.Put(ctx, bytes.NewReader([]byte(data)), int64(len(data)),
+			map[string]string{}, nil, nil, false, false, false)
+		c.Assert(err, IsNil)
+	}
+	c.Assert(objClnt.SetTags(ctx, "", "key=value"), IsNil)
+
+	store, err = loadMemStore(snapshot)
+	c.Assert(err, IsNil)
+	bucketClnt = &memClient{targetURL: newClientURL("mem://mem-snapshot"), store: store}
+	versioning, err := bucketClnt.GetVersion(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(versioning.Status, Equals, "Enabled")
+
+	objClnt = &memClient{targetURL: newClientURL("mem://mem-snapshot/object"), store: store}
+	reader, err := objClnt.Get(ctx, GetOptions{})
+	c.Assert(err, IsNil)
+	data, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "v22")
+	tags, err := objClnt.GetTags(ctx, "")
+	c.Assert(err, IsNil)
+	c.Assert(tags, DeepEquals, map[string]string{"key": "value"})
+
+	var versions int
+	for content := range bucketClnt.List(ctx, ListOptions{Recursive: true, WithOlderVersions: true}) {
+		c.Assert(content.Err, IsNil)
+		versions++
+	}
+	c.Assert(versions, Equals, 2)
+}
+
+// TestMemMirror - tests mirroring between in-memory buckets.
+func (s *TestSuite) TestMemMirror(c *C) {
+	defer func(quiet bool) { globalQuiet = quiet }(globalQuiet)
+	globalQuiet = true
+
+	for _, bucket := range []string{"mem://mem-mirror-src", "mem://mem-mirror-dst"} {
+		clnt, err := newClient(bucket)
+		c.Assert(err, IsNil)
+		c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+	}
+	memPut(c, "mem://mem-mirror-src/dir/object", "data")
+	memPut(c, "mem://mem-mirror-src/changed", "new data")
+	memPut(c, "mem://mem-mirror-dst/changed", "old")
+	memPut(c, "mem://mem-mirror-dst/stale", "data")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mj := newMirrorJob("mem://mem-mirror-src", "mem://mem-mirror-dst", mirrorOptions{
+		isOverwrite: true,
+		isRemove:    true,
+	})
+	c.Assert(mj.mirror(ctx, cancel), Equals, false)
+
+	sizes := map[string]int64{}
+	for _, content := range memList(c, "mem://mem-mirror-dst/", ListOptions{Recursive: true}) {
+		sizes[content.URL.String()] = content.Size
+	}
+	c.Assert(sizes, DeepEquals, map[string]int64{
+		"mem://mem-mirror-dst/changed":    8,
+		"mem://mem-mirror-dst/dir/object": 4,
+	})
+}
+
+// TestMemRm - tests removing in-memory objects and versions.
+func (s *TestSuite) TestMemRm(c *C) {
+	clnt, err := newClient("mem://mem-rm")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+	c.Assert(clnt.SetVersion(context.Background(), "enable"), IsNil)
+	memPut(c, "mem://mem-rm/dir/a", "data")
+	memPut(c, "mem://mem-rm/dir/b", "data")
+	memPut(c, "mem://mem-rm/single", "data")
+	memPut(c, "mem://mem-rm/versioned", "v1")
+	memPut(c, "mem://mem-rm/versioned", "v2")
+
+	c.Assert(removeSingle("mem://mem-rm/single", "", false, false, false, false, nil, nil, retryPolicy{}, nil), IsNil)
+	_, err = memStat(c, "mem://mem-rm/single")
+	c.Assert(err, NotNil)
+
+	c.Assert(listAndRemove("mem://mem-rm/dir/", time.Time{}, false, true, false, false, false, nil, nil, retryPolicy{}, nil), IsNil)
+	c.Assert(len(memList(c, "mem://mem-rm/dir/", ListOptions{Recursive: true})), Equals, 0)
+
+	// All versions of a single object.
+	c.Assert(listAndRemove("mem://mem-rm/versioned", time.Now().UTC().Add(time.Second), true, false, false, false, false, nil, nil, retryPolicy{}, nil), IsNil)
+	contents := memList(c, "mem://mem-rm/", ListOptions{Recursive: true, WithOlderVersions: true, WithDeleteMarkers: true})
+	for _, content := range contents {
+		c.Assert(content.URL.Path, Not(Equals), "/mem-rm/versioned")
+	}
+}
+
+// TestMemUndo - tests undoing operations on in-memory objects.
+func (s *TestSuite) TestMemUndo(c *C) {
+	clnt, err := newClient("mem://mem-undo")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+	c.Assert(clnt.SetVersion(context.Background(), "enable"), IsNil)
+	memPut(c, "mem://mem-undo/object", "v1")
+	time.Sleep(10 * time.Millisecond)
+	memPut(c, "mem://mem-undo/object", "v22")
+
+	// Undo the last overwrite.
+	c.Assert(undoURL(context.Background(), "mem://mem-undo/object", undoOptions{last: 1}), IsNil)
+	st, err := memStat(c, "mem://mem-undo/object")
+	c.Assert(err, IsNil)
+	c.Assert(st.Size, Equals, int64(2))
+
+	// Undo a removal.
+	time.Sleep(10 * time.Millisecond)
+	c.Assert(removeSingle("mem://mem-undo/object", "", false, false, false, false, nil, nil, retryPolicy{}, nil), IsNil)
+	_, err = memStat(c, "mem://mem-undo/object")
+	c.Assert(err, NotNil)
+	c.Assert(undoURL(context.Background(), "mem://mem-undo/", undoOptions{last: 1, recursive: true}), IsNil)
+	st, err = memStat(c, "mem://mem-undo/object")
+	c.Assert(err, IsNil)
+	c.Assert(st.Size, Equals, int64(2))
+}
@@ -904,6 +904,53 @@ func (c *S3Client) Copy(ctx context.Context, source string, opts CopyOptions, pr
 	return nil
 }
 
+// Compose - server side concatenation of source objects of the same
+// alias into the target object.
+func (c *S3Client) Compose(ctx context.Context, sources []string, opts CopyOptions, progress io.Reader) *probe.Error {
+	dstBucket, dstObject := c.url2BucketAndObject()
+	if dstBucket == "" {
+		return probe.NewError(BucketNameEmpty{})
+	}
+
+	srcOpts := make([]minio.CopySrcOptions, 0, len(sources))
+	for _, source := range sources {
+		tokens := splitStr(source, string(c.targetURL.Separator), 3)
+		srcOpts = append(srcOpts, minio.CopySrcOptions{
+			Bucket:     tokens[1],
+			Object:     tokens[2],
+			Encryption: opts.srcSSE,
+		})
+	}
+
+	destOpts := minio.CopyDestOptions{
+		Bucket:          dstBucket,
+		Object:          dstObject,
+		Encryption:      opts.tgtSSE,
+		Progress:        progress,
+		Size:            opts.size,
+		UserMetadata:    opts.metadata,
+		ReplaceMetadata: len(opts.metadata) > 0,
+	}
+
+	if _, e := c.api.ComposeObject(ctx, destOpts, srcOpts...); e != nil {
+		errResponse := minio.ToErrorResponse(e)
+		switch errResponse.Code {
+		case "AccessDenied":
+			return probe.NewError(PathInsufficientPermission{
+				Path: c.targetURL.String(),
+			})
+		case "NoSuchBucket":
+			return probe.NewError(BucketDoesNotExist{
+				Bucket: dstBucket,
+			})
+		case "NoSuchKey":
+			return probe.NewError(ObjectMissing{})
+		}
+		return probe.NewError(e)
+	}
+	return nil
+}
+
 // Put - upload an object with custom metadata.
 func (c *S3Client) Put(ctx context.Context, reader io.Reader, size int64, metadata map[string]string, progress io.Reader, sse encrypt.ServerSide, md5, disableMultipart, isPreserve bool) (int64, *probe.Error) {
 	bucket, object := c.url2BucketAndObject()
@@ -1054,6 +1101,20 @@ func (c *S3Client) AddUserAgent(app string, version string) {
 	c.api.SetAppInfo(app, version)
 }
 
+// removeObjectError - returns the error of a failed removal, naming the
+// object it failed on when known.
+func removeObjectError(bucket string, removeStatus minio.RemoveObjectError) *probe.Error {
+	if removeStatus.ObjectName == "" {
+		return probe.NewError(removeStatus.Err)
+	}
+	return probe.NewError(ObjectRemoveFailed{
+		Bucket:    bucket,
+		Object:    removeStatus.ObjectName,
+		VersionID: removeStatus.VersionID,
+		Err:       removeStatus.Err,
+	})
+}
+
 // Remove - remove object or bucket(s).
 func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isBypass bool, contentCh <-chan *ClientContent) <-chan *probe.Error {
 	errorCh := make(chan *probe.Error)
@@ -1110,7 +1171,7 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 						close(objectsCh)
 					}
 					for removeStatus := range statusCh {
-						errorCh <- probe.NewError(removeStatus.Err)
+						errorCh <- removeObjectError(prevBucket, removeStatus)
 					}
 					// Remove bucket if it qualifies.
 					if isRemoveBucket && !isIncomplete {
@@ -1138,7 +1199,7 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 						case objectsCh <- minio.ObjectInfo{Key: objectName, VersionID: objectVersionID}:
 							sent = true
 						case removeStatus := <-statusCh:
-							errorCh <- probe.NewError(removeStatus.Err)
+							errorCh <- removeObjectError(prevBucket, removeStatus)
 						}
 					}
 				} else {
@@ -1159,7 +1220,7 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 		// Write remove objects status to errorCh
 		if statusCh != nil {
 			for removeStatus := range statusCh {
-				errorCh <- probe.NewError(removeStatus.Err)
+				errorCh <- removeObjectError(prevBucket, removeStatus)
 			}
 		}
 		// Remove last bucket if it qualifies.
@@ -0,0 +1,553 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/hookreader"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	// sftpScheme - URL scheme of SFTP aliases, sftp://host[:port].
+	sftpScheme = "sftp"
+	// sftpAPI - alias `api` value of SFTP aliases.
+	sftpAPI         = "sftp"
+	sftpBackendName = "SFTP"
+
+	sftpDefaultPort = "22"
+	sftpDialTimeout = 30 * time.Second
+
+	// sftpFxPermissionDenied - SSH_FX_PERMISSION_DENIED status code.
+	sftpFxPermissionDenied = 3
+)
+
+// sftpIdentityFiles - private keys of the user tried in order,
+// relative to the user's ~/.ssh directory.
+var sftpIdentityFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// sftpDialFunc - establishes a new SFTP connection to host.
+type sftpDialFunc func(host string, config *ssh.ClientConfig) (*sftp.Client, *probe.Error)
+
+// sftpConn - an SFTP session shared by all clients of an alias.
+type sftpConn struct {
+	*sftp.Client
+	// closed once the session has ended.
+	closed chan struct{}
+}
+
+// newSFTPConn - wraps an SFTP session to keep track of its end.
+func newSFTPConn(client *sftp.Client) *sftpConn {
+	conn := &sftpConn{
+		Client: client,
+		closed: make(chan struct{}),
+	}
+	go func() {
+		client.Wait()
+		close(conn.closed)
+	}()
+	return conn
+}
+
+// isClosed - returns true if the session has ended.
+func (c *sftpConn) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// SFTP client, paths of the URL are absolute paths on the server.
+type sftpClient struct {
+	unsupportedClient
+	targetURL *ClientURL
+	conn      *sftpConn
+}
+
+// newSFTPFactory encloses New function with connection cache, SSH
+// connections are expensive to establish so they are shared by all
+// clients of the same alias.
+func newSFTPFactory(dial sftpDialFunc) clientFactory {
+	connCache := make(map[uint32]*sftpConn)
+	var mutex sync.Mutex
+
+	return func(urlStr string, aliasCfg *aliasConfigV10) (Client, *probe.Error) {
+		if aliasCfg == nil {
+			return nil, errInvalidAliasedURL(urlStr).Trace(urlStr)
+		}
+		targetURL := newClientURL(urlStr)
+		host := targetURL.Host
+		if _, _, e := net.SplitHostPort(host); e != nil {
+			host = net.JoinHostPort(host, sftpDefaultPort)
+		}
+
+		// Generate a hash out of the connection parameters.
+		confHash := fnv.New32a()
+		confHash.Write([]byte(host + aliasCfg.AccessKey + aliasCfg.SecretKey))
+		confSum := confHash.Sum32()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		conn, found := connCache[confSum]
+		if !found || conn.isClosed() {
+			hostKeyCallback, err := sftpHostKeyCallback()
+			if err != nil {
+				return nil, err.Trace(urlStr)
+			}
+			auth, closeAgent := sftpAuthMethods(aliasCfg.SecretKey)
+			config := &ssh.ClientConfig{
+				User:            aliasCfg.AccessKey,
+				Auth:            auth,
+				HostKeyCallback: hostKeyCallback,
+				Timeout:         sftpDialTimeout,
+			}
+			client, err := dial(host, config)
+			// The agent is only needed to authenticate.
+			closeAgent()
+			if err != nil {
+				return nil, err.Trace(urlStr)
+			}
+			conn = newSFTPConn(client)
+			connCache[confSum] = conn
+		}
+
+		return &sftpClient{
+			unsupportedClient: unsupportedClient{backend: sftpBackendName},
+			targetURL:         targetURL,
+			conn:              conn,
+		}, nil
+	}
+}
+
+// sftpHostKeyCallback - verifies server keys against the user's
+// known_hosts file, unless --insecure is set.
+func sftpHostKeyCallback() (ssh.HostKeyCallback, *probe.Error) {
+	if globalInsecure {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	homeDir, e := homedir.Dir()
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	callback, e := knownhosts.New(filepath.Join(homeDir, ".ssh", "known_hosts"))
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return callback, nil
+}
+
+// sftpAuthMethods - returns the SSH authentication methods, public
+// keys held by the ssh-agent listening on SSH_AUTH_SOCK and the user's
+// unencrypted identity files in ~/.ssh are tried before the alias secret
+// key which is used as password. The returned function releases the agent.
+func sftpAuthMethods(password string) (methods []ssh.AuthMethod, closeAgent func()) {
+	closeAgent = func() {}
+	var agentClient agent.Agent
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		if agentConn, e := net.Dial("unix", socket); e == nil {
+			agentClient = agent.NewClient(agentConn)
+			closeAgent = func() { agentConn.Close() }
+		}
+	}
+
+	var fileSigners []ssh.Signer
+	if homeDir, e := homedir.Dir(); e == nil {
+		for _, name := range sftpIdentityFiles {
+			key, e := ioutil.ReadFile(filepath.Join(homeDir, ".ssh", name))
+			if e != nil {
+				continue
+			}
+			// Keys protected by a passphrase are expected
+			// to be served by the agent.
+			if signer, e := ssh.ParsePrivateKey(key); e == nil {
+				fileSigners = append(fileSigners, signer)
+			}
+		}
+	}
+
+	// A single public key method, SSH clients never try
+	// a method again once it failed.
+	if agentClient != nil || len(fileSigners) > 0 {
+		methods = append(methods, ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+			var signers []ssh.Signer
+			if agentClient != nil {
+				// An unusable agent leaves the identity files.
+				signers, _ = agentClient.Signers()
+			}
+			return append(signers, fileSigners...), nil
+		}))
+	}
+
+	if password != "" {
+		methods = append(methods,
+			ssh.Password(password),
+			// Answer all keyboard interactive questions
+			// with the password, many servers only
+			// allow this method for password logins.
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = password
+				}
+				return answers, nil
+			}),
+		)
+	}
+	return methods, closeAgent
+}
+
+// sftpDial - connects to an SFTP server over SSH.
+func sftpDial(host string, config *ssh.ClientConfig) (*sftp.Client, *probe.Error) {
+	sshConn, e := ssh.Dial("tcp", host, config)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(host)
+	}
+	conn, e := sftp.NewClient(sshConn)
+	if e != nil {
+		sshConn.Close()
+		return nil, probe.NewError(e).Trace(host)
+	}
+	return conn, nil
+}
+
+// sftpNew - instantiate a new SFTP client.
+var sftpNew = newSFTPFactory(sftpDial)
+
+// GetURL get url.
+func (s *sftpClient) GetURL() ClientURL {
+	return s.targetURL.Clone()
+}
+
+// AddUserAgent - not applicable for SFTP.
+func (s *sftpClient) AddUserAgent(_, _ string) {
+}
+
+// toClientError error constructs a typed client error for known SFTP errors.
+func (s *sftpClient) toClientError(e error, fpath string) *probe.Error {
+	if se, ok := e.(*sftp.StatusError); ok && se.Code == sftpFxPermissionDenied {
+		return probe.NewError(PathInsufficientPermission{Path: fpath})
+	}
+	if os.IsPermission(e) {
+		return probe.NewError(PathInsufficientPermission{Path: fpath})
+	}
+	if os.IsNotExist(e) {
+		return probe.NewError(PathNotFound{Path: fpath})
+	}
+	return probe.NewError(e)
+}
+
+// info2ClientContent converts a remote file info into ClientContent.
+func (s *sftpClient) info2ClientContent(fpath string, fi os.FileInfo) *ClientContent {
+	url := s.targetURL.Clone()
+	url.Path = fpath
+	return &ClientContent{
+		URL:  url,
+		Time: fi.ModTime(),
+		Size: fi.Size(),
+		Type: fi.Mode(),
+		Metadata: map[string]string{
+			"Content-Type": guessURLContentType(fpath),
+		},
+	}
+}
+
+// Stat - get metadata of a remote file or directory.
+func (s *sftpClient) Stat(ctx context.Context, opts StatOptions) (*ClientContent, *probe.Error) {
+	fpath := s.targetURL.Path
+	fi, e := s.conn.Stat(fpath)
+	if e != nil {
+		return nil, s.toClientError(e, fpath).Trace(s.targetURL.String())
+	}
+	return s.info2ClientContent(fpath, fi), nil
+}
+
+// List - list files and directories, a path without a trailing
+// separator is treated as a prefix.
+func (s *sftpClient) List(ctx context.Context, opts ListOptions) <-chan *ClientContent {
+	contentCh := make(chan *ClientContent)
+	go func() {
+		defer close(contentCh)
+		// Incomplete uploads are never listed.
+		if opts.Incomplete {
+			return
+		}
+		send := func(content *ClientContent) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case contentCh <- content:
+				return true
+			}
+		}
+
+		fpath := s.targetURL.Path
+		if !strings.HasSuffix(fpath, "/") {
+			fi, e := s.conn.Stat(fpath)
+			if e == nil && !fi.IsDir() {
+				send(s.info2ClientContent(fpath, fi))
+				return
+			}
+		}
+		dir, prefix := fpath, ""
+		if !strings.HasSuffix(dir, "/") {
+			dir, prefix = path.Dir(fpath), path.Base(fpath)
+			if !strings.HasSuffix(dir, "/") {
+				dir += "/"
+			}
+		}
+		s.listDir(dir, prefix, opts, send)
+	}()
+	return contentCh
+}
+
+// listDir lists entries of dir starting with prefix in lexical
+// order, returns false when the listing was interrupted.
+func (s *sftpClient) listDir(dir, prefix string, opts ListOptions, send func(*ClientContent) bool) bool {
+	entries, e := s.conn.ReadDir(dir)
+	if e != nil {
+		// Missing directories are empty prefixes.
+		if os.IsNotExist(e) {
+			return true
+		}
+		return send(&ClientContent{Err: s.toClientError(e, dir).Trace(dir)})
+	}
+	sort.Sort(byDirName(entries))
+
+	for _, fi := range entries {
+		name := fi.Name()
+		if !strings.HasPrefix(name, prefix) || strings.HasSuffix(name, partSuffix) {
+			continue
+		}
+		fpath := dir + name
+		if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
+			st, e := s.conn.Stat(fpath)
+			if e != nil {
+				// Ignore any errors on symlink
+				continue
+			}
+			fi = st
+		}
+		switch {
+		case fi.IsDir():
+			content := s.info2ClientContent(fpath+"/", fi)
+			if !opts.Recursive {
+				if !send(content) {
+					return false
+				}
+				continue
+			}
+			if opts.ShowDir == DirFirst && !send(content) {
+				return false
+			}
+			if !s.listDir(fpath+"/", "", opts, send) {
+				return false
+			}
+			if opts.ShowDir == DirLast && !send(content) {
+				return false
+			}
+		case fi.Mode().IsRegular():
+			if !send(s.info2ClientContent(fpath, fi)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MakeBucket - create the directory and all its parents.
+func (s *sftpClient) MakeBucket(ctx context.Context, region string, ignoreExisting, withLock bool) *probe.Error {
+	fpath := s.targetURL.Path
+	if e := s.conn.MkdirAll(fpath); e != nil {
+		return s.toClientError(e, fpath).Trace(s.targetURL.String())
+	}
+	return nil
+}
+
+// put writes reader into fpath, data is first uploaded to a temporary
+// file which is renamed once complete.
+func (s *sftpClient) put(fpath string, reader io.Reader, size int64, progress io.Reader) (int64, *probe.Error) {
+	if fpath == "" || strings.HasSuffix(fpath, "/") {
+		return 0, probe.NewError(ObjectNameEmpty{})
+	}
+	if e := s.conn.MkdirAll(path.Dir(fpath)); e != nil {
+		return 0, s.toClientError(e, path.Dir(fpath))
+	}
+
+	partPath := fpath + partSuffix
+	f, e := s.conn.Create(partPath)
+	if e != nil {
+		return 0, s.toClientError(e, partPath)
+	}
+	n, e := io.Copy(f, hookreader.NewHook(reader, progress))
+	if ce := f.Close(); e == nil {
+		e = ce
+	}
+	var err *probe.Error
+	switch {
+	case e != nil:
+		err = probe.NewError(e)
+	case size >= 0 && n < size:
+		err = probe.NewError(UnexpectedEOF{TotalSize: size, TotalWritten: n})
+	case size >= 0 && n > size:
+		err = probe.NewError(UnexpectedExcessRead{TotalSize: size, TotalWritten: n})
+	}
+	if err != nil {
+		s.conn.Remove(partPath)
+		return n, err
+	}
+
+	if e = s.rename(partPath, fpath); e != nil {
+		return n, s.toClientError(e, fpath)
+	}
+	return n, nil
+}
+
+// rename - replaces newpath by oldpath atomically with the
+// posix-rename@openssh.com extension. Servers without it cannot rename
+// over an existing file, which is removed first.
+func (s *sftpClient) rename(oldpath, newpath string) error {
+	e := s.conn.PosixRename(oldpath, newpath)
+	if se, ok := e.(*sftp.StatusError); !ok || se.FxCode() != sftp.ErrSSHFxOpUnsupported {
+		return e
+	}
+	if e = s.conn.Remove(newpath); e != nil && !os.IsNotExist(e) {
+		return e
+	}
+	return s.conn.Rename(oldpath, newpath)
+}
+
+// Put - upload a file, metadata are not preserved.
+func (s *sftpClient) Put(ctx context.Context, reader io.Reader, size int64, metadata map[string]string, progress io.Reader, sse encrypt.ServerSide, md5, disableMultipart, isPreserve bool) (int64, *probe.Error) {
+	n, err := s.put(s.targetURL.Path, reader, size, progress)
+	if err != nil {
+		return n, err.Trace(s.targetURL.String())
+	}
+	return n, nil
+}
+
+// Copy - copy a file of the same server, data goes through the client.
+func (s *sftpClient) Copy(ctx context.Context, source string, opts CopyOptions, progress io.Reader) *probe.Error {
+	rc, e := s.conn.Open(source)
+	if e != nil {
+		return s.toClientError(e, source).Trace(source)
+	}
+	defer rc.Close()
+
+	if _, err := s.put(s.targetURL.Path, rc, opts.size, progress); err != nil {
+		return err.Trace(s.targetURL.String(), source)
+	}
+	return nil
+}
+
+// Compose - concatenate files of the same server, data goes through
+// the client.
+func (s *sftpClient) Compose(ctx context.Context, sources []string, opts CopyOptions, progress io.Reader) *probe.Error {
+	readers := make([]io.Reader, 0, len(sources))
+	for _, source := range sources {
+		rc, e := s.conn.Open(source)
+		if e != nil {
+			return s.toClientError(e, source).Trace(source)
+		}
+		defer rc.Close()
+		readers = append(readers, rc)
+	}
+
+	if _, err := s.put(s.targetURL.Path, io.MultiReader(readers...), opts.size, progress); err != nil {
+		return err.Trace(s.targetURL.String())
+	}
+	return nil
+}
+
+// Get - returns a reader of a remote file.
+func (s *sftpClient) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *probe.Error) {
+	fpath := s.targetURL.Path
+	f, e := s.conn.Open(fpath)
+	if e != nil {
+		return nil, s.toClientError(e, fpath).Trace(s.targetURL.String())
+	}
+	return f, nil
+}
+
+// remove - removes a file, or a directory if it is empty.
+func (s *sftpClient) remove(fpath string, isDir bool) error {
+	if !isDir {
+		return s.conn.Remove(fpath)
+	}
+	fpath = strings.TrimSuffix(fpath, "/")
+	entries, e := s.conn.ReadDir(fpath)
+	if e != nil {
+		return e
+	}
+	// Non empty directories are left untouched.
+	if len(entries) > 0 {
+		return nil
+	}
+	return s.conn.RemoveDirectory(fpath)
+}
+
+// Remove - remove files and empty directories.
+func (s *sftpClient) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isBypass bool, contentCh <-chan *ClientContent) <-chan *probe.Error {
+	errorCh := make(chan *probe.Error)
+	go func() {
+		defer close(errorCh)
+
+		for content := range contentCh {
+			if content.Err != nil {
+				errorCh <- content.Err
+				continue
+			}
+			fpath := content.URL.Path
+			isDir := content.Type.IsDir() || strings.HasSuffix(fpath, "/")
+			// Add partSuffix for incomplete uploads.
+			if isIncomplete {
+				fpath += partSuffix
+				isDir = false
+			}
+			e := s.remove(fpath, isDir)
+			if e == nil {
+				continue
+			}
+			if os.IsNotExist(e) && isRemoveBucket {
+				// ignore PathNotFound for dir removal.
+				continue
+			}
+			errorCh <- s.toClientError(e, fpath).Trace(content.URL.String())
+		}
+	}()
+	return errorCh
+}
@@ -0,0 +1,176 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	. "gopkg.in/check.v1"
+)
+
+// newTestSFTPFactory - returns a factory connecting to an in-process
+// SFTP server serving the local filesystem.
+func newTestSFTPFactory() clientFactory {
+	return newSFTPFactory(func(host string, config *ssh.ClientConfig) (*sftp.Client, *probe.Error) {
+		clientReader, serverWriter := io.Pipe()
+		serverReader, clientWriter := io.Pipe()
+		server, e := sftp.NewServer(struct {
+			io.Reader
+			io.WriteCloser
+		}{serverReader, serverWriter})
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		go server.Serve()
+		conn, e := sftp.NewClientPipe(clientReader, clientWriter)
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		return conn, nil
+	})
+}
+
+// Test SFTP client operations against an in-process server.
+func (s *TestSuite) TestSFTPClient(c *C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("the test server serves paths of the local filesystem")
+	}
+	root, e := ioutil.TempDir("", "sftp-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	// The server serves absolute paths of the local filesystem.
+	root = filepath.ToSlash(root)
+	rootURL := "sftp://localhost" + root
+
+	sftpNew := newTestSFTPFactory()
+	aliasCfg := &aliasConfigV10{URL: "sftp://localhost", AccessKey: "user", SecretKey: "password", API: sftpAPI}
+	newSFTPClient := func(fpath string) Client {
+		clnt, err := sftpNew(urlJoinPath(rootURL, fpath), aliasCfg)
+		c.Assert(err, IsNil)
+		return clnt
+	}
+
+	data := "hello sftp"
+	for _, object := range []string{"in/file1", "in/dir/file2", "in-other"} {
+		clnt := newSFTPClient(object)
+		n, err := clnt.Put(context.Background(), bytes.NewReader([]byte(data)), int64(len(data)), nil, nil, nil, false, false, false)
+		c.Assert(err, IsNil)
+		c.Assert(n, Equals, int64(len(data)))
+	}
+	local, e := ioutil.ReadFile(filepath.Join(root, "in", "dir", "file2"))
+	c.Assert(e, IsNil)
+	c.Assert(string(local), Equals, data)
+
+	// Existing files are replaced.
+	overwrite := "hello again"
+	_, err := newSFTPClient("in-other").Put(context.Background(), bytes.NewReader([]byte(overwrite)), int64(len(overwrite)), nil, nil, nil, false, false, false)
+	c.Assert(err, IsNil)
+	local, e = ioutil.ReadFile(filepath.Join(root, "in-other"))
+	c.Assert(e, IsNil)
+	c.Assert(string(local), Equals, overwrite)
+
+	clnt := newSFTPClient("in/file1")
+	st, err := clnt.Stat(context.Background(), StatOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(st.Size, Equals, int64(len(data)))
+	c.Assert(st.URL.String(), Equals, rootURL+"/in/file1")
+
+	reader, err := clnt.Get(context.Background(), GetOptions{})
+	c.Assert(err, IsNil)
+	content, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	c.Assert(reader.Close(), IsNil)
+	c.Assert(string(content), Equals, data)
+
+	var urls []string
+	for content := range newSFTPClient("in/").List(context.Background(), ListOptions{Recursive: true}) {
+		c.Assert(content.Err, IsNil)
+		urls = append(urls, content.URL.String())
+	}
+	c.Assert(urls, DeepEquals, []string{rootURL + "/in/dir/file2", rootURL + "/in/file1"})
+
+	urls = nil
+	for content := range newSFTPClient("in").List(context.Background(), ListOptions{}) {
+		c.Assert(content.Err, IsNil)
+		urls = append(urls, content.URL.String())
+	}
+	c.Assert(urls, DeepEquals, []string{rootURL + "/in-other", rootURL + "/in/"})
+
+	contentCh := make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: clnt.GetURL()}
+	close(contentCh)
+	for err := range clnt.Remove(context.Background(), false, false, false, contentCh) {
+		c.Assert(err, IsNil)
+	}
+	_, err = clnt.Stat(context.Background(), StatOptions{})
+	c.Assert(err, NotNil)
+	_, ok := err.ToGoError().(PathNotFound)
+	c.Assert(ok, Equals, true)
+}
+
+// Test SSH authentication methods of SFTP aliases.
+func (s *TestSuite) TestSFTPAuthMethods(c *C) {
+	home, e := ioutil.TempDir("", "sftp-home-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(home)
+
+	defer func(cache bool) { homedir.DisableCache = cache }(homedir.DisableCache)
+	homedir.DisableCache = true
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	defer os.Setenv("SSH_AUTH_SOCK", os.Getenv("SSH_AUTH_SOCK"))
+	c.Assert(os.Setenv("HOME", home), IsNil)
+	c.Assert(os.Setenv("SSH_AUTH_SOCK", ""), IsNil)
+
+	methods, closeAgent := sftpAuthMethods("")
+	closeAgent()
+	c.Assert(len(methods), Equals, 0)
+
+	// Password and keyboard interactive logins.
+	methods, closeAgent = sftpAuthMethods("password")
+	closeAgent()
+	c.Assert(len(methods), Equals, 2)
+
+	// Identity files are tried first.
+	key, e := rsa.GenerateKey(rand.Reader, 1024)
+	c.Assert(e, IsNil)
+	c.Assert(os.Mkdir(filepath.Join(home, ".ssh"), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(home, ".ssh", "id_rsa"), pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}), 0600), IsNil)
+	// Unparsable keys are ignored.
+	c.Assert(ioutil.WriteFile(filepath.Join(home, ".ssh", "id_ed25519"), []byte("garbage"), 0600), IsNil)
+
+	methods, closeAgent = sftpAuthMethods("password")
+	closeAgent()
+	c.Assert(len(methods), Equals, 3)
+}
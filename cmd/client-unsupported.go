@@ -0,0 +1,185 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/minio/minio-go/v7/pkg/replication"
+)
+
+// unsupportedClient - implements the optional Client operations by
+// returning APINotImplemented. Backends supporting only some of them
+// embed it and implement the ones they support.
+type unsupportedClient struct {
+	// Name of the embedding backend as reported in errors.
+	backend string
+}
+
+// notImplemented - returns the error of an unsupported operation.
+func (u unsupportedClient) notImplemented(api string) *probe.Error {
+	return probe.NewError(APINotImplemented{
+		API:     api,
+		APIType: u.backend,
+	})
+}
+
+// Compose - not supported.
+func (u unsupportedClient) Compose(ctx context.Context, sources []string, opts CopyOptions, progress io.Reader) *probe.Error {
+	return u.notImplemented("Compose")
+}
+
+// SetObjectLockConfig - not supported.
+func (u unsupportedClient) SetObjectLockConfig(ctx context.Context, mode minio.RetentionMode, validity uint64, unit minio.ValidityUnit) *probe.Error {
+	return u.notImplemented("SetObjectLockConfig")
+}
+
+// GetObjectLockConfig - not supported.
+func (u unsupportedClient) GetObjectLockConfig(ctx context.Context) (status string, mode minio.RetentionMode, validity uint64, unit minio.ValidityUnit, err *probe.Error) {
+	return "", "", 0, "", u.notImplemented("GetObjectLockConfig")
+}
+
+// GetAccess - not supported.
+func (u unsupportedClient) GetAccess(ctx context.Context) (access string, policyJSON string, err *probe.Error) {
+	return "", "", u.notImplemented("GetAccess")
+}
+
+// GetAccessRules - not supported.
+func (u unsupportedClient) GetAccessRules(ctx context.Context) (map[string]string, *probe.Error) {
+	return map[string]string{}, u.notImplemented("GetAccessRules")
+}
+
+// SetAccess - not supported.
+func (u unsupportedClient) SetAccess(ctx context.Context, access string, isJSON bool) *probe.Error {
+	return u.notImplemented("SetAccess")
+}
+
+// Select - not supported.
+func (u unsupportedClient) Select(ctx context.Context, expression string, sse encrypt.ServerSide, opts SelectObjectOpts) (io.ReadCloser, *probe.Error) {
+	return nil, u.notImplemented("Select")
+}
+
+// PutObjectRetention - not supported.
+func (u unsupportedClient) PutObjectRetention(ctx context.Context, versionID string, mode minio.RetentionMode, retainUntilDate time.Time, bypassGovernance bool) *probe.Error {
+	return u.notImplemented("PutObjectRetention")
+}
+
+// GetObjectRetention - not supported.
+func (u unsupportedClient) GetObjectRetention(ctx context.Context, versionID string) (minio.RetentionMode, time.Time, *probe.Error) {
+	return "", time.Time{}, u.notImplemented("GetObjectRetention")
+}
+
+// PutObjectLegalHold - not supported.
+func (u unsupportedClient) PutObjectLegalHold(ctx context.Context, versionID string, lhold minio.LegalHoldStatus) *probe.Error {
+	return u.notImplemented("PutObjectLegalHold")
+}
+
+// GetObjectLegalHold - not supported.
+func (u unsupportedClient) GetObjectLegalHold(ctx context.Context, versionID string) (minio.LegalHoldStatus, *probe.Error) {
+	return "", u.notImplemented("GetObjectLegalHold")
+}
+
+// ShareDownload - not supported.
+func (u unsupportedClient) ShareDownload(ctx context.Context, versionID string, expires time.Duration) (string, *probe.Error) {
+	return "", u.notImplemented("ShareDownload")
+}
+
+// ShareUpload - not supported.
+func (u unsupportedClient) ShareUpload(ctx context.Context, isRecursive bool, expires time.Duration, contentType string) (string, map[string]string, *probe.Error) {
+	return "", nil, u.notImplemented("ShareUpload")
+}
+
+// Watch - not supported.
+func (u unsupportedClient) Watch(ctx context.Context, options WatchOptions) (*WatchObject, *probe.Error) {
+	return nil, u.notImplemented("Watch")
+}
+
+// GetTags - not supported.
+func (u unsupportedClient) GetTags(ctx context.Context, versionID string) (map[string]string, *probe.Error) {
+	return nil, u.notImplemented("GetTags")
+}
+
+// SetTags - not supported.
+func (u unsupportedClient) SetTags(ctx context.Context, versionID, tags string) *probe.Error {
+	return u.notImplemented("SetTags")
+}
+
+// DeleteTags - not supported.
+func (u unsupportedClient) DeleteTags(ctx context.Context, versionID string) *probe.Error {
+	return u.notImplemented("DeleteTags")
+}
+
+// GetLifecycle - not supported.
+func (u unsupportedClient) GetLifecycle(ctx context.Context) (*lifecycle.Configuration, *probe.Error) {
+	return nil, u.notImplemented("GetLifecycle")
+}
+
+// SetLifecycle - not supported.
+func (u unsupportedClient) SetLifecycle(ctx context.Context, config *lifecycle.Configuration) *probe.Error {
+	return u.notImplemented("SetLifecycle")
+}
+
+// GetVersion - not supported.
+func (u unsupportedClient) GetVersion(ctx context.Context) (minio.BucketVersioningConfiguration, *probe.Error) {
+	return minio.BucketVersioningConfiguration{}, u.notImplemented("GetVersion")
+}
+
+// SetVersion - not supported.
+func (u unsupportedClient) SetVersion(ctx context.Context, status string) *probe.Error {
+	return u.notImplemented("SetVersion")
+}
+
+// GetReplication - not supported.
+func (u unsupportedClient) GetReplication(ctx context.Context) (replication.Config, *probe.Error) {
+	return replication.Config{}, u.notImplemented("GetReplication")
+}
+
+// SetReplication - not supported.
+func (u unsupportedClient) SetReplication(ctx context.Context, cfg *replication.Config, opts replication.Options) *probe.Error {
+	return u.notImplemented("SetReplication")
+}
+
+// RemoveReplication - not supported.
+func (u unsupportedClient) RemoveReplication(ctx context.Context) *probe.Error {
+	return u.notImplemented("RemoveReplication")
+}
+
+// GetEncryption - not supported.
+func (u unsupportedClient) GetEncryption(ctx context.Context) (string, string, *probe.Error) {
+	return "", "", u.notImplemented("GetEncryption")
+}
+
+// SetEncryption - not supported.
+func (u unsupportedClient) SetEncryption(ctx context.Context, algorithm, kmsKeyID string) *probe.Error {
+	return u.notImplemented("SetEncryption")
+}
+
+// DeleteEncryption - not supported.
+func (u unsupportedClient) DeleteEncryption(ctx context.Context) *probe.Error {
+	return u.notImplemented("DeleteEncryption")
+}
+
+// GetBucketInfo - not supported.
+func (u unsupportedClient) GetBucketInfo(ctx context.Context) (BucketInfo, *probe.Error) {
+	return BucketInfo{}, u.notImplemented("GetBucketInfo")
+}
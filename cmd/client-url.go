@@ -95,6 +95,9 @@ func getHost(authority string) (host string) {
 // newClientURL returns an abstracted URL for filesystems and object storage.
 func newClientURL(urlStr string) *ClientURL {
 	scheme, rest := getScheme(urlStr)
+	if scheme == memScheme && strings.HasPrefix(rest, "//") {
+		return newMemClientURL(rest[2:])
+	}
 	if strings.HasPrefix(rest, "//") {
 		// if rest has '//' prefix, skip them
 		var authority string
@@ -103,7 +106,7 @@ func newClientURL(urlStr string) *ClientURL {
 			rest = "/"
 		}
 		host := getHost(authority)
-		if host != "" && (scheme == "http" || scheme == "https") {
+		if host != "" && (scheme == "http" || scheme == "https" || scheme == sftpScheme) {
 			return &ClientURL{
 				Scheme:          scheme,
 				Type:            objectStorage,
@@ -161,17 +164,22 @@ func (u ClientURL) String() string {
 		if h := u.Host; h != "" {
 			buf.WriteString(h)
 		}
+		path := u.Path
+		if u.Host == "" {
+			// Host less URLs carry the bucket in place of the host.
+			path = strings.TrimPrefix(path, "/")
+		}
 		switch runtime.GOOS {
 		case "windows":
-			if u.Path != "" && u.Path[0] != '\\' && u.Host != "" && u.Path[0] != '/' {
+			if path != "" && path[0] != '\\' && u.Host != "" && path[0] != '/' {
 				buf.WriteByte('/')
 			}
-			buf.WriteString(strings.Replace(u.Path, "\\", "/", -1))
+			buf.WriteString(strings.Replace(path, "\\", "/", -1))
 		default:
-			if u.Path != "" && u.Path[0] != '/' && u.Host != "" {
+			if path != "" && path[0] != '/' && u.Host != "" {
 				buf.WriteByte('/')
 			}
-			buf.WriteString(u.Path)
+			buf.WriteString(path)
 		}
 	}
 	return buf.String()
@@ -186,7 +194,7 @@ func urlJoinPath(url1, url2 string) string {
 
 // url2Stat returns stat info for URL.
 func url2Stat(ctx context.Context, urlStr, versionID string, fileAttr bool, encKeyDB map[string][]prefixSSEPair, timeRef time.Time) (client Client, content *ClientContent, err *probe.Error) {
-	client, err = newClient(urlStr)
+	client, err = newClientWithKeys(urlStr, encKeyDB)
 	if err != nil {
 		return nil, nil, err.Trace(urlStr)
 	}
@@ -102,6 +102,7 @@ type Client interface {
 
 	// I/O operations
 	Copy(ctx context.Context, source string, opts CopyOptions, progress io.Reader) *probe.Error
+	Compose(ctx context.Context, sources []string, opts CopyOptions, progress io.Reader) *probe.Error
 
 	// Runs select expression on object storage on specific files.
 	Select(ctx context.Context, expression string, sse encrypt.ServerSide, opts SelectObjectOpts) (io.ReadCloser, *probe.Error)
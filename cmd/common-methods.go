@@ -21,6 +21,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -32,6 +33,7 @@ import (
 	"gopkg.in/h2non/filetype.v1"
 
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/hookreader"
 	"github.com/minio/mc/pkg/probe"
 	minio "github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/encrypt"
@@ -98,6 +100,11 @@ func getEncKeys(ctx *cli.Context) (map[string][]prefixSSEPair, *probe.Error) {
 		return nil, err.Trace(sseKeys)
 	}
 
+	// Client-side encryption keys are looked up like SSE keys.
+	if err = addClientEncryptionKeys(encKeyDB, getClientEncryptionKeys(ctx)); err != nil {
+		return nil, err.Trace()
+	}
+
 	return encKeyDB, nil
 }
 
@@ -155,7 +162,8 @@ func getSourceStreamMetadataFromURL(ctx context.Context, aliasedURL, versionID s
 		versionID = content.VersionID
 	}
 	sseKey := getSSE(aliasedURL, encKeyDB[alias])
-	return getSourceStream(ctx, alias, urlStrFull, versionID, true, sseKey, false)
+	cseKey := getClientEncKey(aliasedURL, encKeyDB[alias])
+	return getSourceStream(ctx, alias, urlStrFull, versionID, true, sseKey, cseKey, false)
 }
 
 // getSourceStreamFromURL gets a reader from URL.
@@ -165,7 +173,8 @@ func getSourceStreamFromURL(ctx context.Context, urlStr, versionID string, encKe
 		return nil, err.Trace(urlStr)
 	}
 	sse := getSSE(urlStr, encKeyDB[alias])
-	reader, _, err = getSourceStream(ctx, alias, urlStrFull, versionID, false, sse, false)
+	cseKey := getClientEncKey(urlStr, encKeyDB[alias])
+	reader, _, err = getSourceStream(ctx, alias, urlStrFull, versionID, false, sse, cseKey, false)
 	return reader, err
 }
 
@@ -217,11 +226,12 @@ func isReadAt(reader io.Reader) (ok bool) {
 }
 
 // getSourceStream gets a reader from URL.
-func getSourceStream(ctx context.Context, alias, urlStr, versionID string, fetchStat bool, sse encrypt.ServerSide, preserve bool) (reader io.ReadCloser, metadata map[string]string, err *probe.Error) {
+func getSourceStream(ctx context.Context, alias, urlStr, versionID string, fetchStat bool, sse encrypt.ServerSide, cseKey []byte, preserve bool) (reader io.ReadCloser, metadata map[string]string, err *probe.Error) {
 	sourceClnt, err := newClientFromAlias(alias, urlStr)
 	if err != nil {
 		return nil, nil, err.Trace(alias, urlStr)
 	}
+	sourceClnt = withClientEncryption(sourceClnt, alias, cseKey)
 	reader, err = sourceClnt.Get(ctx, GetOptions{SSE: sse, VersionID: versionID})
 	if err != nil {
 		return nil, nil, err.Trace(alias, urlStr)
@@ -273,7 +283,7 @@ func getSourceStream(ctx context.Context, alias, urlStr, versionID string, fetch
 			}
 		}
 	}
-	return reader, metadata, nil
+	return limitDownload(ctx, sourceClnt, reader), metadata, nil
 }
 
 // putTargetRetention sets retention headers if any
@@ -304,11 +314,12 @@ func putTargetRetention(ctx context.Context, alias string, urlStr string, metada
 }
 
 // putTargetStream writes to URL from Reader.
-func putTargetStream(ctx context.Context, alias, urlStr, mode, until, legalHold string, reader io.Reader, size int64, metadata map[string]string, progress io.Reader, sse encrypt.ServerSide, md5, disableMultipart, preserve bool) (int64, *probe.Error) {
+func putTargetStream(ctx context.Context, alias, urlStr, mode, until, legalHold string, reader io.Reader, size int64, metadata map[string]string, progress io.Reader, sse encrypt.ServerSide, cseKey []byte, md5, disableMultipart, preserve bool) (int64, *probe.Error) {
 	targetClnt, err := newClientFromAlias(alias, urlStr)
 	if err != nil {
 		return 0, err.Trace(alias, urlStr)
 	}
+	targetClnt = withClientEncryption(targetClnt, alias, cseKey)
 
 	if mode != "" {
 		metadata[AmzObjectLockMode] = mode
@@ -319,6 +330,7 @@ func putTargetStream(ctx context.Context, alias, urlStr, mode, until, legalHold
 	if legalHold != "" {
 		metadata[AmzObjectLockLegalHold] = legalHold
 	}
+	reader = limitUpload(ctx, targetClnt, reader)
 	n, err := targetClnt.Put(ctx, reader, size, metadata, progress, sse, md5, disableMultipart, preserve)
 	if err != nil {
 		return n, err.Trace(alias, urlStr)
@@ -327,7 +339,7 @@ func putTargetStream(ctx context.Context, alias, urlStr, mode, until, legalHold
 }
 
 // putTargetStreamWithURL writes to URL from reader. If length=-1, read until EOF.
-func putTargetStreamWithURL(urlStr string, reader io.Reader, size int64, sse encrypt.ServerSide, md5, disableMultipart, preserve bool, metadata map[string]string) (int64, *probe.Error) {
+func putTargetStreamWithURL(urlStr string, reader io.Reader, size int64, sse encrypt.ServerSide, cseKey []byte, md5, disableMultipart, preserve bool, metadata map[string]string) (int64, *probe.Error) {
 	alias, urlStrFull, _, err := expandAlias(urlStr)
 	if err != nil {
 		return 0, err.Trace(alias, urlStr)
@@ -337,7 +349,7 @@ func putTargetStreamWithURL(urlStr string, reader io.Reader, size int64, sse enc
 		metadata = map[string]string{}
 	}
 	metadata["Content-Type"] = contentType
-	return putTargetStream(context.Background(), alias, urlStrFull, "", "", "", reader, size, metadata, nil, sse, md5, disableMultipart, preserve)
+	return putTargetStream(context.Background(), alias, urlStrFull, "", "", "", reader, size, metadata, nil, sse, cseKey, md5, disableMultipart, preserve)
 }
 
 // copySourceToTargetURL copies to targetURL from source.
@@ -407,10 +419,31 @@ func getAllMetadata(ctx context.Context, sourceAlias, sourceURLStr string, srcSS
 	return filterMetadata(metadata), nil
 }
 
-// uploadSourceToTargetURL - uploads to targetURL from source.
+// uploadSourceToTargetURL - uploads to targetURL from source,
+// retrying transient failures as urls.Retry allows.
+func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader, encKeyDB map[string][]prefixSSEPair, preserve bool) URLs {
+	if urls.Retry.attempts <= 0 {
+		return tryUploadSourceToTargetURL(ctx, urls, progress, encKeyDB, preserve)
+	}
+	// Bytes sent again by retries are not reported twice.
+	var rp *retryProgress
+	if progress != nil {
+		rp = &retryProgress{progress: progress}
+		progress = rp
+	}
+	err := urls.Retry.run(ctx, urls.SourceContent.URL.String(), func() *probe.Error {
+		if rp != nil {
+			rp.reset()
+		}
+		return tryUploadSourceToTargetURL(ctx, urls, progress, encKeyDB, preserve).Error
+	})
+	return urls.WithError(err)
+}
+
+// tryUploadSourceToTargetURL - uploads to targetURL from source.
 // optionally optimizes copy for object sizes <= 5GiB by using
 // server side copy operation.
-func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader, encKeyDB map[string][]prefixSSEPair, preserve bool) URLs {
+func tryUploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader, encKeyDB map[string][]prefixSSEPair, preserve bool) URLs {
 	sourceAlias := urls.SourceAlias
 	sourceURL := urls.SourceContent.URL
 	sourceVersion := urls.SourceContent.VersionID
@@ -422,6 +455,8 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 
 	srcSSE := getSSE(sourcePath, encKeyDB[sourceAlias])
 	tgtSSE := getSSE(targetPath, encKeyDB[targetAlias])
+	srcCSE := getClientEncKey(sourcePath, encKeyDB[sourceAlias])
+	tgtCSE := getClientEncKey(targetPath, encKeyDB[targetAlias])
 
 	var err *probe.Error
 	var metadata = map[string]string{}
@@ -471,8 +506,13 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 		metadata[http.CanonicalHeaderKey(k)] = v
 	}
 
-	// Optimize for server side copy if the host is same.
-	if sourceAlias == targetAlias {
+	// Optimize for server side copy if the host is same, URLs without
+	// alias are only copied server side within the same backend.
+	// Archive entries, client-side encrypted and compressed objects are
+	// always streamed.
+	if sourceAlias == targetAlias && sourceURL.Scheme == targetURL.Scheme &&
+		!isAliasArchiveURL(sourceAlias, sourceURL.String()) && !isAliasArchiveURL(targetAlias, targetURL.String()) &&
+		srcCSE == nil && tgtCSE == nil && urls.Compress == "" {
 		// preserve new metadata and save existing ones.
 		if preserve {
 			currentMetadata, err := getAllMetadata(ctx, sourceAlias, sourceURL.String(), srcSSE, urls)
@@ -531,12 +571,18 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 
 		var reader io.ReadCloser
 		// Proceed with regular stream copy.
-		reader, metadata, err = getSourceStream(ctx, sourceAlias, sourceURL.String(), sourceVersion, true, srcSSE, preserve)
+		reader, metadata, err = getSourceStream(ctx, sourceAlias, sourceURL.String(), sourceVersion, true, srcSSE, srcCSE, preserve)
 		if err != nil {
 			return urls.WithError(err.Trace(sourceURL.String()))
 		}
 		defer reader.Close()
 
+		// Objects decrypted on the way are not written with their
+		// encryption metadata.
+		if srcCSE != nil {
+			filterClientEncryptionMetadata(metadata)
+		}
+
 		// Get metadata from target content as well
 		for k, v := range urls.TargetContent.Metadata {
 			metadata[http.CanonicalHeaderKey(k)] = v
@@ -547,16 +593,34 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 			metadata[http.CanonicalHeaderKey(k)] = v
 		}
 
-		if isReadAt(reader) {
-			_, err = putTargetStream(ctx, targetAlias, targetURL.String(), mode, until,
-				legalHold, reader, length, filterMetadata(metadata),
-				progress, tgtSSE, urls.MD5, urls.DisableMultipart, preserve)
-		} else {
-			_, err = putTargetStream(ctx, targetAlias, targetURL.String(), mode, until,
-				legalHold, io.LimitReader(reader, length),
-				length, filterMetadata(metadata), progress, tgtSSE, urls.MD5,
-				urls.DisableMultipart, preserve)
+		var putReader io.Reader = reader
+		if !isReadAt(reader) {
+			putReader = io.LimitReader(reader, length)
+		}
+
+		// Compressed objects are decompressed when written to the
+		// filesystem, others are compressed when requested. Progress
+		// is reported on the source stream in both cases.
+		codec, size := streamCompression(metadata)
+		switch {
+		case codec != "" && targetURL.Type == fileSystem:
+			var decReader io.ReadCloser
+			decReader, err = decompressStream(ioutil.NopCloser(hookreader.NewHook(putReader, progress)), codec)
+			if err != nil {
+				return urls.WithError(err.Trace(sourceURL.String()))
+			}
+			defer decReader.Close()
+			filterCompressionMetadata(metadata)
+			putReader, length, progress = decReader, size, nil
+		case codec == "" && urls.Compress != "":
+			compReader := compressStream(hookreader.NewHook(putReader, progress), urls.Compress, length, metadata)
+			defer compReader.Close()
+			putReader, length, progress = compReader, -1, nil
 		}
+
+		_, err = putTargetStream(ctx, targetAlias, targetURL.String(), mode, until,
+			legalHold, putReader, length, filterMetadata(metadata),
+			progress, tgtSSE, tgtCSE, urls.MD5, urls.DisableMultipart, preserve)
 	}
 	if err != nil {
 		return urls.WithError(err.Trace(sourceURL.String()))
@@ -566,31 +630,30 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 }
 
 // newClientFromAlias gives a new client interface for matching
-// alias entry in the mc config file. If no matching host config entry
-// is found, fs client is returned.
+// alias entry in the mc config file. The client is instantiated by
+// the backend registered for the alias `api` value or the URL scheme,
+// if no matching entry is found, fs client is returned. Remote
+// objects are read through the local cache when enabled.
 func newClientFromAlias(alias, urlStr string) (Client, *probe.Error) {
 	alias, _, hostCfg, err := expandAlias(alias)
 	if err != nil {
 		return nil, err.Trace(alias, urlStr)
 	}
 
-	if hostCfg == nil {
-		// No matching host config. So we treat it like a
-		// filesystem.
-		fsClient, fsErr := fsNew(urlStr)
-		if fsErr != nil {
-			return nil, fsErr.Trace(alias, urlStr)
-		}
-		return fsClient, nil
-	}
-
-	s3Config := NewS3Config(urlStr, hostCfg)
-
-	s3Client, err := S3New(s3Config)
+	backend := getClientBackend(urlStr, hostCfg)
+	clnt, err := backend.New(urlStr, hostCfg)
 	if err != nil {
 		return nil, err.Trace(alias, urlStr)
 	}
-	return s3Client, nil
+	// Local files and archive entries are never cached.
+	if globalCache && backend.Name != fsBackendName && backend.Name != archiveBackendName {
+		store, err := getCacheStore()
+		if err != nil {
+			return nil, err.Trace(alias, urlStr)
+		}
+		clnt = cacheNew(clnt, store)
+	}
+	return clnt, nil
 }
 
 // urlRgx - verify if aliased url is real URL.
@@ -602,9 +665,13 @@ func newClient(aliasedURL string) (Client, *probe.Error) {
 	if err != nil {
 		return nil, err.Trace(aliasedURL)
 	}
-	// Verify if the aliasedURL is a real URL, fail in those cases
-	// indicating the user to add alias.
+	// Verify if the aliasedURL is a real URL not served by any
+	// backend, fail in those cases indicating the user to add alias.
 	if hostCfg == nil && urlRgx.MatchString(aliasedURL) {
+		scheme, _ := getScheme(aliasedURL)
+		if _, ok := getClientBackendByScheme(scheme); ok {
+			return newClientFromAlias(alias, urlStrFull)
+		}
 		return nil, errInvalidAliasedURL(aliasedURL).Trace(aliasedURL)
 	}
 	return newClientFromAlias(alias, urlStrFull)
@@ -0,0 +1,294 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio/pkg/console"
+)
+
+// s3MinComposePartSize - S3 composes sources as parts of a multipart
+// upload, all sources but the last one need at least this size.
+const s3MinComposePartSize = 5 * humanize.MiByte
+
+var composeFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "attr",
+		Usage: "add custom metadata for the composed object",
+	},
+}
+
+var composeCmd = cli.Command{
+	Name:         "compose",
+	Usage:        "concatenate object(s) into a single object",
+	Action:       mainCompose,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(append(composeFlags, ioFlags...), globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] SOURCE [SOURCE...] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Sources are concatenated in the order given. Sources and target of the
+  same alias are composed on the server side, other sources are streamed
+  through the client.
+
+ENVIRONMENT VARIABLES:
+  MC_ENCRYPT_KEY:        list of comma delimited prefix=secret values
+  MC_ENCRYPT_CLIENT_KEY: list of comma delimited prefix=secret values for client-side encryption
+
+EXAMPLES:
+  1. Compose chunks of a log stored on MinIO cloud storage into a single object.
+     {{.Prompt}} {{.HelpName}} play/mybucket/log.part1 play/mybucket/log.part2 play/mybucket/log
+
+  2. Concatenate local files into a single file.
+     {{.Prompt}} {{.HelpName}} /tmp/part1 /tmp/part2 /tmp/joined
+
+  3. Compose local files into a single object on Amazon S3 cloud storage.
+     {{.Prompt}} {{.HelpName}} part1 part2 part3 s3/mybucket/joined
+`,
+}
+
+// composeMessage container for compose messages.
+type composeMessage struct {
+	Status  string   `json:"status"`
+	Sources []string `json:"sources"`
+	Target  string   `json:"target"`
+	Size    int64    `json:"size"`
+}
+
+// String colorized compose message.
+func (c composeMessage) String() string {
+	return console.Colorize("Compose", fmt.Sprintf("`%s` -> `%s`", strings.Join(c.Sources, "`, `"), c.Target))
+}
+
+// JSON jsonified compose message.
+func (c composeMessage) JSON() string {
+	c.Status = "success"
+	msgBytes, e := json.MarshalIndent(c, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// checkComposeSyntax - validate all the passed arguments.
+func checkComposeSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair, cmdName string) {
+	if len(cliCtx.Args()) < 2 {
+		cli.ShowCommandHelpAndExit(cliCtx, cmdName, 1) // last argument is exit code.
+	}
+
+	URLs := cliCtx.Args()
+	srcURLs := URLs[:len(URLs)-1]
+	tgtURL := URLs[len(URLs)-1]
+
+	for _, srcURL := range srcURLs {
+		if isAliasURLDir(ctx, srcURL, encKeyDB, time.Time{}) {
+			fatalIf(errInvalidArgument().Trace(srcURL), "Source `"+srcURL+"` is a folder, only objects can be composed.")
+		}
+	}
+	_, expandedTargetPath, _ := mustExpandAlias(tgtURL)
+	if strings.HasSuffix(expandedTargetPath, string(newClientURL(expandedTargetPath).Separator)) {
+		fatalIf(errInvalidArgument().Trace(tgtURL), "Target `"+tgtURL+"` is a folder, it should name an object.")
+	}
+}
+
+// composeSourceReader - reads sources one after the other, each
+// source is only opened once the previous one is consumed.
+type composeSourceReader struct {
+	ctx      context.Context
+	sources  []string
+	encKeyDB map[string][]prefixSSEPair
+	current  io.ReadCloser
+}
+
+// Read - reads from the current source, opening the next on EOF.
+func (r *composeSourceReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.sources) == 0 {
+				return 0, io.EOF
+			}
+			reader, err := getSourceStreamFromURL(r.ctx, r.sources[0], "", r.encKeyDB)
+			if err != nil {
+				return 0, err.ToGoError()
+			}
+			r.current, r.sources = reader, r.sources[1:]
+		}
+		n, e := r.current.Read(p)
+		if e == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			e = nil
+		}
+		return n, e
+	}
+}
+
+// Close - closes the source being read, if any.
+func (r *composeSourceReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
+
+// composeSources - concatenates sources into targetURL. Sources of
+// the target alias are composed by the target client, any other
+// source is streamed into a new target object.
+func composeSources(ctx context.Context, sourceURLs []string, targetURL string, metadata map[string]string, progress ProgressReader, encKeyDB map[string][]prefixSSEPair) (int64, *probe.Error) {
+	targetAlias, expandedTargetURL, _ := mustExpandAlias(targetURL)
+	tgtSSE := getSSE(targetURL, encKeyDB[targetAlias])
+	tgtClnt, err := newClientFromAliasWithKeys(targetAlias, expandedTargetURL, encKeyDB)
+	if err != nil {
+		return 0, err.Trace(targetURL)
+	}
+	tgtURL := tgtClnt.GetURL()
+
+	var totalSize int64
+	firstAlias, _, _ := mustExpandAlias(sourceURLs[0])
+	srcSSE := getSSE(sourceURLs[0], encKeyDB[firstAlias])
+	serverSide := !isAliasArchiveURL(targetAlias, expandedTargetURL) &&
+		getClientEncKeyFromAlias(targetAlias, expandedTargetURL, encKeyDB) == nil
+	_, isS3 := unwrapClient(tgtClnt).(*S3Client)
+	sourcePaths := make([]string, 0, len(sourceURLs))
+	for i, sourceURL := range sourceURLs {
+		sourceAlias, expandedSourceURL, _ := mustExpandAlias(sourceURL)
+		sse := getSSE(sourceURL, encKeyDB[sourceAlias])
+		srcClnt, err := newClientFromAliasWithKeys(sourceAlias, expandedSourceURL, encKeyDB)
+		if err != nil {
+			return 0, err.Trace(sourceURL)
+		}
+		st, err := srcClnt.Stat(ctx, StatOptions{sse: sse})
+		if err != nil {
+			return 0, err.Trace(sourceURL)
+		}
+		totalSize += st.Size
+
+		srcURL := srcClnt.GetURL()
+		// Server side compose needs all sources on the target host,
+		// readable with the same key and stored as is.
+		if sourceAlias != targetAlias || srcURL.Scheme != tgtURL.Scheme ||
+			isAliasArchiveURL(sourceAlias, expandedSourceURL) || sse != srcSSE ||
+			getClientEncKeyFromAlias(sourceAlias, expandedSourceURL, encKeyDB) != nil {
+			serverSide = false
+		}
+		// Sources too small to be S3 parts are streamed instead.
+		if isS3 && i < len(sourceURLs)-1 && st.Size < s3MinComposePartSize {
+			serverSide = false
+		}
+		sourcePaths = append(sourcePaths, filepath.ToSlash(srcURL.Path))
+	}
+	progress.SetTotal(totalSize)
+
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	if serverSide {
+		opts := CopyOptions{
+			size:     totalSize,
+			srcSSE:   srcSSE,
+			tgtSSE:   tgtSSE,
+			metadata: metadata,
+		}
+		if err = tgtClnt.Compose(ctx, sourcePaths, opts, progress); err != nil {
+			return 0, err.Trace(targetURL)
+		}
+		return totalSize, nil
+	}
+
+	reader := &composeSourceReader{ctx: ctx, sources: sourceURLs, encKeyDB: encKeyDB}
+	defer reader.Close()
+	n, err := tgtClnt.Put(ctx, reader, totalSize, metadata, progress, tgtSSE, false, false, false)
+	if err != nil {
+		return n, err.Trace(targetURL)
+	}
+	return n, nil
+}
+
+// doCompose - composes the sources given on the command line into
+// the target, shared by `mc compose` and `mc cp --compose`.
+func doCompose(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair, metadata map[string]string, cmdName string) error {
+	checkComposeSyntax(ctx, cliCtx, encKeyDB, cmdName)
+
+	console.SetColor("Compose", color.New(color.FgGreen, color.Bold))
+
+	URLs := cliCtx.Args()
+	sourceURLs := URLs[:len(URLs)-1]
+	targetURL := URLs[len(URLs)-1]
+
+	// Store a progress bar or an accounter
+	var pg ProgressReader
+	if !globalQuiet && !globalJSON {
+		pg = newProgressBar(0)
+	} else {
+		pg = newAccounter(0)
+	}
+
+	size, err := composeSources(ctx, sourceURLs, targetURL, metadata, pg, encKeyDB)
+	if progressReader, ok := pg.(*progressBar); ok {
+		if err != nil {
+			console.Eraseline()
+		} else if progressReader.ProgressBar.Get() > 0 {
+			progressReader.ProgressBar.Finish()
+		}
+	}
+	fatalIf(err, "Unable to compose `"+targetURL+"`.")
+
+	printMsg(composeMessage{
+		Sources: sourceURLs,
+		Target:  targetURL,
+		Size:    size,
+	})
+	return nil
+}
+
+// mainCompose is the entry point for compose command.
+func mainCompose(cliCtx *cli.Context) error {
+	ctx, cancelCompose := context.WithCancel(globalContext)
+	defer cancelCompose()
+
+	// Parse encryption keys per command.
+	encKeyDB, err := getEncKeys(cliCtx)
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	metadata := make(map[string]string)
+	if cliCtx.String("attr") != "" {
+		metadata, err = getMetaDataEntry(cliCtx.String("attr"))
+		fatalIf(err, "Unable to parse attribute %v", cliCtx.String("attr"))
+	}
+
+	return doCompose(ctx, cliCtx, encKeyDB, metadata, "compose")
+}
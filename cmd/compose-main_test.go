@@ -0,0 +1,141 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// TestComposeSources - tests composing objects on the server side
+// and streaming sources of different backends.
+func (s *TestSuite) TestComposeSources(c *C) {
+	clnt, err := newClient("mem://compose")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+	memPut(c, "mem://compose/part.1", "hello ")
+	memPut(c, "mem://compose/part.2", "composed ")
+	memPut(c, "mem://compose/part.3", "world")
+	sources := []string{"mem://compose/part.1", "mem://compose/part.2", "mem://compose/part.3"}
+
+	n, err := composeSources(context.Background(), sources, "mem://compose/joined", nil, newAccounter(0), nil)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, int64(len("hello composed world")))
+	c.Assert(archiveGet(c, "mem://compose/joined"), Equals, "hello composed world")
+
+	dir, e := ioutil.TempDir("", "mc-compose-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(dir)
+	target := filepath.Join(dir, "joined")
+
+	// Sources of another backend are streamed.
+	n, err = composeSources(context.Background(), sources, target, nil, newAccounter(0), nil)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, int64(len("hello composed world")))
+	data, e := ioutil.ReadFile(target)
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "hello composed world")
+
+	// Local files are concatenated by the filesystem backend.
+	_, err = composeSources(context.Background(), []string{target, target}, target+".twice", nil, newAccounter(0), nil)
+	c.Assert(err, IsNil)
+	data, e = ioutil.ReadFile(target + ".twice")
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "hello composed worldhello composed world")
+
+	_, err = composeSources(context.Background(), []string{"mem://compose/missing"}, "mem://compose/none", nil, newAccounter(0), nil)
+	c.Assert(err, NotNil)
+}
+
+// composeHandler - S3 server storing objects in memory, server side
+// copies are recorded since they are not supported.
+type composeHandler struct {
+	sync.Mutex
+	objects map[string][]byte
+	copies  int
+}
+
+func (h *composeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Uploads stream sources read from this server.
+	var body []byte
+	if r.Method == http.MethodPut {
+		body, _ = ioutil.ReadAll(r.Body)
+	}
+	h.Lock()
+	defer h.Unlock()
+	if _, ok := r.URL.Query()["location"]; ok {
+		w.Write([]byte("<LocationConstraint xmlns=\"http://s3.amazonaws.com/doc/2006-03-01/\"></LocationConstraint>"))
+		return
+	}
+	if r.Header.Get("X-Amz-Copy-Source") != "" || r.URL.Query().Get("uploads") != "" {
+		h.copies++
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+	switch r.Method {
+	case http.MethodHead, http.MethodGet:
+		data, ok := h.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("ETag", "\"etag\"")
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case http.MethodPut:
+		h.objects[r.URL.Path] = body
+		w.Header().Set("ETag", "\"etag\"")
+	}
+}
+
+// TestComposeSourcesS3 - tests sources too small to be composed by S3
+// are streamed into the target.
+func (s *TestSuite) TestComposeSourcesS3(c *C) {
+	handler := &composeHandler{objects: map[string][]byte{
+		"/bucket/part.1": []byte("hello "),
+		"/bucket/part.2": []byte("world"),
+	}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	os.Setenv("MC_HOST_composes3", "http://access:secretsecret@"+server.Listener.Addr().String())
+	defer os.Unsetenv("MC_HOST_composes3")
+
+	n, err := composeSources(context.Background(), []string{"composes3/bucket/part.1", "composes3/bucket/part.2"},
+		"composes3/bucket/joined", nil, newAccounter(0), nil)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, int64(len("hello world")))
+
+	handler.Lock()
+	defer handler.Unlock()
+	c.Assert(handler.copies, Equals, 0)
+	// Uploads may be chunk signed, the payload is sent as is.
+	c.Assert(bytes.Contains(handler.objects["/bucket/joined"], []byte("hello world")), Equals, true)
+}
@@ -18,7 +18,8 @@ package cmd
 
 import "strings"
 
-var validAPIs = []string{"S3v4", "S3v2"}
+// validAPIs - alias api values served by the registered backends.
+var validAPIs = clientBackendAPIs()
 
 const (
 	accessKeyMinLen = 3
@@ -51,7 +52,7 @@ func trimTrailingSeparator(hostURL string) string {
 func isValidHostURL(hostURL string) (ok bool) {
 	if strings.TrimSpace(hostURL) != "" {
 		url := newClientURL(hostURL)
-		if url.Scheme == "https" || url.Scheme == "http" {
+		if url.Scheme == "https" || url.Scheme == "http" || url.Scheme == sftpScheme {
 			if url.Path == "/" {
 				ok = true
 			}
@@ -62,11 +63,8 @@ func isValidHostURL(hostURL string) (ok bool) {
 
 // isValidAPI - Validates if API signature string of supported type.
 func isValidAPI(api string) (ok bool) {
-	switch strings.ToLower(api) {
-	case "s3v2", "s3v4":
-		ok = true
-	}
-	return ok
+	_, ok = getClientBackendByAPI(api)
+	return ok && api != ""
 }
 
 // isValidLookup - validates if bucket lookup is of valid type
@@ -50,14 +50,6 @@ var (
 			Name:  "recursive, r",
 			Usage: "copy recursively",
 		},
-		cli.StringFlag{
-			Name:  "older-than",
-			Usage: "copy objects older than L days, M hours and N minutes",
-		},
-		cli.StringFlag{
-			Name:  "newer-than",
-			Usage: "copy objects newer than L days, M hours and N minutes",
-		},
 		cli.StringFlag{
 			Name:  "storage-class, sc",
 			Usage: "set storage class for new object(s) on target",
@@ -98,6 +90,19 @@ var (
 			Name:  lhFlag,
 			Usage: "apply legal hold to the copied object (on, off)",
 		},
+		cli.BoolFlag{
+			Name:  "archive",
+			Usage: "copy object(s) into a single tar archive streamed to target",
+		},
+		cli.BoolFlag{
+			Name:  "compose",
+			Usage: "concatenate source object(s) into a single target object",
+		},
+		cli.StringFlag{
+			Name:  "compress",
+			Usage: "compress object(s) on upload with one of zstd, gzip or s2",
+		},
+		failedLogFlag,
 	}
 )
 
@@ -115,7 +120,7 @@ var cpCmd = cli.Command{
 	Action:       mainCopy,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(cpFlags, ioFlags...), globalFlags...),
+	Flags:        append(append(append(append(append(cpFlags, filterFlags...), ioFlags...), workerFlags...), retryFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -126,8 +131,9 @@ FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}
 ENVIRONMENT VARIABLES:
-  MC_ENCRYPT:      list of comma delimited prefixes
-  MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+  MC_ENCRYPT:            list of comma delimited prefixes
+  MC_ENCRYPT_KEY:        list of comma delimited prefix=secret values
+  MC_ENCRYPT_CLIENT_KEY: list of comma delimited prefix=secret values for client-side encryption
 
 EXAMPLES:
   01. Copy a list of objects from local file system to Amazon S3 cloud storage.
@@ -188,6 +194,36 @@ EXAMPLES:
   19. Roll back 10 days in the past to copy the content of 'mybucket'
       {{.Prompt}} {{.HelpName}} --rewind 10d -r play/mybucket/ /tmp/dest/
 
+  20. Copy a local folder recursively into a single compressed tar archive on MinIO cloud storage.
+      {{.Prompt}} {{.HelpName}} --recursive --archive backup/ play/mybucket/backup.tar.gz
+
+  21. Copy a file stored inside a zip archive to a local folder.
+      {{.Prompt}} {{.HelpName}} play/mybucket/photos.zip/2021/beach.jpg /tmp/
+
+  22. Concatenate objects on MinIO cloud storage into a single object, on the server side.
+      {{.Prompt}} {{.HelpName}} --compose play/mybucket/part.1 play/mybucket/part.2 play/mybucket/joined
+
+  23. Copy a folder recursively to Amazon S3 cloud storage, encrypted on the client side so that the server never sees plaintext or keys.
+      {{.Prompt}} {{.HelpName}} --recursive --encrypt-client-key "s3/private/=32byteslongsecretkeymustbegiven1" documents/ s3/private/
+
+  24. Copy a folder of logs recursively to MinIO cloud storage, compressed with zstd on upload.
+      {{.Prompt}} {{.HelpName}} --recursive --compress zstd /var/log/nginx/ play/logs/nginx/
+
+  25. Copy compressed objects back to a local folder, they are decompressed on the way.
+      {{.Prompt}} {{.HelpName}} --recursive play/logs/nginx/ /tmp/nginx/
+
+  26. Copy many small files to a rate limited gateway, halving the parallel transfers whenever it asks to slow down.
+      {{.Prompt}} {{.HelpName}} --recursive --worker-policy aimd --max-workers 32 ~/thumbnails/ gateway/thumbnails/
+
+  27. Copy a folder recursively over a flaky link, retrying failed objects up to 5 times with at most a minute between retries.
+      {{.Prompt}} {{.HelpName}} --recursive --retry-attempts 5 --retry-max-backoff 1m ~/photos/ play/mybucket/photos/
+
+  28. Copy a folder recursively to MinIO cloud storage, logging the objects failing to copy to retry them later with 'mc replay'.
+      {{.Prompt}} {{.HelpName}} --recursive --failed-log /tmp/failed.json ~/photos/ play/mybucket/photos/
+
+  29. Copy only the JPEG files of a folder recursively, leaving out its 'thumbnails' folders.
+      {{.Prompt}} {{.HelpName}} --recursive --exclude "*thumbnails/*" --include "*.jpg" ~/photos/ play/mybucket/photos/
+
 `,
 }
 
@@ -284,17 +320,20 @@ func doPrepareCopyURLs(ctx context.Context, session *sessionV8, cancelCopy conte
 	// but any number of sources.
 	sourceURLs := session.Header.CommandArgs[:len(session.Header.CommandArgs)-1]
 	targetURL := session.Header.CommandArgs[len(session.Header.CommandArgs)-1] // Last one is target
+	if session.Header.CommandBoolFlags["archive"] {
+		targetURL = archiveRootURL(targetURL)
+	}
 
 	// Access recursive flag inside the session header.
 	isRecursive := session.Header.CommandBoolFlags["recursive"]
 	rewind := session.Header.CommandStringFlags["rewind"]
 	versionID := session.Header.CommandStringFlags["version-id"]
-	olderThan := session.Header.CommandStringFlags["older-than"]
-	newerThan := session.Header.CommandStringFlags["newer-than"]
 	encryptKeys := session.Header.CommandStringFlags["encrypt-key"]
 	encrypt := session.Header.CommandStringFlags["encrypt"]
 	encKeyDB, err := parseAndValidateEncryptionKeys(encryptKeys, encrypt)
 	fatalIf(err, "Unable to parse encryption keys.")
+	err = addClientEncryptionKeys(encKeyDB, session.Header.CommandStringFlags["encrypt-client-key"])
+	fatalIf(err, "Unable to parse encryption keys.")
 
 	// Create a session data file to store the processed URLs.
 	dataFP := session.NewDataWriter()
@@ -304,7 +343,7 @@ func doPrepareCopyURLs(ctx context.Context, session *sessionV8, cancelCopy conte
 		scanBar = scanBarFactory()
 	}
 
-	URLsCh := prepareCopyURLs(ctx, sourceURLs, targetURL, isRecursive, encKeyDB, olderThan, newerThan, parseRewindFlag(rewind), versionID)
+	URLsCh := prepareCopyURLs(ctx, sourceURLs, targetURL, isRecursive, encKeyDB, newObjectFilterFromSession(session), parseRewindFlag(rewind), versionID)
 	done := false
 	for !done {
 		select {
@@ -376,6 +415,48 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 	sourceURLs := cli.Args()[:len(cli.Args())-1]
 	targetURL := cli.Args()[len(cli.Args())-1] // Last one is target
 
+	isArchive := cli.Bool("archive")
+	compress := cli.String("compress")
+	retry := retryPolicyFromContext(cli)
+	if session != nil {
+		isArchive = session.Header.CommandBoolFlags["archive"]
+		compress = session.Header.CommandStringFlags["compress"]
+	}
+
+	// Failed copies of an archive cannot be replayed, the archive being
+	// discarded.
+	if isArchive && cli.String("failed-log") != "" {
+		fatalIf(errInvalidArgument().Trace(cli.Args()...), "`--failed-log` cannot be used with `--archive`.")
+	}
+	cmdName := "cp"
+	if isMvCmd {
+		cmdName = "mv"
+	}
+	failed := openFailedLog(cli, cmdName)
+	defer failed.Close()
+
+	// Objects are copied as entries of the archive being streamed to target.
+	var archive *archiveWriter
+	if isArchive {
+		var err *probe.Error
+		archive, err = openArchiveWriter(ctx, targetURL)
+		fatalIf(err, "Unable to initialize archive `"+targetURL+"`.")
+		targetURL = archiveRootURL(targetURL)
+	}
+
+	// Entries of tar archives copied recursively are read in a single pass.
+	isRecursive := cli.Bool("recursive")
+	if session != nil {
+		isRecursive = session.Header.CommandBoolFlags["recursive"]
+	}
+	if isRecursive {
+		for _, sourceURL := range sourceURLs {
+			if extractor, err := openArchiveExtractor(ctx, sourceURL); err == nil && extractor != nil {
+				defer extractor.Close()
+			}
+		}
+	}
+
 	tgtClnt, err := newClient(targetURL)
 	fatalIf(err, "Unable to initialize `"+targetURL+"`.")
 
@@ -387,8 +468,11 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 
 	if session != nil {
 		// isCopied returns true if an object has been already copied
-		// or not. This is useful when we resume from a session.
-		isCopied = isLastFactory(session.Header.LastCopied)
+		// or not. This is useful when we resume from a session. An
+		// archive is only stored once complete, so it is rewritten.
+		if !isArchive {
+			isCopied = isLastFactory(session.Header.LastCopied)
+		}
 
 		if !session.HasData() {
 			totalBytes, totalObjects = doPrepareCopyURLs(ctx, session, cancelCopy)
@@ -421,15 +505,14 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 	} else {
 		// Access recursive flag inside the session header.
 		isRecursive := cli.Bool("recursive")
-		olderThan := cli.String("older-than")
-		newerThan := cli.String("newer-than")
+		filter := newObjectFilterFromContext(cli)
 		rewind := cli.String("rewind")
 		versionID := cli.String("version-id")
 
 		go func() {
 			totalBytes := int64(0)
 			for cpURLs := range prepareCopyURLs(ctx, sourceURLs, targetURL, isRecursive,
-				encKeyDB, olderThan, newerThan, parseRewindFlag(rewind), versionID) {
+				encKeyDB, filter, parseRewindFlag(rewind), versionID) {
 				if cpURLs.Error != nil {
 					// Print in new line and adjust to top so that we
 					// don't print over the ongoing scan bar
@@ -459,7 +542,8 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 	var quitCh = make(chan struct{})
 	var statusCh = make(chan URLs)
 
-	parallel := newParallelManager(statusCh)
+	parallel := newParallelManager(statusCh, workerOptionsFromContext(cli))
+	retry.throttled = parallel.throttled
 
 	go func() {
 		gracefulStop := func() {
@@ -531,6 +615,8 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 
 				cpURLs.MD5 = cli.Bool("md5") || withLock
 				cpURLs.DisableMultipart = cli.Bool("disable-multipart")
+				cpURLs.Compress = compress
+				cpURLs.Retry = retry
 
 				// Verify if previously copied, notify progress bar.
 				if isCopied != nil && isCopied(cpURLs.SourceContent.URL.String()) {
@@ -593,6 +679,7 @@ loop:
 				}
 
 				errSeen = true
+				failed.addCopy(cpURLs, cli.Bool("preserve") || cli.String("attr") != "", isMvCmd)
 				if progressReader, pgok := pg.(*progressBar); pgok {
 					if progressReader.ProgressBar.Get() > 0 {
 						writeContSize := (int)(cpURLs.SourceContent.Size)
@@ -615,6 +702,14 @@ loop:
 		}
 	}
 
+	if archive != nil {
+		// Any failed copy discards the whole archive.
+		if err := archive.Close(errSeen || ctx.Err() != nil); err != nil {
+			errorIf(err, "Unable to write archive `"+cli.Args()[len(cli.Args())-1]+"`.")
+			retErr = exitStatus(globalErrorExitStatus)
+		}
+	}
+
 	if progressReader, ok := pg.(*progressBar); ok {
 		if (errSeen && totalObjects == 1) || (cpAllFilesErr && totalObjects > 1) {
 			console.Eraseline()
@@ -753,6 +848,16 @@ func mainCopy(cliCtx *cli.Context) error {
 		fatalIf(err, "Unable to parse attribute %v", cliCtx.String("attr"))
 	}
 
+	// Sources are concatenated into a single target object.
+	if cliCtx.Bool("compose") {
+		for _, flag := range []string{"recursive", "archive", "continue", "rewind", "version-id", "compress"} {
+			if cliCtx.IsSet(flag) {
+				fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "`--compose` cannot be used with `--"+flag+"`.")
+			}
+		}
+		return doCompose(ctx, cliCtx, encKeyDB, userMetaMap, "cp")
+	}
+
 	// check 'copy' cli arguments.
 	checkCopySyntax(ctx, cliCtx, encKeyDB, false)
 
@@ -762,8 +867,6 @@ func mainCopy(cliCtx *cli.Context) error {
 	recursive := cliCtx.Bool("recursive")
 	rewind := cliCtx.String("rewind")
 	versionID := cliCtx.String("version-id")
-	olderThan := cliCtx.String("older-than")
-	newerThan := cliCtx.String("newer-than")
 	storageClass := cliCtx.String("storage-class")
 	retentionMode := cliCtx.String(rmFlag)
 	retentionDuration := cliCtx.String(rdFlag)
@@ -792,14 +895,15 @@ func mainCopy(cliCtx *cli.Context) error {
 			session.Header.CommandBoolFlags["recursive"] = recursive
 			session.Header.CommandStringFlags["rewind"] = rewind
 			session.Header.CommandStringFlags["version-id"] = versionID
-			session.Header.CommandStringFlags["older-than"] = olderThan
-			session.Header.CommandStringFlags["newer-than"] = newerThan
+			session.setCommandFlags(cliCtx, filterFlags)
 			session.Header.CommandStringFlags["storage-class"] = storageClass
 			session.Header.CommandStringFlags[rmFlag] = retentionMode
 			session.Header.CommandStringFlags[rdFlag] = retentionDuration
 			session.Header.CommandStringFlags[lhFlag] = legalHold
 			session.Header.CommandStringFlags["encrypt-key"] = sseKeys
 			session.Header.CommandStringFlags["encrypt"] = sse
+			session.Header.CommandStringFlags["encrypt-client-key"] = getClientEncryptionKeys(cliCtx)
+			session.Header.CommandStringFlags["compress"] = cliCtx.String("compress")
 			session.Header.CommandBoolFlags["session"] = cliCtx.Bool("continue")
 
 			if cliCtx.Bool("preserve") {
@@ -808,6 +912,7 @@ func mainCopy(cliCtx *cli.Context) error {
 			session.Header.UserMetaData = userMetaMap
 			session.Header.CommandBoolFlags["md5"] = cliCtx.Bool("md5")
 			session.Header.CommandBoolFlags["disable-multipart"] = cliCtx.Bool("disable-multipart")
+			session.Header.CommandBoolFlags["archive"] = cliCtx.Bool("archive")
 
 			var e error
 			if session.Header.RootPath, e = os.Getwd(); e != nil {
@@ -44,6 +44,19 @@ func checkCopySyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[stri
 	srcURLs := URLs[:len(URLs)-1]
 	tgtURL := URLs[len(URLs)-1]
 	isRecursive := cliCtx.Bool("recursive")
+
+	checkCompressFlag(cliCtx.String("compress"))
+
+	// Sources are copied into the archive, check its entries instead.
+	if cliCtx.Bool("archive") {
+		if cliCtx.String("compress") != "" {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--compress` cannot be used with `--archive`.")
+		}
+		if !isArchiveWriteTarget(tgtURL) {
+			fatalIf(errInvalidArchiveTarget(tgtURL).Trace(tgtURL), "Unable to validate target `"+tgtURL+"`.")
+		}
+		tgtURL = archiveRootURL(tgtURL)
+	}
 	timeRef := parseRewindFlag(cliCtx.String("rewind"))
 	versionID := cliCtx.String("version-id")
 
@@ -165,7 +165,7 @@ func prepareCopyURLsTypeC(ctx context.Context, sourceURL, targetURL string, isRe
 	copyURLsCh := make(chan URLs)
 	go func(sourceURL, targetURL string, copyURLsCh chan URLs) {
 		defer close(copyURLsCh)
-		sourceClient, err := newClient(sourceURL)
+		sourceClient, err := newClientWithKeys(sourceURL, encKeyDB)
 		if err != nil {
 			// Source initialization failed.
 			copyURLsCh <- URLs{Error: err.Trace(sourceURL)}
@@ -220,7 +220,7 @@ func prepareCopyURLsTypeD(ctx context.Context, sourceURLs []string, targetURL st
 }
 
 // prepareCopyURLs - prepares target and source clientURLs for copying.
-func prepareCopyURLs(ctx context.Context, sourceURLs []string, targetURL string, isRecursive bool, encKeyDB map[string][]prefixSSEPair, olderThan, newerThan string, timeRef time.Time, versionID string) chan URLs {
+func prepareCopyURLs(ctx context.Context, sourceURLs []string, targetURL string, isRecursive bool, encKeyDB map[string][]prefixSSEPair, filter *objectFilter, timeRef time.Time, versionID string) chan URLs {
 	copyURLsCh := make(chan URLs)
 	go func(sourceURLs []string, targetURL string, copyURLsCh chan URLs, encKeyDB map[string][]prefixSSEPair, timeRef time.Time) {
 		defer close(copyURLsCh)
@@ -249,13 +249,8 @@ func prepareCopyURLs(ctx context.Context, sourceURLs []string, targetURL string,
 	go func() {
 		defer close(finalCopyURLsCh)
 		for cpURLs := range copyURLsCh {
-			// Skip objects older than --older-than parameter if specified
-			if olderThan != "" && isOlder(cpURLs.SourceContent.Time, olderThan) {
-				continue
-			}
-
-			// Skip objects newer than --newer-than parameter if specified
-			if newerThan != "" && isNewer(cpURLs.SourceContent.Time, newerThan) {
+			// Skip objects the filter flags exclude
+			if cpURLs.Error == nil && !filter.match(ctx, cpURLs.SourceAlias, cpURLs.SourceContent, filterPath(cpURLs.SourceContent, sourceURLs...)) {
 				continue
 			}
 
@@ -31,7 +31,12 @@ import (
 
 // diff specific flags.
 var (
-	diffFlags = []cli.Flag{}
+	diffFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "checksum",
+			Usage: "compare objects of same size by content, using ETags or checksums",
+		},
+	}
 )
 
 // Compute differences in object name, size, and date between two buckets.
@@ -52,7 +57,9 @@ FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}
 DESCRIPTION:
-  Diff only calculates differences in object name, size and time. It *DOES NOT* compare objects' contents.
+  Diff only calculates differences in object name, size and time. It *DOES NOT* compare objects' contents
+  unless --checksum is set, objects of same size are then compared by ETag when both are single-part
+  uploads, local files are hashed to match ETags and objects are read and hashed otherwise.
 
 LEGEND:
   < - object is only in source.
@@ -65,6 +72,9 @@ EXAMPLES:
 
   2. Compare two folders on a local filesystem.
      {{.Prompt}} {{.HelpName}} ~/Photos /Media/Backup/Photos
+
+  3. Compare a local folder with a folder on Amazon S3 cloud storage, including the content of files of same size.
+     {{.Prompt}} {{.HelpName}} --checksum ~/Photos s3/mybucket/Photos
 `,
 }
 
@@ -93,6 +103,8 @@ func (d diffMessage) String() string {
 		msg = console.Colorize("DiffSize", "! "+d.SecondURL)
 	case differInMetadata:
 		msg = console.Colorize("DiffMetadata", "! "+d.SecondURL)
+	case differInContent:
+		msg = console.Colorize("DiffContent", "! "+d.SecondURL)
 	case differInAASourceMTime:
 		msg = console.Colorize("DiffMMSourceMTime", "! "+d.SecondURL)
 	case differInNone:
@@ -156,7 +168,7 @@ func checkDiffSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[stri
 }
 
 // doDiffMain runs the diff.
-func doDiffMain(ctx context.Context, firstURL, secondURL string) error {
+func doDiffMain(ctx context.Context, firstURL, secondURL string, isChecksum bool, encKeyDB map[string][]prefixSSEPair) error {
 	// Source and targets are always directories
 	sourceSeparator := string(newClientURL(firstURL).Separator)
 	if !strings.HasSuffix(firstURL, sourceSeparator) {
@@ -183,8 +195,17 @@ func doDiffMain(ctx context.Context, firstURL, secondURL string) error {
 			fmt.Sprintf("Failed to diff '%s' and '%s'", firstURL, secondURL))
 	}
 
+	var checksum *checksumComparer
+	if isChecksum {
+		checksum = &checksumComparer{
+			sourceAlias: firstAlias,
+			targetAlias: secondAlias,
+			encKeyDB:    encKeyDB,
+		}
+	}
+
 	// Diff first and second urls.
-	for diffMsg := range objectDifference(ctx, firstClient, secondClient, firstURL, secondURL, true) {
+	for diffMsg := range objectDifference(ctx, firstClient, secondClient, firstURL, secondURL, true, checksum, time.Time{}) {
 		if diffMsg.Error != nil {
 			errorIf(diffMsg.Error, "Unable to calculate objects difference.")
 			// Ignore error and proceed to next object.
@@ -215,11 +236,12 @@ func mainDiff(cliCtx *cli.Context) error {
 	console.SetColor("DiffType", color.New(color.FgMagenta))
 	console.SetColor("DiffSize", color.New(color.FgYellow, color.Bold))
 	console.SetColor("DiffMetadata", color.New(color.FgYellow, color.Bold))
+	console.SetColor("DiffContent", color.New(color.FgYellow, color.Bold))
 	console.SetColor("DiffMMSourceMTime", color.New(color.FgYellow, color.Bold))
 
 	URLs := cliCtx.Args()
 	firstURL := URLs.Get(0)
 	secondURL := URLs.Get(1)
 
-	return doDiffMain(ctx, firstURL, secondURL)
+	return doDiffMain(ctx, firstURL, secondURL, cliCtx.Bool("checksum"), encKeyDB)
 }
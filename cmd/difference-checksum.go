@@ -0,0 +1,240 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// checksumComparer - compares the content of objects of same size,
+// for diff and mirror run with --checksum. ETags are compared when
+// they are the MD5 of the stored data, local files are hashed to be
+// compared against them, objects are streamed and hashed otherwise.
+type checksumComparer struct {
+	sourceAlias string
+	targetAlias string
+	encKeyDB    map[string][]prefixSSEPair
+}
+
+// s3ETagRgx - matches the ETags of single-part and multipart uploads.
+var s3ETagRgx = regexp.MustCompile("^[0-9a-f]{32}(-[0-9]+)?$")
+
+// contentETag - returns the ETag of content along with its parts
+// count, zero for single-part uploads. The ETag is empty when it is
+// not derived from the MD5 of the data, as for encrypted objects.
+func contentETag(content *ClientContent) (etag string, parts int) {
+	etag = strings.ToLower(strings.Trim(content.ETag, "\""))
+	if !s3ETagRgx.MatchString(etag) {
+		return "", 0
+	}
+	if _, ok := clientEncryptionIV(content); ok {
+		return "", 0
+	}
+	if codec, _ := contentCompression(content); codec != "" {
+		return "", 0
+	}
+	for _, key := range []string{"X-Amz-Server-Side-Encryption", "X-Amz-Server-Side-Encryption-Customer-Algorithm"} {
+		if metadataValue(content.Metadata, key) != "" {
+			return "", 0
+		}
+	}
+	if i := strings.Index(etag, "-"); i >= 0 {
+		parts, _ = strconv.Atoi(etag[i+1:])
+	}
+	return etag, parts
+}
+
+// multipartPartSizes - returns the part sizes a multipart upload of
+// size in parts may have used, the one of mc first.
+func multipartPartSizes(size int64, parts int) (partSizes []int64) {
+	const mib = 1024 * 1024
+	// minio-go defaults to the smallest multiple of 128MiB fitting
+	// in 10000 parts.
+	mcPartSize := (size/10000 + 128*mib - 1) / (128 * mib) * 128 * mib
+	if mcPartSize == 0 {
+		mcPartSize = 128 * mib
+	}
+	for _, partSize := range []int64{mcPartSize, 5 * mib, 8 * mib, 16 * mib, 32 * mib, 64 * mib, 128 * mib, 256 * mib, 512 * mib, 1024 * mib} {
+		if (size+partSize-1)/partSize != int64(parts) {
+			continue
+		}
+		found := false
+		for _, p := range partSizes {
+			found = found || p == partSize
+		}
+		if !found {
+			partSizes = append(partSizes, partSize)
+		}
+	}
+	return partSizes
+}
+
+// multipartETag - computes the ETag of a multipart upload of what is
+// written to it.
+type multipartETag struct {
+	partSize int64
+	written  int64
+	part     hash.Hash
+	sums     []byte
+	parts    int
+}
+
+func newMultipartETag(partSize int64) *multipartETag {
+	return &multipartETag{partSize: partSize, part: md5.New()}
+}
+
+func (m *multipartETag) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		chunk := p
+		if rem := m.partSize - m.written; int64(len(chunk)) > rem {
+			chunk = chunk[:rem]
+		}
+		m.part.Write(chunk)
+		m.written += int64(len(chunk))
+		p = p[len(chunk):]
+		if m.written == m.partSize {
+			m.sums = m.part.Sum(m.sums)
+			m.part.Reset()
+			m.written = 0
+			m.parts++
+		}
+	}
+	return n, nil
+}
+
+// ETag - returns the ETag of the parts written so far.
+func (m *multipartETag) ETag() string {
+	sums, parts := m.sums, m.parts
+	if m.written > 0 {
+		sums = m.part.Sum(sums)
+		parts++
+	}
+	sum := md5.Sum(sums)
+	return hex.EncodeToString(sum[:]) + "-" + strconv.Itoa(parts)
+}
+
+// get - returns a reader of content, decrypted and decompressed.
+func (c *checksumComparer) get(ctx context.Context, alias string, content *ClientContent) (io.ReadCloser, *probe.Error) {
+	contentPath := filepath.ToSlash(filepath.Join(alias, content.URL.Path))
+	sse := getSSE(contentPath, c.encKeyDB[alias])
+	cseKey := getClientEncKey(contentPath, c.encKeyDB[alias])
+	reader, _, err := getSourceStream(ctx, alias, content.URL.String(), content.VersionID, false, sse, cseKey, false)
+	if err != nil {
+		return nil, err
+	}
+	if codec, _ := contentCompression(content); codec != "" {
+		decReader, err := decompressStream(reader, codec)
+		if err != nil {
+			reader.Close()
+			return nil, err.Trace(content.URL.String())
+		}
+		reader = decReader
+	}
+	return reader, nil
+}
+
+// hash - writes content to writer.
+func (c *checksumComparer) hash(ctx context.Context, alias string, content *ClientContent, writer io.Writer) *probe.Error {
+	reader, err := c.get(ctx, alias, content)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if _, e := io.Copy(writer, reader); e != nil {
+		return probe.NewError(e).Trace(content.URL.String())
+	}
+	return nil
+}
+
+// differ - returns true if the content of source and target differ.
+func (c *checksumComparer) differ(ctx context.Context, source, target *ClientContent) (bool, *probe.Error) {
+	srcETag, srcParts := contentETag(source)
+	tgtETag, tgtParts := contentETag(target)
+	switch {
+	case srcETag != "" && srcETag == tgtETag:
+		return false, nil
+	case srcETag != "" && tgtETag != "" && srcParts == 0 && tgtParts == 0:
+		return true, nil
+	case srcETag != "" && target.URL.Type == fileSystem:
+		if differ, ok, err := c.differFromETag(ctx, c.targetAlias, target, srcETag, srcParts); err != nil || ok {
+			return differ, err
+		}
+	case tgtETag != "" && source.URL.Type == fileSystem:
+		if differ, ok, err := c.differFromETag(ctx, c.sourceAlias, source, tgtETag, tgtParts); err != nil || ok {
+			return differ, err
+		}
+	}
+	return c.differInStream(ctx, source, target)
+}
+
+// differFromETag - hashes content the way etag was computed, returns
+// false for ok when the part size of a multipart ETag is not found.
+func (c *checksumComparer) differFromETag(ctx context.Context, alias string, content *ClientContent, etag string, parts int) (differ, ok bool, err *probe.Error) {
+	if parts == 0 {
+		h := md5.New()
+		if err = c.hash(ctx, alias, content, h); err != nil {
+			return false, false, err
+		}
+		return hex.EncodeToString(h.Sum(nil)) != etag, true, nil
+	}
+
+	partSizes := multipartPartSizes(content.Size, parts)
+	if len(partSizes) == 0 {
+		return false, false, nil
+	}
+	// Compute the ETag of every candidate part size in one pass.
+	etags := make([]*multipartETag, len(partSizes))
+	writers := make([]io.Writer, len(partSizes))
+	for i, partSize := range partSizes {
+		etags[i] = newMultipartETag(partSize)
+		writers[i] = etags[i]
+	}
+	if err = c.hash(ctx, alias, content, io.MultiWriter(writers...)); err != nil {
+		return false, false, err
+	}
+	for _, m := range etags {
+		if m.ETag() == etag {
+			return false, true, nil
+		}
+	}
+	return false, false, nil
+}
+
+// differInStream - hashes the content of source and target.
+func (c *checksumComparer) differInStream(ctx context.Context, source, target *ClientContent) (bool, *probe.Error) {
+	srcHash, tgtHash := sha256.New(), sha256.New()
+	if err := c.hash(ctx, c.sourceAlias, source, srcHash); err != nil {
+		return false, err
+	}
+	if err := c.hash(ctx, c.targetAlias, target, tgtHash); err != nil {
+		return false, err
+	}
+	return !bytes.Equal(srcHash.Sum(nil), tgtHash.Sum(nil)), nil
+}
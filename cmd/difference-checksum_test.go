@@ -0,0 +1,91 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// TestChecksumDifference - tests objects of same size are compared
+// by content with --checksum.
+func (s *TestSuite) TestChecksumDifference(c *C) {
+	ctx := context.Background()
+	for _, bucket := range []string{"mem://checksum-src", "mem://checksum-dst"} {
+		clnt, err := newClient(bucket)
+		c.Assert(err, IsNil)
+		c.Assert(clnt.MakeBucket(ctx, "", false, false), IsNil)
+	}
+	memPut(c, "mem://checksum-src/same", "data")
+	memPut(c, "mem://checksum-dst/same", "data")
+	memPut(c, "mem://checksum-src/content", "data")
+	memPut(c, "mem://checksum-dst/content", "date")
+
+	dir := c.MkDir()
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "same"), []byte("data"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "content"), []byte("dame"), 0600), IsNil)
+
+	diffs := func(sourceURL, targetURL string, checksum *checksumComparer) map[string]differType {
+		srcClnt, err := newClient(sourceURL)
+		c.Assert(err, IsNil)
+		dstClnt, err := newClient(targetURL)
+		c.Assert(err, IsNil)
+		diffs := map[string]differType{}
+		for diff := range objectDifference(ctx, srcClnt, dstClnt, sourceURL, targetURL, false, checksum, time.Time{}) {
+			c.Assert(diff.Error, IsNil)
+			diffs[filepath.Base(diff.SecondURL)] = diff.Diff
+		}
+		return diffs
+	}
+
+	c.Assert(diffs("mem://checksum-src/", "mem://checksum-dst/", nil), HasLen, 0)
+	c.Assert(diffs("mem://checksum-src/", "mem://checksum-dst/", &checksumComparer{}), DeepEquals,
+		map[string]differType{"content": differInContent})
+	c.Assert(diffs(dir+"/", "mem://checksum-dst/", &checksumComparer{}), DeepEquals,
+		map[string]differType{"content": differInContent})
+	c.Assert(diffs("mem://checksum-src/", dir+"/", &checksumComparer{}), DeepEquals,
+		map[string]differType{"content": differInContent})
+}
+
+// TestMultipartETag - tests ETags of multipart uploads are computed
+// from the MD5 of their parts.
+func (s *TestSuite) TestMultipartETag(c *C) {
+	data := []byte("0123456789")
+	m := newMultipartETag(4)
+	m.Write(data[:3])
+	m.Write(data[3:])
+
+	var sums []byte
+	for _, part := range [][]byte{data[:4], data[4:8], data[8:]} {
+		sum := md5.Sum(part)
+		sums = append(sums, sum[:]...)
+	}
+	sum := md5.Sum(sums)
+	c.Assert(m.ETag(), Equals, hex.EncodeToString(sum[:])+"-3")
+
+	const mib = 1024 * 1024
+	c.Assert(multipartPartSizes(200*mib, 2), DeepEquals, []int64{128 * mib})
+	c.Assert(multipartPartSizes(20*mib, 4), DeepEquals, []int64{5 * mib})
+	c.Assert(multipartPartSizes(20*mib, 3), DeepEquals, []int64{8 * mib})
+	c.Assert(multipartPartSizes(20*mib, 7), HasLen, 0)
+}
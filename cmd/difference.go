@@ -41,6 +41,7 @@ const (
 	differInFirst                    // only in source (FIRST)
 	differInSecond                   // only in target (SECOND)
 	differInAASourceMTime            // differs in active-active source modtime
+	differInContent                  // differs in content, same size
 )
 
 func (d differType) String() string {
@@ -53,6 +54,8 @@ func (d differType) String() string {
 		return "metadata"
 	case differInAASourceMTime:
 		return "mm-source-mtime"
+	case differInContent:
+		return "content"
 	case differInType:
 		return "type"
 	case differInFirst:
@@ -160,17 +163,17 @@ func metadataEqual(m1, m2 map[string]string) bool {
 	return true
 }
 
-func objectDifference(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, targetURL string, isMetadata bool) (diffCh chan diffMessage) {
-	return difference(ctx, sourceClnt, targetClnt, sourceURL, targetURL, isMetadata, true, false, DirNone)
+func objectDifference(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, targetURL string, isMetadata bool, checksum *checksumComparer, timeRef time.Time) (diffCh chan diffMessage) {
+	return difference(ctx, sourceClnt, targetClnt, sourceURL, targetURL, isMetadata, checksum, true, false, DirNone, timeRef)
 }
 
 func dirDifference(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, targetURL string) (diffCh chan diffMessage) {
-	return difference(ctx, sourceClnt, targetClnt, sourceURL, targetURL, false, false, true, DirFirst)
+	return difference(ctx, sourceClnt, targetClnt, sourceURL, targetURL, false, nil, false, true, DirFirst, time.Time{})
 }
 
-func differenceInternal(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, targetURL string, isMetadata bool, isRecursive, returnSimilar bool, dirOpt DirOpt, diffCh chan<- diffMessage) *probe.Error {
-	// Set default values for listing.
-	srcCh := sourceClnt.List(ctx, ListOptions{Recursive: isRecursive, WithMetadata: isMetadata, ShowDir: dirOpt})
+func differenceInternal(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, targetURL string, isMetadata bool, checksum *checksumComparer, isRecursive, returnSimilar bool, dirOpt DirOpt, timeRef time.Time, diffCh chan<- diffMessage) *probe.Error {
+	// Set default values for listing, the source being listed as of timeRef if set.
+	srcCh := sourceClnt.List(ctx, ListOptions{Recursive: isRecursive, WithMetadata: isMetadata, ShowDir: dirOpt, TimeRef: timeRef})
 	tgtCh := targetClnt.List(ctx, ListOptions{Recursive: isRecursive, WithMetadata: isMetadata, ShowDir: dirOpt})
 
 	srcCtnt, srcOk := <-srcCh
@@ -255,7 +258,7 @@ func differenceInternal(ctx context.Context, sourceClnt, targetClnt Client, sour
 		}
 		if normalizedExpected == normalizedCurrent {
 			srcType, tgtType := srcCtnt.Type, tgtCtnt.Type
-			srcSize, tgtSize := srcCtnt.Size, tgtCtnt.Size
+			srcSize, tgtSize := contentSize(srcCtnt), contentSize(tgtCtnt)
 			if srcType.IsRegular() && !tgtType.IsRegular() ||
 				!srcType.IsRegular() && tgtType.IsRegular() {
 				// Type differs. Source is never a directory.
@@ -297,6 +300,20 @@ func differenceInternal(ctx context.Context, sourceClnt, targetClnt Client, sour
 					firstContent:  srcCtnt,
 					secondContent: tgtCtnt,
 				}
+			} else if checksum != nil && srcType.IsRegular() {
+				// Regular files of same size, compare their content.
+				differ, err := checksum.differ(ctx, srcCtnt, tgtCtnt)
+				if err != nil {
+					diffCh <- diffMessage{Error: err.Trace(srcCtnt.URL.String(), tgtCtnt.URL.String())}
+				} else if differ {
+					diffCh <- diffMessage{
+						FirstURL:      srcCtnt.URL.String(),
+						SecondURL:     tgtCtnt.URL.String(),
+						Diff:          differInContent,
+						firstContent:  srcCtnt,
+						secondContent: tgtCtnt,
+					}
+				}
 			}
 
 			// No differ
@@ -328,7 +345,7 @@ func differenceInternal(ctx context.Context, sourceClnt, targetClnt Client, sour
 
 // objectDifference function finds the difference between all objects
 // recursively in sorted order from source and target.
-func difference(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, targetURL string, isMetadata bool, isRecursive, returnSimilar bool, dirOpt DirOpt) (diffCh chan diffMessage) {
+func difference(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, targetURL string, isMetadata bool, checksum *checksumComparer, isRecursive, returnSimilar bool, dirOpt DirOpt, timeRef time.Time) (diffCh chan diffMessage) {
 	diffCh = make(chan diffMessage, 10000)
 
 	go func() {
@@ -339,7 +356,7 @@ func difference(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, t
 
 		for range newRetryTimerContinous(retryCtx, time.Second, time.Second*30, minio.MaxJitter) {
 			err := differenceInternal(retryCtx, sourceClnt, targetClnt, sourceURL, targetURL,
-				isMetadata, isRecursive, returnSimilar, dirOpt, diffCh)
+				isMetadata, checksum, isRecursive, returnSimilar, dirOpt, timeRef, diffCh)
 			if err != nil {
 				// handle this specifically for filesystem related errors.
 				switch err.ToGoError().(type) {
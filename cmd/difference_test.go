@@ -37,11 +37,19 @@ var testCases = []struct {
 	{[]string{"*.txt"}, "file/abc/bcd/def.txt", true},
 	{[]string{".*"}, ".sys", true},
 	{[]string{"*."}, ".sys.", true},
+	{[]string{"def"}, "file/abc/bcd/def", false},
+	{[]string{"bcd/def"}, "file/abc/bcd/def", false},
+	{[]string{"file/"}, "file/abc/bcd/def", false},
+	{[]string{"/file*"}, "file/abc/bcd/def", false},
 }
 
 func TestExcludeOptions(t *testing.T) {
 	for _, test := range testCases {
-		if matchExcludeOptions(test.pattern, test.object) != test.match {
+		filter, err := newObjectFilter(map[string][]string{"exclude": test.pattern})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filter.matchPath(test.object) == test.match {
 			t.Fatalf("Unexpected result %t, with pattern %s and object %s \n", !test.match, test.pattern, test.object)
 		}
 	}
@@ -17,9 +17,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net/url"
+	"path"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	humanize "github.com/dustin/go-humanize"
@@ -49,6 +53,15 @@ var (
 			Name:  "versions",
 			Usage: "include all object versions",
 		},
+		cli.StringFlag{
+			Name:  "breakdown",
+			Usage: "comma separated breakdowns of each total by 'storage-class', 'version', 'delete-marker', 'incomplete' and 'age'",
+		},
+		cli.IntFlag{
+			Name:  "max-workers",
+			Value: 16,
+			Usage: "maximum number of folder prefixes listed in parallel",
+		},
 	}
 )
 
@@ -59,7 +72,7 @@ var duCmd = cli.Command{
 	Action:       mainDu,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(duFlags, ioFlags...), globalFlags...),
+	Flags:        append(append(append(duFlags, filterFlags...), ioFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -84,22 +97,137 @@ EXAMPLES:
 
   4. Summarize disk usage of 'jazz-songs' bucket with all objects versions
      {{.Prompt}} {{.HelpName}} --versions s3/jazz-songs/
+
+  5. Summarize disk usage of 'jazz-songs' bucket, of the objects larger than 64MiB only
+     {{.Prompt}} {{.HelpName}} --min-size 64MiB s3/jazz-songs/
+
+  6. Summarize disk usage of 'jazz-songs' bucket by storage class and age of the objects
+     {{.Prompt}} {{.HelpName}} --breakdown storage-class,age s3/jazz-songs/
+
+  7. Summarize disk usage of current and noncurrent versions, delete markers and incomplete uploads of 'jazz-songs' bucket as JSON
+     {{.Prompt}} {{.HelpName}} --json --breakdown version,delete-marker,incomplete s3/jazz-songs/
 `,
 }
 
+// duTotal - the number and size of objects.
+type duTotal struct {
+	Objects int64 `json:"objects"`
+	Size    int64 `json:"size"`
+}
+
+// add - counts an object of size.
+func (t *duTotal) add(size int64) {
+	t.Objects++
+	t.Size += size
+}
+
+// duUsage - the disk usage of a folder prefix, with the breakdowns
+// requested.
+type duUsage struct {
+	duTotal
+	StorageClass  map[string]*duTotal `json:"storageClass,omitempty"`
+	Current       *duTotal            `json:"current,omitempty"`
+	Noncurrent    *duTotal            `json:"noncurrent,omitempty"`
+	DeleteMarkers *int64              `json:"deleteMarkers,omitempty"`
+	Incomplete    *duTotal            `json:"incomplete,omitempty"`
+	Age           map[string]*duTotal `json:"age,omitempty"`
+}
+
+// Breakdowns of du.
+const (
+	duBreakdownStorageClass = "storage-class"
+	duBreakdownVersion      = "version"
+	duBreakdownDeleteMarker = "delete-marker"
+	duBreakdownIncomplete   = "incomplete"
+	duBreakdownAge          = "age"
+)
+
+// duAgeBuckets - the age buckets of the age breakdown, objects older
+// than the last one are in duAgeOldest.
+var duAgeBuckets = []struct {
+	name string
+	age  time.Duration
+}{
+	{"<1d", 24 * time.Hour},
+	{"1d-7d", 7 * 24 * time.Hour},
+	{"7d-30d", 30 * 24 * time.Hour},
+	{"30d-90d", 90 * 24 * time.Hour},
+	{"90d-365d", 365 * 24 * time.Hour},
+}
+
+const duAgeOldest = ">365d"
+
+// duAgeBucket - returns the age bucket of an object modified at
+// modTime, aged as of now.
+func duAgeBucket(modTime, now time.Time) string {
+	age := now.Sub(modTime)
+	for _, bucket := range duAgeBuckets {
+		if age < bucket.age {
+			return bucket.name
+		}
+	}
+	return duAgeOldest
+}
+
+// parseDuBreakdowns - parses the comma separated breakdowns of
+// `--breakdown`.
+func parseDuBreakdowns(value string) (map[string]bool, *probe.Error) {
+	breakdowns := make(map[string]bool)
+	for _, breakdown := range strings.Split(value, ",") {
+		breakdown = strings.TrimSpace(breakdown)
+		switch breakdown {
+		case "":
+		case duBreakdownStorageClass, duBreakdownVersion, duBreakdownDeleteMarker, duBreakdownIncomplete, duBreakdownAge:
+			breakdowns[breakdown] = true
+		default:
+			return nil, errInvalidArgument().Trace(breakdown)
+		}
+	}
+	return breakdowns, nil
+}
+
 // Structured message depending on the type of console.
 type duMessage struct {
 	Prefix string `json:"prefix"`
-	Size   int64  `json:"size"`
 	Status string `json:"status"`
+	duUsage
 }
 
 // Colorized message for console printing.
 func (r duMessage) String() string {
-	humanSize := strings.Join(strings.Fields(humanize.IBytes(uint64(r.Size))), "")
+	humanSize := func(size int64) string {
+		return strings.Join(strings.Fields(humanize.IBytes(uint64(size))), "")
+	}
+	line := func(total *duTotal, label string) string {
+		return fmt.Sprintf("\n%s\t  %s (%d object(s))", console.Colorize("Size", humanSize(total.Size)), label, total.Objects)
+	}
 
-	return fmt.Sprintf("%s\t%s", console.Colorize("Size", humanSize),
+	msg := fmt.Sprintf("%s\t%s", console.Colorize("Size", humanSize(r.Size)),
 		console.Colorize("Prefix", r.Prefix))
+	for _, class := range sortedDuTotals(r.StorageClass) {
+		msg += line(r.StorageClass[class], class)
+	}
+	if r.Current != nil {
+		msg += line(r.Current, "current")
+	}
+	if r.Noncurrent != nil {
+		msg += line(r.Noncurrent, "noncurrent")
+	}
+	if r.DeleteMarkers != nil {
+		msg += fmt.Sprintf("\n\t  %d delete marker(s)", *r.DeleteMarkers)
+	}
+	if r.Incomplete != nil {
+		msg += line(r.Incomplete, "incomplete uploads")
+	}
+	for _, bucket := range duAgeBuckets {
+		if total := r.Age[bucket.name]; total != nil {
+			msg += line(total, bucket.name)
+		}
+	}
+	if total := r.Age[duAgeOldest]; total != nil {
+		msg += line(total, duAgeOldest)
+	}
+	return msg
 }
 
 // JSON'ified message for scripting.
@@ -109,26 +237,169 @@ func (r duMessage) JSON() string {
 	return string(msgBytes)
 }
 
-func du(urlStr string, timeRef time.Time, withVersions bool, depth int, encKeyDB map[string][]prefixSSEPair) (int64, error) {
-	targetAlias, targetURL, _ := mustExpandAlias(urlStr)
-	if !strings.HasSuffix(targetURL, "/") {
-		targetURL += "/"
+// sortedDuTotals - returns the names of totals in lexical order.
+func sortedDuTotals(totals map[string]*duTotal) []string {
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
+
+// duOptions - what du lists and reports.
+type duOptions struct {
+	timeRef      time.Time
+	withVersions bool
+	depth        int
+	breakdowns   map[string]bool
+	workers      workerOptions
+	encKeyDB     map[string][]prefixSSEPair
+	filter       *objectFilter
+}
+
+// duJob - the disk usage of a target, aggregated in memory for each
+// folder prefix up to the depth printed.
+type duJob struct {
+	alias     string
+	targetURL string // expanded URL ending with a separator
+	rootURL   string // URL of the command line argument, filters are relative to
+	opts      duOptions
+	now       time.Time
+
+	mutex  sync.Mutex
+	usages map[string]*duUsage // by folder prefix relative to targetURL
+}
 
-	clnt, pErr := newClientFromAlias(targetAlias, targetURL)
-	if pErr != nil {
-		errorIf(pErr.Trace(urlStr), "Failed to summarize disk usage `"+urlStr+"`.")
-		return 0, exitStatus(globalErrorExitStatus) // End of journey.
+// usage - returns the usage of prefix, callers must hold the mutex.
+func (j *duJob) usage(prefix string) *duUsage {
+	u := j.usages[prefix]
+	if u != nil {
+		return u
+	}
+	u = &duUsage{}
+	if j.opts.breakdowns[duBreakdownStorageClass] {
+		u.StorageClass = make(map[string]*duTotal)
+	}
+	if j.opts.breakdowns[duBreakdownVersion] {
+		u.Current, u.Noncurrent = &duTotal{}, &duTotal{}
+	}
+	if j.opts.breakdowns[duBreakdownDeleteMarker] {
+		u.DeleteMarkers = new(int64)
+	}
+	if j.opts.breakdowns[duBreakdownIncomplete] {
+		u.Incomplete = &duTotal{}
 	}
+	if j.opts.breakdowns[duBreakdownAge] {
+		u.Age = make(map[string]*duTotal)
+	}
+	j.usages[prefix] = u
+	return u
+}
 
-	contentCh := clnt.List(globalContext, ListOptions{
-		TimeRef:           timeRef,
-		WithOlderVersions: withVersions,
-		Recursive:         false,
-		ShowDir:           DirFirst,
-	})
-	size := int64(0)
-	for content := range contentCh {
+// prefixes - returns the folder prefixes of key printed, from the
+// target itself down.
+func (j *duJob) prefixes(key string) []string {
+	prefixes := []string{""}
+	for i := 0; i < len(key); i++ {
+		if j.opts.depth >= 0 && len(prefixes) >= j.opts.depth {
+			break
+		}
+		if key[i] == '/' {
+			prefixes = append(prefixes, key[:i+1])
+		}
+	}
+	return prefixes
+}
+
+// key - returns the path of content relative to the target.
+func (j *duJob) key(content *ClientContent) string {
+	key := strings.TrimPrefix(content.URL.String(), j.targetURL)
+	return strings.ReplaceAll(key, string(content.URL.Separator), "/")
+}
+
+// addFolder - adds a folder to the usage, printed even if empty.
+func (j *duJob) addFolder(content *ClientContent) {
+	key := j.key(content)
+	if !strings.HasSuffix(key, "/") {
+		key += "/"
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	for _, prefix := range j.prefixes(key) {
+		j.usage(prefix)
+	}
+}
+
+// add - adds content to the usage of its folder prefixes.
+func (j *duJob) add(content *ClientContent, incomplete bool) {
+	key := j.key(content)
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	for _, prefix := range j.prefixes(key) {
+		u := j.usage(prefix)
+		switch {
+		case incomplete:
+			u.Incomplete.add(content.Size)
+			continue
+		case content.IsDeleteMarker:
+			if u.DeleteMarkers != nil {
+				*u.DeleteMarkers++
+			}
+			continue
+		}
+		u.add(content.Size)
+		if u.StorageClass != nil {
+			class := content.StorageClass
+			if class == "" {
+				class = "STANDARD"
+			}
+			if u.StorageClass[class] == nil {
+				u.StorageClass[class] = &duTotal{}
+			}
+			u.StorageClass[class].add(content.Size)
+		}
+		if u.Current != nil {
+			// Objects without versions are current.
+			if content.IsLatest || content.VersionID == "" {
+				u.Current.add(content.Size)
+			} else {
+				u.Noncurrent.add(content.Size)
+			}
+		}
+		if u.Age != nil {
+			bucket := duAgeBucket(content.Time, j.now)
+			if u.Age[bucket] == nil {
+				u.Age[bucket] = &duTotal{}
+			}
+			u.Age[bucket].add(content.Size)
+		}
+	}
+}
+
+// list - lists urlStr, recursively or not, adding its objects to the
+// usage. Folders found by a listing which is not recursive are
+// returned to be listed in turn.
+func (j *duJob) list(ctx context.Context, urlStr string, recursive, incomplete bool) (folders []string, err *probe.Error) {
+	clnt, err := newClientFromAliasWithKeys(j.alias, urlStr, j.opts.encKeyDB)
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+
+	showDir := DirNone
+	if !recursive {
+		showDir = DirFirst
+	}
+	for content := range clnt.List(ctx, ListOptions{
+		TimeRef:           j.opts.timeRef,
+		WithOlderVersions: j.opts.withVersions,
+		WithDeleteMarkers: j.opts.breakdowns[duBreakdownDeleteMarker],
+		Incomplete:        incomplete,
+		Recursive:         recursive,
+		ShowDir:           showDir,
+	}) {
 		if content.Err != nil {
 			switch content.Err.ToGoError().(type) {
 			// handle this specifically for filesystem related errors.
@@ -138,60 +409,137 @@ func du(urlStr string, timeRef time.Time, withVersions bool, depth int, encKeyDB
 				errorIf(content.Err.Trace(clnt.GetURL().String()), "Unable to list folder.")
 				continue
 			}
-			errorIf(content.Err.Trace(urlStr), "Failed to find disk usage of `"+urlStr+"` recursively.")
-			return 0, exitStatus(globalErrorExitStatus)
+			return nil, content.Err.Trace(urlStr)
 		}
-		if content.URL.String() == targetURL {
+		if content.URL.String() == urlStr {
 			continue
 		}
-
 		if content.Type.IsDir() {
-			depth := depth
-			if depth > 0 {
-				depth--
+			if !recursive {
+				folders = append(folders, content.URL.String())
+				j.addFolder(content)
 			}
+			continue
+		}
+		if j.opts.filter.match(ctx, j.alias, content, filterPath(content, j.rootURL)) {
+			j.add(content, incomplete)
+		}
+	}
+	return folders, nil
+}
 
-			subDirAlias := content.URL.Path
-			if targetAlias != "" {
-				subDirAlias = targetAlias + "/" + content.URL.Path
-			}
-			used, err := du(subDirAlias, timeRef, withVersions, depth, encKeyDB)
-			if err != nil {
-				return 0, err
+// run - lists the target, each folder at its top in parallel, and
+// returns the usage of each folder prefix.
+func (j *duJob) run(ctx context.Context) (map[string]*duUsage, *probe.Error) {
+	j.mutex.Lock()
+	j.usage("")
+	j.mutex.Unlock()
+
+	folders, err := j.list(ctx, j.targetURL, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	type listing struct {
+		urlStr     string
+		incomplete bool
+	}
+	var listings []listing
+	for _, folder := range folders {
+		listings = append(listings, listing{urlStr: folder})
+	}
+	if j.opts.breakdowns[duBreakdownIncomplete] {
+		listings = append(listings, listing{urlStr: j.targetURL, incomplete: true})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	statusCh := make(chan URLs)
+	parallel := newParallelManager(statusCh, j.opts.workers)
+	go func() {
+		defer close(statusCh)
+		defer parallel.stopAndWait()
+		for _, l := range listings {
+			if ctx.Err() != nil {
+				return
 			}
-			size += used
-		} else {
-			size += content.Size
+			l := l
+			parallel.queueTask(func() URLs {
+				_, err := j.list(ctx, l.urlStr, true, l.incomplete)
+				return URLs{Error: err}
+			})
 		}
+	}()
+	for urls := range statusCh {
+		if urls.Error != nil && err == nil {
+			err = urls.Error
+			cancel()
+		}
+	}
+	if err != nil {
+		return nil, err
 	}
+	return j.usages, nil
+}
 
-	if depth != 0 {
-		u, err := url.Parse(targetURL)
-		if err != nil {
-			panic(err)
-		}
+// du - summarizes the disk usage of urlStr, printing the total of each
+// folder prefix up to the depth requested, deepest first.
+func du(ctx context.Context, urlStr string, opts duOptions) error {
+	targetAlias, targetURL, _ := mustExpandAlias(urlStr)
+	if !strings.HasSuffix(targetURL, "/") {
+		targetURL += "/"
+	}
 
+	j := &duJob{
+		alias:     targetAlias,
+		targetURL: targetURL,
+		rootURL:   urlStr,
+		opts:      opts,
+		now:       UTCNow(),
+		usages:    make(map[string]*duUsage),
+	}
+	if !opts.timeRef.IsZero() {
+		j.now = opts.timeRef
+	}
+	usages, err := j.run(ctx)
+	if err != nil {
+		errorIf(err.Trace(urlStr), "Failed to find disk usage of `"+urlStr+"` recursively.")
+		return exitStatus(globalErrorExitStatus)
+	}
+
+	if opts.depth == 0 {
+		return nil
+	}
+	u, e := url.Parse(targetURL)
+	if e != nil {
+		panic(e)
+	}
+	for _, prefix := range sortDuPrefixes(usages) {
 		printMsg(duMessage{
-			Prefix: strings.Trim(u.Path, "/"),
-			Size:   size,
-			Status: "success",
+			Prefix:  strings.Trim(path.Join(u.Path, prefix), "/"),
+			Status:  "success",
+			duUsage: *usages[prefix],
 		})
 	}
-
-	return size, nil
+	return nil
 }
 
-// main for du command.
-func mainDu(ctx *cli.Context) error {
-	if !ctx.Args().Present() {
-		cli.ShowCommandHelpAndExit(ctx, "du", 1)
+// sortDuPrefixes - returns the folder prefixes of usages with the
+// folders inside a prefix sorted before it.
+func sortDuPrefixes(usages map[string]*duUsage) []string {
+	prefixes := make([]string, 0, len(usages))
+	for prefix := range usages {
+		prefixes = append(prefixes, prefix)
 	}
+	sort.Slice(prefixes, func(i, k int) bool {
+		return prefixes[i]+"\xff" < prefixes[k]+"\xff"
+	})
+	return prefixes
+}
 
-	// Set colors.
-	console.SetColor("Remove", color.New(color.FgGreen, color.Bold))
-	console.SetColor("Prefix", color.New(color.FgCyan, color.Bold))
-	console.SetColor("Size", color.New(color.FgYellow))
-
+// duOptionsFromContext - returns the du options of ctx, exits on
+// invalid values.
+func duOptionsFromContext(ctx *cli.Context) duOptions {
 	// Parse encryption keys per command.
 	encKeyDB, err := getEncKeys(ctx)
 	fatalIf(err, "Unable to parse encryption keys.")
@@ -208,12 +556,42 @@ func mainDu(ctx *cli.Context) error {
 		}
 	}
 
-	withVersions := ctx.Bool("versions")
-	timeRef := parseRewindFlag(ctx.String("rewind"))
+	breakdowns, err := parseDuBreakdowns(ctx.String("breakdown"))
+	fatalIf(err, "Unable to parse `--breakdown`.")
+
+	if ctx.Int("max-workers") < 1 || ctx.Int("max-workers") > maxParallelWorkers {
+		fatalIf(errInvalidArgument().Trace(ctx.String("max-workers")), "`--max-workers` should be between 1 and 128.")
+	}
+
+	return duOptions{
+		timeRef: parseRewindFlag(ctx.String("rewind")),
+		// Current and noncurrent versions are told apart, and delete
+		// markers listed, only when all versions are listed.
+		withVersions: ctx.Bool("versions") || breakdowns[duBreakdownVersion] || breakdowns[duBreakdownDeleteMarker],
+		depth:        depth,
+		breakdowns:   breakdowns,
+		workers:      workerOptions{maxWorkers: ctx.Int("max-workers"), policy: workerPolicyFixed},
+		encKeyDB:     encKeyDB,
+		filter:       newObjectFilterFromContext(ctx),
+	}
+}
+
+// main for du command.
+func mainDu(ctx *cli.Context) error {
+	if !ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, "du", 1)
+	}
+
+	// Set colors.
+	console.SetColor("Remove", color.New(color.FgGreen, color.Bold))
+	console.SetColor("Prefix", color.New(color.FgCyan, color.Bold))
+	console.SetColor("Size", color.New(color.FgYellow))
+
+	opts := duOptionsFromContext(ctx)
 
 	var duErr error
 	for _, urlStr := range ctx.Args() {
-		if _, err := du(urlStr, timeRef, withVersions, depth, encKeyDB); duErr == nil {
+		if err := du(globalContext, urlStr, opts); duErr == nil {
 			duErr = err
 		}
 	}
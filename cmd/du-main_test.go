@@ -0,0 +1,106 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/minio/cli"
+	. "gopkg.in/check.v1"
+)
+
+// TestMemDu - tests disk usage is aggregated per folder prefix with
+// its breakdowns.
+func (s *TestSuite) TestMemDu(c *C) {
+	ctx := context.Background()
+	clnt, err := newClient("mem://mem-du")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(ctx, "", false, false), IsNil)
+	c.Assert(clnt.SetVersion(ctx, "enable"), IsNil)
+
+	memPut(c, "mem://mem-du/top", "1")
+	memPut(c, "mem://mem-du/dir/a", "22")
+	memPut(c, "mem://mem-du/dir/a", "333")
+	memPut(c, "mem://mem-du/dir/sub/b", "4444")
+	objClnt, err := newClient("mem://mem-du/dir/sub/b")
+	c.Assert(err, IsNil)
+	contentCh := make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: objClnt.GetURL()}
+	close(contentCh)
+	for err := range objClnt.Remove(ctx, false, false, false, contentCh) {
+		c.Assert(err, IsNil)
+	}
+
+	breakdowns, err := parseDuBreakdowns("storage-class, version,delete-marker,age")
+	c.Assert(err, IsNil)
+	_, err = parseDuBreakdowns("size")
+	c.Assert(err, NotNil)
+
+	j := &duJob{
+		targetURL: "mem://mem-du/",
+		rootURL:   "mem://mem-du/",
+		opts: duOptions{
+			withVersions: true,
+			depth:        2,
+			breakdowns:   breakdowns,
+		},
+		now:    UTCNow(),
+		usages: make(map[string]*duUsage),
+	}
+	usages, err := j.run(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(sortDuPrefixes(usages), DeepEquals, []string{"dir/", ""})
+
+	root := usages[""]
+	c.Assert(root.duTotal, Equals, duTotal{Objects: 4, Size: 10})
+	c.Assert(*root.Current, Equals, duTotal{Objects: 2, Size: 4})
+	c.Assert(*root.Noncurrent, Equals, duTotal{Objects: 2, Size: 6})
+	c.Assert(*root.DeleteMarkers, Equals, int64(1))
+	c.Assert(*root.StorageClass["STANDARD"], Equals, duTotal{Objects: 4, Size: 10})
+	c.Assert(*root.Age["<1d"], Equals, duTotal{Objects: 4, Size: 10})
+	c.Assert(usages["dir/"].duTotal, Equals, duTotal{Objects: 3, Size: 9})
+
+	// Folders deeper than the depth printed are only summed up.
+	j.opts.depth = -1
+	c.Assert(j.prefixes("dir/sub/b"), DeepEquals, []string{"", "dir/", "dir/sub/"})
+	j.opts.depth = 1
+	c.Assert(j.prefixes("dir/sub/b"), DeepEquals, []string{""})
+	c.Assert(duAgeBucket(UTCNow().Add(-48*time.Hour), UTCNow()), Equals, "1d-7d")
+	c.Assert(duAgeBucket(UTCNow().Add(-400*24*time.Hour), UTCNow()), Equals, duAgeOldest)
+
+	// Delete markers are only listed along with all versions, which
+	// their breakdown alone lists.
+	set := flag.NewFlagSet("du", flag.ContinueOnError)
+	for _, f := range duCmd.Flags {
+		f.Apply(set)
+	}
+	c.Assert(set.Parse([]string{"--breakdown=delete-marker", "mem://mem-du/"}), IsNil)
+	j = &duJob{
+		targetURL: "mem://mem-du/",
+		rootURL:   "mem://mem-du/",
+		opts:      duOptionsFromContext(cli.NewContext(nil, set, nil)),
+		now:       UTCNow(),
+		usages:    make(map[string]*duUsage),
+	}
+	usages, err = j.run(ctx)
+	c.Assert(err, IsNil)
+	root = usages[""]
+	c.Assert(*root.DeleteMarkers, Equals, int64(1))
+	c.Assert(root.Current, IsNil)
+}
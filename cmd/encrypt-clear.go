@@ -85,7 +85,7 @@ func mainEncryptClear(cliCtx *cli.Context) error {
 	args := cliCtx.Args()
 	aliasedURL := args.Get(0)
 	// Create a new Client
-	client, err := newClient(aliasedURL)
+	client, err := newClientWithCapability(aliasedURL, capEncryption)
 	fatalIf(err, "Unable to initialize connection.")
 	fatalIf(client.DeleteEncryption(ctx), "Unable to clear auto encryption configuration")
 	printMsg(encryptClearMessage{
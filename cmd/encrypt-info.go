@@ -99,7 +99,7 @@ func mainEncryptInfo(cliCtx *cli.Context) error {
 	args := cliCtx.Args()
 	aliasedURL := args.Get(0)
 	// Create a new Client
-	client, err := newClient(aliasedURL)
+	client, err := newClientWithCapability(aliasedURL, capEncryption)
 	fatalIf(err, "Unable to initialize connection.")
 	algorithm, keyID, e := client.GetEncryption(ctx)
 	fatalIf(e, "Unable to get encryption info")
@@ -93,7 +93,7 @@ func mainEncryptSet(cliCtx *cli.Context) error {
 	args := cliCtx.Args()
 	aliasedURL := args.Get(len(args) - 1)
 	// Create a new Client
-	client, err := newClient(aliasedURL)
+	client, err := newClientWithCapability(aliasedURL, capEncryption)
 	fatalIf(err, "Unable to initialize connection.")
 	var algorithm, keyID string
 	switch len(args) {
@@ -123,7 +123,7 @@ func mainEventRemove(cliCtx *cli.Context) error {
 		fatalIf(err.Trace(), "Unable to parse the provided url.")
 	}
 
-	s3Client, ok := client.(*S3Client)
+	s3Client, ok := unwrapClient(client).(*S3Client)
 	if !ok {
 		fatalIf(errDummy().Trace(), "The provided url doesn't point to a S3 server.")
 	}
@@ -0,0 +1,205 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Flag of commands journaling the objects they fail to copy or remove.
+var failedLogFlag = cli.StringFlag{
+	Name:  "failed-log",
+	Usage: "append object(s) failing to FILE as JSON lines, retried by 'mc replay FILE'",
+}
+
+// failedLogEntry - a line of a failed log, either a copy or a removal
+// along with the options it ran with. Encryption keys are not logged,
+// they are given again to replay.
+type failedLogEntry struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	Error   string    `json:"error"`
+
+	// A failed copy, the source of moves being removed once copied.
+	URLs     *URLs `json:"urls,omitempty"`
+	Preserve bool  `json:"preserve,omitempty"`
+	Move     bool  `json:"move,omitempty"`
+
+	// A failed removal.
+	Remove     string `json:"remove,omitempty"`
+	VersionID  string `json:"versionID,omitempty"`
+	Incomplete bool   `json:"incomplete,omitempty"`
+	Bypass     bool   `json:"bypass,omitempty"`
+}
+
+// failedLog - appends the failures of a command to its file, a nil
+// log discards them.
+type failedLog struct {
+	mutex   sync.Mutex
+	file    *os.File
+	command string
+}
+
+// openFailedLog - opens the file of --failed-log for command, returns
+// nil when the flag is not set.
+func openFailedLog(ctx *cli.Context, command string) *failedLog {
+	filename := ctx.String("failed-log")
+	if filename == "" {
+		return nil
+	}
+	file, e := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	fatalIf(probe.NewError(e).Trace(filename), "Unable to open failed log `"+filename+"`.")
+	return &failedLog{file: file, command: command}
+}
+
+// failedLogURL - returns urlStr, absolute when it is a local path so
+// that it is replayed from any folder.
+func failedLogURL(urlStr string) string {
+	if alias, _, _ := mustExpandAlias(urlStr); alias != "" || newClientURL(urlStr).Type != fileSystem {
+		return urlStr
+	}
+	if absPath, e := filepath.Abs(urlStr); e == nil {
+		return absPath
+	}
+	return urlStr
+}
+
+// failedLogContent - returns a copy of content with an absolute URL
+// when it is a local path.
+func failedLogContent(content *ClientContent) *ClientContent {
+	if content == nil || content.URL.Type != fileSystem {
+		return content
+	}
+	c := *content
+	if absPath, e := filepath.Abs(c.URL.Path); e == nil {
+		c.URL = *newClientURL(absPath)
+	}
+	return &c
+}
+
+// write - appends entry to the log.
+func (l *failedLog) write(entry failedLogEntry) {
+	if l == nil {
+		return
+	}
+	var jsoniter = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	// Replayed entries keep the command they were first logged by.
+	entry.Time = UTCNow()
+	if entry.Command == "" {
+		entry.Command = l.command
+	}
+	data, e := jsoniter.Marshal(entry)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_, e = l.file.Write(append(data, '\n'))
+	errorIf(probe.NewError(e).Trace(l.file.Name()), "Unable to write failed log `"+l.file.Name()+"`.")
+}
+
+// addCopy - logs the failed copy of urls.
+func (l *failedLog) addCopy(urls URLs, preserve, move bool) {
+	if l == nil {
+		return
+	}
+	urls.SourceContent = failedLogContent(urls.SourceContent)
+	urls.TargetContent = failedLogContent(urls.TargetContent)
+	l.write(failedLogEntry{
+		Error:    urls.Error.ToGoError().Error(),
+		URLs:     &urls,
+		Preserve: preserve,
+		Move:     move,
+	})
+}
+
+// addRemove - logs the failed removal of urlStr.
+func (l *failedLog) addRemove(urlStr, versionID string, isIncomplete, isBypass bool, err *probe.Error) {
+	l.write(failedLogEntry{
+		Error:      err.ToGoError().Error(),
+		Remove:     failedLogURL(urlStr),
+		VersionID:  versionID,
+		Incomplete: isIncomplete,
+		Bypass:     isBypass,
+	})
+}
+
+// Close - closes the log.
+func (l *failedLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// removeFailedObject - returns the aliased URL and the version of the
+// object a removal from alias failed on, false when err does not name
+// it.
+func removeFailedObject(alias string, err *probe.Error) (urlStr, versionID string, ok bool) {
+	e := err.ToGoError()
+	var removeErr ObjectRemoveFailed
+	if errors.As(e, &removeErr) {
+		return alias + "/" + removeErr.Bucket + "/" + removeErr.Object, removeErr.VersionID, true
+	}
+	var pathErr *os.PathError
+	if errors.As(e, &pathErr) {
+		return pathErr.Path, "", true
+	}
+	switch e := e.(type) {
+	case PathInsufficientPermission:
+		return e.Path, "", true
+	case PathNotFound:
+		return e.Path, "", true
+	}
+	return "", "", false
+}
+
+// readFailedLog - reads the entries of a failed log.
+func readFailedLog(reader io.Reader) ([]failedLogEntry, *probe.Error) {
+	var jsoniter = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	var entries []failedLogEntry
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry failedLogEntry
+		if e := jsoniter.Unmarshal(scanner.Bytes(), &entry); e != nil {
+			return nil, probe.NewError(e).Trace(scanner.Text())
+		}
+		if (entry.URLs == nil) == (entry.Remove == "") {
+			return nil, errInvalidArgument().Trace(scanner.Text())
+		}
+		entries = append(entries, entry)
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,98 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/mc/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+// TestFailedLogReplay - tests failed copies and removals are logged
+// and replayed with the options they failed with.
+func (s *TestSuite) TestFailedLogReplay(c *C) {
+	ctx := context.Background()
+	clnt, err := newClient("mem://failed-log")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(ctx, "", false, false), IsNil)
+	memPut(c, "mem://failed-log/source", "data")
+	memPut(c, "mem://failed-log/stale", "data")
+
+	src, err := memStat(c, "mem://failed-log/source")
+	c.Assert(err, IsNil)
+
+	filename := filepath.Join(c.MkDir(), "failed.json")
+	file, e := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	c.Assert(e, IsNil)
+	failed := &failedLog{file: file, command: "cp"}
+	failed.addCopy(URLs{
+		SourceContent: src,
+		TargetContent: &ClientContent{URL: *newClientURL("mem://failed-log/target")},
+		Compress:      compressGzip,
+		Error:         probe.NewError(errors.New("connection reset")),
+	}, true, false)
+	failed.addRemove("mem://failed-log/stale", "", false, true, probe.NewError(errors.New("slow down")))
+	c.Assert(failed.Close(), IsNil)
+
+	file, e = os.Open(filename)
+	c.Assert(e, IsNil)
+	entries, err := readFailedLog(file)
+	c.Assert(err, IsNil)
+	c.Assert(file.Close(), IsNil)
+	c.Assert(len(entries), Equals, 2)
+
+	copyEntry := entries[0]
+	c.Assert(copyEntry.Command, Equals, "cp")
+	c.Assert(copyEntry.Error, Equals, "connection reset")
+	c.Assert(copyEntry.Preserve, Equals, true)
+	c.Assert(copyEntry.URLs.Compress, Equals, compressGzip)
+	c.Assert(copyEntry.URLs.TargetContent.URL.String(), Equals, "mem://failed-log/target")
+
+	urls := uploadSourceToTargetURL(ctx, *copyEntry.URLs, nil, nil, copyEntry.Preserve)
+	c.Assert(urls.Error, IsNil)
+	st, err := memStat(c, "mem://failed-log/target")
+	c.Assert(err, IsNil)
+	c.Assert(st.UserMetadata["Mc-Compression"], Equals, compressGzip)
+
+	removeEntry := entries[1]
+	c.Assert(removeEntry.Remove, Equals, "mem://failed-log/stale")
+	c.Assert(removeEntry.Bypass, Equals, true)
+	c.Assert(replayRemove(ctx, removeEntry, retryPolicy{}), IsNil)
+	_, err = memStat(c, "mem://failed-log/stale")
+	c.Assert(err, NotNil)
+}
+
+// TestRemoveFailedObject - tests failed removals name their object.
+func (s *TestSuite) TestRemoveFailedObject(c *C) {
+	err := probe.NewError(ObjectRemoveFailed{Bucket: "bucket", Object: "dir/object", VersionID: "v1", Err: errors.New("slow down")})
+	urlStr, versionID, ok := removeFailedObject("play", err)
+	c.Assert(ok, Equals, true)
+	c.Assert(urlStr, Equals, "play/bucket/dir/object")
+	c.Assert(versionID, Equals, "v1")
+
+	_, _, ok = removeFailedObject("play", probe.NewError(errors.New("slow down")))
+	c.Assert(ok, Equals, false)
+
+	// Local paths are logged absolute.
+	absPath, e := filepath.Abs("photos/beach.jpg")
+	c.Assert(e, IsNil)
+	c.Assert(failedLogURL("photos/beach.jpg"), Equals, absPath)
+}
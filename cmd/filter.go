@@ -0,0 +1,438 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/ioutils"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio/pkg/mimedb"
+	"github.com/minio/minio/pkg/wildcard"
+)
+
+// Flags of commands walking a listing, selecting the objects they act on.
+var filterFlags = []cli.Flag{
+	cli.StringSliceFlag{
+		Name:  "include",
+		Usage: "only object(s) matching the specified object name pattern",
+	},
+	cli.StringSliceFlag{
+		Name:  "exclude",
+		Usage: "exclude object(s) that match specified object name pattern",
+	},
+	cli.StringFlag{
+		Name:  "older-than",
+		Usage: "only object(s) older than L days, M hours and N minutes",
+	},
+	cli.StringFlag{
+		Name:  "newer-than",
+		Usage: "only object(s) newer than L days, M hours and N minutes",
+	},
+	cli.StringFlag{
+		Name:  "min-size",
+		Usage: "only object(s) of at least the specified size, such as 64KiB",
+	},
+	cli.StringFlag{
+		Name:  "max-size",
+		Usage: "only object(s) of at most the specified size, such as 1GiB",
+	},
+	cli.StringSliceFlag{
+		Name:  "match-storage-class",
+		Usage: "only object(s) stored in the specified storage class",
+	},
+	cli.StringSliceFlag{
+		Name:  "match-metadata",
+		Usage: "only object(s) with metadata matching KEY=PATTERN",
+	},
+	cli.StringSliceFlag{
+		Name:  "match-tags",
+		Usage: "only object(s) with a tag matching KEY=PATTERN",
+	},
+	cli.StringSliceFlag{
+		Name:  "match-content-type",
+		Usage: "only object(s) with a content type matching the specified pattern, such as image/*",
+	},
+	cli.StringFlag{
+		Name:  "filter-file",
+		Usage: "read rsync-style filter rules from FILE, '+ PATTERN' and '- PATTERN' lines along with lines of any filter flag",
+	},
+}
+
+// filterRule - a rule including or excluding the paths matching its
+// pattern.
+type filterRule struct {
+	include bool
+	pattern string
+	// Rules of filter files match as rsync does, those of --include
+	// and --exclude match the whole path.
+	rsync bool
+}
+
+// match - returns true if pattern matches p, a path relative to the
+// listed URL. As with rsync a pattern of a filter file starting with
+// a slash is anchored, one ending with a slash matches folders and one
+// without slashes matches names as well.
+func (r filterRule) match(p string) bool {
+	pattern := r.pattern
+	if !r.rsync {
+		return wildcard.Match(pattern, p)
+	}
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "*"
+	}
+	if strings.HasPrefix(pattern, "/") {
+		return wildcard.Match(strings.TrimPrefix(pattern, "/"), p)
+	}
+	if wildcard.Match(pattern, p) {
+		return true
+	}
+	if !strings.Contains(pattern, "/") {
+		return wildcard.Match(pattern, path.Base(p))
+	}
+	return wildcard.Match("*/"+pattern, p)
+}
+
+// keyValueMatcher - matches the value of a metadata or tag key.
+type keyValueMatcher struct {
+	key     string
+	pattern string
+}
+
+// match - returns true if values has a key matching m.
+func (m keyValueMatcher) match(values map[string]string) bool {
+	for k, v := range values {
+		if strings.EqualFold(strings.TrimPrefix(strings.ToLower(k), "x-amz-meta-"), m.key) && wildcard.Match(m.pattern, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// objectFilter - selects the objects a command acts on, a nil filter
+// selects all of them.
+type objectFilter struct {
+	// First matching rule decides, unmatched paths are excluded when
+	// --include is given and included otherwise.
+	rules            []filterRule
+	excludeUnmatched bool
+
+	olderThan, newerThan time.Duration
+	minSize, maxSize     int64
+
+	storageClasses []string
+	metadata       []keyValueMatcher
+	tags           []keyValueMatcher
+	contentTypes   []string
+}
+
+// filterFlagValues - returns the values of the filter flags set in
+// ctx, by flag name.
+func filterFlagValues(ctx *cli.Context) map[string][]string {
+	values := make(map[string][]string)
+	for _, flag := range filterFlags {
+		name := flag.GetName()
+		switch flag.(type) {
+		case cli.StringSliceFlag:
+			if v := ctx.StringSlice(name); len(v) > 0 {
+				values[name] = v
+			}
+		case cli.StringFlag:
+			if v := ctx.String(name); v != "" {
+				values[name] = []string{v}
+			}
+		}
+	}
+	return values
+}
+
+// newObjectFilterFromContext - returns the filter of the command line,
+// exits on invalid filters.
+func newObjectFilterFromContext(ctx *cli.Context) *objectFilter {
+	filter, err := newObjectFilter(filterFlagValues(ctx))
+	fatalIf(err.Trace(ctx.Args()...), "Unable to parse filters.")
+	return filter
+}
+
+// newObjectFilterFromSession - returns the filter session was started
+// with, saved along with its flags.
+func newObjectFilterFromSession(s *sessionV8) *objectFilter {
+	values := make(map[string][]string)
+	for _, flag := range filterFlags {
+		name := flag.GetName()
+		if v := s.Header.CommandStringFlags[name]; v != "" {
+			values[name] = strings.Split(v, "\n")
+		}
+	}
+	filter, err := newObjectFilter(values)
+	fatalIf(err.Trace(s.SessionID), "Unable to parse filters.")
+	return filter
+}
+
+// readFilterFile - reads the rules of a filter file, along with the
+// values of the filter flags it sets. Empty lines and lines starting
+// with '#' are ignored.
+func readFilterFile(reader io.Reader, values map[string][]string) (rules []filterRule, err *probe.Error) {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			return nil, errInvalidArgument().Trace(line)
+		}
+		key, value := fields[0], strings.TrimSpace(fields[1])
+		switch key {
+		case "+", "include":
+			rules = append(rules, filterRule{include: true, pattern: value, rsync: true})
+			continue
+		case "-", "exclude":
+			rules = append(rules, filterRule{pattern: value, rsync: true})
+			continue
+		case "filter-file":
+			return nil, errInvalidArgument().Trace(line)
+		}
+		known := false
+		for _, flag := range filterFlags {
+			known = known || flag.GetName() == key
+		}
+		if !known {
+			return nil, errInvalidArgument().Trace(line)
+		}
+		values[key] = append(values[key], value)
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return rules, nil
+}
+
+// newObjectFilter - returns the filter of the values of the filter
+// flags, nil when none is set. Command line excludes come first, then
+// the rules of the filter file and the command line includes.
+// Command line values of other flags take precedence over those of
+// the filter file.
+func newObjectFilter(values map[string][]string) (*objectFilter, *probe.Error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	var fileRules []filterRule
+	if filename := firstValue(values, "filter-file"); filename != "" {
+		file, e := os.Open(filename)
+		if e != nil {
+			return nil, probe.NewError(e).Trace(filename)
+		}
+		defer file.Close()
+		var err *probe.Error
+		if fileRules, err = readFilterFile(file, values); err != nil {
+			return nil, err.Trace(filename)
+		}
+	}
+
+	filter := &objectFilter{maxSize: -1}
+	for _, pattern := range values["exclude"] {
+		filter.rules = append(filter.rules, filterRule{pattern: pattern})
+	}
+	filter.rules = append(filter.rules, fileRules...)
+	for _, pattern := range values["include"] {
+		filter.rules = append(filter.rules, filterRule{include: true, pattern: pattern})
+		filter.excludeUnmatched = true
+	}
+
+	for _, d := range []struct {
+		name     string
+		duration *time.Duration
+	}{{"older-than", &filter.olderThan}, {"newer-than", &filter.newerThan}} {
+		if v := firstValue(values, d.name); v != "" {
+			duration, e := ioutils.ParseDurationTime(v)
+			if e != nil {
+				return nil, probe.NewError(e).Trace(v)
+			}
+			*d.duration = duration
+		}
+	}
+
+	for _, s := range []struct {
+		name string
+		size *int64
+	}{{"min-size", &filter.minSize}, {"max-size", &filter.maxSize}} {
+		if v := firstValue(values, s.name); v != "" {
+			size, e := humanize.ParseBytes(v)
+			if e != nil {
+				return nil, probe.NewError(e).Trace(v)
+			}
+			*s.size = int64(size)
+		}
+	}
+
+	filter.storageClasses = values["match-storage-class"]
+	filter.contentTypes = values["match-content-type"]
+	for _, m := range []struct {
+		name     string
+		matchers *[]keyValueMatcher
+	}{{"match-metadata", &filter.metadata}, {"match-tags", &filter.tags}} {
+		for _, v := range values[m.name] {
+			kv := strings.SplitN(v, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return nil, errInvalidArgument().Trace(v)
+			}
+			*m.matchers = append(*m.matchers, keyValueMatcher{key: strings.ToLower(kv[0]), pattern: kv[1]})
+		}
+	}
+	return filter, nil
+}
+
+// firstValue - returns the first value of name.
+func firstValue(values map[string][]string, name string) string {
+	if len(values[name]) == 0 {
+		return ""
+	}
+	return values[name][0]
+}
+
+// matchPath - returns true if the rules select p, a path relative to
+// the listed URL.
+func (f *objectFilter) matchPath(p string) bool {
+	if f == nil {
+		return true
+	}
+	p = strings.TrimPrefix(filepath.ToSlash(p), "/")
+	for _, rule := range f.rules {
+		if rule.match(p) {
+			return rule.include
+		}
+	}
+	return !f.excludeUnmatched
+}
+
+// matchContent - returns true if content passes the time, size,
+// storage class, metadata, tags and content type filters. Contents
+// listed without the metadata or tags to match are stated from alias.
+// Folders are only filtered by path.
+func (f *objectFilter) matchContent(ctx context.Context, alias string, content *ClientContent) bool {
+	if f == nil || content.Type.IsDir() {
+		return true
+	}
+	age := time.Since(content.Time)
+	if f.olderThan > 0 && age < f.olderThan {
+		return false
+	}
+	if f.newerThan > 0 && age >= f.newerThan {
+		return false
+	}
+	if content.Size < f.minSize || (f.maxSize >= 0 && content.Size > f.maxSize) {
+		return false
+	}
+	if len(f.storageClasses) > 0 {
+		storageClass := content.StorageClass
+		if storageClass == "" {
+			storageClass = "STANDARD"
+		}
+		found := false
+		for _, class := range f.storageClasses {
+			found = found || strings.EqualFold(class, storageClass)
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.metadata) == 0 && len(f.tags) == 0 && len(f.contentTypes) == 0 {
+		return true
+	}
+	clnt, err := newClientFromAlias(alias, content.URL.String())
+	if err != nil {
+		return false
+	}
+	if len(f.metadata) > 0 || len(f.contentTypes) > 0 {
+		if len(content.Metadata) == 0 && len(content.UserMetadata) == 0 {
+			st, err := clnt.Stat(ctx, StatOptions{versionID: content.VersionID})
+			if err != nil {
+				return false
+			}
+			content = st
+		}
+		for _, m := range f.metadata {
+			if !m.match(content.Metadata) && !m.match(content.UserMetadata) {
+				return false
+			}
+		}
+		if len(f.contentTypes) > 0 {
+			contentType := metadataValue(content.Metadata, "Content-Type")
+			if contentType == "" {
+				contentType = mimedb.TypeByExtension(filepath.Ext(content.URL.Path))
+			}
+			found := false
+			for _, pattern := range f.contentTypes {
+				found = found || wildcard.Match(strings.ToLower(pattern), strings.ToLower(contentType))
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	if len(f.tags) > 0 {
+		tags, err := clnt.GetTags(ctx, content.VersionID)
+		if err != nil {
+			return false
+		}
+		for _, m := range f.tags {
+			if !m.match(tags) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// match - returns true if the content listed at p, relative to the
+// listed URL, passes the filter.
+func (f *objectFilter) match(ctx context.Context, alias string, content *ClientContent, p string) bool {
+	return f.matchPath(p) && f.matchContent(ctx, alias, content)
+}
+
+// filterPath - returns the path of content relative to the first of
+// urls it is listed under, its name when it is one of them.
+func filterPath(content *ClientContent, urls ...string) string {
+	contentPath := filepath.ToSlash(content.URL.Path)
+	for _, urlStr := range urls {
+		_, expandedURL, _ := mustExpandAlias(urlStr)
+		root := filepath.ToSlash(newClientURL(expandedURL).Path)
+		if root == contentPath {
+			break
+		}
+		if !strings.HasSuffix(root, "/") {
+			root += "/"
+		}
+		if strings.HasPrefix(contentPath, root) {
+			return strings.TrimPrefix(contentPath, root)
+		}
+	}
+	return path.Base(contentPath)
+}
@@ -0,0 +1,176 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// TestFilterRules - tests the first matching rule decides and
+// unmatched paths are excluded once an include is given.
+func (s *TestSuite) TestFilterRules(c *C) {
+	testCases := []struct {
+		values map[string][]string
+		path   string
+		match  bool
+	}{
+		{map[string][]string{"exclude": {"*.tmp"}}, "a/b/c.tmp", false},
+		{map[string][]string{"exclude": {"*.tmp"}}, "a/b/c.txt", true},
+		{map[string][]string{"exclude": {"logs/*"}}, "logs/today.log", false},
+		{map[string][]string{"exclude": {"logs/*"}}, "app/logs/today.log", true},
+		{map[string][]string{"exclude": {"logs/"}}, "logs/today.log", true},
+		{map[string][]string{"include": {"*.jpg"}}, "photos/beach.jpg", true},
+		{map[string][]string{"include": {"*.jpg"}}, "photos/beach.png", false},
+		{map[string][]string{"include": {"*.jpg"}, "exclude": {"*thumbs/*"}}, "thumbs/beach.jpg", false},
+		{map[string][]string{"include": {"*.jpg"}, "exclude": {"*thumbs/*"}}, "photos/beach.jpg", true},
+	}
+	for i, testCase := range testCases {
+		filter, err := newObjectFilter(testCase.values)
+		c.Assert(err, IsNil)
+		c.Assert(filter.matchPath(testCase.path), Equals, testCase.match, Commentf("Test %d", i+1))
+	}
+
+	filter, err := newObjectFilter(nil)
+	c.Assert(err, IsNil)
+	c.Assert(filter, IsNil)
+	c.Assert(filter.matchPath("anything"), Equals, true)
+}
+
+// TestFilterRuleRsync - tests rules of filter files are anchored and
+// match folders and names as rsync does.
+func (s *TestSuite) TestFilterRuleRsync(c *C) {
+	testCases := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/logs/", "logs/today.log", true},
+		{"/logs/", "app/logs/today.log", false},
+		{"logs/", "app/logs/today.log", true},
+		{"today.log", "app/logs/today.log", true},
+		{"logs/today.log", "app/logs/today.log", true},
+		{"logs/today.log", "app/mylogs/today.log", false},
+		{"*.tmp", "a/b/c.tmp", true},
+	}
+	for i, testCase := range testCases {
+		rule := filterRule{pattern: testCase.pattern, rsync: true}
+		c.Assert(rule.match(testCase.path), Equals, testCase.match, Commentf("Test %d", i+1))
+	}
+}
+
+// TestReadFilterFile - tests rules and flag values are read from a
+// filter file.
+func (s *TestSuite) TestReadFilterFile(c *C) {
+	values := map[string][]string{}
+	rules, err := readFilterFile(strings.NewReader(`
+# Skip temporary files.
+- *.tmp
++ /docs/
+exclude cache/
+min-size 1KiB
+match-storage-class STANDARD
+`), values)
+	c.Assert(err, IsNil)
+	c.Assert(rules, DeepEquals, []filterRule{
+		{pattern: "*.tmp", rsync: true},
+		{include: true, pattern: "/docs/", rsync: true},
+		{pattern: "cache/", rsync: true},
+	})
+	c.Assert(values, DeepEquals, map[string][]string{
+		"min-size":            {"1KiB"},
+		"match-storage-class": {"STANDARD"},
+	})
+
+	for _, line := range []string{"unknown-flag value", "*.tmp", "filter-file other"} {
+		_, err = readFilterFile(strings.NewReader(line), map[string][]string{})
+		c.Assert(err, NotNil, Commentf("%s", line))
+	}
+}
+
+// TestFilterContent - tests the time, size, storage class, metadata,
+// tags and content type filters.
+func (s *TestSuite) TestFilterContent(c *C) {
+	ctx := context.Background()
+	clnt, err := newClient("mem://filter")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(ctx, "", false, false), IsNil)
+
+	clnt, err = newClient("mem://filter/photo.png")
+	c.Assert(err, IsNil)
+	data := []byte("image data")
+	_, err = clnt.Put(ctx, bytes.NewReader(data), int64(len(data)), map[string]string{
+		"Content-Type":        "image/png",
+		"X-Amz-Meta-Owner":    "alice",
+		"X-Amz-Storage-Class": "REDUCED_REDUNDANCY",
+		"X-Amz-Tagging":       "project=beach",
+	}, nil, nil, false, false, false)
+	c.Assert(err, IsNil)
+	content, err := memStat(c, "mem://filter/photo.png")
+	c.Assert(err, IsNil)
+
+	testCases := []struct {
+		values map[string][]string
+		match  bool
+	}{
+		{map[string][]string{"older-than": {"1d"}}, false},
+		{map[string][]string{"newer-than": {"1d"}}, true},
+		{map[string][]string{"min-size": {"1KiB"}}, false},
+		{map[string][]string{"max-size": {"1KiB"}}, true},
+		{map[string][]string{"match-storage-class": {"reduced_redundancy"}}, true},
+		{map[string][]string{"match-storage-class": {"STANDARD"}}, false},
+		{map[string][]string{"match-metadata": {"owner=ali*"}}, true},
+		{map[string][]string{"match-metadata": {"owner=bob"}}, false},
+		{map[string][]string{"match-tags": {"project=beach"}}, true},
+		{map[string][]string{"match-tags": {"project=city"}}, false},
+		{map[string][]string{"match-content-type": {"image/*"}}, true},
+		{map[string][]string{"match-content-type": {"text/*"}}, false},
+	}
+	for i, testCase := range testCases {
+		filter, err := newObjectFilter(testCase.values)
+		c.Assert(err, IsNil)
+		c.Assert(filter.matchContent(ctx, "", content), Equals, testCase.match, Commentf("Test %d", i+1))
+	}
+
+	for _, values := range []map[string][]string{
+		{"older-than": {"yesterday"}},
+		{"min-size": {"big"}},
+		{"match-metadata": {"owner"}},
+	} {
+		_, err = newObjectFilter(values)
+		c.Assert(err, NotNil)
+	}
+
+	// Folders are only filtered by path.
+	filter, err := newObjectFilter(map[string][]string{"older-than": {"1d"}})
+	c.Assert(err, IsNil)
+	c.Assert(filter.matchContent(ctx, "", &ClientContent{Type: os.ModeDir, Time: time.Now()}), Equals, true)
+}
+
+// TestFilterPath - tests paths are relative to the listed URL.
+func (s *TestSuite) TestFilterPath(c *C) {
+	content := &ClientContent{URL: *newClientURL("mem://filter/dir/sub/object")}
+	c.Assert(filterPath(content, "mem://filter/dir/"), Equals, "sub/object")
+	c.Assert(filterPath(content, "mem://filter/dir"), Equals, "sub/object")
+	c.Assert(filterPath(content, "mem://filter/di"), Equals, "object")
+	c.Assert(filterPath(content, "mem://filter/dir/sub/object"), Equals, "object")
+}
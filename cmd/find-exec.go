@@ -0,0 +1,164 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio/pkg/console"
+)
+
+// Limits of the objects passed to a single command in the "{} +" form,
+// in number and in bytes of arguments, as xargs does.
+const (
+	findExecBatchSize   = 1000
+	findExecBatchLength = 128 * 1024
+)
+
+// findExecMessage - a failed --exec command.
+type findExecMessage struct {
+	Status     string   `json:"status"`
+	Command    []string `json:"command"`
+	ExitStatus int      `json:"exitStatus"`
+	Error      string   `json:"error"`
+}
+
+// String colorized failed command message.
+func (m findExecMessage) String() string {
+	return console.Colorize("FindExecErr", m.Error)
+}
+
+// JSON jsonified failed command message.
+func (m findExecMessage) JSON() string {
+	m.Status = "error"
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// findExec - runs the --exec command of the matching objects, in up to
+// parallel processes. A command ending with a placeholder and "+", as
+// in "rm {} +", is run once for a batch of objects with the
+// placeholder substituted for each of them.
+type findExec struct {
+	ctx      context.Context
+	command  string   // command substituted for each object
+	args     []string // leading arguments of a batched command
+	batchArg string   // argument substituted for each batched object
+	batch    []string
+	batchLen int
+
+	jobs chan []string
+	wg   sync.WaitGroup
+
+	mu         sync.Mutex
+	exitStatus int
+}
+
+// newFindExec - starts parallel runners of command.
+func newFindExec(ctx context.Context, command string, parallel int) *findExec {
+	if parallel <= 0 {
+		parallel = 1
+	}
+	e := &findExec{
+		ctx:     ctx,
+		command: command,
+		jobs:    make(chan []string, parallel),
+	}
+	args := strings.Split(command, " ")
+	if n := len(args); n >= 3 && args[n-1] == "+" &&
+		strings.HasPrefix(args[n-2], "{") && strings.HasSuffix(args[n-2], "}") {
+		e.args, e.batchArg = args[:n-2], args[n-2]
+	}
+	for i := 0; i < parallel; i++ {
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			for args := range e.jobs {
+				e.run(args)
+			}
+		}()
+	}
+	return e
+}
+
+// add - runs the command for fileContent, or adds it to the current
+// batch.
+func (e *findExec) add(fileContent contentMessage) {
+	if e.batchArg == "" {
+		e.jobs <- strings.Split(stringsReplace(e.ctx, e.command, fileContent), " ")
+		return
+	}
+	arg := stringsReplace(e.ctx, e.batchArg, fileContent)
+	if len(e.batch) > 0 && (len(e.batch) >= findExecBatchSize || e.batchLen+len(arg) > findExecBatchLength) {
+		e.flush()
+	}
+	e.batch = append(e.batch, arg)
+	e.batchLen += len(arg) + 1
+}
+
+// flush - runs the command for the current batch, if any.
+func (e *findExec) flush() {
+	if len(e.batch) == 0 {
+		return
+	}
+	args := append(append([]string{}, e.args...), e.batch...)
+	e.batch, e.batchLen = nil, 0
+	e.jobs <- args
+}
+
+// wait - runs the last batch and waits for all commands, returns the
+// highest exit status of the commands run.
+func (e *findExec) wait() int {
+	e.flush()
+	close(e.jobs)
+	e.wg.Wait()
+	return e.exitStatus
+}
+
+// run - executes a command and prints its output, and a message when
+// it fails.
+func (e *findExec) run(args []string) {
+	cmd := exec.Command(args[0], args[1:]...)
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := findExecMessage{
+			Command:    args,
+			ExitStatus: getExitStatus(err),
+			Error:      strings.TrimSpace(stderr.String()),
+		}
+		if msg.Error == "" {
+			msg.Error = err.Error()
+		}
+		e.mu.Lock()
+		if msg.ExitStatus > e.exitStatus {
+			e.exitStatus = msg.ExitStatus
+		}
+		e.mu.Unlock()
+		printMsg(msg)
+	}
+	console.PrintC(out.String())
+}
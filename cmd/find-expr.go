@@ -0,0 +1,360 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/ioutils"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio/pkg/mimedb"
+	"github.com/minio/minio/pkg/wildcard"
+)
+
+// findObject - an object evaluated by a find expression, its metadata
+// and tags are read once, when a predicate needs them.
+type findObject struct {
+	ctx     context.Context
+	alias   string
+	content *ClientContent
+	path    string // relative to the find target
+
+	stated   bool
+	tags     map[string]string
+	tagsRead bool
+}
+
+// metadata - returns the content of the object with its metadata, nil
+// if it cannot be read.
+func (o *findObject) metadata() *ClientContent {
+	if o.stated || len(o.content.Metadata) > 0 || len(o.content.UserMetadata) > 0 {
+		return o.content
+	}
+	o.stated = true
+	clnt, err := newClientFromAlias(o.alias, o.content.URL.String())
+	if err != nil {
+		return nil
+	}
+	st, err := clnt.Stat(o.ctx, StatOptions{versionID: o.content.VersionID})
+	if err != nil {
+		return nil
+	}
+	o.content.Metadata, o.content.UserMetadata = st.Metadata, st.UserMetadata
+	if o.content.ETag == "" {
+		o.content.ETag = st.ETag
+	}
+	return o.content
+}
+
+// getTags - returns the tags of the object, nil if they cannot be read.
+func (o *findObject) getTags() map[string]string {
+	if !o.tagsRead {
+		o.tagsRead = true
+		if clnt, err := newClientFromAlias(o.alias, o.content.URL.String()); err == nil {
+			o.tags, _ = clnt.GetTags(o.ctx, o.content.VersionID)
+		}
+	}
+	return o.tags
+}
+
+// findExpr - a find expression, evaluated for each object listed.
+type findExpr interface {
+	eval(o *findObject) bool
+}
+
+type findAnd struct{ left, right findExpr }
+
+func (e findAnd) eval(o *findObject) bool { return e.left.eval(o) && e.right.eval(o) }
+
+type findOr struct{ left, right findExpr }
+
+func (e findOr) eval(o *findObject) bool { return e.left.eval(o) || e.right.eval(o) }
+
+type findNot struct{ expr findExpr }
+
+func (e findNot) eval(o *findObject) bool { return !e.expr.eval(o) }
+
+// findPredicate - a test of an object.
+type findPredicate func(o *findObject) bool
+
+func (p findPredicate) eval(o *findObject) bool { return p(o) }
+
+// findVersionPredicates - predicates testing the version state of the
+// objects, every version is listed when one is used.
+var findVersionPredicates = map[string]findPredicate{
+	"-latest": func(o *findObject) bool {
+		return !o.content.IsDeleteMarker && (o.content.IsLatest || o.content.VersionID == "")
+	},
+	"-noncurrent": func(o *findObject) bool {
+		return !o.content.IsLatest && o.content.VersionID != ""
+	},
+	"-delete-marker": func(o *findObject) bool {
+		return o.content.IsDeleteMarker
+	},
+}
+
+// newFindPredicate - returns the predicate name of argument arg.
+func newFindPredicate(name, arg string) (findPredicate, *probe.Error) {
+	switch name {
+	case "-name":
+		return func(o *findObject) bool { return nameMatch(arg, o.path) }, nil
+	case "-path":
+		return func(o *findObject) bool { return pathMatch(arg, o.path) }, nil
+	case "-regex":
+		re, e := regexp.Compile(arg)
+		if e != nil {
+			return nil, probe.NewError(e).Trace(arg)
+		}
+		return func(o *findObject) bool { return re.MatchString(o.path) }, nil
+	case "-size":
+		// As with find, +N is larger than N, -N smaller than N and N
+		// exactly N.
+		if arg == "" {
+			return nil, errInvalidArgument().Trace(name)
+		}
+		cmp := arg[:1]
+		if cmp == "+" || cmp == "-" {
+			arg = arg[1:]
+		}
+		size, e := humanize.ParseBytes(arg)
+		if e != nil {
+			return nil, probe.NewError(e).Trace(arg)
+		}
+		return func(o *findObject) bool {
+			switch cmp {
+			case "+":
+				return o.content.Size > int64(size)
+			case "-":
+				return o.content.Size < int64(size)
+			}
+			return o.content.Size == int64(size)
+		}, nil
+	case "-newer", "-older":
+		duration, e := ioutils.ParseDurationTime(arg)
+		if e != nil {
+			return nil, probe.NewError(e).Trace(arg)
+		}
+		if name == "-newer" {
+			return func(o *findObject) bool { return time.Since(o.content.Time) < duration }, nil
+		}
+		return func(o *findObject) bool { return time.Since(o.content.Time) >= duration }, nil
+	case "-metadata", "-tag":
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, errInvalidArgument().Trace(arg)
+		}
+		m := keyValueMatcher{key: strings.ToLower(kv[0]), pattern: kv[1]}
+		if name == "-tag" {
+			return func(o *findObject) bool { return m.match(o.getTags()) }, nil
+		}
+		return func(o *findObject) bool {
+			content := o.metadata()
+			return content != nil && (m.match(content.Metadata) || m.match(content.UserMetadata))
+		}, nil
+	case "-storage-class":
+		return func(o *findObject) bool {
+			storageClass := o.content.StorageClass
+			if storageClass == "" {
+				storageClass = "STANDARD"
+			}
+			return strings.EqualFold(storageClass, arg)
+		}, nil
+	case "-etag":
+		return func(o *findObject) bool {
+			etag := o.content.ETag
+			if etag == "" {
+				if content := o.metadata(); content != nil {
+					etag = content.ETag
+				}
+			}
+			return strings.Trim(etag, "\"") == strings.Trim(arg, "\"")
+		}, nil
+	case "-content-type":
+		return func(o *findObject) bool {
+			var contentType string
+			if content := o.metadata(); content != nil {
+				contentType = metadataValue(content.Metadata, "Content-Type")
+			}
+			if contentType == "" {
+				contentType = mimedb.TypeByExtension(filepath.Ext(o.content.URL.Path))
+			}
+			return wildcard.Match(strings.ToLower(arg), strings.ToLower(contentType))
+		}, nil
+	}
+	return nil, probe.NewError(fmt.Errorf("unknown predicate `%s`", name))
+}
+
+// splitFindExpr - splits an expression into its tokens, separated by
+// spaces unless quoted. Parentheses and a leading '!' are tokens of
+// their own.
+func splitFindExpr(s string) ([]string, *probe.Error) {
+	var tokens []string
+	var token strings.Builder
+	inToken := false
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				token.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inToken {
+				tokens = append(tokens, token.String())
+				token.Reset()
+				inToken = false
+			}
+		case r == '(' || r == ')' || (r == '!' && !inToken):
+			if inToken {
+				tokens = append(tokens, token.String())
+				token.Reset()
+				inToken = false
+			}
+			tokens = append(tokens, string(r))
+		default:
+			token.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quote != 0 {
+		return nil, probe.NewError(fmt.Errorf("unterminated quote in `%s`", s))
+	}
+	if inToken {
+		tokens = append(tokens, token.String())
+	}
+	return tokens, nil
+}
+
+// findExprParser - parses the tokens of an expression, with the
+// grammar:
+//
+//	expr    = and { ("-or" | "-o") and }
+//	and     = unary { [ "-and" | "-a" ] unary }
+//	unary   = ("-not" | "!") unary | "(" expr ")" | predicate
+type findExprParser struct {
+	tokens       []string
+	pos          int
+	withVersions bool
+}
+
+func (p *findExprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *findExprParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *findExprParser) expr() (findExpr, *probe.Error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "-or" || p.peek() == "-o" {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = findOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *findExprParser) and() (findExpr, *probe.Error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek() {
+		case "", ")", "-or", "-o":
+			return left, nil
+		case "-and", "-a":
+			p.next()
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = findAnd{left, right}
+	}
+}
+
+func (p *findExprParser) unary() (findExpr, *probe.Error) {
+	switch token := p.next(); token {
+	case "":
+		return nil, probe.NewError(fmt.Errorf("unexpected end of expression"))
+	case "-not", "!":
+		expr, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return findNot{expr}, nil
+	case "(":
+		expr, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, probe.NewError(fmt.Errorf("missing `)`"))
+		}
+		return expr, nil
+	default:
+		if predicate, ok := findVersionPredicates[token]; ok {
+			p.withVersions = true
+			return predicate, nil
+		}
+		if p.peek() == "" {
+			return nil, probe.NewError(fmt.Errorf("missing argument to `%s`", token))
+		}
+		return newFindPredicate(token, p.next())
+	}
+}
+
+// parseFindExpr - parses a find expression, withVersions is true when
+// it tests the version state of the objects.
+func parseFindExpr(s string) (expr findExpr, withVersions bool, err *probe.Error) {
+	tokens, err := splitFindExpr(s)
+	if err != nil {
+		return nil, false, err
+	}
+	p := &findExprParser{tokens: tokens}
+	if expr, err = p.expr(); err != nil {
+		return nil, false, err.Trace(s)
+	}
+	if p.pos < len(tokens) {
+		return nil, false, probe.NewError(fmt.Errorf("unexpected `%s`", p.peek())).Trace(s)
+	}
+	return expr, p.withVersions, nil
+}
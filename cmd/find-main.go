@@ -25,6 +25,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7/pkg/tags"
 	"github.com/minio/minio/pkg/console"
 )
 
@@ -35,6 +36,11 @@ var (
 			Name:  "exec",
 			Usage: "spawn an external process for each matching object (see FORMAT)",
 		},
+		cli.IntFlag{
+			Name:  "exec-parallel",
+			Usage: "run up to N --exec commands in parallel",
+			Value: 1,
+		},
 		cli.StringFlag{
 			Name:  "ignore",
 			Usage: "exclude objects matching the wildcard pattern",
@@ -43,14 +49,6 @@ var (
 			Name:  "name",
 			Usage: "find object names matching wildcard pattern",
 		},
-		cli.StringFlag{
-			Name:  "newer-than",
-			Usage: "match all objects newer than L days, M hours and N minutes",
-		},
-		cli.StringFlag{
-			Name:  "older-than",
-			Usage: "match all objects older than L days, M hours and N minutes",
-		},
 		cli.StringFlag{
 			Name:  "path",
 			Usage: "match directory names matching wildcard pattern",
@@ -79,6 +77,26 @@ var (
 			Name:  "watch",
 			Usage: "monitor a specified path for newly created object(s)",
 		},
+		cli.StringFlag{
+			Name:  "expr",
+			Usage: "match objects with an expression of predicates (see EXPRESSION)",
+		},
+		cli.BoolFlag{
+			Name:  "delete",
+			Usage: "remove matching objects",
+		},
+		cli.StringFlag{
+			Name:  "tag",
+			Usage: "set tags on matching objects, in the form key1=value1&key2=value2",
+		},
+		cli.StringFlag{
+			Name:  "copy-to",
+			Usage: "copy matching objects under the specified path, keeping their relative path",
+		},
+		cli.StringFlag{
+			Name:  "set-storage-class",
+			Usage: "change the storage class of matching objects",
+		},
 	}
 )
 
@@ -88,7 +106,7 @@ var findCmd = cli.Command{
 	Action:       mainFind,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(findFlags, globalFlags...),
+	Flags:        append(append(findFlags, filterFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -122,6 +140,41 @@ FORMAT
 
      {url} --> Substitutes to a shareable URL of the path.
 
+EXEC
+  --exec runs the command once for each matching object, up to --exec-parallel
+  commands at a time. Ending the command with a keyword followed by "+", as in
+  "rm {} +", runs it for batches of objects instead, the keyword repeated for
+  each of them as xargs does. Failed commands are reported, and find exits with
+  the highest of their exit statuses.
+
+EXPRESSION
+  --expr accepts predicates combined with "-and" (or "-a", implied between two
+  predicates), "-or" (or "-o"), "-not" (or "!") and parentheses, "-not" binding
+  tighter than "-and", itself tighter than "-or". Quote arguments with spaces.
+
+     -name PATTERN           --> object name matches the wildcard pattern.
+     -path PATTERN           --> object path matches the wildcard pattern.
+     -regex PATTERN          --> object path matches the PCRE regex pattern.
+     -size [+-]SIZE          --> object larger (+), smaller (-) or exactly SIZE (see UNITS).
+     -newer DURATION         --> object modified within DURATION (see UNITS).
+     -older DURATION         --> object modified DURATION ago or earlier (see UNITS).
+     -metadata KEY=PATTERN   --> object metadata KEY matches the wildcard pattern.
+     -tag KEY=PATTERN        --> object tag KEY matches the wildcard pattern.
+     -storage-class CLASS    --> object storage class is CLASS.
+     -etag ETAG              --> object ETag is ETAG.
+     -content-type PATTERN   --> object content type matches the wildcard pattern.
+     -latest                 --> object version is the latest.
+     -noncurrent             --> object version is not the latest.
+     -delete-marker          --> object version is a delete marker.
+
+  Using -latest, -noncurrent or -delete-marker lists every version of the objects.
+
+ACTIONS
+  --tag, --set-storage-class, --copy-to and --delete run on each matching object
+  in this order, before it is printed or --exec runs. On versioned buckets
+  --delete only removes versions when the expression lists them, objects get
+  a delete marker otherwise. Actions cannot be used with --maxdepth.
+
 EXAMPLES:
   01. Find all "foo.jpg" in all buckets under "s3" account.
       {{.Prompt}} {{.HelpName}} s3 --name "foo.jpg"
@@ -153,6 +206,30 @@ EXAMPLES:
 
   10. List all objects up to 3 levels sub-directory deep under "s3/bucket".
       {{.Prompt}} {{.HelpName}} s3/bucket --maxdepth 3
+
+  11. Find all objects under "s3/bucket" with the "owner" metadata set to "alice".
+      {{.Prompt}} {{.HelpName}} s3/bucket --match-metadata "owner=alice"
+
+  12. Find all ".log" or ".tmp" objects larger than 1 MB under "s3/bucket".
+      {{.Prompt}} {{.HelpName}} s3/bucket --expr "( -name '*.log' -or -name '*.tmp' ) -size +1MB"
+
+  13. Remove all noncurrent versions older than 30 days under "s3/bucket".
+      {{.Prompt}} {{.HelpName}} s3/bucket --expr "-noncurrent -older 30d" --delete
+
+  14. Tag all objects not tagged by a "project" under "s3/bucket".
+      {{.Prompt}} {{.HelpName}} s3/bucket --expr "-not -tag 'project=*'" --tag "project=unknown"
+
+  15. Archive all PDF documents older than a year from "s3/docs" to "s3/archive".
+      {{.Prompt}} {{.HelpName}} s3/docs --expr "-content-type application/pdf -older 365d" --copy-to s3/archive --delete
+
+  16. Move all objects of "s3/bucket" in the "STANDARD" storage class, larger than 1 GB, to "REDUCED_REDUNDANCY".
+      {{.Prompt}} {{.HelpName}} s3/bucket --expr "-storage-class STANDARD -size +1GB" --set-storage-class REDUCED_REDUNDANCY
+
+  17. Generate thumbnails of all images under "s3/photos", running 16 commands at a time.
+      {{.Prompt}} {{.HelpName}} s3/photos --name "*.jpg" --exec "thumbnail {url}" --exec-parallel 16
+
+  18. Remove all ".tmp" objects under "s3/bucket", many objects at a time.
+      {{.Prompt}} {{.HelpName}} s3/bucket --name "*.tmp" --exec "mc rm {} +"
 `,
 }
 
@@ -190,6 +267,7 @@ func checkFindSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[stri
 type findContext struct {
 	*cli.Context
 	execCmd       string
+	exec          *findExec
 	ignorePattern string
 	namePattern   string
 	pathPattern   string
@@ -201,12 +279,20 @@ type findContext struct {
 	largerSize    uint64
 	smallerSize   uint64
 	watch         bool
+	filter        *objectFilter
+	expr          findExpr
+	withVersions  bool
+	deleteObjects bool
+	tags          string
+	copyTo        string
+	storageClass  string
 
 	// Internal values
 	targetAlias   string
 	targetURL     string
 	targetFullURL string
 	clnt          Client
+	encKeyDB      map[string][]prefixSSEPair
 }
 
 // mainFind - handler for mc find commands
@@ -258,6 +344,34 @@ func mainFind(cliCtx *cli.Context) error {
 		fatalIf(probe.NewError(e).Trace(cliCtx.String("smaller")), "Unable to parse input bytes.")
 	}
 
+	var expr findExpr
+	var withVersions bool
+	if cliCtx.String("expr") != "" {
+		expr, withVersions, err = parseFindExpr(cliCtx.String("expr"))
+		fatalIf(err, "Unable to parse --expr.")
+	}
+
+	if cliCtx.String("tag") != "" {
+		_, e = tags.Parse(cliCtx.String("tag"), true)
+		fatalIf(probe.NewError(e).Trace(cliCtx.String("tag")), "Unable to parse --tag.")
+	}
+	if cliCtx.String("set-storage-class") != "" && withVersions {
+		fatalIf(errInvalidArgument().Trace(cliCtx.String("expr")),
+			"--set-storage-class cannot be used on object versions.")
+	}
+	// Objects deeper than --maxdepth are only printed once per folder,
+	// actions would leave most of them alone.
+	if cliCtx.Uint("maxdepth") > 0 && (cliCtx.String("tag") != "" || cliCtx.String("set-storage-class") != "" ||
+		cliCtx.String("copy-to") != "" || cliCtx.Bool("delete")) {
+		fatalIf(errInvalidArgument().Trace(cliCtx.String("maxdepth")),
+			"--tag, --set-storage-class, --copy-to and --delete cannot be used with --maxdepth.")
+	}
+
+	var exec *findExec
+	if cliCtx.String("exec") != "" {
+		exec = newFindExec(ctx, cliCtx.String("exec"), cliCtx.Int("exec-parallel"))
+	}
+
 	targetAlias, _, hostCfg, err := expandAlias(args[0])
 	fatalIf(err.Trace(args[0]), "Unable to expand alias.")
 
@@ -270,6 +384,7 @@ func mainFind(cliCtx *cli.Context) error {
 		Context:       cliCtx,
 		maxDepth:      cliCtx.Uint("maxdepth"),
 		execCmd:       cliCtx.String("exec"),
+		exec:          exec,
 		printFmt:      cliCtx.String("print"),
 		namePattern:   cliCtx.String("name"),
 		pathPattern:   cliCtx.String("path"),
@@ -280,9 +395,17 @@ func mainFind(cliCtx *cli.Context) error {
 		largerSize:    largerSize,
 		smallerSize:   smallerSize,
 		watch:         cliCtx.Bool("watch"),
+		filter:        newObjectFilterFromContext(cliCtx),
+		expr:          expr,
+		withVersions:  withVersions,
+		deleteObjects: cliCtx.Bool("delete"),
+		tags:          cliCtx.String("tag"),
+		copyTo:        cliCtx.String("copy-to"),
+		storageClass:  cliCtx.String("set-storage-class"),
 		targetAlias:   targetAlias,
 		targetURL:     args[0],
 		targetFullURL: targetFullURL,
 		clnt:          clnt,
+		encKeyDB:      encKeyDB,
 	})
 }
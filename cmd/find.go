@@ -17,9 +17,7 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
@@ -111,24 +109,6 @@ func getExitStatus(err error) int {
 	return 1
 }
 
-// execFind executes the input command line, additionally formats input
-// for the command line in accordance with subsititution arguments.
-func execFind(command string) {
-	commandArgs := strings.Split(command, " ")
-
-	cmd := exec.Command(commandArgs[0], commandArgs[1:]...)
-	var out bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		console.Print(console.Colorize("FindExecErr", stderr.String()))
-		// Return exit status of the command run
-		os.Exit(getExitStatus(err))
-	}
-	console.PrintC(out.String())
-}
-
 // watchFind - enables listening on the input path, listens for all file/object
 // created actions. Asynchronously executes the input command line, also allows
 // formatting for the command line in accordance with subsititution arguments.
@@ -163,11 +143,24 @@ func watchFind(ctxCtx context.Context, ctx *findContext) {
 					continue
 				}
 
-				find(ctxCtx, ctx, contentMessage{
+				content := &ClientContent{
+					URL:  *newClientURL(event.Path),
+					Time: time,
+					Size: event.Size,
+				}
+				fileContent := contentMessage{
 					Key:  getAliasedPath(ctx, event.Path),
 					Time: time,
 					Size: event.Size,
-				})
+				}
+				// Match the incoming content, didn't match continue.
+				if matchFind(ctx, fileContent) && matchFindExpr(ctxCtx, ctx, content, findPath(ctx, fileContent.Key)) {
+					find(ctxCtx, ctx, content, fileContent)
+					// Batches are not held while watching.
+					if ctx.execCmd != "" {
+						ctx.exec.flush()
+					}
+				}
 			}
 		case err, ok := <-watchObj.Errors():
 			if !ok {
@@ -221,35 +214,121 @@ func getAliasedPath(ctx *findContext, path string) string {
 	return trimSuffixAtMaxDepth(ctx.targetURL, aliasedPath, separator, ctx.maxDepth)
 }
 
-func find(ctxCtx context.Context, ctx *findContext, fileContent contentMessage) {
-	// Match the incoming content, didn't match return.
-	if !matchFind(ctx, fileContent) {
-		return
-	} // For all matching content
+// find - runs the actions requested on matching content and prints
+// it, returns false if an action failed.
+func find(ctxCtx context.Context, ctx *findContext, content *ClientContent, fileContent contentMessage) bool {
+	if err := actFind(ctxCtx, ctx, content, findPath(ctx, fileContent.Key)); err != nil {
+		errorIf(err.Trace(fileContent.Key), "Unable to act on `"+fileContent.Key+"`.")
+		return false
+	}
 
 	// proceed to either exec, format the output string.
 	if ctx.execCmd != "" {
-		execFind(stringsReplace(ctxCtx, ctx.execCmd, fileContent))
-		return
+		ctx.exec.add(fileContent)
+		return true
 	}
 	if ctx.printFmt != "" {
 		fileContent.Key = stringsReplace(ctxCtx, ctx.printFmt, fileContent)
 	}
 	printMsg(findMessage{fileContent})
+	return true
+}
+
+// matchFindExpr - returns true if content, listed at path relative to
+// the target, passes the expression of ctx if any.
+func matchFindExpr(ctxCtx context.Context, ctx *findContext, content *ClientContent, path string) bool {
+	if ctx.expr == nil {
+		return true
+	}
+	return ctx.expr.eval(&findObject{ctx: ctxCtx, alias: ctx.targetAlias, content: content, path: path})
+}
+
+// actFind - runs the actions requested on content, listed at path
+// relative to the target: tagging it, changing its storage class,
+// copying it and removing it, in this order. Only delete markers can
+// be removed, folders are left alone.
+func actFind(ctxCtx context.Context, ctx *findContext, content *ClientContent, path string) *probe.Error {
+	if content.Type.IsDir() || (content.IsDeleteMarker && !ctx.deleteObjects) {
+		return nil
+	}
+	if ctx.tags == "" && ctx.storageClass == "" && ctx.copyTo == "" && !ctx.deleteObjects {
+		return nil
+	}
+	clnt, err := newClientFromAliasWithKeys(ctx.targetAlias, content.URL.String(), ctx.encKeyDB)
+	if err != nil {
+		return err.Trace(content.URL.String())
+	}
+
+	if ctx.tags != "" && !content.IsDeleteMarker {
+		if err = clnt.SetTags(ctxCtx, content.VersionID, ctx.tags); err != nil {
+			return err.Trace(content.URL.String())
+		}
+	}
+	if ctx.storageClass != "" && !content.IsDeleteMarker {
+		// The object is copied over itself, keeping its metadata.
+		urls := uploadSourceToTargetURL(ctxCtx, URLs{
+			SourceAlias:   ctx.targetAlias,
+			SourceContent: content,
+			TargetAlias:   ctx.targetAlias,
+			TargetContent: &ClientContent{
+				URL:          content.URL,
+				Metadata:     map[string]string{"X-Amz-Storage-Class": ctx.storageClass},
+				UserMetadata: map[string]string{},
+			},
+		}, nil, ctx.encKeyDB, true)
+		if urls.Error != nil {
+			return urls.Error.Trace(content.URL.String())
+		}
+	}
+	if ctx.copyTo != "" && !content.IsDeleteMarker {
+		targetAlias, targetURL, _ := mustExpandAlias(urlJoinPath(ctx.copyTo, filepath.ToSlash(path)))
+		urls := uploadSourceToTargetURL(ctxCtx, URLs{
+			SourceAlias:   ctx.targetAlias,
+			SourceContent: content,
+			TargetAlias:   targetAlias,
+			TargetContent: &ClientContent{
+				URL:          *newClientURL(targetURL),
+				Metadata:     map[string]string{},
+				UserMetadata: map[string]string{},
+			},
+		}, nil, ctx.encKeyDB, false)
+		if urls.Error != nil {
+			return urls.Error.Trace(content.URL.String(), targetURL)
+		}
+	}
+	if ctx.deleteObjects {
+		// Versions are removed only when listed, objects get a
+		// delete marker on versioned buckets otherwise.
+		removed := &ClientContent{URL: content.URL}
+		if ctx.withVersions {
+			removed.VersionID = content.VersionID
+			removed.IsDeleteMarker = content.IsDeleteMarker
+		}
+		contentCh := make(chan *ClientContent, 1)
+		contentCh <- removed
+		close(contentCh)
+		for err := range clnt.Remove(ctxCtx, false, false, false, contentCh) {
+			if err != nil {
+				return err.Trace(content.URL.String())
+			}
+		}
+	}
+	return nil
 }
 
 // doFind - find is main function body which interprets and executes
 // all the input parameters.
 func doFind(ctxCtx context.Context, ctx *findContext) error {
-	// If watch is enabled we will wait on the prefix perpetually
-	// for all I/O events until canceled by user, if watch is not enabled
-	// following defer is a no-op.
-	defer watchFind(ctxCtx, ctx)
-
 	var prevKeyName string
+	failed := false
 
 	// iterate over all content which is within the given directory
-	for content := range ctx.clnt.List(globalContext, ListOptions{Recursive: true, ShowDir: DirFirst}) {
+	for content := range ctx.clnt.List(globalContext, ListOptions{
+		Recursive:         true,
+		WithOlderVersions: ctx.withVersions,
+		WithDeleteMarkers: ctx.withVersions,
+		ShowDir:           DirFirst,
+	}) {
 		if content.Err != nil {
 			switch content.Err.ToGoError().(type) {
 			// handle this specifically for filesystem related errors.
@@ -279,28 +358,41 @@ func doFind(ctxCtx context.Context, ctx *findContext) error {
 			Time: content.Time.Local(),
 			Size: content.Size,
 		}
+		if ctx.withVersions {
+			fileContent.VersionID = content.VersionID
+			fileContent.IsDeleteMarker = content.IsDeleteMarker
+		}
 
-		// Match the incoming content, didn't match return.
-		if !matchFind(ctx, fileContent) || prevKeyName == fileKeyName {
+		// Match the incoming content, didn't match return. Versions
+		// of an object share its key.
+		if !matchFind(ctx, fileContent) || (prevKeyName == fileKeyName && !ctx.withVersions) ||
+			!ctx.filter.match(ctxCtx, ctx.targetAlias, content, filterPath(content, ctx.targetURL)) ||
+			!matchFindExpr(ctxCtx, ctx, content, findPath(ctx, fileKeyName)) {
 			continue
 		} // For all matching content
 
 		prevKeyName = fileKeyName
 
-		// proceed to either exec, format the output string.
-		if ctx.execCmd != "" {
-			execFind(stringsReplace(ctxCtx, ctx.execCmd, fileContent))
-			continue
-		}
-		if ctx.printFmt != "" {
-			fileContent.Key = stringsReplace(ctxCtx, ctx.printFmt, fileContent)
+		if !find(ctxCtx, ctx, content, fileContent) {
+			failed = true
 		}
-
-		printMsg(findMessage{fileContent})
 	}
 
-	// Success, notice watch will execute in defer only if enabled and this call
-	// will return after watch is canceled.
+	// If watch is enabled we will wait on the prefix perpetually
+	// for all I/O events until canceled by user, if watch is not enabled
+	// following call is a no-op.
+	watchFind(ctxCtx, ctx)
+
+	// Wait for all commands run, find exits with the highest of
+	// their exit statuses.
+	if ctx.execCmd != "" {
+		if status := ctx.exec.wait(); status != 0 {
+			return exitStatus(status)
+		}
+	}
+	if failed {
+		return exitStatus(globalErrorExitStatus)
+	}
 	return nil
 }
 
@@ -371,18 +463,23 @@ func stringsReplace(ctx context.Context, args string, fileContent contentMessage
 	return str
 }
 
-// matchFind matches whether fileContent matches appropriately with standard
-// "pattern matching" flags requested by the user, such as "name", "path", "regex" ..etc.
-func matchFind(ctx *findContext, fileContent contentMessage) (match bool) {
-	match = true
+// findPath - returns the path of key relative to the find target, file
+// path matching techniques apply to the path excluding the starting
+// prefix.
+func findPath(ctx *findContext, key string) string {
 	prefixPath := ctx.targetURL
 	// Add separator only if targetURL doesn't already have separator.
 	if !strings.HasPrefix(prefixPath, string(ctx.clnt.GetURL().Separator)) {
 		prefixPath = ctx.targetURL + string(ctx.clnt.GetURL().Separator)
 	}
-	// Trim the prefix such that we will apply file path matching techniques
-	// on path excluding the starting prefix.
-	path := strings.TrimPrefix(fileContent.Key, prefixPath)
+	return strings.TrimPrefix(key, prefixPath)
+}
+
+// matchFind matches whether fileContent matches appropriately with standard
+// "pattern matching" flags requested by the user, such as "name", "path", "regex" ..etc.
+func matchFind(ctx *findContext, fileContent contentMessage) (match bool) {
+	match = true
+	path := findPath(ctx, fileContent.Key)
 	if match && ctx.ignorePattern != "" {
 		match = !pathMatch(ctx.ignorePattern, path)
 	}
@@ -22,6 +22,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	. "gopkg.in/check.v1"
 )
 
 // Tests match find function with all supported inputs on
@@ -453,3 +455,132 @@ func TestGetExitStatus(t *testing.T) {
 		}
 	}
 }
+
+// Tests parsing and evaluating find expressions.
+func TestParseFindExpr(t *testing.T) {
+	modTime := time.Now().Add(-48 * time.Hour)
+	testCases := []struct {
+		expr         string
+		path         string
+		content      ClientContent
+		match        bool
+		withVersions bool
+	}{
+		{"-name *.jpg", "a/b.jpg", ClientContent{}, true, false},
+		{"-name *.jpg", "a/b.png", ClientContent{}, false, false},
+		{"-name *.jpg -or -name *.png", "a/b.png", ClientContent{}, true, false},
+		{"-name *.jpg -size +1KiB", "a/b.jpg", ClientContent{Size: 10}, false, false},
+		{"-name *.jpg -and -size -1KiB", "a/b.jpg", ClientContent{Size: 10}, true, false},
+		// -and binds tighter than -or.
+		{"-name *.png -or -name *.jpg -size 1", "a/b.png", ClientContent{Size: 10}, true, false},
+		{"( -name *.png -or -name *.jpg ) -size 1", "a/b.png", ClientContent{Size: 10}, false, false},
+		{"!(-name *.png)", "a/b.png", ClientContent{}, false, false},
+		{"-not -path 'a b/*'", "a b/c", ClientContent{}, false, false},
+		{"-regex ^a/ -older 1d", "a/b", ClientContent{Time: modTime}, true, false},
+		{"-regex ^a/ -newer 1d", "a/b", ClientContent{Time: modTime}, false, false},
+		{"-storage-class standard", "a", ClientContent{}, true, false},
+		{"-storage-class GLACIER", "a", ClientContent{StorageClass: "STANDARD"}, false, false},
+		{"-etag abc", "a", ClientContent{ETag: `"abc"`}, true, false},
+		{"-metadata content-type=image/*", "a", ClientContent{Metadata: map[string]string{"Content-Type": "image/png"}}, true, false},
+		{"-content-type image/*", "a", ClientContent{Metadata: map[string]string{"Content-Type": "image/png"}}, true, false},
+		{"-noncurrent", "a", ClientContent{VersionID: "1"}, true, true},
+		{"-latest -or -delete-marker", "a", ClientContent{VersionID: "1"}, false, true},
+		{"-delete-marker", "a", ClientContent{VersionID: "1", IsDeleteMarker: true, IsLatest: true}, true, true},
+	}
+	for i, testCase := range testCases {
+		expr, withVersions, err := parseFindExpr(testCase.expr)
+		if err != nil {
+			t.Fatalf("Test %d: %s", i+1, err)
+		}
+		if withVersions != testCase.withVersions {
+			t.Errorf("Test %d: expected withVersions %t, got %t", i+1, testCase.withVersions, withVersions)
+		}
+		content := testCase.content
+		o := &findObject{ctx: context.Background(), content: &content, path: testCase.path}
+		if match := expr.eval(o); match != testCase.match {
+			t.Errorf("Test %d: expected %t for `%s` on %s, got %t", i+1, testCase.match, testCase.expr, testCase.path, match)
+		}
+	}
+
+	for i, expr := range []string{"", "-name", "( -name a", "-name a )", "-foo a", "-size", "-size +x", "-regex (", "-tag a", "-name 'a", "-name a -or"} {
+		if _, _, err := parseFindExpr(expr); err == nil {
+			t.Errorf("Test %d: expected an error for `%s`", i+1, expr)
+		}
+	}
+}
+
+// Tests running --exec commands in parallel and in batches.
+func TestFindExec(t *testing.T) {
+	ctx := context.Background()
+
+	e := newFindExec(ctx, "ls {}", 4)
+	for _, key := range []string{".", "..", "asdf"} {
+		e.add(contentMessage{Key: key})
+	}
+	if status := e.wait(); status != 2 {
+		t.Errorf("Expected exit status 2, got %d", status)
+	}
+
+	e = newFindExec(ctx, "ls -d {} +", 1)
+	if e.batchArg != "{}" || strings.Join(e.args, " ") != "ls -d" {
+		t.Fatalf("Unexpected batched command %v %s", e.args, e.batchArg)
+	}
+	for i := 0; i <= findExecBatchSize; i++ {
+		e.add(contentMessage{Key: "."})
+	}
+	if len(e.batch) != 1 {
+		t.Errorf("Expected a new batch of 1 object, got %d", len(e.batch))
+	}
+	if status := e.wait(); status != 0 {
+		t.Errorf("Expected exit status 0, got %d", status)
+	}
+
+	// Only a keyword followed by "+" batches objects.
+	if e = newFindExec(ctx, "expr 1 +", 1); e.batchArg != "" {
+		t.Errorf("Unexpected batched command %v %s", e.args, e.batchArg)
+	}
+	e.wait()
+}
+
+// TestMemFind - tests find expressions and the actions run on the
+// matching objects.
+func (s *TestSuite) TestMemFind(c *C) {
+	ctx := context.Background()
+	for _, bucket := range []string{"mem://mem-find", "mem://mem-find-copy"} {
+		clnt, err := newClient(bucket)
+		c.Assert(err, IsNil)
+		c.Assert(clnt.MakeBucket(ctx, "", false, false), IsNil)
+	}
+	memPut(c, "mem://mem-find/a.log", "1")
+	memPut(c, "mem://mem-find/dir/b.tmp", "22")
+	memPut(c, "mem://mem-find/c.txt", "333")
+
+	newFindContext := func(exprStr string) *findContext {
+		expr, withVersions, err := parseFindExpr(exprStr)
+		c.Assert(err, IsNil)
+		clnt, err := newClient("mem://mem-find")
+		c.Assert(err, IsNil)
+		return &findContext{expr: expr, withVersions: withVersions, targetURL: "mem://mem-find", clnt: clnt}
+	}
+
+	findCtx := newFindContext("( -name '*.log' -or -name '*.tmp' ) -size +1")
+	findCtx.tags = "state=old"
+	findCtx.copyTo = "mem://mem-find-copy/archive"
+	c.Assert(doFind(ctx, findCtx), IsNil)
+	clnt, err := newClient("mem://mem-find/dir/b.tmp")
+	c.Assert(err, IsNil)
+	tags, err := clnt.GetTags(ctx, "")
+	c.Assert(err, IsNil)
+	c.Assert(tags, DeepEquals, map[string]string{"state": "old"})
+	st, err := memStat(c, "mem://mem-find-copy/archive/dir/b.tmp")
+	c.Assert(err, IsNil)
+	c.Assert(st.Size, Equals, int64(2))
+	c.Assert(memList(c, "mem://mem-find-copy", ListOptions{Recursive: true}), HasLen, 1)
+
+	findCtx = newFindContext("-tag state=old -or -name c.txt")
+	findCtx.deleteObjects = true
+	c.Assert(doFind(ctx, findCtx), IsNil)
+	contents := memList(c, "mem://mem-find", ListOptions{Recursive: true})
+	c.Assert(contents, HasLen, 1)
+	c.Assert(contents[0].URL.Path, Equals, "/mem-find/a.log")
+}
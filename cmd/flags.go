@@ -47,6 +47,18 @@ var globalFlags = []cli.Flag{
 		Name:  "insecure",
 		Usage: "disable SSL certificate verification",
 	},
+	cli.BoolFlag{
+		Name:  "cache",
+		Usage: "cache downloaded objects locally, capped by MC_CACHE_QUOTA",
+	},
+	cli.StringFlag{
+		Name:  "limit-upload",
+		Usage: "limit the upload rate of all transfers, e.g. 10MiB per second",
+	},
+	cli.StringFlag{
+		Name:  "limit-download",
+		Usage: "limit the download rate of all transfers, e.g. 10MiB per second",
+	},
 }
 
 // Flags common across all I/O commands such as cp, mirror, stat, pipe etc.
@@ -55,4 +67,8 @@ var ioFlags = []cli.Flag{
 		Name:  "encrypt-key",
 		Usage: "encrypt/decrypt objects (using server-side encryption with customer provided keys)",
 	},
+	cli.StringFlag{
+		Name:  "encrypt-client-key",
+		Usage: "encrypt/decrypt objects on the client side, keys and plaintext are never sent to the server",
+	},
 }
@@ -22,6 +22,7 @@ import (
 	"crypto/x509"
 
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/minio/pkg/console"
 )
 
@@ -35,6 +36,9 @@ const (
 	// session config and shared urls related constants
 	globalSessionDir           = "session"
 	globalSharedURLsDataDir    = "share"
+	globalCacheDir             = "cache"
+	globalSyncDir              = "sync"
+	globalVersionMapDir        = "versions"
 	globalSessionConfigVersion = "8"
 
 	// Profile directory for dumping profiler outputs.
@@ -50,6 +54,10 @@ var (
 	globalDebug    = false // Debug flag set via command line
 	globalNoColor  = false // No Color flag set via command line
 	globalInsecure = false // Insecure flag set via command line
+	globalCache    = false // Cache flag set via command line
+
+	globalLimitUpload   *bandwidthLimiter // Upload limit set via command line
+	globalLimitDownload *bandwidthLimiter // Download limit set via command line
 
 	globalContext, globalCancel = context.WithCancel(context.Background())
 )
@@ -63,12 +71,13 @@ var (
 )
 
 // Set global states. NOTE: It is deliberately kept monolithic to ensure we dont miss out any flags.
-func setGlobals(quiet, debug, json, noColor, insecure bool) {
+func setGlobals(quiet, debug, json, noColor, insecure, cache bool) {
 	globalQuiet = globalQuiet || quiet
 	globalDebug = globalDebug || debug
 	globalJSON = globalJSON || json
 	globalNoColor = globalNoColor || noColor
 	globalInsecure = globalInsecure || insecure
+	globalCache = globalCache || cache
 
 	// Disable colorified messages if requested.
 	if globalNoColor || globalQuiet {
@@ -83,6 +92,30 @@ func setGlobalsFromContext(ctx *cli.Context) error {
 	json := ctx.IsSet("json") || ctx.GlobalIsSet("json")
 	noColor := ctx.IsSet("no-color") || ctx.GlobalIsSet("no-color")
 	insecure := ctx.IsSet("insecure") || ctx.GlobalIsSet("insecure")
-	setGlobals(quiet, debug, json, noColor, insecure)
+	cache := ctx.IsSet("cache") || ctx.GlobalIsSet("cache")
+	setGlobals(quiet, debug, json, noColor, insecure, cache)
+	setGlobalLimits(globalStringFromContext(ctx, "limit-upload"), globalStringFromContext(ctx, "limit-download"))
 	return nil
 }
+
+// globalStringFromContext - returns the value of a global string flag
+// set either before or after the command.
+func globalStringFromContext(ctx *cli.Context, name string) string {
+	if ctx.IsSet(name) {
+		return ctx.String(name)
+	}
+	return ctx.GlobalString(name)
+}
+
+// setGlobalLimits - sets the bandwidth limits shared by all transfers.
+func setGlobalLimits(limitUpload, limitDownload string) {
+	var err *probe.Error
+	if limitUpload != "" {
+		globalLimitUpload, err = parseBandwidthLimit(limitUpload)
+		fatalIf(err, "Unable to parse `--limit-upload`.")
+	}
+	if limitDownload != "" {
+		globalLimitDownload, err = parseBandwidthLimit(limitDownload)
+		fatalIf(err, "Unable to parse `--limit-download`.")
+	}
+}
@@ -102,7 +102,15 @@ func headURL(sourceURL, sourceVersion string, timeRef time.Time, encKeyDB map[st
 			return err.Trace(sourceURL)
 		}
 		ctype := metadata["Content-Type"]
-		if strings.Contains(ctype, "gzip") {
+		if codec, _ := streamCompression(metadata); codec != "" {
+			decReader, err := decompressStream(reader, codec)
+			if err != nil {
+				reader.Close()
+				return err.Trace(sourceURL)
+			}
+			reader = decReader
+			defer reader.Close()
+		} else if strings.Contains(ctype, "gzip") {
 			var e error
 			reader, e = gzip.NewReader(reader)
 			if e != nil {
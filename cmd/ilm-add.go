@@ -141,7 +141,7 @@ func mainILMAdd(cliCtx *cli.Context) error {
 	args := cliCtx.Args()
 	urlStr := args.Get(0)
 
-	client, err := newClient(urlStr)
+	client, err := newClientWithCapability(urlStr, capLifecycle)
 	fatalIf(err.Trace(urlStr), "Unable to initialize client for "+urlStr)
 
 	// Configuration that is already set.
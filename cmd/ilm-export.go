@@ -88,7 +88,7 @@ func mainILMExport(cliCtx *cli.Context) error {
 	args := cliCtx.Args()
 	urlStr := args.Get(0)
 
-	client, err := newClient(urlStr)
+	client, err := newClientWithCapability(urlStr, capLifecycle)
 	fatalIf(err.Trace(args...), "Unable to initialize client for "+urlStr+".")
 
 	ilmCfg, err := client.GetLifecycle(ctx)
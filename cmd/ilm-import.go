@@ -98,7 +98,7 @@ func mainILMImport(cliCtx *cli.Context) error {
 	args := cliCtx.Args()
 	urlStr := args.Get(0)
 
-	client, err := newClient(urlStr)
+	client, err := newClientWithCapability(urlStr, capLifecycle)
 	fatalIf(err.Trace(urlStr), "Unable to initialize client for "+urlStr)
 
 	ilmCfg, err := readILMConfig()
@@ -258,7 +258,7 @@ func mainILMList(cliCtx *cli.Context) error {
 	args := cliCtx.Args()
 	urlStr := args.Get(0)
 
-	client, err := newClient(urlStr)
+	client, err := newClientWithCapability(urlStr, capLifecycle)
 	fatalIf(err.Trace(urlStr), "Unable to initialize client for "+urlStr)
 
 	ilmCfg, err := client.GetLifecycle(ctx)
@@ -123,7 +123,7 @@ func mainILMRemove(cliCtx *cli.Context) error {
 	args := cliCtx.Args()
 	urlStr := args.Get(0)
 
-	client, err := newClient(urlStr)
+	client, err := newClientWithCapability(urlStr, capLifecycle)
 	fatalIf(err.Trace(args...), "Unable to initialize client for "+urlStr+".")
 
 	ilmCfg, err := client.GetLifecycle(ctx)
@@ -0,0 +1,744 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/parquet"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio/pkg/console"
+)
+
+// inventory specific flags.
+var (
+	inventoryFlags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Value: inventoryFormatCSV,
+			Usage: "format of the report, 'csv', 'jsonl' or 'parquet'",
+		},
+		cli.BoolFlag{
+			Name:  "versions",
+			Usage: "report every object version and delete marker",
+		},
+		cli.BoolFlag{
+			Name:  "tags",
+			Usage: "report the tags of the objects, read object by object",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "write the report to an object or a file instead of the standard output",
+		},
+		cli.StringFlag{
+			Name:  "diff-with",
+			Usage: "print the objects added and removed since a previous CSV or JSON lines report",
+		},
+	}
+)
+
+// Report the objects of a bucket.
+var inventoryCmd = cli.Command{
+	Name:         "inventory",
+	Usage:        "report every object of a bucket as CSV, JSON lines or Parquet",
+	Action:       mainInventory,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(append(inventoryFlags, ioFlags...), globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Each record of the report holds the key of an object relative to TARGET, its version ID, size, ETag,
+  modification time, storage class, replication status, retention, legal hold, tags and user metadata.
+  Tags and user metadata are URL encoded in CSV and Parquet reports.
+
+  With --diff-with, the objects added and removed since the previous report are printed, an object is
+  identified by its key, version ID and ETag. The report itself is written only when --out is set.
+
+ENVIRONMENT VARIABLES:
+  MC_ENCRYPT_KEY: list of comma delimited prefix=secret values
+
+EXAMPLES:
+  1. Report the objects of 'jazz-songs' bucket as CSV.
+     {{.Prompt}} {{.HelpName}} s3/jazz-songs > jazz-songs.csv
+
+  2. Report every version of the objects of 'jazz-songs' bucket, with their tags, as JSON lines.
+     {{.Prompt}} {{.HelpName}} --versions --tags --format jsonl s3/jazz-songs > jazz-songs.jsonl
+
+  3. Write a Parquet report of 'jazz-songs' bucket to the 'audit' bucket.
+     {{.Prompt}} {{.HelpName}} --format parquet --out s3/audit/jazz-songs/2021-03-01.parquet s3/jazz-songs
+
+  4. Print the objects added and removed since the last report and write the new one.
+     {{.Prompt}} {{.HelpName}} --diff-with s3/audit/jazz-songs/2021-03-01.csv --out s3/audit/jazz-songs/2021-03-02.csv s3/jazz-songs
+`,
+}
+
+// Formats of a report.
+const (
+	inventoryFormatCSV     = "csv"
+	inventoryFormatJSONL   = "jsonl"
+	inventoryFormatParquet = "parquet"
+)
+
+// inventoryContentTypes - the content type of a report written to an
+// object, by format.
+var inventoryContentTypes = map[string]string{
+	inventoryFormatCSV:     "text/csv",
+	inventoryFormatJSONL:   "application/x-ndjson",
+	inventoryFormatParquet: "application/octet-stream",
+}
+
+// inventoryFormatOf - returns the format of the report at urlStr by
+// its extension, csv by default.
+func inventoryFormatOf(urlStr string) string {
+	switch strings.ToLower(filepath.Ext(urlStr)) {
+	case ".jsonl", ".json", ".ndjson":
+		return inventoryFormatJSONL
+	case ".parquet":
+		return inventoryFormatParquet
+	}
+	return inventoryFormatCSV
+}
+
+// inventoryRecord - a record of the report, an object or a version.
+type inventoryRecord struct {
+	Key               string            `json:"key"`
+	VersionID         string            `json:"versionId,omitempty"`
+	IsLatest          bool              `json:"isLatest"`
+	IsDeleteMarker    bool              `json:"isDeleteMarker"`
+	Size              int64             `json:"size"`
+	ETag              string            `json:"etag"`
+	LastModified      time.Time         `json:"lastModified"`
+	StorageClass      string            `json:"storageClass,omitempty"`
+	ReplicationStatus string            `json:"replicationStatus,omitempty"`
+	RetentionMode     string            `json:"retentionMode,omitempty"`
+	RetainUntil       string            `json:"retainUntil,omitempty"`
+	LegalHold         string            `json:"legalHold,omitempty"`
+	Tags              map[string]string `json:"tags,omitempty"`
+	UserMetadata      map[string]string `json:"userMetadata,omitempty"`
+}
+
+// inventoryColumns - the columns of CSV and Parquet reports.
+var inventoryColumns = []parquet.Column{
+	{Name: "key", Type: parquet.String},
+	{Name: "version_id", Type: parquet.String},
+	{Name: "is_latest", Type: parquet.Boolean},
+	{Name: "is_delete_marker", Type: parquet.Boolean},
+	{Name: "size", Type: parquet.Int64},
+	{Name: "etag", Type: parquet.String},
+	{Name: "last_modified", Type: parquet.TimestampMillis},
+	{Name: "storage_class", Type: parquet.String},
+	{Name: "replication_status", Type: parquet.String},
+	{Name: "retention_mode", Type: parquet.String},
+	{Name: "retain_until", Type: parquet.String},
+	{Name: "legal_hold", Type: parquet.String},
+	{Name: "tags", Type: parquet.String},
+	{Name: "user_metadata", Type: parquet.String},
+}
+
+// values - returns the values of the record, in the order of
+// inventoryColumns.
+func (r inventoryRecord) values() []interface{} {
+	return []interface{}{
+		r.Key,
+		r.VersionID,
+		r.IsLatest,
+		r.IsDeleteMarker,
+		r.Size,
+		r.ETag,
+		r.LastModified,
+		r.StorageClass,
+		r.ReplicationStatus,
+		r.RetentionMode,
+		r.RetainUntil,
+		r.LegalHold,
+		encodeInventoryMap(r.Tags),
+		encodeInventoryMap(r.UserMetadata),
+	}
+}
+
+// encodeInventoryMap - encodes tags or user metadata as a query
+// string, sorted by key.
+func encodeInventoryMap(m map[string]string) string {
+	values := url.Values{}
+	for k, v := range m {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+// inventoryKey - identifies an object in reports.
+type inventoryKey struct {
+	key, versionID, etag string
+}
+
+func (r inventoryRecord) identity() inventoryKey {
+	return inventoryKey{r.Key, r.VersionID, r.ETag}
+}
+
+// inventoryMetadata - returns the value of a metadata header of
+// content, listings return it as metadata or as user metadata.
+func inventoryMetadata(content *ClientContent, key string) string {
+	for _, m := range []map[string]string{content.Metadata, content.UserMetadata} {
+		for k, v := range m {
+			if http.CanonicalHeaderKey(k) == key {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// newInventoryRecord - returns the record of content, its key relative
+// to targetURL.
+func newInventoryRecord(content *ClientContent, targetURL ClientURL) inventoryRecord {
+	r := inventoryRecord{
+		Key:               strings.TrimPrefix(strings.TrimPrefix(content.URL.Path, targetURL.Path), string(content.URL.Separator)),
+		VersionID:         content.VersionID,
+		IsLatest:          content.IsLatest || content.VersionID == "",
+		IsDeleteMarker:    content.IsDeleteMarker,
+		Size:              content.Size,
+		ETag:              content.ETag,
+		LastModified:      content.Time.UTC(),
+		StorageClass:      content.StorageClass,
+		ReplicationStatus: content.ReplicationStatus,
+		RetentionMode:     content.RetentionMode,
+		RetainUntil:       content.RetentionDuration,
+		LegalHold:         content.LegalHold,
+	}
+	if r.Key == "" {
+		// TARGET is an object.
+		r.Key = filepath.Base(content.URL.Path)
+	}
+	if r.RetentionMode == "" {
+		r.RetentionMode = inventoryMetadata(content, AmzObjectLockMode)
+		r.RetainUntil = inventoryMetadata(content, AmzObjectLockRetainUntilDate)
+	}
+	if r.LegalHold == "" {
+		r.LegalHold = inventoryMetadata(content, AmzObjectLockLegalHold)
+	}
+	for k, v := range content.UserMetadata {
+		switch k = http.CanonicalHeaderKey(k); {
+		case strings.HasPrefix(k, "X-Amz-Meta-"):
+			k = strings.TrimPrefix(k, "X-Amz-Meta-")
+		case strings.HasPrefix(k, "X-Amz-"), strings.HasPrefix(k, "Content-"), k == "Cache-Control", k == "Expires":
+			// System metadata.
+			continue
+		}
+		if r.UserMetadata == nil {
+			r.UserMetadata = make(map[string]string)
+		}
+		r.UserMetadata[k] = v
+	}
+	return r
+}
+
+// inventoryWriter - writes the records of a report in a format.
+type inventoryWriter interface {
+	write(r inventoryRecord) error
+	// close - writes what is left of the report, the underlying
+	// writer is not closed.
+	close() error
+}
+
+type csvInventoryWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvInventoryWriter) write(r inventoryRecord) error {
+	record := make([]string, 0, len(inventoryColumns))
+	for _, v := range r.values() {
+		switch v := v.(type) {
+		case string:
+			record = append(record, v)
+		case bool:
+			record = append(record, strconv.FormatBool(v))
+		case int64:
+			record = append(record, strconv.FormatInt(v, 10))
+		case time.Time:
+			record = append(record, v.Format(time.RFC3339Nano))
+		}
+	}
+	return w.w.Write(record)
+}
+
+func (w *csvInventoryWriter) close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type jsonlInventoryWriter struct {
+	w *bufio.Writer
+}
+
+func (w *jsonlInventoryWriter) write(r inventoryRecord) error {
+	var jsoniter = jsoniter.ConfigCompatibleWithStandardLibrary
+	data, e := jsoniter.Marshal(r)
+	if e != nil {
+		return e
+	}
+	w.w.Write(data)
+	return w.w.WriteByte('\n')
+}
+
+func (w *jsonlInventoryWriter) close() error {
+	return w.w.Flush()
+}
+
+type parquetInventoryWriter struct {
+	w *parquet.Writer
+}
+
+func (w *parquetInventoryWriter) write(r inventoryRecord) error {
+	return w.w.Write(r.values()...)
+}
+
+func (w *parquetInventoryWriter) close() error {
+	return w.w.Close()
+}
+
+// newInventoryWriter - returns a writer of a report in format to w.
+func newInventoryWriter(w io.Writer, format string) (inventoryWriter, *probe.Error) {
+	switch format {
+	case inventoryFormatJSONL:
+		return &jsonlInventoryWriter{w: bufio.NewWriter(w)}, nil
+	case inventoryFormatParquet:
+		pw, e := parquet.NewWriter(w, inventoryColumns, parquet.DefaultRowGroupSize)
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		return &parquetInventoryWriter{w: pw}, nil
+	}
+	header := make([]string, 0, len(inventoryColumns))
+	for _, column := range inventoryColumns {
+		header = append(header, column.Name)
+	}
+	cw := csv.NewWriter(w)
+	if e := cw.Write(header); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return &csvInventoryWriter{w: cw}, nil
+}
+
+// readInventory - reads the records of a CSV or JSON lines report.
+func readInventory(ctx context.Context, urlStr string) ([]inventoryRecord, *probe.Error) {
+	format := inventoryFormatOf(urlStr)
+	if format == inventoryFormatParquet {
+		return nil, errInvalidArgument().Trace(urlStr)
+	}
+	clnt, err := newClient(urlStr)
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	reader, err := clnt.Get(ctx, GetOptions{})
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	defer reader.Close()
+
+	var records []inventoryRecord
+	if format == inventoryFormatJSONL {
+		var jsoniter = jsoniter.ConfigCompatibleWithStandardLibrary
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+			var r inventoryRecord
+			if e := jsoniter.Unmarshal(scanner.Bytes(), &r); e != nil {
+				return nil, probe.NewError(e).Trace(urlStr)
+			}
+			records = append(records, r)
+		}
+		if e := scanner.Err(); e != nil {
+			return nil, probe.NewError(e).Trace(urlStr)
+		}
+		return records, nil
+	}
+
+	cr := csv.NewReader(reader)
+	header, e := cr.Read()
+	if e != nil {
+		return nil, probe.NewError(e).Trace(urlStr)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range []string{"key", "version_id", "etag", "size"} {
+		if _, ok := columns[name]; !ok {
+			return nil, probe.NewError(fmt.Errorf("missing column %s", name)).Trace(urlStr)
+		}
+	}
+	for {
+		record, e := cr.Read()
+		if e == io.EOF {
+			return records, nil
+		}
+		if e != nil {
+			return nil, probe.NewError(e).Trace(urlStr)
+		}
+		size, e := strconv.ParseInt(record[columns["size"]], 10, 64)
+		if e != nil {
+			return nil, probe.NewError(e).Trace(urlStr)
+		}
+		records = append(records, inventoryRecord{
+			Key:       record[columns["key"]],
+			VersionID: record[columns["version_id"]],
+			ETag:      record[columns["etag"]],
+			Size:      size,
+		})
+	}
+}
+
+// inventoryDiff - the records of a previous report not found yet in
+// the new one.
+type inventoryDiff struct {
+	previous map[inventoryKey]inventoryRecord
+}
+
+func newInventoryDiff(records []inventoryRecord) *inventoryDiff {
+	d := &inventoryDiff{previous: make(map[inventoryKey]inventoryRecord, len(records))}
+	for _, r := range records {
+		d.previous[r.identity()] = r
+	}
+	return d
+}
+
+// added - returns true if r is not in the previous report.
+func (d *inventoryDiff) added(r inventoryRecord) bool {
+	if _, ok := d.previous[r.identity()]; ok {
+		delete(d.previous, r.identity())
+		return false
+	}
+	return true
+}
+
+// removed - returns the records of the previous report not found in
+// the new one, sorted by key.
+func (d *inventoryDiff) removed() []inventoryRecord {
+	records := make([]inventoryRecord, 0, len(d.previous))
+	for _, r := range d.previous {
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, k int) bool {
+		if records[i].Key != records[k].Key {
+			return records[i].Key < records[k].Key
+		}
+		return records[i].VersionID < records[k].VersionID
+	})
+	return records
+}
+
+// inventoryDiffMessage - an object added or removed since the previous
+// report.
+type inventoryDiffMessage struct {
+	Status    string `json:"status"`
+	Diff      string `json:"diff"`
+	Key       string `json:"key"`
+	VersionID string `json:"versionId,omitempty"`
+	Size      int64  `json:"size"`
+	ETag      string `json:"etag"`
+}
+
+func (m inventoryDiffMessage) String() string {
+	msg := m.Key
+	if m.VersionID != "" {
+		msg += " (" + m.VersionID + ")"
+	}
+	if m.Diff == "added" {
+		return console.Colorize("InventoryAdded", "+ "+msg)
+	}
+	return console.Colorize("InventoryRemoved", "- "+msg)
+}
+
+func (m inventoryDiffMessage) JSON() string {
+	m.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+func newInventoryDiffMessage(diff string, r inventoryRecord) inventoryDiffMessage {
+	return inventoryDiffMessage{
+		Diff:      diff,
+		Key:       r.Key,
+		VersionID: r.VersionID,
+		Size:      r.Size,
+		ETag:      r.ETag,
+	}
+}
+
+// inventoryMessage - the summary of a report written to an object or
+// a file.
+type inventoryMessage struct {
+	Status  string `json:"status"`
+	URL     string `json:"url"`
+	Format  string `json:"format"`
+	Objects int64  `json:"objects"`
+	Size    int64  `json:"size"`
+}
+
+func (m inventoryMessage) String() string {
+	return console.Colorize("Inventory", fmt.Sprintf("Reported %d objects, %s, to `%s`.",
+		m.Objects, humanize.IBytes(uint64(m.Size)), m.URL))
+}
+
+func (m inventoryMessage) JSON() string {
+	m.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// inventoryUpload - the upload of a report through Put, the report is
+// written to the pipe.
+type inventoryUpload struct {
+	*io.PipeWriter
+	doneCh chan *probe.Error
+}
+
+// uploadInventory - starts the upload of a report in format to urlStr.
+func uploadInventory(ctx context.Context, urlStr, format string) (*inventoryUpload, *probe.Error) {
+	clnt, err := newClient(urlStr)
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	pr, pw := io.Pipe()
+	u := &inventoryUpload{PipeWriter: pw, doneCh: make(chan *probe.Error, 1)}
+	go func() {
+		_, err := clnt.Put(ctx, pr, -1, map[string]string{"Content-Type": inventoryContentTypes[format]}, nil, nil, false, false, false)
+		if err != nil {
+			pr.CloseWithError(err.ToGoError())
+		} else {
+			pr.Close()
+		}
+		u.doneCh <- err
+	}()
+	return u, nil
+}
+
+// finish - ends the report and waits for its upload, the upload fails
+// if err is not nil.
+func (u *inventoryUpload) finish(err *probe.Error) *probe.Error {
+	if err != nil {
+		u.CloseWithError(err.ToGoError())
+		<-u.doneCh
+		return err
+	}
+	u.Close()
+	return <-u.doneCh
+}
+
+// inventoryOptions - the options of a report.
+type inventoryOptions struct {
+	format   string
+	versions bool
+	tags     bool
+	outURL   string
+	diffWith string
+	encKeyDB map[string][]prefixSSEPair
+}
+
+// inventory - lists urlStr and reports its objects, to opts.outURL or
+// the standard output, and prints the differences with opts.diffWith.
+func inventory(ctx context.Context, urlStr string, opts inventoryOptions) *probe.Error {
+	targetAlias, targetURL, _ := mustExpandAlias(urlStr)
+	clnt, err := newClientFromAliasWithKeys(targetAlias, targetURL, opts.encKeyDB)
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+
+	var diff *inventoryDiff
+	if opts.diffWith != "" {
+		records, err := readInventory(ctx, opts.diffWith)
+		if err != nil {
+			return err.Trace(opts.diffWith)
+		}
+		diff = newInventoryDiff(records)
+	}
+
+	var out io.Writer
+	var upload *inventoryUpload
+	switch {
+	case opts.outURL != "":
+		if upload, err = uploadInventory(ctx, opts.outURL, opts.format); err != nil {
+			return err.Trace(opts.outURL)
+		}
+		out = upload
+	case diff == nil:
+		out = os.Stdout
+	}
+	var w inventoryWriter
+	if out != nil {
+		if w, err = newInventoryWriter(out, opts.format); err != nil && upload != nil {
+			upload.finish(err)
+		}
+		if err != nil {
+			return err.Trace(urlStr)
+		}
+	}
+	total, err := listInventory(ctx, clnt, targetAlias, w, diff, opts)
+	if err == nil && w != nil {
+		if e := w.close(); e != nil {
+			err = probe.NewError(e)
+		}
+	}
+	if upload != nil {
+		err = upload.finish(err)
+	}
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+
+	if upload != nil {
+		printMsg(inventoryMessage{
+			URL:     opts.outURL,
+			Format:  opts.format,
+			Objects: total.Objects,
+			Size:    total.Size,
+		})
+	}
+	if diff != nil {
+		for _, r := range diff.removed() {
+			printMsg(newInventoryDiffMessage("removed", r))
+		}
+	}
+	return nil
+}
+
+// listInventory - writes a record of each object of clnt to w if not
+// nil, printing the ones not in diff if not nil, and returns the total
+// of the objects.
+func listInventory(ctx context.Context, clnt Client, alias string, w inventoryWriter, diff *inventoryDiff, opts inventoryOptions) (total duTotal, err *probe.Error) {
+	for content := range clnt.List(ctx, ListOptions{
+		Recursive:         true,
+		WithMetadata:      true,
+		WithOlderVersions: opts.versions,
+		WithDeleteMarkers: opts.versions,
+		ShowDir:           DirNone,
+	}) {
+		if content.Err != nil {
+			switch content.Err.ToGoError().(type) {
+			// handle this specifically for filesystem related errors.
+			case BrokenSymlink, TooManyLevelsSymlink, PathNotFound, ObjectOnGlacier:
+				continue
+			}
+			return total, content.Err.Trace(clnt.GetURL().String())
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+
+		r := newInventoryRecord(content, clnt.GetURL())
+		if opts.tags && !content.IsDeleteMarker {
+			var objClnt Client
+			objClnt, err = newClientFromAliasWithKeys(alias, content.URL.String(), opts.encKeyDB)
+			if err != nil {
+				return total, err.Trace(content.URL.String())
+			}
+			var tags map[string]string
+			tags, err = objClnt.GetTags(ctx, content.VersionID)
+			if err != nil {
+				return total, err.Trace(content.URL.String())
+			}
+			if len(tags) > 0 {
+				r.Tags = tags
+			}
+		}
+
+		if w != nil {
+			if e := w.write(r); e != nil {
+				return total, probe.NewError(e)
+			}
+		}
+		if diff != nil && diff.added(r) {
+			printMsg(newInventoryDiffMessage("added", r))
+		}
+		total.add(r.Size)
+	}
+	return total, nil
+}
+
+// checkInventorySyntax - validates the arguments and flags of inventory.
+func checkInventorySyntax(cliCtx *cli.Context) {
+	if len(cliCtx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(cliCtx, "inventory", 1) // last argument is exit code
+	}
+	switch cliCtx.String("format") {
+	case inventoryFormatCSV, inventoryFormatJSONL, inventoryFormatParquet:
+	default:
+		fatalIf(errInvalidArgument().Trace(cliCtx.String("format")), "`--format` should be 'csv', 'jsonl' or 'parquet'.")
+	}
+	if diffWith := cliCtx.String("diff-with"); diffWith != "" && inventoryFormatOf(diffWith) == inventoryFormatParquet {
+		fatalIf(errInvalidArgument().Trace(diffWith), "Unable to read a Parquet report, `--diff-with` should be a CSV or JSON lines report.")
+	}
+}
+
+// main for inventory command.
+func mainInventory(cliCtx *cli.Context) error {
+	ctx, cancelInventory := context.WithCancel(globalContext)
+	defer cancelInventory()
+
+	checkInventorySyntax(cliCtx)
+
+	// Set colors.
+	console.SetColor("Inventory", color.New(color.FgGreen, color.Bold))
+	console.SetColor("InventoryAdded", color.New(color.FgGreen))
+	console.SetColor("InventoryRemoved", color.New(color.FgRed))
+
+	// Parse encryption keys per command.
+	encKeyDB, err := getEncKeys(cliCtx)
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	urlStr := cliCtx.Args().Get(0)
+	err = inventory(ctx, urlStr, inventoryOptions{
+		format:   cliCtx.String("format"),
+		versions: cliCtx.Bool("versions"),
+		tags:     cliCtx.Bool("tags"),
+		outURL:   cliCtx.String("out"),
+		diffWith: cliCtx.String("diff-with"),
+		encKeyDB: encKeyDB,
+	})
+	fatalIf(err, "Unable to report the objects of `"+urlStr+"`.")
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+// TestMemInventory - tests reports of the objects of a bucket and their
+// differences with a previous report.
+func (s *TestSuite) TestMemInventory(c *C) {
+	ctx := context.Background()
+	for _, bucket := range []string{"mem://mem-inventory", "mem://mem-inventory-out"} {
+		clnt, err := newClient(bucket)
+		c.Assert(err, IsNil)
+		c.Assert(clnt.MakeBucket(ctx, "", false, false), IsNil)
+	}
+
+	memPut(c, "mem://mem-inventory/a", "1")
+	objClnt, err := newClient("mem://mem-inventory/dir/b")
+	c.Assert(err, IsNil)
+	_, err = objClnt.Put(ctx, bytes.NewReader([]byte("22")), 2,
+		map[string]string{"X-Amz-Meta-Color": "blue", "X-Amz-Tagging": "team=jazz"}, nil, nil, false, false, false)
+	c.Assert(err, IsNil)
+
+	opts := inventoryOptions{format: inventoryFormatJSONL, tags: true, outURL: "mem://mem-inventory-out/report.jsonl"}
+	c.Assert(inventory(ctx, "mem://mem-inventory", opts), IsNil)
+	records, err := readInventory(ctx, opts.outURL)
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+	c.Assert(records[1].Key, Equals, "dir/b")
+	c.Assert(records[1].Size, Equals, int64(2))
+	c.Assert(records[1].StorageClass, Equals, "STANDARD")
+	c.Assert(records[1].Tags, DeepEquals, map[string]string{"team": "jazz"})
+	c.Assert(records[1].UserMetadata, DeepEquals, map[string]string{"Color": "blue"})
+
+	opts = inventoryOptions{format: inventoryFormatCSV, outURL: "mem://mem-inventory-out/report.csv"}
+	c.Assert(inventory(ctx, "mem://mem-inventory", opts), IsNil)
+	csvRecords, err := readInventory(ctx, opts.outURL)
+	c.Assert(err, IsNil)
+	c.Assert(csvRecords, HasLen, 2)
+	c.Assert(csvRecords[1].identity(), Equals, records[1].identity())
+
+	opts = inventoryOptions{format: inventoryFormatParquet, outURL: "mem://mem-inventory-out/report.parquet"}
+	c.Assert(inventory(ctx, "mem://mem-inventory", opts), IsNil)
+	reportClnt, err := newClient(opts.outURL)
+	c.Assert(err, IsNil)
+	reader, err := reportClnt.Get(ctx, GetOptions{})
+	c.Assert(err, IsNil)
+	data, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	c.Assert(bytes.HasPrefix(data, []byte("PAR1")), Equals, true)
+	c.Assert(bytes.HasSuffix(data, []byte("PAR1")), Equals, true)
+	_, err = readInventory(ctx, opts.outURL)
+	c.Assert(err, NotNil)
+
+	// An object rewritten is removed and added again.
+	memPut(c, "mem://mem-inventory/a", "333")
+	current := memList(c, "mem://mem-inventory/a", ListOptions{})
+	c.Assert(current, HasLen, 1)
+	diff := newInventoryDiff(csvRecords)
+	rootClnt, err := newClient("mem://mem-inventory")
+	c.Assert(err, IsNil)
+	c.Assert(diff.added(newInventoryRecord(current[0], rootClnt.GetURL())), Equals, true)
+	c.Assert(diff.added(records[1]), Equals, false)
+	removed := diff.removed()
+	c.Assert(removed, HasLen, 1)
+	c.Assert(removed[0].Key, Equals, "a")
+}
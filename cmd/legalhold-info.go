@@ -130,7 +130,7 @@ func (l legalHoldInfoMessage) JSON() string {
 
 // showLegalHoldInfo - show legalhold for one or many objects within a given prefix, with or without versioning
 func showLegalHoldInfo(ctx context.Context, urlStr, versionID string, timeRef time.Time, withOlderVersions, recursive bool) error {
-	clnt, err := newClient(urlStr)
+	clnt, err := newClientWithCapability(urlStr, capLegalHold)
 	if err != nil {
 		fatalIf(err.Trace(), "Unable to parse the provided url.")
 	}
@@ -177,7 +177,7 @@ func showLegalHoldInfo(ctx context.Context, urlStr, versionID string, timeRef ti
 			continue
 		}
 
-		if !recursive && alias+getKey(content) != getStandardizedURL(urlStr) {
+		if !recursive && getAliasedKey(alias, content) != getStandardizedURL(urlStr) {
 			break
 		}
 
@@ -84,7 +84,7 @@ EXAMPLES:
 // setLegalHold - Set legalhold for all objects within a given prefix.
 func setLegalHold(ctx context.Context, urlStr, versionID string, timeRef time.Time, withOlderVersions, recursive bool, lhold minio.LegalHoldStatus) error {
 
-	clnt, err := newClient(urlStr)
+	clnt, err := newClientWithCapability(urlStr, capLegalHold)
 	if err != nil {
 		fatalIf(err.Trace(), "Unable to parse the provided url.")
 	}
@@ -130,7 +130,7 @@ func setLegalHold(ctx context.Context, urlStr, versionID string, timeRef time.Ti
 			continue
 		}
 
-		if !recursive && alias+getKey(content) != getStandardizedURL(urlStr) {
+		if !recursive && getAliasedKey(alias, content) != getStandardizedURL(urlStr) {
 			break
 		}
 
@@ -62,7 +62,7 @@ var lsCmd = cli.Command{
 	Action:       mainList,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(lsFlags, globalFlags...),
+	Flags:        append(append(lsFlags, filterFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -101,6 +101,9 @@ EXAMPLES:
 
   9. List all objects on mybucket, summarize the number of objects and total size.
      {{.Prompt}} {{.HelpName}} --summarize s3/mybucket/
+
+  10. List the images of mybucket recursively, by their content type.
+     {{.Prompt}} {{.HelpName}} --recursive --match-content-type "image/*" s3/mybucket/
 `,
 }
 
@@ -189,6 +192,8 @@ func mainList(cliCtx *cli.Context) error {
 	// check 'ls' cliCtx arguments.
 	args, isRecursive, isIncomplete, isSummary, timeRef, withOlderVersions := checkListSyntax(ctx, cliCtx)
 
+	filter := newObjectFilterFromContext(cliCtx)
+
 	var cErr error
 	for _, targetURL := range args {
 		clnt, err := newClient(targetURL)
@@ -202,7 +207,8 @@ func mainList(cliCtx *cli.Context) error {
 				fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
 			}
 		}
-		if e := doList(ctx, clnt, isRecursive, isIncomplete, isSummary, timeRef, withOlderVersions); e != nil {
+		targetAlias, _, _ := mustExpandAlias(targetURL)
+		if e := doList(ctx, clnt, isRecursive, isIncomplete, isSummary, timeRef, withOlderVersions, targetAlias, filter); e != nil {
 			cErr = e
 		}
 	}
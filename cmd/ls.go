@@ -107,6 +107,15 @@ func getKey(c *ClientContent) string {
 	return getOSDependantKey(c.URL.Path, c.Type.IsDir())
 }
 
+// get content key prefixed by its alias, URLs served without
+// an alias such as mem:// URLs are returned in full.
+func getAliasedKey(alias string, c *ClientContent) string {
+	if alias == "" && c.URL.Type == objectStorage {
+		return getOSDependantKey(c.URL.String(), c.Type.IsDir())
+	}
+	return alias + getKey(c)
+}
+
 // Generate printable listing from a list of sorted client
 // contents, the latest created content comes first.
 func generateContentMessages(clntURL ClientURL, ctnts []*ClientContent, printAllVersions bool) (msgs []contentMessage) {
@@ -201,7 +210,7 @@ func printObjectVersions(clntURL ClientURL, ctntVersions []*ClientContent, print
 }
 
 // doList - list all entities inside a folder.
-func doList(ctx context.Context, clnt Client, isRecursive, isIncomplete, isSummary bool, timeRef time.Time, withOlderVersions bool) error {
+func doList(ctx context.Context, clnt Client, isRecursive, isIncomplete, isSummary bool, timeRef time.Time, withOlderVersions bool, targetAlias string, filter *objectFilter) error {
 
 	var (
 		lastPath          string
@@ -244,6 +253,11 @@ func doList(ctx context.Context, clnt Client, isRecursive, isIncomplete, isSumma
 			continue
 		}
 
+		// Skip objects the filter flags exclude
+		if !filter.match(ctx, targetAlias, content, filterPath(content, clnt.GetURL().String())) {
+			continue
+		}
+
 		if lastPath != content.URL.Path {
 			// Print any object in the current list before reinitializing it
 			printObjectVersions(clnt.GetURL(), perObjectVersions, withOlderVersions, isSummary)
@@ -407,6 +407,7 @@ var appCmds = []cli.Command{
 	mbCmd,
 	rbCmd,
 	cpCmd,
+	composeCmd,
 	mirrorCmd,
 	catCmd,
 	headCmd,
@@ -418,6 +419,7 @@ var appCmds = []cli.Command{
 	mvCmd,
 	treeCmd,
 	duCmd,
+	inventoryCmd,
 	retentionCmd,
 	legalHoldCmd,
 	diffCmd,
@@ -428,6 +430,10 @@ var appCmds = []cli.Command{
 	eventCmd,
 	watchCmd,
 	undoCmd,
+	cacheCmd,
+	sessionCmd,
+	replayCmd,
+	syncCmd,
 	policyCmd,
 	tagCmd,
 	replicateCmd,
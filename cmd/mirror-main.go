@@ -20,13 +20,16 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"os"
 	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	json "github.com/minio/mc/pkg/colorjson"
@@ -61,6 +64,14 @@ var (
 			Name:  "remove",
 			Usage: "remove extraneous object(s) on target",
 		},
+		cli.StringFlag{
+			Name:  "max-delete",
+			Usage: "abort removing extraneous object(s) if more than N or P% of the object(s) on target would be removed",
+		},
+		cli.StringFlag{
+			Name:  "backup-dir, trash",
+			Usage: "move extraneous object(s) on target to a folder instead of removing them",
+		},
 		cli.StringFlag{
 			Name:  "region",
 			Usage: "specify region when creating new bucket(s) on target",
@@ -87,18 +98,6 @@ var (
 			Name:  "disable-multipart",
 			Usage: "disable multipart upload feature",
 		},
-		cli.StringSliceFlag{
-			Name:  "exclude",
-			Usage: "exclude object(s) that match specified object name pattern",
-		},
-		cli.StringFlag{
-			Name:  "older-than",
-			Usage: "filter object(s) older than L days, M hours and N minutes",
-		},
-		cli.StringFlag{
-			Name:  "newer-than",
-			Usage: "filter object(s) newer than L days, M hours and N minutes",
-		},
 		cli.StringFlag{
 			Name:  "storage-class, sc",
 			Usage: "specify storage class for new object(s) on target",
@@ -111,6 +110,39 @@ var (
 			Name:  "attr",
 			Usage: "add custom metadata for all objects",
 		},
+		cli.BoolFlag{
+			Name:  "to-archive",
+			Usage: "mirror object(s) into a single tar archive streamed to target",
+		},
+		cli.StringFlag{
+			Name:  "compress",
+			Usage: "compress object(s) on upload with one of zstd, gzip or s2",
+		},
+		cli.BoolFlag{
+			Name:  "continue, c",
+			Usage: "create or resume mirror session",
+		},
+		cli.BoolFlag{
+			Name:  "checksum",
+			Usage: "compare object(s) of same size by content, using ETags or checksums",
+		},
+		cli.StringFlag{
+			Name:  "rewind",
+			Usage: "mirror object(s) of a versioned source as they were at the specified time",
+		},
+		cli.BoolFlag{
+			Name:  "snapshot-now",
+			Usage: "mirror object(s) of a versioned source as they are when mirroring starts",
+		},
+		cli.BoolFlag{
+			Name:  "versions",
+			Usage: "mirror all versions and delete markers of a versioned source, in order",
+		},
+		cli.StringFlag{
+			Name:  "version-map",
+			Usage: "file recording the target version ID of each source version mirrored with --versions",
+		},
+		failedLogFlag,
 	}
 )
 
@@ -121,7 +153,7 @@ var mirrorCmd = cli.Command{
 	Action:       mainMirror,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(mirrorFlags, ioFlags...), globalFlags...),
+	Flags:        append(append(append(append(append(mirrorFlags, filterFlags...), ioFlags...), workerFlags...), retryFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -132,8 +164,9 @@ FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}
 ENVIRONMENT VARIABLES:
-   MC_ENCRYPT:      list of comma delimited prefixes
-   MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+   MC_ENCRYPT:            list of comma delimited prefixes
+   MC_ENCRYPT_KEY:        list of comma delimited prefix=secret values
+   MC_ENCRYPT_CLIENT_KEY: list of comma delimited prefix=secret values for client-side encryption
 
 EXAMPLES:
   01. Mirror a bucket recursively from MinIO cloud storage to a bucket on Amazon S3 cloud storage.
@@ -188,6 +221,51 @@ EXAMPLES:
   16. Cross mirror between sites in a active-active deployment.
       Site-A: {{.Prompt}} {{.HelpName}} --active-active siteA siteB
       Site-B: {{.Prompt}} {{.HelpName}} --active-active siteB siteA
+
+  17. Mirror a local folder into a single zstd compressed tar archive on Amazon S3 cloud storage.
+      {{.Prompt}} {{.HelpName}} --to-archive /var/lib/backups s3/archive/backups.tar.zst
+
+  18. Mirror a local folder of logs to MinIO cloud storage, objects are compressed with s2 on upload.
+      {{.Prompt}} {{.HelpName}} --compress s2 /var/log/app/ play/logs/app/
+
+  19. Mirror a bucket to Amazon S3 cloud storage and create or resume mirror session.
+      {{.Prompt}} {{.HelpName}} --continue play/photos s3/backup-photos
+
+  20. Mirror a local folder to MinIO cloud storage, overwriting objects of same size whose content differs.
+      {{.Prompt}} {{.HelpName}} --checksum --overwrite backup/ play/archive
+
+  21. Mirror a local folder to Amazon S3 cloud storage, uploading no faster than 10MiB per second overall.
+      {{.Prompt}} {{.HelpName}} --limit-upload 10MiB backup/ s3/archive
+
+  22. Mirror a local folder to MinIO cloud storage with exactly 8 parallel transfers.
+      {{.Prompt}} {{.HelpName}} --worker-policy fixed --max-workers 8 backup/ play/archive
+
+  23. Mirror a local folder to Amazon S3 cloud storage, retrying objects failing with network or server errors up to 5 times.
+      {{.Prompt}} {{.HelpName}} --retry-attempts 5 --retry-max-backoff 1m backup/ s3/archive
+
+  24. Mirror a local folder to Amazon S3 cloud storage, logging the objects failing to copy to retry them later with 'mc replay'.
+      {{.Prompt}} {{.HelpName}} --failed-log /tmp/failed.json backup/ s3/archive
+
+  25. Mirror a local folder to Amazon S3 cloud storage, reading the filter rules from a file.
+      {{.Prompt}} {{.HelpName}} --filter-file ~/.mirror-rules backup/ s3/archive
+
+  26. Mirror a versioned bucket on Amazon S3 cloud storage to a local folder as it was on March 1st, 2021 at 10 AM.
+      {{.Prompt}} {{.HelpName}} --rewind 2021.03.01T10:00 s3/mybucket/ backup/mybucket/
+
+  27. Mirror a versioned bucket on Amazon S3 cloud storage to a local folder as it is when mirroring starts, ignoring later changes.
+      {{.Prompt}} {{.HelpName}} --snapshot-now s3/mybucket/ backup/mybucket/
+
+  28. Mirror a local folder to Amazon S3 cloud storage, removing extraneous objects unless more than 10% of the objects would be removed.
+      {{.Prompt}} {{.HelpName}} --remove --max-delete 10% backup/ s3/archive
+
+  29. Mirror a local folder to Amazon S3 cloud storage, moving extraneous objects to another bucket instead of removing them.
+      {{.Prompt}} {{.HelpName}} --remove --backup-dir s3/trash/archive backup/ s3/archive
+
+  30. Summarize what mirroring a local folder to Amazon S3 cloud storage would copy and remove.
+      {{.Prompt}} {{.HelpName}} --fake --remove backup/ s3/archive
+
+  31. Mirror all versions of a versioned bucket to another cluster, recording the version IDs of the copies.
+      {{.Prompt}} {{.HelpName}} --versions --version-map versions.jsonl site1/mybucket site2/mybucket
 `,
 }
 
@@ -219,6 +297,19 @@ type mirrorJob struct {
 	targetURL string
 
 	opts mirrorOptions
+
+	// Checkpoints of a mirror run with --continue, and the URLs
+	// the resumed session queued but never completed.
+	session *mirrorSession
+	pending []URLs
+
+	// Journal of the objects failing to be copied or removed.
+	failed *failedLog
+
+	// Objects a fake mirror would copy and remove, summarized
+	// when it ends.
+	copyCount, copySize     int64
+	removeCount, removeSize int64
 }
 
 // mirrorMessage container for file mirror messages
@@ -245,6 +336,36 @@ func (m mirrorMessage) JSON() string {
 	return string(mirrorMessageBytes)
 }
 
+// mirrorSummaryMessage container for the summary of a fake mirror
+type mirrorSummaryMessage struct {
+	Status      string `json:"status"`
+	CopyCount   int64  `json:"copyCount"`
+	CopySize    int64  `json:"copySize"`
+	RemoveCount int64  `json:"removeCount"`
+	RemoveSize  int64  `json:"removeSize"`
+	BackupDir   string `json:"backupDir,omitempty"`
+}
+
+// String colorized mirror summary message
+func (m mirrorSummaryMessage) String() string {
+	msg := fmt.Sprintf("Would copy %d object(s) (%s)", m.CopyCount, humanize.IBytes(uint64(m.CopySize)))
+	if m.BackupDir != "" {
+		msg += fmt.Sprintf(" and move %d object(s) (%s) to `%s`.", m.RemoveCount, humanize.IBytes(uint64(m.RemoveSize)), m.BackupDir)
+	} else {
+		msg += fmt.Sprintf(" and remove %d object(s) (%s).", m.RemoveCount, humanize.IBytes(uint64(m.RemoveSize)))
+	}
+	return console.Colorize("Mirror", msg)
+}
+
+// JSON jsonified mirror summary message
+func (m mirrorSummaryMessage) JSON() string {
+	m.Status = "success"
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(msgBytes)
+}
+
 func (mj *mirrorJob) doCreateBucket(ctx context.Context, sURLs URLs) URLs {
 	if mj.opts.isFake {
 		return sURLs.WithError(nil)
@@ -288,36 +409,67 @@ func (mj *mirrorJob) doDeleteBucket(ctx context.Context, sURLs URLs) URLs {
 	return sURLs.WithError(nil)
 }
 
-// doRemove - removes files on target.
+// doBackup - copies an object about to be removed from target to the
+// backup folder, at the same path relative to target.
+func (mj *mirrorJob) doBackup(ctx context.Context, sURLs URLs) *probe.Error {
+	_, targetURL, _ := mustExpandAlias(mj.targetURL)
+	separator := string(sURLs.TargetContent.URL.Separator)
+	targetSuffix := strings.TrimPrefix(sURLs.TargetContent.URL.String(), strings.TrimSuffix(targetURL, separator)+separator)
+
+	backupAlias, backupURL, _ := mustExpandAlias(mj.opts.backupDir)
+	urls := uploadSourceToTargetURL(ctx, URLs{
+		SourceAlias:   sURLs.TargetAlias,
+		SourceContent: sURLs.TargetContent,
+		TargetAlias:   backupAlias,
+		TargetContent: &ClientContent{
+			URL:          *newClientURL(urlJoinPath(backupURL, targetSuffix)),
+			Metadata:     map[string]string{},
+			UserMetadata: map[string]string{},
+		},
+		Retry: mj.opts.retry,
+	}, nil, mj.opts.encKeyDB, false)
+	return urls.Error
+}
+
+// doRemove - removes files on target, after copying them to the backup
+// folder if any.
 func (mj *mirrorJob) doRemove(ctx context.Context, sURLs URLs) URLs {
 	if mj.opts.isFake {
+		atomic.AddInt64(&mj.removeCount, 1)
+		atomic.AddInt64(&mj.removeSize, sURLs.TargetContent.Size)
 		return sURLs.WithError(nil)
 	}
 
-	// Construct proper path with alias.
-	targetWithAlias := filepath.Join(sURLs.TargetAlias, sURLs.TargetContent.URL.Path)
-	clnt, pErr := newClient(targetWithAlias)
+	clnt, pErr := newClientFromAlias(sURLs.TargetAlias, sURLs.TargetContent.URL.String())
 	if pErr != nil {
 		return sURLs.WithError(pErr)
 	}
+	if mj.opts.backupDir != "" {
+		if pErr = mj.doBackup(ctx, sURLs); pErr != nil {
+			return sURLs.WithError(pErr.Trace(mj.opts.backupDir))
+		}
+	}
 	clnt.AddUserAgent(uaMirrorAppName, ReleaseTag)
-	contentCh := make(chan *ClientContent, 1)
-	contentCh <- &ClientContent{URL: *newClientURL(sURLs.TargetContent.URL.Path)}
-	close(contentCh)
-	isRemoveBucket := false
-	errorCh := clnt.Remove(ctx, false, isRemoveBucket, false, contentCh)
-	for pErr := range errorCh {
-		if pErr != nil {
-			switch pErr.ToGoError().(type) {
-			case PathInsufficientPermission:
-				// Ignore Permission error.
-				continue
+	pErr = mj.opts.retry.run(ctx, sURLs.TargetContent.URL.String(), func() *probe.Error {
+		contentCh := make(chan *ClientContent, 1)
+		contentCh <- &ClientContent{URL: *newClientURL(sURLs.TargetContent.URL.Path)}
+		close(contentCh)
+		isRemoveBucket := false
+		errorCh := clnt.Remove(ctx, false, isRemoveBucket, false, contentCh)
+		for pErr := range errorCh {
+			if pErr != nil {
+				switch pErr.ToGoError().(type) {
+				case PathInsufficientPermission:
+					// Ignore Permission error.
+					continue
+				}
+				return pErr
 			}
-			return sURLs.WithError(pErr)
 		}
-	}
+		return nil
+	})
 
-	return sURLs.WithError(nil)
+	return sURLs.WithError(pErr)
 }
 
 // doMirror - Mirror an object to multiple destination. URLs status contains a copy of sURLs and error if any.
@@ -362,6 +514,8 @@ func (mj *mirrorJob) doMirror(ctx context.Context, sURLs URLs) URLs {
 	if mj.opts.isFake {
 		if sURLs.SourceContent != nil {
 			mj.status.Add(sURLs.SourceContent.Size)
+			atomic.AddInt64(&mj.copyCount, 1)
+			atomic.AddInt64(&mj.copySize, sURLs.SourceContent.Size)
 		}
 		mj.status.Update()
 		return sURLs.WithError(nil)
@@ -407,6 +561,8 @@ func (mj *mirrorJob) doMirror(ctx context.Context, sURLs URLs) URLs {
 	})
 	sURLs.MD5 = mj.opts.md5
 	sURLs.DisableMultipart = mj.opts.disableMultipart
+	sURLs.Compress = mj.opts.compress
+	sURLs.Retry = mj.opts.retry
 	return uploadSourceToTargetURL(ctx, sURLs, mj.status, mj.opts.encKeyDB, mj.opts.isMetadata)
 }
 
@@ -423,12 +579,15 @@ func (mj *mirrorJob) monitorMirrorStatus() (errDuringMirror bool) {
 				if !isErrIgnored(sURLs.Error) {
 					errorIf(sURLs.Error.Trace(sURLs.SourceContent.URL.String()),
 						fmt.Sprintf("Failed to copy `%s`.", sURLs.SourceContent.URL.String()))
+					mj.failed.addCopy(sURLs, mj.opts.isMetadata, false)
 					errDuringMirror = true
 				}
 			case sURLs.TargetContent != nil:
 				// When sURLs.SourceContent is nil, we know that we have an error related to removing
 				errorIf(sURLs.Error.Trace(sURLs.TargetContent.URL.String()),
 					fmt.Sprintf("Failed to remove `%s`.", sURLs.TargetContent.URL.String()))
+				targetPath := filepath.ToSlash(filepath.Join(sURLs.TargetAlias, sURLs.TargetContent.URL.Path))
+				mj.failed.addRemove(targetPath, "", false, false, sURLs.Error)
 				errDuringMirror = true
 			default:
 				if sURLs.ErrorCond == differInUnknown {
@@ -445,6 +604,10 @@ func (mj *mirrorJob) monitorMirrorStatus() (errDuringMirror bool) {
 			}
 		}
 
+		if sURLs.Error == nil && mj.session != nil {
+			errorIf(mj.session.done(sURLs), "Unable to save mirror session.")
+		}
+
 		if sURLs.SourceContent != nil {
 		} else if sURLs.TargetContent != nil {
 			// Construct user facing message and path.
@@ -486,8 +649,8 @@ func (mj *mirrorJob) watchMirrorEvents(ctx context.Context, events []EventInfo)
 		// build target path, it is the relative of the eventPath with the sourceUrl
 		// joined to the targetURL.
 		sourceSuffix := strings.TrimPrefix(eventPath, sourceURLFull)
-		//Skip the object, if it matches the Exclude options provided
-		if matchExcludeOptions(mj.opts.excludeOptions, sourceSuffix) {
+		//Skip the object, if the filter rules exclude it
+		if !mj.opts.filter.matchPath(sourceSuffix) {
 			continue
 		}
 
@@ -514,6 +677,7 @@ func (mj *mirrorJob) watchMirrorEvents(ctx context.Context, events []EventInfo)
 				TargetContent:    &ClientContent{URL: *targetURL},
 				MD5:              mj.opts.md5,
 				DisableMultipart: mj.opts.disableMultipart,
+				Compress:         mj.opts.compress,
 				encKeyDB:         mj.opts.encKeyDB,
 			}
 			if mj.opts.activeActive &&
@@ -524,6 +688,9 @@ func (mj *mirrorJob) watchMirrorEvents(ctx context.Context, events []EventInfo)
 				// to avoid copying it.
 				continue
 			}
+			if !mj.opts.filter.matchContent(ctx, sourceAlias, mirrorURL.SourceContent) {
+				continue
+			}
 			mj.parallel.queueTask(func() URLs {
 				return mj.doMirrorWatch(ctx, targetPath, tgtSSE, mirrorURL)
 			})
@@ -613,6 +780,11 @@ func (mj *mirrorJob) startMirror(ctx context.Context, cancelMirror context.Cance
 	mj.m.Lock()
 	defer mj.m.Unlock()
 
+	// URLs queued but never completed by a resumed session go first.
+	for _, sURLs := range mj.pending {
+		mj.queueURLs(ctx, sURLs)
+	}
+
 	URLsCh := prepareMirrorURLs(ctx, mj.sourceURL, mj.targetURL, mj.opts)
 
 	for {
@@ -627,36 +799,22 @@ func (mj *mirrorJob) startMirror(ctx context.Context, cancelMirror context.Cance
 				continue
 			}
 
-			if sURLs.SourceContent != nil {
-				if isOlder(sURLs.SourceContent.Time, mj.opts.olderThan) {
+			if sURLs.SourceContent != nil && !mj.opts.filter.matchContent(ctx, sURLs.SourceAlias, sURLs.SourceContent) {
+				continue
+			}
+
+			if mj.session != nil && (sURLs.SourceContent != nil || mj.opts.isRemove) {
+				queued, err := mj.session.queue(sURLs)
+				if err != nil {
+					mj.statusCh <- sURLs.WithError(err)
 					continue
 				}
-				if isNewer(sURLs.SourceContent.Time, mj.opts.newerThan) {
+				if !queued {
 					continue
 				}
 			}
 
-			if sURLs.SourceContent != nil {
-				mj.status.Add(sURLs.SourceContent.Size)
-			}
-
-			mj.status.SetTotal(mj.status.Get()).Update()
-			mj.status.AddCounts(1)
-
-			// Save total count.
-			sURLs.TotalCount = mj.status.GetCounts()
-			// Save totalSize.
-			sURLs.TotalSize = mj.status.Get()
-
-			if sURLs.SourceContent != nil {
-				mj.parallel.queueTask(func() URLs {
-					return mj.doMirror(ctx, sURLs)
-				})
-			} else if sURLs.TargetContent != nil && mj.opts.isRemove {
-				mj.parallel.queueTask(func() URLs {
-					return mj.doRemove(ctx, sURLs)
-				})
-			}
+			mj.queueURLs(ctx, sURLs)
 		case <-globalContext.Done():
 			stopParallel()
 			return
@@ -667,6 +825,31 @@ func (mj *mirrorJob) startMirror(ctx context.Context, cancelMirror context.Cance
 	}
 }
 
+// queueURLs - queues the copy or removal of sURLs.
+func (mj *mirrorJob) queueURLs(ctx context.Context, sURLs URLs) {
+	if sURLs.SourceContent != nil {
+		mj.status.Add(sURLs.SourceContent.Size)
+	}
+
+	mj.status.SetTotal(mj.status.Get()).Update()
+	mj.status.AddCounts(1)
+
+	// Save total count.
+	sURLs.TotalCount = mj.status.GetCounts()
+	// Save totalSize.
+	sURLs.TotalSize = mj.status.Get()
+
+	if sURLs.SourceContent != nil {
+		mj.parallel.queueTask(func() URLs {
+			return mj.doMirror(ctx, sURLs)
+		})
+	} else if sURLs.TargetContent != nil && mj.opts.isRemove {
+		mj.parallel.queueTask(func() URLs {
+			return mj.doRemove(ctx, sURLs)
+		})
+	}
+}
+
 // when using a struct for copying, we could save a lot of passing of variables
 func (mj *mirrorJob) mirror(ctx context.Context, cancelMirror context.CancelFunc) bool {
 
@@ -719,7 +902,8 @@ func newMirrorJob(srcURL, dstURL string, opts mirrorOptions) *mirrorJob {
 		watcher:   NewWatcher(UTCNow()),
 	}
 
-	mj.parallel = newParallelManager(mj.statusCh)
+	mj.parallel = newParallelManager(mj.statusCh, opts.workers)
+	mj.opts.retry.throttled = mj.parallel.throttled
 
 	// we'll define the status to use here,
 	// do we want the quiet status? or the progressbar
@@ -786,7 +970,7 @@ func getEventPathURLWin(srcURL, eventPath string) string {
 }
 
 // runMirror - mirrors all buckets to another S3 server
-func runMirror(ctx context.Context, cancelMirror context.CancelFunc, srcURL, dstURL string, cli *cli.Context, encKeyDB map[string][]prefixSSEPair) bool {
+func runMirror(ctx context.Context, cancelMirror context.CancelFunc, srcURL, dstURL string, cli *cli.Context, encKeyDB map[string][]prefixSSEPair, timeRef time.Time, session *sessionV8) bool {
 	// Parse metadata.
 	userMetadata := make(map[string]string)
 	if cli.String("attr") != "" {
@@ -795,6 +979,23 @@ func runMirror(ctx context.Context, cancelMirror context.CancelFunc, srcURL, dst
 		fatalIf(err, "Unable to parse attribute %v", cli.String("attr"))
 	}
 
+	// Objects are mirrored as entries of the archive being streamed to target.
+	var archive *archiveWriter
+	if cli.Bool("to-archive") {
+		if cli.String("failed-log") != "" {
+			fatalIf(errInvalidArgument().Trace(cli.Args()...), "`--failed-log` cannot be used with `--to-archive`.")
+		}
+		var err *probe.Error
+		archive, err = openArchiveWriter(ctx, dstURL)
+		fatalIf(err, "Unable to initialize archive `"+dstURL+"`.")
+		dstURL = archiveRootURL(dstURL)
+	}
+
+	// Entries of a tar archive are mirrored in a single pass.
+	if extractor, err := openArchiveExtractor(ctx, srcURL); err == nil && extractor != nil {
+		defer extractor.Close()
+	}
+
 	srcClt, err := newClient(srcURL)
 	fatalIf(err, "Unable to initialize `"+srcURL+"`.")
 
@@ -810,6 +1011,9 @@ func runMirror(ctx context.Context, cancelMirror context.CancelFunc, srcURL, dst
 	isWatch := cli.Bool("watch") || cli.Bool("multi-master") || cli.Bool("active-active")
 	isRemove := cli.Bool("remove")
 
+	maxDelete, err := parseMaxDelete(cli.String("max-delete"))
+	fatalIf(err, "Unable to parse `--max-delete`.")
+
 	// preserve is also expected to be overwritten if necessary
 	isMetadata := cli.Bool("a") || isWatch || len(userMetadata) > 0
 	isOverwrite = isOverwrite || isMetadata
@@ -820,19 +1024,36 @@ func runMirror(ctx context.Context, cancelMirror context.CancelFunc, srcURL, dst
 		isOverwrite:      isOverwrite,
 		isWatch:          isWatch,
 		isMetadata:       isMetadata,
+		isChecksum:       cli.Bool("checksum"),
+		workers:          workerOptionsFromContext(cli),
+		retry:            retryPolicyFromContext(cli),
 		md5:              cli.Bool("md5"),
 		disableMultipart: cli.Bool("disable-multipart"),
-		excludeOptions:   cli.StringSlice("exclude"),
-		olderThan:        cli.String("older-than"),
-		newerThan:        cli.String("newer-than"),
+		compress:         cli.String("compress"),
+		filter:           newObjectFilterFromContext(cli),
+		timeRef:          timeRef,
+		maxDelete:        maxDelete,
+		backupDir:        cli.String("backup-dir"),
 		storageClass:     cli.String("storage-class"),
 		userMetadata:     userMetadata,
 		encKeyDB:         encKeyDB,
 		activeActive:     isWatch,
 	}
 
+	if session != nil {
+		mopts.diffCursor = session.Header.DiffCursor
+	}
+
 	// Create a new mirror job and execute it
 	mj := newMirrorJob(srcURL, dstURL, mopts)
+	mj.failed = openFailedLog(cli, "mirror")
+	defer mj.failed.Close()
+
+	if session != nil {
+		var err *probe.Error
+		mj.session, mj.pending, err = newMirrorSession(session)
+		fatalIf(err, "Unable to load mirror session.")
+	}
 
 	preserve := cli.Bool("preserve")
 
@@ -919,7 +1140,24 @@ func runMirror(ctx context.Context, cancelMirror context.CancelFunc, srcURL, dst
 		}
 	}
 
-	return mj.mirror(ctx, cancelMirror)
+	errorDetected := mj.mirror(ctx, cancelMirror)
+	if mj.opts.isFake {
+		printMsg(mirrorSummaryMessage{
+			CopyCount:   mj.copyCount,
+			CopySize:    mj.copySize,
+			RemoveCount: mj.removeCount,
+			RemoveSize:  mj.removeSize,
+			BackupDir:   mj.opts.backupDir,
+		})
+	}
+	if archive != nil {
+		// Any failed copy discards the whole archive.
+		if err := archive.Close(errorDetected || ctx.Err() != nil); err != nil {
+			errorIf(err, "Unable to write archive `"+cli.Args()[1]+"`.")
+			return true
+		}
+	}
+	return errorDetected
 }
 
 // Main entry point for mirror command.
@@ -937,17 +1175,67 @@ func mainMirror(cliCtx *cli.Context) error {
 	// check 'mirror' cli arguments.
 	srcURL, tgtURL := checkMirrorSyntax(ctx, cliCtx, encKeyDB)
 
+	if cliCtx.Bool("versions") {
+		if runMirrorVersions(ctx, srcURL, tgtURL, cliCtx, encKeyDB) {
+			return exitStatus(globalErrorExitStatus)
+		}
+		return nil
+	}
+
+	// A snapshot is taken when mirroring starts, sessions resume
+	// mirroring the same snapshot.
+	timeRef := parseRewindFlag(cliCtx.String("rewind"))
+	if cliCtx.Bool("snapshot-now") {
+		timeRef = UTCNow()
+	}
+
+	var session *sessionV8
+	if cliCtx.Bool("continue") {
+		sessionID := getHash("mirror", cliCtx.Args())
+		if isSessionExists(sessionID) {
+			session, err = loadSessionV8(sessionID)
+			fatalIf(err.Trace(sessionID), "Unable to load session.")
+			if rewind := session.Header.CommandStringFlags["rewind"]; rewind != "" {
+				timeRef = parseRewindFlag(rewind)
+			}
+		} else {
+			session = newSessionV8(sessionID)
+			session.Header.CommandType = "mirror"
+			session.setCommandFlags(cliCtx, append(append(append(append(mirrorFlags, filterFlags...), ioFlags...), workerFlags...), retryFlags...))
+			if !timeRef.IsZero() {
+				session.Header.CommandStringFlags["rewind"] = timeRef.Format(time.RFC3339Nano)
+				delete(session.Header.CommandBoolFlags, "snapshot-now")
+			}
+
+			var e error
+			if session.Header.RootPath, e = os.Getwd(); e != nil {
+				session.Delete()
+				fatalIf(probe.NewError(e), "Unable to get current working folder.")
+			}
+
+			// extract URLs.
+			session.Header.CommandArgs = cliCtx.Args()
+		}
+	}
+
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	for {
 		select {
 		case <-ctx.Done():
 			return exitStatus(globalErrorExitStatus)
 		default:
-			errorDetected := runMirror(ctx, cancelMirror, srcURL, tgtURL, cliCtx, encKeyDB)
+			errorDetected := runMirror(ctx, cancelMirror, srcURL, tgtURL, cliCtx, encKeyDB, timeRef, session)
 			if cliCtx.Bool("multi-master") || cliCtx.Bool("active-active") {
 				time.Sleep(time.Duration(r.Float64() * float64(2*time.Second)))
 				continue
 			}
+			if session != nil {
+				// Failed URLs are retried on resume.
+				if errorDetected || globalContext.Err() != nil {
+					session.CloseAndDie()
+				}
+				session.Delete()
+			}
 			if errorDetected {
 				return exitStatus(globalErrorExitStatus)
 			}
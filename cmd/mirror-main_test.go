@@ -0,0 +1,199 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// TestMemMirrorRewind - tests mirroring a versioned bucket as it was
+// at a point in time.
+func (s *TestSuite) TestMemMirrorRewind(c *C) {
+	defer func(quiet bool) { globalQuiet = quiet }(globalQuiet)
+	globalQuiet = true
+
+	for _, bucket := range []string{"mem://mem-rewind-src", "mem://mem-rewind-dst"} {
+		clnt, err := newClient(bucket)
+		c.Assert(err, IsNil)
+		c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+		c.Assert(clnt.SetVersion(context.Background(), "enable"), IsNil)
+	}
+	memPut(c, "mem://mem-rewind-src/object", "v1")
+	time.Sleep(10 * time.Millisecond)
+	rewind := time.Now().UTC()
+	time.Sleep(10 * time.Millisecond)
+	memPut(c, "mem://mem-rewind-src/object", "version 2")
+	memPut(c, "mem://mem-rewind-src/later", "data")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mj := newMirrorJob("mem://mem-rewind-src", "mem://mem-rewind-dst", mirrorOptions{timeRef: rewind})
+	c.Assert(mj.mirror(ctx, cancel), Equals, false)
+
+	contents := memList(c, "mem://mem-rewind-dst/", ListOptions{Recursive: true})
+	c.Assert(contents, HasLen, 1)
+	c.Assert(contents[0].URL.String(), Equals, "mem://mem-rewind-dst/object")
+	c.Assert(contents[0].Size, Equals, int64(2))
+}
+
+// TestMemMirrorMaxDelete - tests removals past `--max-delete` are
+// aborted and removed objects are moved to the backup folder.
+func (s *TestSuite) TestMemMirrorMaxDelete(c *C) {
+	defer func(quiet bool) { globalQuiet = quiet }(globalQuiet)
+	globalQuiet = true
+
+	for _, bucket := range []string{"mem://mem-maxdel-src", "mem://mem-maxdel-dst", "mem://mem-maxdel-trash"} {
+		clnt, err := newClient(bucket)
+		c.Assert(err, IsNil)
+		c.Assert(clnt.MakeBucket(context.Background(), "", false, false), IsNil)
+	}
+	memPut(c, "mem://mem-maxdel-src/kept", "data")
+	memPut(c, "mem://mem-maxdel-dst/kept", "data")
+	for _, object := range []string{"a", "b", "dir/c"} {
+		memPut(c, "mem://mem-maxdel-dst/"+object, "stale")
+	}
+
+	mirror := func(opts mirrorOptions) (*mirrorJob, bool) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		mj := newMirrorJob("mem://mem-maxdel-src", "mem://mem-maxdel-dst", opts)
+		return mj, mj.mirror(ctx, cancel)
+	}
+
+	// 3 removals out of 4 objects.
+	maxDelete, err := parseMaxDelete("50%")
+	c.Assert(err, IsNil)
+	_, errorDetected := mirror(mirrorOptions{isRemove: true, maxDelete: maxDelete})
+	c.Assert(errorDetected, Equals, true)
+	c.Assert(memList(c, "mem://mem-maxdel-dst/", ListOptions{Recursive: true}), HasLen, 4)
+
+	mj, errorDetected := mirror(mirrorOptions{isRemove: true, isFake: true})
+	c.Assert(errorDetected, Equals, false)
+	c.Assert(mj.removeCount, Equals, int64(3))
+	c.Assert(mj.removeSize, Equals, int64(15))
+	c.Assert(mj.copyCount, Equals, int64(0))
+
+	maxDelete, err = parseMaxDelete("3")
+	c.Assert(err, IsNil)
+	_, errorDetected = mirror(mirrorOptions{isRemove: true, maxDelete: maxDelete, backupDir: "mem://mem-maxdel-trash/dst"})
+	c.Assert(errorDetected, Equals, false)
+	c.Assert(memList(c, "mem://mem-maxdel-dst/", ListOptions{Recursive: true}), HasLen, 1)
+	var trashed []string
+	for _, content := range memList(c, "mem://mem-maxdel-trash/", ListOptions{Recursive: true}) {
+		trashed = append(trashed, content.URL.String())
+	}
+	c.Assert(trashed, DeepEquals, []string{
+		"mem://mem-maxdel-trash/dst/a",
+		"mem://mem-maxdel-trash/dst/b",
+		"mem://mem-maxdel-trash/dst/dir/c",
+	})
+}
+
+// TestParseMaxDelete - tests `--max-delete` limits.
+func (s *TestSuite) TestParseMaxDelete(c *C) {
+	testCases := []struct {
+		value           string
+		removals, total int64
+		exceeded        bool
+	}{
+		{"10", 10, 100, false},
+		{"10", 11, 100, true},
+		{"0", 1, 100, true},
+		{"10%", 10, 100, false},
+		{"10%", 11, 100, true},
+		{"2.5%", 1, 40, false},
+		{"2.5%", 2, 40, true},
+	}
+	for i, testCase := range testCases {
+		limit, err := parseMaxDelete(testCase.value)
+		c.Assert(err, IsNil)
+		c.Assert(limit.String(), Equals, testCase.value)
+		c.Assert(limit.exceeded(testCase.removals, testCase.total), Equals, testCase.exceeded, Commentf("Test %d", i+1))
+	}
+	for _, value := range []string{"-1", "ten", "101%", "%"} {
+		_, err := parseMaxDelete(value)
+		c.Assert(err, NotNil, Commentf("%s", value))
+	}
+	limit, err := parseMaxDelete("")
+	c.Assert(err, IsNil)
+	c.Assert(limit.exceeded(100, 100), Equals, false)
+}
+
+// TestMemMirrorVersions - tests versions and delete markers are
+// replayed in order, once.
+func (s *TestSuite) TestMemMirrorVersions(c *C) {
+	defer func(quiet bool) { globalQuiet = quiet }(globalQuiet)
+	globalQuiet = true
+
+	ctx := context.Background()
+	for _, bucket := range []string{"mem://mem-versions-src", "mem://mem-versions-dst"} {
+		clnt, err := newClient(bucket)
+		c.Assert(err, IsNil)
+		c.Assert(clnt.MakeBucket(ctx, "", false, false), IsNil)
+		c.Assert(clnt.SetVersion(ctx, "enable"), IsNil)
+	}
+	c.Assert(checkVersionedTarget(ctx, "mem://mem-versions-dst"), IsNil)
+
+	memPut(c, "mem://mem-versions-src/object", "v1")
+	memPut(c, "mem://mem-versions-src/object", "v22")
+	objClnt, err := newClient("mem://mem-versions-src/object")
+	c.Assert(err, IsNil)
+	contentCh := make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: objClnt.GetURL()}
+	close(contentCh)
+	for err := range objClnt.Remove(ctx, false, false, false, contentCh) {
+		c.Assert(err, IsNil)
+	}
+	memPut(c, "mem://mem-versions-src/dir/other", "data")
+	memPut(c, "mem://mem-versions-src/object2", "data")
+
+	filename := filepath.Join(c.MkDir(), "versions.jsonl")
+	for i := 0; i < 2; i++ {
+		versionMap, err := openVersionMap(filename)
+		c.Assert(err, IsNil)
+		j := newMirrorVersionsJob("mem://mem-versions-src", "mem://mem-versions-dst", mirrorOptions{}, versionMap)
+		c.Assert(j.run(ctx), HasLen, 0)
+		c.Assert(versionMap.mirrored, HasLen, 5)
+		c.Assert(versionMap.Close(), IsNil)
+	}
+
+	contents := memList(c, "mem://mem-versions-dst/", ListOptions{Recursive: true, WithOlderVersions: true, WithDeleteMarkers: true})
+	c.Assert(contents, HasLen, 5)
+	c.Assert(contents[0].URL.String(), Equals, "mem://mem-versions-dst/dir/other")
+	c.Assert(contents[1].IsDeleteMarker, Equals, true)
+	c.Assert(contents[2].Size, Equals, int64(3))
+	c.Assert(contents[3].Size, Equals, int64(2))
+
+	c.Assert(contents[4].URL.String(), Equals, "mem://mem-versions-dst/object2")
+
+	// Each version, the delete marker included, maps to the one
+	// replayed on target, versions of other keys left aside.
+	versionMap, err := openVersionMap(filename)
+	c.Assert(err, IsNil)
+	defer versionMap.Close()
+	srcContents := memList(c, "mem://mem-versions-src/object", ListOptions{WithOlderVersions: true, WithDeleteMarkers: true})
+	// The listing of object is a prefix one, object2 comes last.
+	c.Assert(srcContents, HasLen, 4)
+	c.Assert(srcContents[0].IsDeleteMarker, Equals, true)
+	for i, srcContent := range srcContents[:3] {
+		c.Assert(versionMap.mirrored[versionMapKey{"object", srcContent.VersionID}], Equals, contents[i+1].VersionID)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/minio/mc/pkg/probe"
+	"golang.org/x/text/unicode/norm"
+)
+
+// mirrorSession - checkpoints a mirror into its session. URLs are
+// recorded in the session data file when queued and again once done,
+// the diff cursor being the target of the last queued one. A resumed
+// mirror replays the URLs queued but not done, then only diffs what
+// sorts after the cursor.
+type mirrorSession struct {
+	mutex   sync.Mutex
+	session *sessionV8
+	dataFP  io.Writer
+
+	// URLs replayed from the session data file, by key.
+	pending map[string]bool
+}
+
+// mirrorSessionEntry - a line of the mirror session data file.
+type mirrorSessionEntry struct {
+	Queued *URLs  `json:"queued,omitempty"`
+	Done   string `json:"done,omitempty"`
+}
+
+// mirrorSessionKey - returns the key identifying a copy or a removal.
+func mirrorSessionKey(sURLs URLs) string {
+	if sURLs.SourceContent != nil {
+		return "copy:" + sURLs.SourceContent.URL.String()
+	}
+	if sURLs.TargetContent != nil {
+		return "remove:" + sURLs.TargetContent.URL.String()
+	}
+	return ""
+}
+
+// newMirrorSession - returns the checkpointer of session along with
+// the URLs it queued but never completed, in queue order.
+func newMirrorSession(session *sessionV8) (*mirrorSession, []URLs, *probe.Error) {
+	var jsoniter = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	var queued []URLs
+	done := make(map[string]bool)
+	urlScanner := bufio.NewScanner(session.NewDataReader())
+	for urlScanner.Scan() {
+		var entry mirrorSessionEntry
+		if e := jsoniter.Unmarshal(urlScanner.Bytes(), &entry); e != nil {
+			return nil, nil, probe.NewError(e).Trace(session.SessionID)
+		}
+		if entry.Queued != nil {
+			queued = append(queued, *entry.Queued)
+		} else {
+			done[entry.Done] = true
+		}
+	}
+	if e := urlScanner.Err(); e != nil {
+		return nil, nil, probe.NewError(e).Trace(session.SessionID)
+	}
+
+	ms := &mirrorSession{
+		session: session,
+		pending: make(map[string]bool),
+	}
+	var pending []URLs
+	for _, sURLs := range queued {
+		key := mirrorSessionKey(sURLs)
+		if done[key] || ms.pending[key] {
+			continue
+		}
+		ms.pending[key] = true
+		pending = append(pending, sURLs)
+	}
+
+	dataFP, err := session.NewDataAppender()
+	if err != nil {
+		return nil, nil, err.Trace(session.SessionID)
+	}
+	ms.dataFP = dataFP
+	return ms, pending, nil
+}
+
+// write - appends entry to the session data file.
+func (ms *mirrorSession) write(entry mirrorSessionEntry) *probe.Error {
+	var jsoniter = jsoniter.ConfigCompatibleWithStandardLibrary
+	data, e := jsoniter.Marshal(entry)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if _, e = ms.dataFP.Write(append(data, '\n')); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// queue - records sURLs as queued and advances the diff cursor past
+// it, returns false for URLs already replayed from the session.
+func (ms *mirrorSession) queue(sURLs URLs) (bool, *probe.Error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if ms.pending[mirrorSessionKey(sURLs)] {
+		return false, nil
+	}
+	if err := ms.write(mirrorSessionEntry{Queued: &sURLs}); err != nil {
+		return false, err.Trace(ms.session.SessionID)
+	}
+	if sURLs.TargetContent != nil {
+		ms.session.Header.DiffCursor = sURLs.TargetContent.URL.String()
+	}
+	return true, nil
+}
+
+// done - records sURLs as completed and saves the session.
+func (ms *mirrorSession) done(sURLs URLs) *probe.Error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if err := ms.write(mirrorSessionEntry{Done: mirrorSessionKey(sURLs)}); err != nil {
+		return err.Trace(ms.session.SessionID)
+	}
+	return ms.session.Save().Trace(ms.session.SessionID)
+}
+
+// isBeforeDiffCursor - returns true if the target URL of a diff sorts
+// at or before cursor, i.e. was queued by the resumed session.
+func isBeforeDiffCursor(targetURL, cursor string) bool {
+	return cursor != "" && norm.NFC.String(targetURL) <= norm.NFC.String(cursor)
+}
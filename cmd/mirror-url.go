@@ -21,11 +21,12 @@ import (
 	"fmt"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/minio/cli"
-	"github.com/minio/minio/pkg/wildcard"
+	"github.com/minio/mc/pkg/probe"
 )
 
 //
@@ -50,6 +51,77 @@ func checkMirrorSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[st
 		errorIf(errInvalidArgument().Trace(URLs...), "`--force` is deprecated, please use `--overwrite` instead for the same functionality.")
 	}
 
+	checkCompressFlag(cliCtx.String("compress"))
+
+	if cliCtx.Bool("to-archive") {
+		if cliCtx.Bool("watch") || cliCtx.Bool("active-active") || cliCtx.Bool("multi-master") || cliCtx.Bool("remove") {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--to-archive` cannot be used with `--watch`, `--active-active` or `--remove`.")
+		}
+		if cliCtx.String("compress") != "" {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--compress` cannot be used with `--to-archive`.")
+		}
+		if !isArchiveWriteTarget(tgtURL) {
+			fatalIf(errInvalidArchiveTarget(tgtURL).Trace(tgtURL), "Unable to validate target `"+tgtURL+"`.")
+		}
+	}
+
+	if cliCtx.String("rewind") != "" || cliCtx.Bool("snapshot-now") {
+		if cliCtx.String("rewind") != "" && cliCtx.Bool("snapshot-now") {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--rewind` cannot be used with `--snapshot-now`.")
+		}
+		if cliCtx.Bool("watch") || cliCtx.Bool("active-active") || cliCtx.Bool("multi-master") {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--rewind` and `--snapshot-now` cannot be used with `--watch` or `--active-active`.")
+		}
+		if _, expandedSourcePath, _ := mustExpandAlias(srcURL); newClientURL(expandedSourcePath).Type == fileSystem {
+			fatalIf(errInvalidArgument().Trace(srcURL), "`--rewind` and `--snapshot-now` need a versioned object storage source.")
+		}
+	}
+
+	if cliCtx.Bool("versions") {
+		for _, flag := range []string{"watch", "active-active", "multi-master", "to-archive", "continue", "remove", "overwrite", "rewind", "snapshot-now"} {
+			if cliCtx.IsSet(flag) {
+				fatalIf(errInvalidArgument().Trace(URLs...), "`--versions` cannot be used with `--"+flag+"`.")
+			}
+		}
+		for _, urlStr := range URLs {
+			if _, expandedPath, _ := mustExpandAlias(urlStr); newClientURL(expandedPath).Type == fileSystem {
+				fatalIf(errInvalidArgument().Trace(urlStr), "`--versions` needs versioned object storage source and target.")
+			}
+		}
+	} else if cliCtx.String("version-map") != "" {
+		fatalIf(errInvalidArgument().Trace(URLs...), "`--version-map` can only be used with `--versions`.")
+	}
+
+	if cliCtx.String("max-delete") != "" || cliCtx.String("backup-dir") != "" {
+		if !cliCtx.Bool("remove") {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--max-delete` and `--backup-dir` can only be used with `--remove`.")
+		}
+	}
+
+	if cliCtx.String("max-delete") != "" {
+		if cliCtx.Bool("watch") || cliCtx.Bool("active-active") || cliCtx.Bool("multi-master") {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--max-delete` cannot be used with `--watch` or `--active-active`.")
+		}
+		_, err := parseMaxDelete(cliCtx.String("max-delete"))
+		fatalIf(err, "Unable to parse `--max-delete`.")
+	}
+
+	if backupDir := cliCtx.String("backup-dir"); backupDir != "" {
+		_, expandedBackupPath, _ := mustExpandAlias(backupDir)
+		for _, urlStr := range URLs {
+			_, expandedPath, _ := mustExpandAlias(urlStr)
+			if isURLInside(expandedPath, expandedBackupPath) {
+				fatalIf(errInvalidArgument().Trace(backupDir, urlStr), "`--backup-dir` cannot be inside `"+urlStr+"`.")
+			}
+		}
+	}
+
+	if cliCtx.Bool("continue") {
+		if cliCtx.Bool("watch") || cliCtx.Bool("active-active") || cliCtx.Bool("multi-master") || cliCtx.Bool("to-archive") {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--continue` cannot be used with `--watch`, `--active-active` or `--to-archive`.")
+		}
+	}
+
 	_, expandedSourcePath, _ := mustExpandAlias(srcURL)
 	srcClient := newClientURL(expandedSourcePath)
 	_, expandedTargetPath, _ := mustExpandAlias(tgtURL)
@@ -88,13 +160,12 @@ func checkMirrorSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[st
 	return
 }
 
-func matchExcludeOptions(excludeOptions []string, srcSuffix string) bool {
-	for _, pattern := range excludeOptions {
-		if wildcard.Match(pattern, srcSuffix) {
-			return true
-		}
-	}
-	return false
+// isURLInside - returns true if urlStr is the folder dir or inside it.
+func isURLInside(dir, urlStr string) bool {
+	separator := string(newClientURL(dir).Separator)
+	dir = strings.TrimSuffix(dir, separator)
+	urlStr = strings.TrimSuffix(urlStr, separator)
+	return urlStr == dir || strings.HasPrefix(urlStr, dir+separator)
 }
 
 func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mirrorOptions, URLsCh chan<- URLs) {
@@ -114,44 +185,82 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 
 	defer close(URLsCh)
 
-	sourceClnt, err := newClientFromAlias(sourceAlias, sourceURL)
+	sourceClnt, err := newClientFromAliasWithKeys(sourceAlias, sourceURL, opts.encKeyDB)
 	if err != nil {
 		URLsCh <- URLs{Error: err.Trace(sourceAlias, sourceURL)}
 		return
 	}
 
-	targetClnt, err := newClientFromAlias(targetAlias, targetURL)
+	targetClnt, err := newClientFromAliasWithKeys(targetAlias, targetURL, opts.encKeyDB)
 	if err != nil {
 		URLsCh <- URLs{Error: err.Trace(targetAlias, targetURL)}
 		return
 	}
 
+	// With --max-delete, removals are held back until both listings
+	// complete, and only queued if within the limit.
+	holdRemovals := opts.isRemove && opts.maxDelete != nil
+	var (
+		removals      []URLs
+		targetObjects int64
+		listingFailed bool
+	)
+
+	var checksum *checksumComparer
+	if opts.isChecksum {
+		checksum = &checksumComparer{
+			sourceAlias: sourceAlias,
+			targetAlias: targetAlias,
+			encKeyDB:    opts.encKeyDB,
+		}
+	}
+
 	// List both source and target, compare and return values through channel.
-	for diffMsg := range objectDifference(ctx, sourceClnt, targetClnt, sourceURL, targetURL, opts.isMetadata) {
+	// Similar objects are only needed to count the objects on target.
+	for diffMsg := range difference(ctx, sourceClnt, targetClnt, sourceURL, targetURL, opts.isMetadata, checksum, true, holdRemovals, DirNone, opts.timeRef) {
 		if diffMsg.Error != nil {
 			// Send all errors through the channel
 			URLsCh <- URLs{Error: diffMsg.Error, ErrorCond: differInUnknown}
+			listingFailed = true
 			continue
 		}
 
 		srcSuffix := strings.TrimPrefix(diffMsg.FirstURL, sourceURL)
-		//Skip the source object if it matches the Exclude options provided
-		if matchExcludeOptions(opts.excludeOptions, srcSuffix) {
+		//Skip the source object if the filter rules exclude it
+		if diffMsg.FirstURL != "" && !opts.filter.matchPath(srcSuffix) {
 			continue
 		}
 
 		tgtSuffix := strings.TrimPrefix(diffMsg.SecondURL, targetURL)
-		//Skip the target object if it matches the Exclude options provided
-		if matchExcludeOptions(opts.excludeOptions, tgtSuffix) {
+		//Skip the target object if the filter rules exclude it
+		if diffMsg.SecondURL != "" && !opts.filter.matchPath(tgtSuffix) {
 			continue
 		}
 
+		// Objects differing are also returned as similar, only
+		// count them once.
+		switch diffMsg.Diff {
+		case differInNone, differInType, differInSecond:
+			targetObjects++
+		}
+
+		// Skip what a resumed session already queued.
+		if opts.diffCursor != "" {
+			tgtPath := diffMsg.SecondURL
+			if diffMsg.FirstURL != "" {
+				tgtPath = urlJoinPath(targetURL, srcSuffix)
+			}
+			if isBeforeDiffCursor(newClientURL(tgtPath).String(), opts.diffCursor) {
+				continue
+			}
+		}
+
 		switch diffMsg.Diff {
 		case differInNone:
 			// No difference, continue.
 		case differInType:
 			URLsCh <- URLs{Error: errInvalidTarget(diffMsg.SecondURL)}
-		case differInSize, differInMetadata, differInAASourceMTime:
+		case differInSize, differInMetadata, differInAASourceMTime, differInContent:
 			if !opts.isOverwrite && !opts.isFake && !opts.activeActive {
 				// Size or time or etag differs but --overwrite not set.
 				URLsCh <- URLs{
@@ -188,10 +297,15 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 			if !opts.isRemove && !opts.isFake {
 				continue
 			}
-			URLsCh <- URLs{
+			removal := URLs{
 				TargetAlias:   targetAlias,
 				TargetContent: diffMsg.secondContent,
 			}
+			if holdRemovals {
+				removals = append(removals, removal)
+				continue
+			}
+			URLsCh <- removal
 		default:
 			URLsCh <- URLs{
 				Error:     errUnrecognizedDiffType(diffMsg.Diff).Trace(diffMsg.FirstURL, diffMsg.SecondURL),
@@ -199,19 +313,89 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 			}
 		}
 	}
+
+	if len(removals) == 0 {
+		return
+	}
+	switch {
+	case listingFailed:
+		// A partial listing of source would remove too much.
+		URLsCh <- URLs{Error: errRemovalAborted(len(removals), "the listing is incomplete").Trace(sourceURL, targetURL)}
+	case opts.maxDelete.exceeded(int64(len(removals)), targetObjects):
+		URLsCh <- URLs{Error: errRemovalAborted(len(removals), fmt.Sprintf("more than `--max-delete %s` of %d object(s) on target", opts.maxDelete, targetObjects)).Trace(sourceURL, targetURL)}
+	default:
+		for _, removal := range removals {
+			URLsCh <- removal
+		}
+	}
 }
 
 type mirrorOptions struct {
 	isFake, isOverwrite, activeActive bool
 	isWatch, isRemove, isMetadata     bool
-	excludeOptions                    []string
+	isChecksum                        bool
+	workers                           workerOptions
+	retry                             retryPolicy
+	filter                            *objectFilter
 	encKeyDB                          map[string][]prefixSSEPair
 	md5, disableMultipart             bool
-	olderThan, newerThan              string
+	compress                          string
+	diffCursor                        string
+	timeRef                           time.Time
+	maxDelete                         *maxDeleteLimit
+	backupDir                         string
 	storageClass                      string
 	userMetadata                      map[string]string
 }
 
+// maxDeleteLimit - the most object(s) mirror removes from target, a
+// count or a percentage of the object(s) on target.
+type maxDeleteLimit struct {
+	count     int64
+	percent   float64
+	isPercent bool
+}
+
+// parseMaxDelete - parses the value of `--max-delete`, N or P%. Returns
+// nil if value is empty.
+func parseMaxDelete(value string) (*maxDeleteLimit, *probe.Error) {
+	if value == "" {
+		return nil, nil
+	}
+	if strings.HasSuffix(value, "%") {
+		percent, e := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if e != nil || percent < 0 || percent > 100 {
+			return nil, errInvalidArgument().Trace(value)
+		}
+		return &maxDeleteLimit{percent: percent, isPercent: true}, nil
+	}
+	count, e := strconv.ParseInt(value, 10, 64)
+	if e != nil || count < 0 {
+		return nil, errInvalidArgument().Trace(value)
+	}
+	return &maxDeleteLimit{count: count}, nil
+}
+
+// exceeded - returns true if removing removals of the total object(s)
+// on target goes past the limit.
+func (l *maxDeleteLimit) exceeded(removals, total int64) bool {
+	if l == nil {
+		return false
+	}
+	if l.isPercent {
+		return float64(removals)*100 > l.percent*float64(total)
+	}
+	return removals > l.count
+}
+
+// String - returns the limit as given to `--max-delete`.
+func (l *maxDeleteLimit) String() string {
+	if l.isPercent {
+		return strconv.FormatFloat(l.percent, 'f', -1, 64) + "%"
+	}
+	return strconv.FormatInt(l.count, 10)
+}
+
 // Prepares urls that need to be copied or removed based on requested options.
 func prepareMirrorURLs(ctx context.Context, sourceURL string, targetURL string, opts mirrorOptions) <-chan URLs {
 	URLsCh := make(chan URLs)
@@ -0,0 +1,429 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio/pkg/console"
+)
+
+// versionMapEntry - a source version mirrored with --versions and the
+// target version it was replayed as.
+type versionMapEntry struct {
+	Key             string `json:"key"`
+	SourceVersionID string `json:"sourceVersionId"`
+	TargetVersionID string `json:"targetVersionId"`
+	IsDeleteMarker  bool   `json:"isDeleteMarker,omitempty"`
+}
+
+// versionMap - the mapping of source to target version IDs, appended
+// to its file as versions are replayed so that an interrupted mirror
+// resumes after the last version replayed.
+type versionMap struct {
+	mutex    sync.Mutex
+	mirrored map[versionMapKey]string
+	file     *os.File
+}
+
+type versionMapKey struct {
+	key, versionID string
+}
+
+// getVersionMapFile - returns the default version map file of the
+// mirror of srcURL to tgtURL in the mc configuration folder.
+func getVersionMapFile(srcURL, tgtURL string) (string, *probe.Error) {
+	configDir, err := getMcConfigDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+	return filepath.Join(configDir, globalVersionMapDir, getHash("mirror", []string{srcURL, tgtURL})+".jsonl"), nil
+}
+
+// openVersionMap - reads the versions already mirrored from filename
+// and opens it to record the next ones.
+func openVersionMap(filename string) (*versionMap, *probe.Error) {
+	var jsoniter = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	if e := os.MkdirAll(filepath.Dir(filename), 0700); e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	file, e := os.OpenFile(filename, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+
+	m := &versionMap{mirrored: make(map[versionMapKey]string), file: file}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry versionMapEntry
+		if e = jsoniter.Unmarshal(scanner.Bytes(), &entry); e != nil {
+			file.Close()
+			return nil, probe.NewError(e).Trace(filename)
+		}
+		m.mirrored[versionMapKey{entry.Key, entry.SourceVersionID}] = entry.TargetVersionID
+	}
+	if e = scanner.Err(); e != nil {
+		file.Close()
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	return m, nil
+}
+
+// isMirrored - returns true if the version of key was replayed.
+func (m *versionMap) isMirrored(key, versionID string) bool {
+	if m == nil {
+		return false
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	_, ok := m.mirrored[versionMapKey{key, versionID}]
+	return ok
+}
+
+// add - records a replayed version.
+func (m *versionMap) add(entry versionMapEntry) *probe.Error {
+	if m == nil {
+		return nil
+	}
+	var jsoniter = jsoniter.ConfigCompatibleWithStandardLibrary
+	data, e := jsoniter.Marshal(entry)
+	if e != nil {
+		return probe.NewError(e)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, e = m.file.Write(append(data, '\n')); e != nil {
+		return probe.NewError(e).Trace(m.file.Name())
+	}
+	m.mirrored[versionMapKey{entry.Key, entry.SourceVersionID}] = entry.TargetVersionID
+	return nil
+}
+
+// Close - closes the file of the version map.
+func (m *versionMap) Close() error {
+	if m == nil {
+		return nil
+	}
+	return m.file.Close()
+}
+
+// mirrorVersionMessage container for replayed version messages
+type mirrorVersionMessage struct {
+	Status          string `json:"status"`
+	Source          string `json:"source"`
+	Target          string `json:"target"`
+	SourceVersionID string `json:"sourceVersionId"`
+	TargetVersionID string `json:"targetVersionId,omitempty"`
+	IsDeleteMarker  bool   `json:"isDeleteMarker,omitempty"`
+}
+
+// String colorized replayed version message
+func (m mirrorVersionMessage) String() string {
+	if m.IsDeleteMarker {
+		return console.Colorize("Mirror", fmt.Sprintf("`%s` (%s) -> `%s`, delete marker", m.Source, m.SourceVersionID, m.Target))
+	}
+	return console.Colorize("Mirror", fmt.Sprintf("`%s` (%s) -> `%s`", m.Source, m.SourceVersionID, m.Target))
+}
+
+// JSON jsonified replayed version message
+func (m mirrorVersionMessage) JSON() string {
+	m.Status = "success"
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(msgBytes)
+}
+
+// mirrorVersionsJob - mirrors all versions and delete markers of
+// source, replaying them in order onto a versioned target.
+type mirrorVersionsJob struct {
+	sourceURL, targetURL string // aliased URLs ending with a separator
+	opts                 mirrorOptions
+	versionMap           *versionMap
+}
+
+// newMirrorVersionsJob - returns the job mirroring the versions of
+// srcURL to tgtURL.
+func newMirrorVersionsJob(srcURL, tgtURL string, opts mirrorOptions, versionMap *versionMap) *mirrorVersionsJob {
+	separator := string(newClientURL(srcURL).Separator)
+	if !strings.HasSuffix(srcURL, separator) {
+		srcURL += separator
+	}
+	separator = string(newClientURL(tgtURL).Separator)
+	if !strings.HasSuffix(tgtURL, separator) {
+		tgtURL += separator
+	}
+	return &mirrorVersionsJob{
+		sourceURL:  srcURL,
+		targetURL:  tgtURL,
+		opts:       opts,
+		versionMap: versionMap,
+	}
+}
+
+// list - returns the versions and delete markers of each key of
+// source, oldest first.
+func (j *mirrorVersionsJob) list(ctx context.Context) (map[string][]*ClientContent, *probe.Error) {
+	sourceAlias, sourceURL, _ := mustExpandAlias(j.sourceURL)
+	clnt, err := newClientFromAliasWithKeys(sourceAlias, sourceURL, j.opts.encKeyDB)
+	if err != nil {
+		return nil, err.Trace(j.sourceURL)
+	}
+
+	versions := make(map[string][]*ClientContent)
+	for content := range clnt.List(ctx, ListOptions{Recursive: true, WithOlderVersions: true, WithDeleteMarkers: true, ShowDir: DirNone}) {
+		if content.Err != nil {
+			return nil, content.Err.Trace(j.sourceURL)
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+		key := strings.TrimPrefix(content.URL.String(), sourceURL)
+		if !j.opts.filter.matchPath(key) {
+			continue
+		}
+		versions[key] = append(versions[key], content)
+	}
+
+	// Versions are listed newest first.
+	for _, keyVersions := range versions {
+		for i, k := 0, len(keyVersions)-1; i < k; i, k = i+1, k-1 {
+			keyVersions[i], keyVersions[k] = keyVersions[k], keyVersions[i]
+		}
+	}
+	return versions, nil
+}
+
+// latestVersions - returns the IDs of the n latest versions of key on
+// target, oldest first. IDs of versions target does not have, such as
+// those of an unversioned bucket, are empty.
+func (j *mirrorVersionsJob) latestVersions(ctx context.Context, key string, n int) ([]string, *probe.Error) {
+	targetAlias, targetURL, _ := mustExpandAlias(urlJoinPath(j.targetURL, key))
+	clnt, err := newClientFromAliasWithKeys(targetAlias, targetURL, j.opts.encKeyDB)
+	if err != nil {
+		return nil, err.Trace(targetURL)
+	}
+	// Versions are listed newest first.
+	versionIDs := make([]string, n)
+	i := n
+	for content := range clnt.List(ctx, ListOptions{WithOlderVersions: true, WithDeleteMarkers: true, ShowDir: DirNone}) {
+		if content.Err != nil {
+			return nil, content.Err.Trace(targetURL)
+		}
+		if i > 0 && content.URL.String() == clnt.GetURL().String() {
+			i--
+			versionIDs[i] = content.VersionID
+		}
+	}
+	return versionIDs, nil
+}
+
+// replayVersion - copies a version of key to target, or removes key
+// from target for a delete marker.
+func (j *mirrorVersionsJob) replayVersion(ctx context.Context, key string, version *ClientContent) *probe.Error {
+	targetAlias, targetURL, _ := mustExpandAlias(urlJoinPath(j.targetURL, key))
+	if version.IsDeleteMarker {
+		clnt, err := newClientFromAliasWithKeys(targetAlias, targetURL, j.opts.encKeyDB)
+		if err != nil {
+			return err.Trace(targetURL)
+		}
+		return j.opts.retry.run(ctx, targetURL, func() *probe.Error {
+			contentCh := make(chan *ClientContent, 1)
+			contentCh <- &ClientContent{URL: clnt.GetURL()}
+			close(contentCh)
+			for err := range clnt.Remove(ctx, false, false, false, contentCh) {
+				return err
+			}
+			return nil
+		})
+	}
+
+	// Object storage sets the modification time of the copy, the
+	// time of the source version is kept as metadata.
+	sourceAlias, _, _ := mustExpandAlias(j.sourceURL)
+	urls := uploadSourceToTargetURL(ctx, URLs{
+		SourceAlias:   sourceAlias,
+		SourceContent: version,
+		TargetAlias:   targetAlias,
+		TargetContent: &ClientContent{
+			URL: *newClientURL(targetURL),
+			Metadata: map[string]string{
+				activeActiveSourceModTimeKey: version.Time.Format(time.RFC3339Nano),
+			},
+			UserMetadata: map[string]string{},
+		},
+		Retry: j.opts.retry,
+	}, nil, j.opts.encKeyDB, false)
+	return urls.Error
+}
+
+// replay - replays the versions of key not mirrored yet onto target,
+// in order. The versions created on target are listed once replayed,
+// paired with those of source in the same order.
+func (j *mirrorVersionsJob) replay(ctx context.Context, key string, versions []*ClientContent) *probe.Error {
+	var msgs []mirrorVersionMessage
+	var rerr *probe.Error
+	for _, version := range versions {
+		if j.versionMap.isMirrored(key, version.VersionID) {
+			continue
+		}
+		msg := mirrorVersionMessage{
+			Source:          urlJoinPath(j.sourceURL, key),
+			Target:          urlJoinPath(j.targetURL, key),
+			SourceVersionID: version.VersionID,
+			IsDeleteMarker:  version.IsDeleteMarker,
+		}
+		if j.opts.isFake {
+			printMsg(msg)
+			continue
+		}
+
+		if err := j.replayVersion(ctx, key, version); err != nil {
+			rerr = err.Trace(msg.Source, version.VersionID)
+			break
+		}
+		msgs = append(msgs, msg)
+	}
+	if len(msgs) == 0 {
+		return rerr
+	}
+
+	// Versions replayed before a failure are recorded all the same.
+	targetVersionIDs, err := j.latestVersions(ctx, key, len(msgs))
+	if err != nil {
+		return err.Trace(msgs[0].Target)
+	}
+	for i, msg := range msgs {
+		if err = j.versionMap.add(versionMapEntry{
+			Key:             key,
+			SourceVersionID: msg.SourceVersionID,
+			TargetVersionID: targetVersionIDs[i],
+			IsDeleteMarker:  msg.IsDeleteMarker,
+		}); err != nil {
+			return err
+		}
+		msg.TargetVersionID = targetVersionIDs[i]
+		printMsg(msg)
+	}
+	return rerr
+}
+
+// run - mirrors the versions of all keys, each key in a task of its
+// own, and returns the errors met.
+func (j *mirrorVersionsJob) run(ctx context.Context) (errs []*probe.Error) {
+	versions, err := j.list(ctx)
+	if err != nil {
+		return []*probe.Error{err}
+	}
+	keys := make([]string, 0, len(versions))
+	for key := range versions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	statusCh := make(chan URLs)
+	parallel := newParallelManager(statusCh, j.opts.workers)
+	j.opts.retry.throttled = parallel.throttled
+	go func() {
+		defer close(statusCh)
+		defer parallel.stopAndWait()
+		for _, key := range keys {
+			if ctx.Err() != nil {
+				return
+			}
+			key, keyVersions := key, versions[key]
+			parallel.queueTask(func() URLs {
+				return URLs{Error: j.replay(ctx, key, keyVersions)}
+			})
+		}
+	}()
+	for urls := range statusCh {
+		if urls.Error != nil {
+			errs = append(errs, urls.Error)
+		}
+	}
+	return errs
+}
+
+// checkVersionedTarget - validates that versioning is enabled on the
+// bucket of tgtURL, for versions to be replayed.
+func checkVersionedTarget(ctx context.Context, tgtURL string) *probe.Error {
+	clnt, err := newClient(tgtURL)
+	if err != nil {
+		return err.Trace(tgtURL)
+	}
+	config, err := clnt.GetVersion(ctx)
+	if err != nil {
+		return err.Trace(tgtURL)
+	}
+	if config.Status != "Enabled" {
+		return errInvalidArgument().Trace(tgtURL)
+	}
+	return nil
+}
+
+// runMirrorVersions - mirrors all versions of srcURL to tgtURL.
+func runMirrorVersions(ctx context.Context, srcURL, tgtURL string, cli *cli.Context, encKeyDB map[string][]prefixSSEPair) bool {
+	if err := checkVersionedTarget(ctx, tgtURL); err != nil {
+		fatalIf(err, "Versioning should be enabled on target `"+tgtURL+"` to mirror versions.")
+	}
+
+	opts := mirrorOptions{
+		isFake:   cli.Bool("fake"),
+		workers:  workerOptionsFromContext(cli),
+		retry:    retryPolicyFromContext(cli),
+		filter:   newObjectFilterFromContext(cli),
+		encKeyDB: encKeyDB,
+	}
+
+	var versionMap *versionMap
+	if !opts.isFake {
+		filename := cli.String("version-map")
+		if filename == "" {
+			var err *probe.Error
+			filename, err = getVersionMapFile(syncStateURL(srcURL), syncStateURL(tgtURL))
+			fatalIf(err, "Unable to get the version map file.")
+		}
+		var err *probe.Error
+		versionMap, err = openVersionMap(filename)
+		fatalIf(err, "Unable to open version map `"+filename+"`.")
+		defer versionMap.Close()
+	}
+
+	errs := newMirrorVersionsJob(srcURL, tgtURL, opts, versionMap).run(ctx)
+	for _, err := range errs {
+		errorIf(err, "Unable to mirror versions.")
+	}
+	return len(errs) > 0
+}
@@ -36,14 +36,6 @@ var (
 			Name:  "recursive, r",
 			Usage: "move recursively",
 		},
-		cli.StringFlag{
-			Name:  "older-than",
-			Usage: "move objects older than L days, M hours and N minutes",
-		},
-		cli.StringFlag{
-			Name:  "newer-than",
-			Usage: "move objects newer than L days, M hours and N minutes",
-		},
 		cli.StringFlag{
 			Name:  "storage-class, sc",
 			Usage: "set storage class for new object(s) on target",
@@ -68,6 +60,7 @@ var (
 			Name:  "disable-multipart",
 			Usage: "disable multipart upload feature",
 		},
+		failedLogFlag,
 	}
 )
 
@@ -78,7 +71,7 @@ var mvCmd = cli.Command{
 	Action:       mainMove,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(mvFlags, ioFlags...), globalFlags...),
+	Flags:        append(append(append(append(append(mvFlags, filterFlags...), ioFlags...), workerFlags...), retryFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -89,8 +82,9 @@ FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}
 ENVIRONMENT VARIABLES:
-  MC_ENCRYPT:      list of comma delimited prefixes
-  MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+  MC_ENCRYPT:            list of comma delimited prefixes
+  MC_ENCRYPT_KEY:        list of comma delimited prefix=secret values
+  MC_ENCRYPT_CLIENT_KEY: list of comma delimited prefix=secret values for client-side encryption
 
 EXAMPLES:
   01. Move a list of objects from local file system to Amazon S3 cloud storage.
@@ -141,6 +135,9 @@ EXAMPLES:
 
   16. Move a text file to an object storage and disable multipart upload feature.
       {{.Prompt}} {{.HelpName}} --disable-multipart myobject.txt play/mybucket
+
+  17. Move the objects of at least 1GiB from a bucket recursively to another.
+      {{.Prompt}} {{.HelpName}} --recursive --min-size 1GiB play/mybucket/ s3/archive/
 `,
 }
 
@@ -305,7 +302,7 @@ func mainMove(cliCtx *cli.Context) error {
 		if err != nil {
 			fatalIf(err.Trace(), "Unable to parse the provided url.")
 		}
-		if _, ok := client.(*S3Client); ok {
+		if _, ok := unwrapClient(client).(*S3Client); ok {
 			enabled, err := isBucketLockEnabled(ctx, urlStr)
 			if err != nil {
 				fatalIf(err.Trace(), "Unable to get bucket lock configuration of `%s`", urlStr)
@@ -320,8 +317,6 @@ func mainMove(cliCtx *cli.Context) error {
 	console.SetColor("Copy", color.New(color.FgGreen, color.Bold))
 
 	recursive := cliCtx.Bool("recursive")
-	olderThan := cliCtx.String("older-than")
-	newerThan := cliCtx.String("newer-than")
 	storageClass := cliCtx.String("storage-class")
 	sseKeys := os.Getenv("MC_ENCRYPT_KEY")
 	if key := cliCtx.String("encrypt-key"); key != "" {
@@ -345,11 +340,11 @@ func mainMove(cliCtx *cli.Context) error {
 			session = newSessionV8(sessionID)
 			session.Header.CommandType = "mv"
 			session.Header.CommandBoolFlags["recursive"] = recursive
-			session.Header.CommandStringFlags["older-than"] = olderThan
-			session.Header.CommandStringFlags["newer-than"] = newerThan
+			session.setCommandFlags(cliCtx, filterFlags)
 			session.Header.CommandStringFlags["storage-class"] = storageClass
 			session.Header.CommandStringFlags["encrypt-key"] = sseKeys
 			session.Header.CommandStringFlags["encrypt"] = sse
+			session.Header.CommandStringFlags["encrypt-client-key"] = getClientEncryptionKeys(cliCtx)
 			session.Header.CommandBoolFlags["session"] = cliCtx.Bool("continue")
 
 			if cliCtx.Bool("preserve") {
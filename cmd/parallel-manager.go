@@ -17,10 +17,16 @@
 package cmd
 
 import (
+	"errors"
+	"net/http"
 	"runtime"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
 )
 
 const (
@@ -34,6 +40,87 @@ const (
 // Number of workers added per bandwidth monitoring.
 var defaultWorkerFactor = runtime.GOMAXPROCS(0)
 
+// Policies deciding the number of workers.
+const (
+	// Add workers while the bandwidth improves.
+	workerPolicyBandwidth = "bandwidth"
+	// Run a fixed number of workers.
+	workerPolicyFixed = "fixed"
+	// Add a worker per monitor tick, halve the workers when the
+	// server throttles requests.
+	workerPolicyAIMD = "aimd"
+)
+
+// Minimum time between two decreases of the workers, throttling
+// errors of tasks running at the time of a decrease are not counted
+// again.
+const aimdDecreasePeriod = time.Second
+
+// workerOptions - number of workers of a ParallelManager and how they
+// are added or removed.
+type workerOptions struct {
+	maxWorkers int
+	policy     string
+}
+
+// Flags of commands running parallel workers.
+var workerFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "max-workers",
+		Usage: "maximum number of parallel transfers, the number of transfers with --worker-policy fixed",
+	},
+	cli.StringFlag{
+		Name:  "worker-policy",
+		Value: workerPolicyBandwidth,
+		Usage: "add parallel transfers while the bandwidth improves (bandwidth), run --max-workers transfers (fixed) or back off when the server throttles (aimd)",
+	},
+}
+
+// workerOptionsFromContext - returns the worker options of ctx, exits
+// on invalid values.
+func workerOptionsFromContext(ctx *cli.Context) workerOptions {
+	opts := workerOptions{
+		maxWorkers: ctx.Int("max-workers"),
+		policy:     ctx.String("worker-policy"),
+	}
+	// A zero value picks the policy default, but only when unset.
+	if opts.maxWorkers < 0 || opts.maxWorkers > maxParallelWorkers || (opts.maxWorkers == 0 && ctx.IsSet("max-workers")) {
+		fatalIf(errInvalidArgument().Trace(ctx.String("max-workers")), "`--max-workers` should be between 1 and 128.")
+	}
+	switch opts.policy {
+	case "":
+		opts.policy = workerPolicyBandwidth
+	case workerPolicyBandwidth, workerPolicyFixed, workerPolicyAIMD:
+	default:
+		fatalIf(errInvalidArgument().Trace(opts.policy), "`--worker-policy` should be one of `bandwidth`, `fixed`, `aimd`.")
+	}
+	return opts
+}
+
+// toErrorResponse - returns the S3 error response err wraps, such as
+// the one of a failed removal.
+func toErrorResponse(err error) minio.ErrorResponse {
+	var errResp minio.ErrorResponse
+	if errors.As(err, &errResp) {
+		return errResp
+	}
+	return minio.ToErrorResponse(err)
+}
+
+// isThrottlingError - returns true if err is the server asking to
+// slow down.
+func isThrottlingError(err *probe.Error) bool {
+	if err == nil {
+		return false
+	}
+	errResp := toErrorResponse(err.ToGoError())
+	switch errResp.Code {
+	case "SlowDown", "SlowDownRead", "SlowDownWrite", "Throttling", "ThrottlingException", "RequestLimitExceeded", "ServiceUnavailable", "TooManyRequests":
+		return true
+	}
+	return errResp.StatusCode == http.StatusServiceUnavailable || errResp.StatusCode == http.StatusTooManyRequests
+}
+
 // A task is a copy/mirror action that needs to be executed
 type task struct {
 	// The function to execute in this task
@@ -58,6 +145,15 @@ type ParallelManager struct {
 	// Current threads number
 	workersNum uint32
 
+	// Number of workers to run, workers above it quit once done with
+	// their task.
+	targetWorkers uint32
+	maxWorkers    uint32
+	policy        string
+
+	decreaseMutex sync.Mutex
+	lastDecrease  time.Time
+
 	// Channel to receive tasks to run
 	queueCh chan task
 
@@ -67,39 +163,102 @@ type ParallelManager struct {
 	stopMonitorCh chan struct{}
 }
 
-// addWorker creates a new worker to process tasks
-func (p *ParallelManager) addWorker() {
-	if atomic.LoadUint32(&p.workersNum) >= maxParallelWorkers {
-		// Number of maximum workers is reached, no need to
-		// to create a new one.
-		return
+// addWorker creates a new worker to process tasks, returns false when
+// the target number of workers is already running.
+func (p *ParallelManager) addWorker() bool {
+	for {
+		n := atomic.LoadUint32(&p.workersNum)
+		if n >= atomic.LoadUint32(&p.targetWorkers) {
+			// Number of maximum workers is reached, no need to
+			// to create a new one.
+			return false
+		}
+		// Update number of threads
+		if atomic.CompareAndSwapUint32(&p.workersNum, n, n+1) {
+			break
+		}
 	}
 
-	// Update number of threads
-	atomic.AddUint32(&p.workersNum, 1)
-
 	// Start a new worker
 	p.wg.Add(1)
 	go func() {
+		defer p.wg.Done()
 		for {
 			// Wait for jobs
 			t, ok := <-p.queueCh
 			if !ok {
 				// No more tasks, quit
-				p.wg.Done()
 				return
 			}
 
 			// Execute the task and send the result to channel.
-			p.resultCh <- t.fn()
+			urls := t.fn()
+			if isThrottlingError(urls.Error) {
+				p.throttled()
+			}
+			p.resultCh <- urls
 
 			if t.barrier {
 				p.barrierSync.Unlock()
 			} else {
 				p.barrierSync.RUnlock()
 			}
+
+			if p.removeWorker() {
+				return
+			}
 		}
 	}()
+	return true
+}
+
+// removeWorker - returns true if the calling worker should quit, the
+// workers being above their target.
+func (p *ParallelManager) removeWorker() bool {
+	for {
+		n := atomic.LoadUint32(&p.workersNum)
+		if n <= atomic.LoadUint32(&p.targetWorkers) {
+			return false
+		}
+		if atomic.CompareAndSwapUint32(&p.workersNum, n, n-1) {
+			return true
+		}
+	}
+}
+
+// decreaseWorkers - halves the target number of workers, at most once
+// per aimdDecreasePeriod.
+func (p *ParallelManager) decreaseWorkers() {
+	p.decreaseMutex.Lock()
+	defer p.decreaseMutex.Unlock()
+
+	if time.Since(p.lastDecrease) < aimdDecreasePeriod {
+		return
+	}
+	p.lastDecrease = time.Now()
+	if target := atomic.LoadUint32(&p.targetWorkers); target > 1 {
+		atomic.StoreUint32(&p.targetWorkers, target/2)
+	}
+}
+
+// throttled - decreases the workers with the aimd policy, called by
+// tasks throttled by the server, including by each retried attempt.
+func (p *ParallelManager) throttled() {
+	if p.policy == workerPolicyAIMD {
+		p.decreaseWorkers()
+	}
+}
+
+// increaseWorkers - adds n workers to the target number of workers,
+// up to the maximum.
+func (p *ParallelManager) increaseWorkers(n uint32) {
+	target := atomic.LoadUint32(&p.targetWorkers) + n
+	if target > p.maxWorkers {
+		target = p.maxWorkers
+	}
+	atomic.StoreUint32(&p.targetWorkers, target)
+	for p.addWorker() {
+	}
 }
 
 func (p *ParallelManager) Read(b []byte) (n int, err error) {
@@ -130,6 +289,13 @@ func (p *ParallelManager) monitorProgress() {
 				bandwidth := sentBytes - prevSentBytes
 				prevSentBytes = sentBytes
 
+				if p.policy == workerPolicyAIMD {
+					// Additive increase, throttled tasks decrease
+					// the workers as they finish.
+					p.increaseWorkers(1)
+					continue
+				}
+
 				if bandwidth <= maxBandwidth {
 					retry++
 					// We still want to add more workers
@@ -143,9 +309,7 @@ func (p *ParallelManager) monitorProgress() {
 					maxBandwidth = bandwidth
 				}
 
-				for i := 0; i < defaultWorkerFactor; i++ {
-					p.addWorker()
-				}
+				p.increaseWorkers(uint32(defaultWorkerFactor))
 			}
 		}
 	}()
@@ -180,22 +344,34 @@ func (p *ParallelManager) stopAndWait() {
 }
 
 // newParallelManager starts new workers waiting for executing tasks
-func newParallelManager(resultCh chan URLs) *ParallelManager {
+func newParallelManager(resultCh chan URLs, opts workerOptions) *ParallelManager {
 	p := &ParallelManager{
 		wg:            &sync.WaitGroup{},
 		workersNum:    0,
+		maxWorkers:    maxParallelWorkers,
+		policy:        opts.policy,
 		stopMonitorCh: make(chan struct{}),
 		queueCh:       make(chan task),
 		resultCh:      resultCh,
 	}
+	if opts.maxWorkers > 0 {
+		p.maxWorkers = uint32(opts.maxWorkers)
+	}
 
-	// Start with runtime.NumCPU().
-	for i := 0; i < runtime.NumCPU(); i++ {
-		p.addWorker()
+	// Start with runtime.NumCPU(), or all workers when fixed.
+	workers := uint32(runtime.NumCPU())
+	if p.policy == workerPolicyFixed && opts.maxWorkers > 0 {
+		workers = p.maxWorkers
+	}
+	if workers > p.maxWorkers {
+		workers = p.maxWorkers
 	}
+	p.increaseWorkers(workers)
 
 	// Start monitoring tasks progress
-	p.monitorProgress()
+	if p.policy != workerPolicyFixed {
+		p.monitorProgress()
+	}
 
 	return p
 }
@@ -0,0 +1,54 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	. "gopkg.in/check.v1"
+)
+
+// TestParallelManagerWorkers - tests fixed workers and the backoff of
+// the AIMD policy on throttling errors.
+func (s *TestSuite) TestParallelManagerWorkers(c *C) {
+	slowDown := probe.NewError(minio.ErrorResponse{Code: "SlowDown", StatusCode: http.StatusServiceUnavailable})
+	c.Assert(isThrottlingError(slowDown), Equals, true)
+	c.Assert(isThrottlingError(probe.NewError(errors.New("connection reset"))), Equals, false)
+	c.Assert(isThrottlingError(nil), Equals, false)
+
+	resultCh := make(chan URLs)
+	p := newParallelManager(resultCh, workerOptions{maxWorkers: 8, policy: workerPolicyFixed})
+	c.Assert(atomic.LoadUint32(&p.workersNum), Equals, uint32(8))
+	p.stopAndWait()
+
+	p = newParallelManager(resultCh, workerOptions{maxWorkers: 8, policy: workerPolicyAIMD})
+	p.increaseWorkers(8)
+	c.Assert(atomic.LoadUint32(&p.targetWorkers), Equals, uint32(8))
+	p.queueTask(func() URLs { return URLs{Error: slowDown} })
+	<-resultCh
+	c.Assert(atomic.LoadUint32(&p.targetWorkers), Equals, uint32(4))
+
+	// Throttling errors right after a decrease are not counted again.
+	p.queueTask(func() URLs { return URLs{Error: slowDown} })
+	<-resultCh
+	c.Assert(atomic.LoadUint32(&p.targetWorkers), Equals, uint32(4))
+	p.stopAndWait()
+}
@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"io"
 	"os"
 	"syscall"
 
@@ -34,6 +35,10 @@ var (
 			Name:  "storage-class, sc",
 			Usage: "set storage class for new object(s) on target",
 		},
+		cli.StringFlag{
+			Name:  "compress",
+			Usage: "compress the object on upload with one of zstd, gzip or s2",
+		},
 	}
 )
 
@@ -55,8 +60,9 @@ FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}{{end}}
 ENVIRONMENT VARIABLES:
-  MC_ENCRYPT:      list of comma delimited prefix values
-  MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+  MC_ENCRYPT:            list of comma delimited prefix values
+  MC_ENCRYPT_KEY:        list of comma delimited prefix=secret values
+  MC_ENCRYPT_CLIENT_KEY: list of comma delimited prefix=secret values for client-side encryption
 
 EXAMPLES:
   1. Write contents of stdin to a file on local filesystem.
@@ -73,25 +79,35 @@ EXAMPLES:
 
   5. Write contents of stdin to an object on Amazon S3 cloud storage and assign REDUCED_REDUNDANCY storage-class to the uploaded object.
      {{.Prompt}} {{.HelpName}} --storage-class REDUCED_REDUNDANCY s3/personalbuck/meeting-notes.txt
+
+  6. Stream a log file to MinIO cloud storage compressed with zstd, 'mc cat' decompresses it back.
+     {{.Prompt}} tail -f /var/log/app.log | {{.HelpName}} --compress zstd play/logs/app.log
 `,
 }
 
-func pipe(targetURL string, encKeyDB map[string][]prefixSSEPair, storageClass string) *probe.Error {
+func pipe(targetURL string, encKeyDB map[string][]prefixSSEPair, storageClass, compress string) *probe.Error {
 	if targetURL == "" {
 		// When no target is specified, pipe cat's stdin to stdout.
 		return catOut(os.Stdin, -1).Trace()
 	}
 	alias, _ := url2Alias(targetURL)
 	sseKey := getSSE(targetURL, encKeyDB[alias])
+	cseKey := getClientEncKey(targetURL, encKeyDB[alias])
 
 	// Stream from stdin to multiple objects until EOF.
 	// Ignore size, since os.Stat() would not return proper size all the time
 	// for local filesystem for example /proc files.
-	var metadata map[string]string
+	metadata := map[string]string{}
 	if storageClass != "" {
-		metadata = map[string]string{"X-Amz-Storage-Class": storageClass}
+		metadata["X-Amz-Storage-Class"] = storageClass
+	}
+	var reader io.Reader = os.Stdin
+	if compress != "" {
+		compReader := compressStream(os.Stdin, compress, -1, metadata)
+		defer compReader.Close()
+		reader = compReader
 	}
-	_, err := putTargetStreamWithURL(targetURL, os.Stdin, -1, sseKey, false, false, false, metadata)
+	_, err := putTargetStreamWithURL(targetURL, reader, -1, sseKey, cseKey, false, false, false, metadata)
 	// TODO: See if this check is necessary.
 	switch e := err.ToGoError().(type) {
 	case *os.PathError:
@@ -108,6 +124,7 @@ func checkPipeSyntax(ctx *cli.Context) {
 	if len(ctx.Args()) > 1 {
 		cli.ShowCommandHelpAndExit(ctx, "pipe", 1) // last argument is exit code.
 	}
+	checkCompressFlag(ctx.String("compress"))
 }
 
 // mainPipe is the main entry point for pipe command.
@@ -120,12 +137,12 @@ func mainPipe(ctx *cli.Context) error {
 	checkPipeSyntax(ctx)
 
 	if len(ctx.Args()) == 0 {
-		err = pipe("", nil, ctx.String("storage-class"))
+		err = pipe("", nil, ctx.String("storage-class"), "")
 		fatalIf(err.Trace("stdout"), "Unable to write to one or more targets.")
 	} else {
 		// extract URLs.
 		URLs := ctx.Args()
-		err = pipe(URLs[0], encKeyDB, ctx.String("storage-class"))
+		err = pipe(URLs[0], encKeyDB, ctx.String("storage-class"), ctx.String("compress"))
 		fatalIf(err.Trace(URLs[0]), "Unable to write to one or more targets.")
 	}
 
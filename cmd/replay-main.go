@@ -0,0 +1,241 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio/pkg/console"
+)
+
+var replayFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "encrypt",
+		Usage: "encrypt/decrypt objects (using server-side encryption with server managed keys)",
+	},
+	failedLogFlag,
+}
+
+// Retry the objects journaled by --failed-log.
+var replayCmd = cli.Command{
+	Name:         "replay",
+	Usage:        "retry the objects a command logged with --failed-log",
+	Action:       mainReplay,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(append(append(append(replayFlags, ioFlags...), workerFlags...), retryFlags...), globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] FILE
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+ENVIRONMENT VARIABLES:
+  MC_ENCRYPT:            list of comma delimited prefixes
+  MC_ENCRYPT_KEY:        list of comma delimited prefix=secret values
+  MC_ENCRYPT_CLIENT_KEY: list of comma delimited prefix=secret values for client-side encryption
+
+  Copies and removals are replayed with the options they were logged with,
+  encryption keys are not logged and have to be given again.
+
+EXAMPLES:
+  1. Retry the objects a recursive copy failed to copy.
+     {{.Prompt}} mc cp --recursive --failed-log /tmp/failed.json ~/photos/ play/mybucket/photos/
+     {{.Prompt}} {{.HelpName}} /tmp/failed.json
+
+  2. Retry the objects a recursive removal failed to remove, logging those still failing to another file.
+     {{.Prompt}} mc rm --recursive --force --failed-log /tmp/failed.json play/mybucket/old/
+     {{.Prompt}} {{.HelpName}} --failed-log /tmp/still-failed.json /tmp/failed.json
+
+  3. Retry the objects a mirror to an encrypted bucket failed to copy.
+     {{.Prompt}} {{.HelpName}} --encrypt-key "s3/backup/=32byteslongsecretkeymustbegiven1" /tmp/failed.json
+`,
+}
+
+// replayRemove - removes the object of entry.
+func replayRemove(ctx context.Context, entry failedLogEntry, retry retryPolicy) *probe.Error {
+	targetAlias, targetURL, _ := mustExpandAlias(entry.Remove)
+	clnt, err := newClientFromAlias(targetAlias, targetURL)
+	if err != nil {
+		return err.Trace(entry.Remove)
+	}
+	return retry.run(ctx, entry.Remove, func() *probe.Error {
+		contentCh := make(chan *ClientContent, 1)
+		contentCh <- &ClientContent{URL: *newClientURL(targetURL), VersionID: entry.VersionID}
+		close(contentCh)
+		isRemoveBucket := false
+		var rerr *probe.Error
+		for err := range clnt.Remove(ctx, entry.Incomplete, isRemoveBucket, entry.Bypass, contentCh) {
+			if err != nil && rerr == nil {
+				rerr = err
+			}
+		}
+		return rerr
+	})
+}
+
+// mainReplay is the handle for "mc replay" command.
+func mainReplay(cliCtx *cli.Context) error {
+	ctx, cancelReplay := context.WithCancel(globalContext)
+	defer cancelReplay()
+
+	if len(cliCtx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(cliCtx, "replay", 1) // last argument is exit code
+	}
+	filename := cliCtx.Args().First()
+
+	// Parse encryption keys per command.
+	encKeyDB, err := getEncKeys(cliCtx)
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	file, e := os.Open(filename)
+	fatalIf(probe.NewError(e).Trace(filename), "Unable to open failed log `"+filename+"`.")
+	entries, err := readFailedLog(file)
+	file.Close()
+	fatalIf(err.Trace(filename), "Unable to read failed log `"+filename+"`.")
+
+	// Objects still failing are not logged to the log being replayed.
+	if logname := cliCtx.String("failed-log"); logname != "" {
+		logPath, e1 := filepath.Abs(logname)
+		replayPath, e2 := filepath.Abs(filename)
+		if e1 == nil && e2 == nil && logPath == replayPath {
+			fatalIf(errInvalidArgument().Trace(logname), "`--failed-log` cannot be the log being replayed.")
+		}
+	}
+	failed := openFailedLog(cliCtx, "replay")
+	defer failed.Close()
+
+	retry := retryPolicyFromContext(cliCtx)
+
+	// Additional command specific theme customization.
+	console.SetColor("Copy", color.New(color.FgGreen, color.Bold))
+	console.SetColor("Remove", color.New(color.FgGreen, color.Bold))
+
+	var totalBytes, totalObjects int64
+	isMove := false
+	for _, entry := range entries {
+		if entry.URLs != nil {
+			totalBytes += entry.URLs.SourceContent.Size
+			isMove = isMove || entry.Move
+		}
+		totalObjects++
+	}
+
+	// Store a progress bar or an accounter
+	var pg ProgressReader
+	if !globalQuiet && !globalJSON {
+		pg = newProgressBar(totalBytes)
+	} else {
+		pg = newAccounter(totalBytes)
+	}
+
+	statusCh := make(chan URLs)
+	parallel := newParallelManager(statusCh, workerOptionsFromContext(cliCtx))
+	retry.throttled = parallel.throttled
+
+	go func() {
+		defer close(statusCh)
+		defer parallel.stopAndWait()
+
+		for _, entry := range entries {
+			if ctx.Err() != nil {
+				return
+			}
+			entry := entry
+			if entry.URLs == nil {
+				parallel.queueTask(func() URLs {
+					urls := URLs{TargetContent: &ClientContent{URL: *newClientURL(entry.Remove), VersionID: entry.VersionID}}
+					if err := replayRemove(ctx, entry, retry); err != nil {
+						entry.Error = err.ToGoError().Error()
+						failed.write(entry)
+						return urls.WithError(err)
+					}
+					return urls
+				})
+				continue
+			}
+			parallel.queueTask(func() URLs {
+				urls := *entry.URLs
+				urls.TotalCount = totalObjects
+				urls.TotalSize = totalBytes
+				urls.Retry = retry
+				if urls.TargetContent.Metadata == nil {
+					urls.TargetContent.Metadata = make(map[string]string)
+				}
+				if urls.TargetContent.UserMetadata == nil {
+					urls.TargetContent.UserMetadata = make(map[string]string)
+				}
+				urls = doCopy(ctx, urls, pg, encKeyDB, entry.Move, entry.Preserve)
+				if urls.Error != nil {
+					entry.Error = urls.Error.ToGoError().Error()
+					failed.write(entry)
+				}
+				return urls
+			})
+		}
+	}()
+
+	var retErr error
+	for urls := range statusCh {
+		if urls.Error == nil {
+			if urls.SourceContent == nil {
+				if _, ok := pg.(*accounter); ok {
+					printMsg(rmMessage{Key: urls.TargetContent.URL.String(), VersionID: urls.TargetContent.VersionID})
+				}
+			}
+			continue
+		}
+
+		// Set exit status for any failure.
+		retErr = exitStatus(globalErrorExitStatus)
+
+		// Print in new line and adjust to top so that we
+		// don't print over the ongoing progress bar.
+		if !globalQuiet && !globalJSON {
+			console.Eraseline()
+		}
+		if urls.SourceContent != nil {
+			errorIf(urls.Error.Trace(urls.SourceContent.URL.String()),
+				fmt.Sprintf("Failed to copy `%s`.", urls.SourceContent.URL.String()))
+		} else {
+			errorIf(urls.Error.Trace(urls.TargetContent.URL.String()),
+				fmt.Sprintf("Failed to remove `%s`.", urls.TargetContent.URL.String()))
+		}
+	}
+
+	if progressReader, ok := pg.(*progressBar); ok {
+		if progressReader.ProgressBar.Get() > 0 {
+			progressReader.ProgressBar.Finish()
+		}
+	} else if accntReader, ok := pg.(*accounter); ok {
+		printMsg(accntReader.Stat())
+	}
+
+	if isMove {
+		rmManager.close()
+	}
+	return retErr
+}
@@ -151,7 +151,7 @@ func mainReplicateAdd(cliCtx *cli.Context) error {
 	args := cliCtx.Args()
 	aliasedURL := args.Get(0)
 	// Create a new Client
-	client, err := newClient(aliasedURL)
+	client, err := newClientWithCapability(aliasedURL, capReplication)
 	fatalIf(err, "Unable to initialize connection.")
 	rcfg, err := client.GetReplication(ctx)
 	fatalIf(err.Trace(args...), "Unable to get replication configuration")
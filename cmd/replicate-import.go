@@ -103,7 +103,7 @@ func mainReplicateImport(cliCtx *cli.Context) error {
 	args := cliCtx.Args()
 	aliasedURL := args.Get(0)
 	// Create a new Client
-	client, err := newClient(aliasedURL)
+	client, err := newClientWithCapability(aliasedURL, capReplication)
 	fatalIf(err, "Unable to initialize connection.")
 	rCfg, err := readReplicationConfig()
 	fatalIf(err.Trace(args...), "Unable to read replication configuration")
@@ -195,12 +195,8 @@ func applyRetention(ctx context.Context, op lockOpType, target, versionID string
 		fatalIf(err.Trace(), "Unable to parse the provided url.")
 	}
 
-	// Quit early if urlStr does not point to an S3 server
-	switch clnt.(type) {
-	case *S3Client:
-	default:
-		fatal(errDummy().Trace(), "Retention is supported only for S3 servers.")
-	}
+	// Quit early if urlStr does not point to a backend supporting retention
+	fatalIf(checkClientCapability(target, capRetention).Trace(target), "Unable to apply retention on the provided url.")
 
 	var until time.Time
 	if mode != "" {
@@ -245,7 +241,7 @@ func applyRetention(ctx context.Context, op lockOpType, target, versionID string
 			continue
 		}
 
-		if !isRecursive && alias+getKey(content) != getStandardizedURL(target) {
+		if !isRecursive && getAliasedKey(alias, content) != getStandardizedURL(target) {
 			break
 		}
 
@@ -268,7 +264,7 @@ func applyRetention(ctx context.Context, op lockOpType, target, versionID string
 
 // applyBucketLock - set object lock configuration.
 func applyBucketLock(op lockOpType, urlStr string, mode minio.RetentionMode, validity uint64, unit minio.ValidityUnit) error {
-	client, err := newClient(urlStr)
+	client, err := newClientWithCapability(urlStr, capObjectLock)
 	if err != nil {
 		fatalIf(err.Trace(), "Unable to parse the provided url.")
 	}
@@ -296,7 +292,7 @@ func applyBucketLock(op lockOpType, urlStr string, mode minio.RetentionMode, val
 
 // showBucketLock - show object lock configuration.
 func showBucketLock(urlStr string) error {
-	client, err := newClient(urlStr)
+	client, err := newClientWithCapability(urlStr, capObjectLock)
 	if err != nil {
 		fatalIf(err.Trace(), "Unable to parse the provided url.")
 	}
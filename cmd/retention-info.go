@@ -295,12 +295,8 @@ func getRetention(ctx context.Context, target, versionID string, timeRef time.Ti
 		fatalIf(err.Trace(), "Unable to parse the provided url.")
 	}
 
-	// Quit early if urlStr does not point to an S3 server
-	switch clnt.(type) {
-	case *S3Client:
-	default:
-		fatal(errDummy().Trace(), "Retention is supported only for S3 servers.")
-	}
+	// Quit early if urlStr does not point to a backend supporting retention
+	fatalIf(checkClientCapability(target, capRetention).Trace(target), "Unable to get retention of the provided url.")
 
 	alias, urlStr, _ := mustExpandAlias(target)
 	if versionID != "" || !isRecursive && !withOlderVersions {
@@ -332,7 +328,7 @@ func getRetention(ctx context.Context, target, versionID string, timeRef time.Ti
 			continue
 		}
 
-		if !isRecursive && alias+getKey(content) != getStandardizedURL(target) {
+		if !isRecursive && getAliasedKey(alias, content) != getStandardizedURL(target) {
 			break
 		}
 
@@ -0,0 +1,209 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio/pkg/console"
+)
+
+// Base wait of the exponential backoff between retries.
+const retryUnit = time.Second
+
+// Flags of commands retrying objects failing with transient errors.
+var retryFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "retry-attempts",
+		Value: 3,
+		Usage: "retry object(s) failing with network or server errors up to N times, 0 disables retries",
+	},
+	cli.DurationFlag{
+		Name:  "retry-max-backoff",
+		Value: 30 * time.Second,
+		Usage: "maximum wait between two retries of an object",
+	},
+}
+
+// retryPolicy - retries of the operations on an object, the zero
+// value never retries.
+type retryPolicy struct {
+	attempts   int
+	maxBackoff time.Duration
+
+	// Called when an attempt is throttled, before waiting to retry
+	// it, so that the workers back off without waiting for the object
+	// to run out of attempts.
+	throttled func()
+}
+
+// retryPolicyFromContext - returns the retry policy of ctx, exits on
+// invalid values.
+func retryPolicyFromContext(ctx *cli.Context) retryPolicy {
+	policy := retryPolicy{
+		attempts:   ctx.Int("retry-attempts"),
+		maxBackoff: ctx.Duration("retry-max-backoff"),
+	}
+	if policy.attempts < 0 {
+		fatalIf(errInvalidArgument().Trace(ctx.String("retry-attempts")), "`--retry-attempts` cannot be negative.")
+	}
+	if policy.maxBackoff < retryUnit {
+		fatalIf(errInvalidArgument().Trace(policy.maxBackoff.String()), "`--retry-max-backoff` should be at least 1s.")
+	}
+	console.SetColor("Retry", color.New(color.FgYellow))
+	return policy
+}
+
+// isRetryableError - returns true for network errors, server errors
+// and throttling, which may not happen again.
+func isRetryableError(err *probe.Error) bool {
+	if err == nil {
+		return false
+	}
+	e := err.ToGoError()
+	if errors.Is(e, context.Canceled) {
+		return false
+	}
+	if isThrottlingError(err) {
+		return true
+	}
+	if errors.Is(e, io.ErrUnexpectedEOF) || errors.Is(e, syscall.ECONNRESET) || errors.Is(e, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(e, &netErr) {
+		return true
+	}
+	errResp := toErrorResponse(e)
+	switch errResp.Code {
+	case "InternalError", "RequestTimeout", "OperationAborted":
+		return true
+	}
+	return errResp.StatusCode >= 500
+}
+
+// retryMessage container for retries of an object.
+type retryMessage struct {
+	Status      string `json:"status"`
+	URL         string `json:"url"`
+	Attempt     int    `json:"attempt"`
+	MaxAttempts int    `json:"maxAttempts"`
+	Delay       string `json:"delay"`
+	Error       string `json:"error"`
+}
+
+// String colorized retry message.
+func (r retryMessage) String() string {
+	return console.Colorize("Retry", fmt.Sprintf("Retrying `%s` (%d/%d) after %s: %s", r.URL, r.Attempt, r.MaxAttempts, r.Delay, r.Error))
+}
+
+// JSON jsonified retry message.
+func (r retryMessage) JSON() string {
+	r.Status = "retry"
+	retryJSONBytes, e := json.MarshalIndent(r, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(retryJSONBytes)
+}
+
+// run - runs op on urlStr until it succeeds, fails with an error
+// that is not retryable or runs out of attempts. Retries wait for a
+// jittered exponential backoff and are reported as they start.
+func (r retryPolicy) run(ctx context.Context, urlStr string, op func() *probe.Error) *probe.Error {
+	return r.retry(ctx, urlStr, op(), op)
+}
+
+// retry - retries op on urlStr as run does, the first attempt having
+// already failed with err, such as the removal of an object of a batch.
+func (r retryPolicy) retry(ctx context.Context, urlStr string, err *probe.Error, op func() *probe.Error) *probe.Error {
+	if r.attempts <= 0 || !isRetryableError(err) {
+		return err
+	}
+
+	retryCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var failedAt time.Time
+	for attempt := range newRetryTimerContinous(retryCtx, retryUnit, r.maxBackoff, minio.MaxJitter) {
+		if attempt > 0 {
+			printMsg(retryMessage{
+				URL:         urlStr,
+				Attempt:     attempt,
+				MaxAttempts: r.attempts,
+				Delay:       time.Since(failedAt).Round(time.Millisecond).String(),
+				Error:       err.ToGoError().Error(),
+			})
+			err = op()
+		}
+		if err == nil || attempt >= r.attempts || !isRetryableError(err) {
+			return err
+		}
+		if r.throttled != nil && isThrottlingError(err) {
+			r.throttled()
+		}
+		failedAt = time.Now()
+	}
+	return err
+}
+
+// retryProgress - reports the progress of a retried transfer, bytes
+// transferred again by a retry are only reported once.
+type retryProgress struct {
+	mutex    sync.Mutex
+	progress io.Reader
+
+	// Bytes reported so far, and transferred by the current attempt.
+	reported int64
+	current  int64
+}
+
+// reset - starts a new attempt.
+func (r *retryProgress) reset() {
+	r.mutex.Lock()
+	r.current = 0
+	r.mutex.Unlock()
+}
+
+// Read - reports the bytes of p not reported by earlier attempts.
+func (r *retryProgress) Read(p []byte) (n int, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.current += int64(len(p))
+	if r.current <= r.reported {
+		return len(p), nil
+	}
+	unreported := r.current - r.reported
+	if unreported > int64(len(p)) {
+		unreported = int64(len(p))
+	}
+	r.reported = r.current
+	_, err = r.progress.Read(p[int64(len(p))-unreported:])
+	return len(p), err
+}
@@ -0,0 +1,136 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"syscall"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	. "gopkg.in/check.v1"
+)
+
+// TestRetryableError - tests transient errors are told apart from
+// permanent ones.
+func (s *TestSuite) TestRetryableError(c *C) {
+	testCases := []struct {
+		err       *probe.Error
+		retryable bool
+	}{
+		{nil, false},
+		{probe.NewError(context.Canceled), false},
+		{probe.NewError(io.ErrUnexpectedEOF), true},
+		{probe.NewError(syscall.ECONNRESET), true},
+		{probe.NewError(minio.ErrorResponse{Code: "SlowDown", StatusCode: 503}), true},
+		{probe.NewError(minio.ErrorResponse{Code: "InternalError", StatusCode: 500}), true},
+		{probe.NewError(minio.ErrorResponse{Code: "NoSuchKey", StatusCode: 404}), false},
+		{probe.NewError(minio.ErrorResponse{Code: "AccessDenied", StatusCode: 403}), false},
+	}
+	for i, testCase := range testCases {
+		c.Assert(isRetryableError(testCase.err), Equals, testCase.retryable, Commentf("Test %d", i+1))
+	}
+}
+
+// TestRetryPolicyRun - tests operations are retried on transient
+// errors only, as many times as the policy allows.
+func (s *TestSuite) TestRetryPolicyRun(c *C) {
+	ctx := context.Background()
+	policy := retryPolicy{attempts: 1, maxBackoff: time.Second}
+
+	calls := 0
+	err := policy.run(ctx, "mem://retry/a", func() *probe.Error {
+		calls++
+		if calls == 1 {
+			return probe.NewError(io.ErrUnexpectedEOF)
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(calls, Equals, 2)
+
+	calls = 0
+	err = policy.run(ctx, "mem://retry/b", func() *probe.Error {
+		calls++
+		return probe.NewError(minio.ErrorResponse{Code: "NoSuchKey", StatusCode: 404})
+	})
+	c.Assert(err, NotNil)
+	c.Assert(calls, Equals, 1)
+
+	calls = 0
+	err = retryPolicy{}.run(ctx, "mem://retry/c", func() *probe.Error {
+		calls++
+		return probe.NewError(io.ErrUnexpectedEOF)
+	})
+	c.Assert(err, NotNil)
+	c.Assert(calls, Equals, 1)
+
+	// Throttled attempts are reported before they are retried.
+	throttled := 0
+	policy.throttled = func() { throttled++ }
+	calls = 0
+	err = policy.run(ctx, "mem://retry/d", func() *probe.Error {
+		calls++
+		if calls == 1 {
+			return probe.NewError(minio.ErrorResponse{Code: "SlowDown", StatusCode: 503})
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(calls, Equals, 2)
+	c.Assert(throttled, Equals, 1)
+
+	// Operations which first attempt already failed, such as the
+	// removals of a batch, are only run again.
+	calls = 0
+	err = policy.retry(ctx, "mem://retry/e", probe.NewError(io.ErrUnexpectedEOF), func() *probe.Error {
+		calls++
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(calls, Equals, 1)
+}
+
+// progressCounter - counts the bytes reported to it.
+type progressCounter struct {
+	n int64
+}
+
+func (p *progressCounter) Read(b []byte) (int, error) {
+	p.n += int64(len(b))
+	return len(b), nil
+}
+
+// TestRetryProgress - tests bytes sent again by a retry are reported
+// once.
+func (s *TestSuite) TestRetryProgress(c *C) {
+	progress := &progressCounter{}
+	rp := &retryProgress{progress: progress}
+
+	n, e := rp.Read([]byte("hello"))
+	c.Assert(e, IsNil)
+	c.Assert(n, Equals, 5)
+
+	rp.reset()
+	for _, chunk := range []string{"hel", "lo wo", "rld"} {
+		_, e = rp.Read([]byte(chunk))
+		c.Assert(e, IsNil)
+	}
+	c.Assert(progress.n, Equals, int64(len("hello world")))
+}
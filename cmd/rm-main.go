@@ -73,18 +73,11 @@ var (
 			Name:  "stdin",
 			Usage: "read object names from STDIN",
 		},
-		cli.StringFlag{
-			Name:  "older-than",
-			Usage: "remove objects older than L days, M hours and N minutes",
-		},
-		cli.StringFlag{
-			Name:  "newer-than",
-			Usage: "remove objects newer than L days, M hours and N minutes",
-		},
 		cli.BoolFlag{
 			Name:  "bypass",
 			Usage: "bypass governance",
 		},
+		failedLogFlag,
 	}
 )
 
@@ -95,7 +88,7 @@ var rmCmd = cli.Command{
 	Action:       mainRm,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(rmFlags, ioFlags...), globalFlags...),
+	Flags:        append(append(append(append(rmFlags, filterFlags...), ioFlags...), retryFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -148,6 +141,15 @@ EXAMPLES:
   13. Remove all object versions older than one year.
       {{.Prompt}} {{.HelpName}} s3/docs/ --recursive --versions --rewind 365d
 
+  14. Remove an object, retrying up to 5 times while the server is unavailable.
+      {{.Prompt}} {{.HelpName}} --retry-attempts 5 s3/docs/money.xls
+
+  15. Remove all objects recursively, logging the objects failing to be removed to retry them later with 'mc replay'.
+      {{.Prompt}} {{.HelpName}} --recursive --force --failed-log /tmp/failed.json s3/jazz-songs/
+
+  16. Remove the objects tagged as temporary and stored in the reduced redundancy storage class recursively.
+      {{.Prompt}} {{.HelpName}} --recursive --force --match-tags "temporary=true" --match-storage-class REDUCED_REDUNDANCY s3/jazz-songs/
+
 `,
 }
 
@@ -240,7 +242,7 @@ func checkRmSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string
 }
 
 // Remove a single object or a single version in a versioned bucket
-func removeSingle(url, versionID string, isIncomplete, isFake, isForce, isBypass bool, olderThan, newerThan string, encKeyDB map[string][]prefixSSEPair) error {
+func removeSingle(url, versionID string, isIncomplete, isFake, isForce, isBypass bool, filter *objectFilter, encKeyDB map[string][]prefixSSEPair, retry retryPolicy, failed *failedLog) error {
 	ctx, cancel := context.WithCancel(globalContext)
 	defer cancel()
 
@@ -252,9 +254,8 @@ func removeSingle(url, versionID string, isIncomplete, isFake, isForce, isBypass
 		// so we simply ignore them.
 		ignoreStatError bool
 
-		isDir   bool
-		size    int64
-		modTime time.Time
+		isDir bool
+		size  int64
 	)
 
 	_, content, pErr := url2Stat(ctx, url, versionID, false, encKeyDB, time.Time{})
@@ -264,28 +265,26 @@ func removeSingle(url, versionID string, isIncomplete, isFake, isForce, isBypass
 			ignoreStatError = true
 		default:
 			errorIf(pErr.Trace(url), "Failed to remove `"+url+"`.")
+			failed.addRemove(url, versionID, isIncomplete, isBypass, pErr)
 			return exitStatus(globalErrorExitStatus)
 		}
 	} else {
 		isDir = content.Type.IsDir()
 		size = content.Size
-		modTime = content.Time
 	}
 
 	// We should not proceed
-	if ignoreStatError && olderThan != "" || newerThan != "" {
+	if ignoreStatError && filter != nil {
 		errorIf(pErr.Trace(url), "Unable to stat `"+url+"`.")
 		return exitStatus(globalErrorExitStatus)
 	}
 
-	// Skip objects older than older--than parameter if specified
-	if olderThan != "" && isOlder(modTime, olderThan) {
-		return nil
-	}
-
-	// Skip objects older than older--than parameter if specified
-	if newerThan != "" && isNewer(modTime, newerThan) {
-		return nil
+	// Skip objects the filter flags exclude
+	if !ignoreStatError {
+		alias, _, _ := mustExpandAlias(url)
+		if !filter.match(ctx, alias, content, filepath.Base(content.URL.Path)) {
+			return nil
+		}
 	}
 
 	printMsg(rmMessage{
@@ -306,21 +305,29 @@ func removeSingle(url, versionID string, isIncomplete, isFake, isForce, isBypass
 			targetURL = targetURL + string(clnt.GetURL().Separator)
 		}
 
-		contentCh := make(chan *ClientContent, 1)
-		contentCh <- &ClientContent{URL: *newClientURL(targetURL), VersionID: versionID}
-		close(contentCh)
-		isRemoveBucket := false
-		errorCh := clnt.Remove(ctx, isIncomplete, isRemoveBucket, isBypass, contentCh)
-		for pErr := range errorCh {
-			if pErr != nil {
-				errorIf(pErr.Trace(url), "Failed to remove `"+url+"`.")
-				switch pErr.ToGoError().(type) {
-				case PathInsufficientPermission:
-					// Ignore Permission error.
-					continue
+		pErr = retry.run(ctx, url, func() *probe.Error {
+			contentCh := make(chan *ClientContent, 1)
+			contentCh <- &ClientContent{URL: *newClientURL(targetURL), VersionID: versionID}
+			close(contentCh)
+			isRemoveBucket := false
+			errorCh := clnt.Remove(ctx, isIncomplete, isRemoveBucket, isBypass, contentCh)
+			for pErr := range errorCh {
+				if pErr != nil {
+					switch pErr.ToGoError().(type) {
+					case PathInsufficientPermission:
+						// Ignore Permission error.
+						errorIf(pErr.Trace(url), "Failed to remove `"+url+"`.")
+						continue
+					}
+					return pErr
 				}
-				return exitStatus(globalErrorExitStatus)
 			}
+			return nil
+		})
+		if pErr != nil {
+			errorIf(pErr.Trace(url), "Failed to remove `"+url+"`.")
+			failed.addRemove(url, versionID, isIncomplete, isBypass, pErr)
+			return exitStatus(globalErrorExitStatus)
 		}
 	}
 	return nil
@@ -330,7 +337,7 @@ func removeSingle(url, versionID string, isIncomplete, isFake, isForce, isBypass
 //   Use cases:
 //      * Remove objects recursively
 //      * Remove all versions of a single object
-func listAndRemove(url string, timeRef time.Time, withVersions, isRecursive, isIncomplete, isFake, isBypass bool, olderThan, newerThan string, encKeyDB map[string][]prefixSSEPair) error {
+func listAndRemove(url string, timeRef time.Time, withVersions, isRecursive, isIncomplete, isFake, isBypass bool, filter *objectFilter, encKeyDB map[string][]prefixSSEPair, retry retryPolicy, failed *failedLog) error {
 	ctx, cancelRemove := context.WithCancel(globalContext)
 	defer cancelRemove()
 
@@ -353,6 +360,7 @@ func listAndRemove(url string, timeRef time.Time, withVersions, isRecursive, isI
 	}
 
 	atLeastOneObjectFound := false
+	var rerr error
 
 	for content := range clnt.List(ctx, listOpts) {
 		if content.Err != nil {
@@ -374,7 +382,7 @@ func listAndRemove(url string, timeRef time.Time, withVersions, isRecursive, isI
 		}
 
 		if !isRecursive {
-			currentObjectURL := targetAlias + getKey(content)
+			currentObjectURL := getAliasedKey(targetAlias, content)
 			standardizedURL := getStandardizedURL(currentObjectURL)
 			if !strings.HasPrefix(url, standardizedURL) {
 				break
@@ -387,13 +395,8 @@ func listAndRemove(url string, timeRef time.Time, withVersions, isRecursive, isI
 		atLeastOneObjectFound = true
 
 		if !content.Time.IsZero() {
-			// Skip objects older than --older-than parameter, if specified
-			if olderThan != "" && isOlder(content.Time, olderThan) {
-				continue
-			}
-
-			// Skip objects newer than --newer-than parameter if specified
-			if newerThan != "" && isNewer(content.Time, newerThan) {
+			// Skip objects the filter flags exclude
+			if !filter.match(ctx, targetAlias, content, filterPath(content, url)) {
 				continue
 			}
 		} else {
@@ -415,12 +418,19 @@ func listAndRemove(url string, timeRef time.Time, withVersions, isRecursive, isI
 				case contentCh <- content:
 					sent = true
 				case pErr := <-errorCh:
+					if pErr = retryRemove(ctx, targetAlias, isIncomplete, isBypass, retry, pErr); pErr == nil {
+						continue
+					}
 					errorIf(pErr.Trace(urlString), "Failed to remove `"+urlString+"`.")
 					switch pErr.ToGoError().(type) {
 					case PathInsufficientPermission:
 						// Ignore Permission error.
 						continue
 					}
+					if logRemoveFailed(failed, targetAlias, isIncomplete, isBypass, pErr) {
+						rerr = exitStatus(globalErrorExitStatus)
+						continue
+					}
 					close(contentCh)
 					return exitStatus(globalErrorExitStatus)
 				}
@@ -430,12 +440,19 @@ func listAndRemove(url string, timeRef time.Time, withVersions, isRecursive, isI
 
 	close(contentCh)
 	for pErr := range errorCh {
+		if pErr = retryRemove(ctx, targetAlias, isIncomplete, isBypass, retry, pErr); pErr == nil {
+			continue
+		}
 		errorIf(pErr.Trace(url), "Failed to remove `"+url+"` recursively.")
 		switch pErr.ToGoError().(type) {
 		case PathInsufficientPermission:
 			// Ignore Permission error.
 			continue
 		}
+		if logRemoveFailed(failed, targetAlias, isIncomplete, isBypass, pErr) {
+			rerr = exitStatus(globalErrorExitStatus)
+			continue
+		}
 		return exitStatus(globalErrorExitStatus)
 	}
 
@@ -444,7 +461,52 @@ func listAndRemove(url string, timeRef time.Time, withVersions, isRecursive, isI
 		return exitStatus(globalErrorExitStatus)
 	}
 
-	return nil
+	return rerr
+}
+
+// retryRemove - retries the removal of the object a batched removal
+// from alias failed on with err, returns the error of the last attempt.
+// Batches are not retried whole, only the objects failing in them.
+func retryRemove(ctx context.Context, alias string, isIncomplete, isBypass bool, retry retryPolicy, err *probe.Error) *probe.Error {
+	if retry.attempts <= 0 || !isRetryableError(err) {
+		return err
+	}
+	urlStr, versionID, ok := removeFailedObject(alias, err)
+	if !ok {
+		return err
+	}
+	targetAlias, targetURL, _ := mustExpandAlias(urlStr)
+	clnt, pErr := newClientFromAlias(targetAlias, targetURL)
+	if pErr != nil {
+		return err
+	}
+	return retry.retry(ctx, urlStr, err, func() *probe.Error {
+		contentCh := make(chan *ClientContent, 1)
+		contentCh <- &ClientContent{URL: *newClientURL(targetURL), VersionID: versionID}
+		close(contentCh)
+		isRemoveBucket := false
+		var rerr *probe.Error
+		for err := range clnt.Remove(ctx, isIncomplete, isRemoveBucket, isBypass, contentCh) {
+			if err != nil && rerr == nil {
+				rerr = err
+			}
+		}
+		return rerr
+	})
+}
+
+// logRemoveFailed - logs the object a removal from alias failed on,
+// returns false when there is no failed log or err does not name the
+// object. Removals go on past failures logged to be replayed.
+func logRemoveFailed(failed *failedLog, alias string, isIncomplete, isBypass bool, err *probe.Error) bool {
+	if failed == nil {
+		return false
+	}
+	urlStr, versionID, ok := removeFailedObject(alias, err)
+	if ok {
+		failed.addRemove(urlStr, versionID, isIncomplete, isBypass, err)
+	}
+	return ok
 }
 
 // main for rm command.
@@ -465,12 +527,14 @@ func mainRm(cliCtx *cli.Context) error {
 	isFake := cliCtx.Bool("fake")
 	isStdin := cliCtx.Bool("stdin")
 	isBypass := cliCtx.Bool("bypass")
-	olderThan := cliCtx.String("older-than")
-	newerThan := cliCtx.String("newer-than")
+	filter := newObjectFilterFromContext(cliCtx)
 	isForce := cliCtx.Bool("force")
 	withVersions := cliCtx.Bool("versions")
 	versionID := cliCtx.String("version-id")
 	rewind := parseRewindFlag(cliCtx.String("rewind"))
+	retry := retryPolicyFromContext(cliCtx)
+	failed := openFailedLog(cliCtx, "rm")
+	defer failed.Close()
 
 	if withVersions && rewind.IsZero() {
 		rewind = time.Now().UTC()
@@ -484,9 +548,9 @@ func mainRm(cliCtx *cli.Context) error {
 	// Support multiple targets.
 	for _, url := range cliCtx.Args() {
 		if isRecursive || withVersions {
-			e = listAndRemove(url, rewind, withVersions, isRecursive, isIncomplete, isFake, isBypass, olderThan, newerThan, encKeyDB)
+			e = listAndRemove(url, rewind, withVersions, isRecursive, isIncomplete, isFake, isBypass, filter, encKeyDB, retry, failed)
 		} else {
-			e = removeSingle(url, versionID, isIncomplete, isFake, isForce, isBypass, olderThan, newerThan, encKeyDB)
+			e = removeSingle(url, versionID, isIncomplete, isFake, isForce, isBypass, filter, encKeyDB, retry, failed)
 		}
 		if rerr == nil {
 			rerr = e
@@ -501,9 +565,9 @@ func mainRm(cliCtx *cli.Context) error {
 	for scanner.Scan() {
 		url := scanner.Text()
 		if isRecursive || withVersions {
-			e = listAndRemove(url, rewind, withVersions, isRecursive, isIncomplete, isFake, isBypass, olderThan, newerThan, encKeyDB)
+			e = listAndRemove(url, rewind, withVersions, isRecursive, isIncomplete, isFake, isBypass, filter, encKeyDB, retry, failed)
 		} else {
-			e = removeSingle(url, versionID, isIncomplete, isFake, isForce, isBypass, olderThan, newerThan, encKeyDB)
+			e = removeSingle(url, versionID, isIncomplete, isFake, isForce, isBypass, filter, encKeyDB, retry, failed)
 		}
 		if rerr == nil {
 			rerr = e
@@ -0,0 +1,94 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio/pkg/console"
+)
+
+var sessionClearCmd = cli.Command{
+	Name:         "clear",
+	Usage:        "remove interrupted sessions",
+	Action:       mainSessionClear,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] SESSION-ID|all
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Remove a session, the interrupted command starts over when run again.
+     {{.Prompt}} {{.HelpName}} mirror-3f9c0a1b2c3d4e5f
+
+  2. Remove all sessions.
+     {{.Prompt}} {{.HelpName}} all
+`,
+}
+
+// clearSessionMessage container for clearing session messages.
+type clearSessionMessage struct {
+	Status    string `json:"success"`
+	SessionID string `json:"sessionId"`
+}
+
+// String colorized clear session message.
+func (c clearSessionMessage) String() string {
+	return console.Colorize("ClearSession", "Session `"+c.SessionID+"` cleared successfully.")
+}
+
+// JSON jsonified clear session message.
+func (c clearSessionMessage) JSON() string {
+	c.Status = "success"
+	clearSessionJSONBytes, e := json.MarshalIndent(c, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(clearSessionJSONBytes)
+}
+
+// clearSession removes the session sid and its data file.
+func clearSession(sid string) {
+	s := loadSessionHeader(sid)
+	fatalIf(s.Delete().Trace(sid), "Unable to remove session `"+sid+"`.")
+	printMsg(clearSessionMessage{SessionID: sid})
+}
+
+// mainSessionClear is the handle for "mc session clear" command.
+func mainSessionClear(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "clear", 1) // last argument is exit code
+	}
+
+	setSessionColors()
+
+	if sid := ctx.Args().First(); sid != "all" {
+		clearSession(sid)
+		return nil
+	}
+	for _, sid := range getSessionIDs() {
+		clearSession(sid)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/minio/cli"
+)
+
+var sessionListCmd = cli.Command{
+	Name:         "list",
+	Usage:        "list interrupted sessions",
+	Action:       mainSessionList,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. List sessions of interrupted cp, mv and mirror commands run with --continue.
+     {{.Prompt}} {{.HelpName}}
+`,
+}
+
+// mainSessionList is the handle for "mc session list" command.
+func mainSessionList(ctx *cli.Context) error {
+	if len(ctx.Args()) != 0 {
+		cli.ShowCommandHelpAndExit(ctx, "list", 1) // last argument is exit code
+	}
+
+	setSessionColors()
+
+	for _, sid := range getSessionIDs() {
+		printMsg(loadSessionHeader(sid))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/minio/pkg/console"
+)
+
+var sessionSubcommands = []cli.Command{
+	sessionListCmd,
+	sessionResumeCmd,
+	sessionClearCmd,
+}
+
+var sessionCmd = cli.Command{
+	Name:            "session",
+	Usage:           "resume interrupted operations",
+	Action:          mainSession,
+	Before:          setGlobalsFromContext,
+	Flags:           globalFlags,
+	HideHelpCommand: true,
+	Subcommands:     sessionSubcommands,
+}
+
+func mainSession(ctx *cli.Context) error {
+	commandNotFound(ctx, sessionSubcommands)
+	return nil
+}
+
+// setSessionColors - sets the colors of session messages.
+func setSessionColors() {
+	console.SetColor("Command", color.New(color.FgWhite, color.Bold))
+	console.SetColor("SessionID", color.New(color.FgYellow, color.Bold))
+	console.SetColor("SessionTime", color.New(color.FgGreen))
+	console.SetColor("ClearSession", color.New(color.FgGreen, color.Bold))
+}
+
+// loadSessionHeader - loads the session sid, its data file is not used.
+func loadSessionHeader(sid string) *sessionV8 {
+	if !isSessionExists(sid) {
+		fatalIf(errDummy().Trace(sid), "Session `"+sid+"` not found.")
+	}
+	s, err := loadSessionV8(sid)
+	fatalIf(err.Trace(sid), "Unable to load session `"+sid+"`.")
+	s.DataFP.Close()
+	return s
+}
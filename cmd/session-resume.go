@@ -0,0 +1,106 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+var sessionResumeCmd = cli.Command{
+	Name:         "resume",
+	Usage:        "resume an interrupted session",
+	Action:       mainSessionResume,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] SESSION-ID
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Resume an interrupted mirror, from the folder and with the flags it was started with.
+     {{.Prompt}} {{.HelpName}} mirror-3f9c0a1b2c3d4e5f
+`,
+}
+
+// sessionCommand - returns the command of session along with the
+// flags running it again.
+func sessionCommand(s *sessionV8) (cli.Command, []string, *probe.Error) {
+	switch s.Header.CommandType {
+	case "mirror":
+		return mirrorCmd, s.commandLine(mirrorCmd.Flags), nil
+	case "cp", "mv":
+		cmd := cpCmd
+		if s.Header.CommandType == "mv" {
+			cmd = mvCmd
+		}
+		// cp and mv read their flags back from the session once
+		// loaded, only those their syntax checks depend on are passed.
+		var flags []cli.Flag
+		for _, f := range cmd.Flags {
+			if _, ok := f.(cli.BoolFlag); ok {
+				flags = append(flags, f)
+			}
+		}
+		return cmd, s.commandLine(flags), nil
+	}
+	return cli.Command{}, nil, errInvalidArgument().Trace(s.Header.CommandType)
+}
+
+// mainSessionResume is the handle for "mc session resume" command.
+func mainSessionResume(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "resume", 1) // last argument is exit code
+	}
+
+	sid := ctx.Args().First()
+	s := loadSessionHeader(sid)
+
+	cmd, args, err := sessionCommand(s)
+	fatalIf(err.Trace(sid), "Session `"+sid+"` of `"+s.Header.CommandType+"` cannot be resumed.")
+
+	// Relative URLs are relative to where the session started.
+	e := os.Chdir(s.Header.RootPath)
+	fatalIf(probe.NewError(e).Trace(s.Header.RootPath), "Unable to change to the session folder.")
+
+	set := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+	for _, f := range cmd.Flags {
+		f.Apply(set)
+	}
+	e = set.Parse(append([]string{"--continue"}, args...))
+	fatalIf(probe.NewError(e).Trace(sid), "Unable to parse the flags of session `"+sid+"`.")
+
+	cmdCtx := cli.NewContext(ctx.App, set, ctx)
+	cmdCtx.Command = cmd
+	if cmd.Before != nil {
+		if e = cmd.Before(cmdCtx); e != nil {
+			return e
+		}
+	}
+	return cli.HandleAction(cmd.Action, cmdCtx)
+}
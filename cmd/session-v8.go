@@ -23,10 +23,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/minio/cli"
 	json "github.com/minio/mc/pkg/colorjson"
 	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/minio/pkg/console"
@@ -48,6 +50,7 @@ type sessionV8Header struct {
 	CommandStringFlags map[string]string `json:"cmdStringFlags"`
 	LastCopied         string            `json:"lastCopied"`
 	LastRemoved        string            `json:"lastRemoved"`
+	DiffCursor         string            `json:"diffCursor,omitempty"`
 	TotalBytes         int64             `json:"totalBytes"`
 	TotalObjects       int64             `json:"totalObjects"`
 	UserMetaData       map[string]string `json:"metaData"`
@@ -195,7 +198,7 @@ func newSessionV8(sessionID string) *sessionV8 {
 
 // HasData provides true if this is a session resume, false otherwise.
 func (s sessionV8) HasData() bool {
-	return s.Header.LastCopied != "" || s.Header.LastRemoved != ""
+	return s.Header.LastCopied != "" || s.Header.LastRemoved != "" || s.Header.DiffCursor != ""
 }
 
 // NewDataReader provides reader interface to session data file.
@@ -215,6 +218,24 @@ func (s *sessionV8) NewDataWriter() io.Writer {
 	return io.Writer(s.DataFP)
 }
 
+// NewDataAppender provides writer interface appending to session data
+// file, for commands checkpointing their progress as they go instead of
+// preparing all URLs upfront.
+func (s *sessionV8) NewDataAppender() (io.Writer, *probe.Error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Loaded sessions have their data file opened read only.
+	name := s.DataFP.Name()
+	s.DataFP.Close()
+	dataFile, e := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(name)
+	}
+	s.DataFP = &sessionDataFP{false, dataFile}
+	return s.DataFP, nil
+}
+
 // Save this session.
 func (s *sessionV8) Save() *probe.Error {
 	s.mutex.Lock()
@@ -251,6 +272,70 @@ func (s *sessionV8) setGlobals() {
 	s.Header.GlobalBoolFlags["json"] = globalJSON
 	s.Header.GlobalBoolFlags["noColor"] = globalNoColor
 	s.Header.GlobalBoolFlags["insecure"] = globalInsecure
+	s.Header.GlobalBoolFlags["cache"] = globalCache
+}
+
+// sessionFlagName - returns the long name of a command flag.
+func sessionFlagName(f cli.Flag) string {
+	return strings.TrimSpace(strings.Split(f.GetName(), ",")[0])
+}
+
+// setCommandFlags records the flags of ctx set among flags into the
+// session header, for `mc session resume` to run the command again.
+// Values of repeated flags are newline separated.
+func (s *sessionV8) setCommandFlags(ctx *cli.Context, flags []cli.Flag) {
+	for _, f := range flags {
+		name := sessionFlagName(f)
+		if !ctx.IsSet(name) {
+			continue
+		}
+		switch f.(type) {
+		case cli.BoolFlag:
+			s.Header.CommandBoolFlags[name] = ctx.Bool(name)
+		case cli.IntFlag:
+			s.Header.CommandIntFlags[name] = ctx.Int(name)
+		case cli.StringSliceFlag:
+			s.Header.CommandStringFlags[name] = strings.Join(ctx.StringSlice(name), "\n")
+		case cli.StringFlag:
+			s.Header.CommandStringFlags[name] = ctx.String(name)
+		case cli.DurationFlag:
+			s.Header.CommandStringFlags[name] = ctx.Duration(name).String()
+		default:
+			panic(fmt.Sprintf("flag `%s` of type %T cannot be saved in a session", name, f))
+		}
+	}
+}
+
+// commandLine returns the flags and arguments running the session
+// command again, flags being those the command accepts.
+func (s *sessionV8) commandLine(flags []cli.Flag) []string {
+	var args []string
+	for _, f := range flags {
+		name := sessionFlagName(f)
+		switch f.(type) {
+		case cli.BoolFlag:
+			if s.Header.CommandBoolFlags[name] {
+				args = append(args, "--"+name)
+			}
+		case cli.IntFlag:
+			if v, ok := s.Header.CommandIntFlags[name]; ok {
+				args = append(args, "--"+name+"="+strconv.Itoa(v))
+			}
+		case cli.StringSliceFlag:
+			if v := s.Header.CommandStringFlags[name]; v != "" {
+				for _, value := range strings.Split(v, "\n") {
+					args = append(args, "--"+name+"="+value)
+				}
+			}
+		case cli.StringFlag, cli.DurationFlag:
+			if v := s.Header.CommandStringFlags[name]; v != "" {
+				args = append(args, "--"+name+"="+v)
+			}
+		default:
+			panic(fmt.Sprintf("flag `%s` of type %T cannot be restored from a session", name, f))
+		}
+	}
+	return append(append(args, "--"), s.Header.CommandArgs...)
 }
 
 // IsModified - returns if in memory session header has changed from
@@ -17,9 +17,11 @@
 package cmd
 
 import (
+	"flag"
 	"os"
 	"regexp"
 
+	"github.com/minio/cli"
 	. "gopkg.in/check.v1"
 )
 
@@ -58,3 +60,79 @@ func (s *TestSuite) TestSession(c *C) {
 	_, e = os.Stat(session.DataFP.Name())
 	c.Assert(e, NotNil)
 }
+
+func (s *TestSuite) TestMirrorSession(c *C) {
+	err := createSessionDir()
+	c.Assert(err, IsNil)
+
+	session := newSessionV8(getHash("mirror", []string{"mem://src", "mem://dst"}))
+	ms, pending, err := newMirrorSession(session)
+	c.Assert(err, IsNil)
+	c.Assert(pending, HasLen, 0)
+
+	var urls []URLs
+	for _, object := range []string{"a", "b", "c"} {
+		urls = append(urls, URLs{
+			SourceContent: &ClientContent{URL: *newClientURL("mem://src/" + object)},
+			TargetContent: &ClientContent{URL: *newClientURL("mem://dst/" + object)},
+		})
+	}
+	for _, sURLs := range urls {
+		queued, err := ms.queue(sURLs)
+		c.Assert(err, IsNil)
+		c.Assert(queued, Equals, true)
+	}
+	c.Assert(ms.done(urls[0]), IsNil)
+	c.Assert(session.Close(), IsNil)
+
+	// Queued URLs not done are replayed once on resume.
+	savedSession, err := loadSessionV8(session.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(savedSession.HasData(), Equals, true)
+	c.Assert(savedSession.Header.DiffCursor, Equals, "mem://dst/c")
+	ms, pending, err = newMirrorSession(savedSession)
+	c.Assert(err, IsNil)
+	c.Assert(pending, HasLen, 2)
+	c.Assert(pending[0].SourceContent.URL.String(), Equals, "mem://src/b")
+	c.Assert(pending[1].SourceContent.URL.String(), Equals, "mem://src/c")
+	queued, err := ms.queue(pending[0])
+	c.Assert(err, IsNil)
+	c.Assert(queued, Equals, false)
+
+	c.Assert(isBeforeDiffCursor("mem://dst/b", savedSession.Header.DiffCursor), Equals, true)
+	c.Assert(isBeforeDiffCursor("mem://dst/d", savedSession.Header.DiffCursor), Equals, false)
+	c.Assert(isBeforeDiffCursor("mem://dst/a", ""), Equals, false)
+
+	c.Assert(savedSession.Close(), IsNil)
+	c.Assert(savedSession.Delete(), IsNil)
+}
+
+func (s *TestSuite) TestSessionCommandLine(c *C) {
+	flags := []cli.Flag{
+		cli.BoolFlag{Name: "overwrite"},
+		cli.BoolFlag{Name: "remove"},
+		cli.StringFlag{Name: "storage-class, sc"},
+		cli.StringSliceFlag{Name: "exclude"},
+		cli.IntFlag{Name: "retry-attempts"},
+		cli.DurationFlag{Name: "retry-max-backoff"},
+	}
+	set := flag.NewFlagSet("mirror", flag.ContinueOnError)
+	for _, f := range flags {
+		f.Apply(set)
+	}
+	c.Assert(set.Parse([]string{"--overwrite", "--sc=REDUCED_REDUNDANCY", "--exclude=*.tmp", "--exclude=*.log", "--retry-attempts=5", "--retry-max-backoff=1m", "src", "dst"}), IsNil)
+
+	session := newSessionV8(getHash("mirror", set.Args()))
+	session.setCommandFlags(cli.NewContext(nil, set, nil), flags)
+	session.Header.CommandArgs = set.Args()
+	c.Assert(session.commandLine(flags), DeepEquals, []string{
+		"--overwrite", "--storage-class=REDUCED_REDUNDANCY", "--exclude=*.tmp", "--exclude=*.log",
+		"--retry-attempts=5", "--retry-max-backoff=1m0s", "--", "src", "dst",
+	})
+
+	// All the flags of mirror can be restored, others fail loudly.
+	session.commandLine(mirrorCmd.Flags)
+	c.Assert(func() { session.commandLine([]cli.Flag{cli.Float64Flag{Name: "ratio"}}) }, PanicMatches, "flag `ratio` of type cli.Float64Flag cannot be restored from a session")
+	c.Assert(session.Close(), IsNil)
+	c.Assert(session.Delete(), IsNil)
+}
@@ -145,7 +145,7 @@ func saveSharedURL(objectURL string, shareURL string, expiry time.Duration, cont
 
 // doShareUploadURL uploads files to the target.
 func doShareUploadURL(ctx context.Context, objectURL string, isRecursive bool, expiry time.Duration, contentType string) *probe.Error {
-	clnt, err := newClient(objectURL)
+	clnt, err := newClientWithCapability(objectURL, capShare)
 	if err != nil {
 		return err.Trace(objectURL)
 	}
@@ -93,7 +93,8 @@ FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}{{end}}
 ENVIRONMENT VARIABLES:
-  MC_ENCRYPT_KEY: list of comma delimited prefix=secret values
+  MC_ENCRYPT_KEY:        list of comma delimited prefix=secret values
+  MC_ENCRYPT_CLIENT_KEY: list of comma delimited prefix=secret values for client-side encryption
 
 SERIALIZATION OPTIONS:
   For query serialization options, refer to https://docs.min.io/docs/minio-client-complete-guide#sql
@@ -392,10 +393,12 @@ func sqlSelect(targetURL, expression string, encKeyDB map[string][]prefixSSEPair
 		return err.Trace(targetURL)
 	}
 
-	targetClnt, err := newClient(targetURL)
+	targetClnt, err := newClientWithCapability(targetURL, capSelect)
 	if err != nil {
 		return err.Trace(targetURL)
 	}
+	// Client-side encrypted objects are decrypted and queried locally.
+	targetClnt = withClientEncryption(targetClnt, alias, getClientEncKey(targetURL, encKeyDB[alias]))
 
 	sseKey := getSSE(targetURL, encKeyDB[alias])
 	outputer, err := targetClnt.Select(ctx, expression, sseKey, selOpts)
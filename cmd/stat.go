@@ -49,6 +49,7 @@ type statMessage struct {
 	ExpirationRuleID  string            `json:"expirationRuleID"`
 	ReplicationStatus string            `json:"replicationStatus"`
 	Metadata          map[string]string `json:"metadata"`
+	ClientEncryption  string            `json:"clientEncryption,omitempty"`
 	VersionID         string            `json:"versionID,omitempty"`
 	DeleteMarker      bool              `json:"deleteMarker,omitempty"`
 	singleObject      bool
@@ -79,9 +80,16 @@ func (stat statMessage) String() (msg string) {
 		msgBuilder.WriteString(fmt.Sprintf("%-10s: %s (lifecycle-rule-id: %s) ", "Expiration",
 			stat.Expiration.Local().Format(printDate), stat.ExpirationRuleID) + "\n")
 	}
+	if stat.ClientEncryption != "" {
+		msgBuilder.WriteString(fmt.Sprintf("%-10s: client-side (%s) ", "Encryption", stat.ClientEncryption) + "\n")
+	}
 	var maxKeyMetadata = 0
 	var maxKeyEncrypted = 0
 	for k := range stat.Metadata {
+		// Client-side encryption is printed above.
+		if isClientEncryptionMetaKey(k) {
+			continue
+		}
 		// Skip encryption headers, we print them later.
 		if !strings.HasPrefix(strings.ToLower(k), serverEncryptionKeyPrefix) {
 			if len(k) > maxKeyMetadata {
@@ -97,7 +105,7 @@ func (stat statMessage) String() (msg string) {
 		msgBuilder.WriteString(fmt.Sprintf("%-10s:", "Metadata") + "\n")
 		for k, v := range stat.Metadata {
 			// Skip encryption headers, we print them later.
-			if !strings.HasPrefix(strings.ToLower(k), serverEncryptionKeyPrefix) {
+			if !strings.HasPrefix(strings.ToLower(k), serverEncryptionKeyPrefix) && !isClientEncryptionMetaKey(k) {
 				msgBuilder.WriteString(fmt.Sprintf("  %-*.*s: %s ", maxKeyMetadata, maxKeyMetadata, k, v) + "\n")
 			}
 		}
@@ -148,6 +156,9 @@ func parseStat(c *ClientContent) statMessage {
 	content.Expiration = c.Expiration
 	content.ExpirationRuleID = c.ExpirationRuleID
 	content.ReplicationStatus = c.ReplicationStatus
+	if _, ok := clientEncryptionIV(c); ok {
+		content.ClientEncryption = clientEncryptionScheme
+	}
 	return content
 }
 
@@ -163,7 +174,7 @@ func statURL(ctx context.Context, targetURL, versionID string, timeRef time.Time
 	var stats []*ClientContent
 	var bucketStats []*BucketInfo
 	var clnt Client
-	clnt, err := newClient(targetURL)
+	clnt, err := newClientWithKeys(targetURL, encKeyDB)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -212,7 +223,7 @@ func statURL(ctx context.Context, targetURL, versionID string, timeRef time.Time
 			continue
 		}
 
-		url := targetAlias + getKey(content)
+		url := getAliasedKey(targetAlias, content)
 		standardizedURL := getStandardizedURL(targetURL)
 
 		if !isRecursive && !strings.HasPrefix(url, standardizedURL) {
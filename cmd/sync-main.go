@@ -0,0 +1,502 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio/pkg/console"
+)
+
+const (
+	syncConflictNewer    = "newer"
+	syncConflictKeepBoth = "keep-both"
+	syncConflictFail     = "fail"
+)
+
+var syncFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "conflict",
+		Value: syncConflictFail,
+		Usage: "resolve object(s) changed on both sides, one of 'newer', 'keep-both' or 'fail'",
+	},
+	cli.StringFlag{
+		Name:  "state",
+		Usage: "keep the state of the last sync in FILE instead of the mc configuration folder",
+	},
+	cli.BoolFlag{
+		Name:  "fake",
+		Usage: "perform a fake sync operation",
+	},
+	cli.StringFlag{
+		Name:  "encrypt",
+		Usage: "encrypt/decrypt objects (using server-side encryption with server managed keys)",
+	},
+}
+
+// Sync two folders both ways.
+var syncCmd = cli.Command{
+	Name:         "sync",
+	Usage:        "synchronize two folders both ways",
+	Action:       mainSync,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(append(append(append(syncFlags, ioFlags...), workerFlags...), retryFlags...), globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] FIRST SECOND
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+ENVIRONMENT VARIABLES:
+  MC_ENCRYPT:            list of comma delimited prefixes
+  MC_ENCRYPT_KEY:        list of comma delimited prefix=secret values
+  MC_ENCRYPT_CLIENT_KEY: list of comma delimited prefix=secret values for client-side encryption
+
+  The version of each object on both sides is recorded when they are synced. Objects
+  created, modified or removed on one side since then are copied or removed on the
+  other side. Objects changed on both sides are conflicts, resolved by --conflict:
+    newer:     the most recently modified side wins, a modification wins over a removal.
+    keep-both: as with newer, the other version being kept on both sides as NAME.sync-conflict-TIME.EXT.
+    fail:      the object is left as is and reported, the default.
+  Objects present on both sides when first synced are in sync when of the same size and ETag.
+
+EXAMPLES:
+  1. Synchronize a local folder with a bucket both ways.
+     {{.Prompt}} {{.HelpName}} ~/documents/ play/mybucket/documents/
+
+  2. Synchronize two buckets both ways, the most recently modified object winning conflicts.
+     {{.Prompt}} {{.HelpName}} --conflict newer s3/mybucket/ play/mybucket/
+
+  3. Synchronize a local folder with a bucket both ways, keeping both versions of conflicting objects.
+     {{.Prompt}} {{.HelpName}} --conflict keep-both ~/documents/ play/mybucket/documents/
+
+  4. Show what a sync would do, without syncing.
+     {{.Prompt}} {{.HelpName}} --fake ~/documents/ play/mybucket/documents/
+
+  5. Synchronize a local folder with a bucket both ways, keeping the state along with the folder.
+     {{.Prompt}} {{.HelpName}} --state ~/documents.sync.json ~/documents/ play/mybucket/documents/
+`,
+}
+
+// syncMessage - a copy or a removal of a sync.
+type syncMessage struct {
+	Status string `json:"status"`
+	Action string `json:"action"`
+	Source string `json:"source,omitempty"`
+	Target string `json:"target"`
+}
+
+// Colorized message for console printing.
+func (s syncMessage) String() string {
+	if s.Action == "remove" {
+		return console.Colorize("Sync", fmt.Sprintf("Removed `%s`.", s.Target))
+	}
+	return console.Colorize("Sync", fmt.Sprintf("`%s` -> `%s`", s.Source, s.Target))
+}
+
+// JSON'ified message for scripting.
+func (s syncMessage) JSON() string {
+	s.Status = "success"
+	msgBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// syncSide - one of the folders of a sync.
+type syncSide struct {
+	alias string
+	url   string // aliased URL of the folder, ending with a separator
+}
+
+// newSyncSide - returns the side of aliased urlStr.
+func newSyncSide(urlStr string) syncSide {
+	separator := string(newClientURL(urlStr).Separator)
+	if !strings.HasSuffix(urlStr, separator) {
+		urlStr += separator
+	}
+	alias, _, _ := mustExpandAlias(urlStr)
+	return syncSide{alias: alias, url: urlStr}
+}
+
+// join - returns the aliased URL of key on the side.
+func (s syncSide) join(key string) string {
+	return urlJoinPath(s.url, key)
+}
+
+type syncOptions struct {
+	conflict string
+	isFake   bool
+	workers  workerOptions
+	retry    retryPolicy
+	encKeyDB map[string][]prefixSSEPair
+}
+
+// syncJob - syncs first and second, recording the versions synced in
+// state.
+type syncJob struct {
+	first, second syncSide
+	opts          syncOptions
+	state         *syncState
+}
+
+// syncPair - the contents of a key on both sides, nil when missing.
+type syncPair struct {
+	first, second *ClientContent
+}
+
+// list - returns the contents of the keys on either side or in the
+// state. Listing is complete or fails, so that a failed listing is
+// never taken for removals.
+func (j *syncJob) list(ctx context.Context) (map[string]*syncPair, *probe.Error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	firstAlias, firstURL, _ := mustExpandAlias(j.first.url)
+	firstClnt, err := newClientFromAliasWithKeys(firstAlias, firstURL, j.opts.encKeyDB)
+	if err != nil {
+		return nil, err.Trace(j.first.url)
+	}
+	secondAlias, secondURL, _ := mustExpandAlias(j.second.url)
+	secondClnt, err := newClientFromAliasWithKeys(secondAlias, secondURL, j.opts.encKeyDB)
+	if err != nil {
+		return nil, err.Trace(j.second.url)
+	}
+
+	pairs := make(map[string]*syncPair)
+	pair := func(key string) *syncPair {
+		key = filepath.ToSlash(key)
+		if pairs[key] == nil {
+			pairs[key] = &syncPair{}
+		}
+		return pairs[key]
+	}
+	for diffMsg := range difference(ctx, firstClnt, secondClnt, firstURL, secondURL, false, nil, true, true, DirNone, time.Time{}) {
+		if diffMsg.Error != nil {
+			return nil, diffMsg.Error.Trace(j.first.url, j.second.url)
+		}
+		if diffMsg.Diff == differInType {
+			return nil, errInvalidTarget(diffMsg.SecondURL)
+		}
+		if diffMsg.firstContent != nil {
+			pair(strings.TrimPrefix(diffMsg.FirstURL, firstURL)).first = diffMsg.firstContent
+		}
+		if diffMsg.secondContent != nil {
+			pair(strings.TrimPrefix(diffMsg.SecondURL, secondURL)).second = diffMsg.secondContent
+		}
+	}
+
+	// Keys removed from both sides are forgotten.
+	for _, key := range j.state.keys() {
+		pair(key)
+	}
+	return pairs, nil
+}
+
+// copy - copies content from key on a side to toKey on another side,
+// returns the copy.
+func (j *syncJob) copy(ctx context.Context, content *ClientContent, from syncSide, key string, to syncSide, toKey string) (*ClientContent, *probe.Error) {
+	msg := syncMessage{Action: "copy", Source: from.join(key), Target: to.join(toKey)}
+	if j.opts.isFake {
+		printMsg(msg)
+		return nil, nil
+	}
+
+	targetAlias, targetURL, _ := mustExpandAlias(msg.Target)
+	urls := uploadSourceToTargetURL(ctx, URLs{
+		SourceAlias:   from.alias,
+		SourceContent: content,
+		TargetAlias:   targetAlias,
+		TargetContent: &ClientContent{
+			URL:          *newClientURL(targetURL),
+			Metadata:     map[string]string{},
+			UserMetadata: map[string]string{},
+		},
+		Retry: j.opts.retry,
+	}, nil, j.opts.encKeyDB, false)
+	if urls.Error != nil {
+		return nil, urls.Error.Trace(msg.Source, msg.Target)
+	}
+	_, st, err := url2Stat(ctx, msg.Target, "", false, j.opts.encKeyDB, time.Time{})
+	if err != nil {
+		return nil, err.Trace(msg.Target)
+	}
+	printMsg(msg)
+	return st, nil
+}
+
+// remove - removes key from a side.
+func (j *syncJob) remove(ctx context.Context, side syncSide, key string) *probe.Error {
+	msg := syncMessage{Action: "remove", Target: side.join(key)}
+	if j.opts.isFake {
+		printMsg(msg)
+		return nil
+	}
+
+	clnt, err := newClientWithKeys(msg.Target, j.opts.encKeyDB)
+	if err != nil {
+		return err.Trace(msg.Target)
+	}
+	err = j.opts.retry.run(ctx, msg.Target, func() *probe.Error {
+		contentCh := make(chan *ClientContent, 1)
+		contentCh <- &ClientContent{URL: clnt.GetURL()}
+		close(contentCh)
+		isIncomplete, isRemoveBucket, isBypass := false, false, false
+		var rerr *probe.Error
+		for err := range clnt.Remove(ctx, isIncomplete, isRemoveBucket, isBypass, contentCh) {
+			if err != nil && rerr == nil {
+				rerr = err
+			}
+		}
+		return rerr
+	})
+	if err != nil {
+		return err.Trace(msg.Target)
+	}
+	printMsg(msg)
+	return nil
+}
+
+// syncConflictKey - returns the key keeping the version of key
+// modified at modTime, losing a conflict.
+func syncConflictKey(key string, modTime time.Time) string {
+	ext := path.Ext(key)
+	return strings.TrimSuffix(key, ext) + ".sync-conflict-" + modTime.UTC().Format("20060102-150405") + ext
+}
+
+// resolveSyncConflict - returns the action resolving a conflict by
+// policy, syncConflict when it is left as is.
+func resolveSyncConflict(policy string, first, second *ClientContent) syncAction {
+	switch {
+	case policy == syncConflictFail:
+		return syncConflict
+	case first == nil:
+		return syncCopyToFirst
+	case second == nil:
+		return syncCopyToSecond
+	case second.Time.After(first.Time):
+		return syncCopyToFirst
+	}
+	return syncCopyToSecond
+}
+
+// keepConflict - copies the version of key losing a conflict, on side
+// from, to its conflict key on both sides.
+func (j *syncJob) keepConflict(ctx context.Context, key string, content *ClientContent, from syncSide) *probe.Error {
+	conflictKey := syncConflictKey(key, content.Time)
+	first, err := j.copy(ctx, content, from, key, j.first, conflictKey)
+	if err != nil {
+		return err
+	}
+	second, err := j.copy(ctx, content, from, key, j.second, conflictKey)
+	if err != nil {
+		return err
+	}
+	if first != nil && second != nil {
+		j.state.set(conflictKey, syncStateEntry{First: syncVersion(first), Second: syncVersion(second)})
+	}
+	return nil
+}
+
+// sync - syncs key, recording the versions synced.
+func (j *syncJob) sync(ctx context.Context, key string, pair *syncPair) *probe.Error {
+	var entry *syncStateEntry
+	if e, ok := j.state.get(key); ok {
+		entry = &e
+	}
+
+	action := decideSync(entry, pair.first, pair.second)
+	if action == syncConflict {
+		action = resolveSyncConflict(j.opts.conflict, pair.first, pair.second)
+		if action == syncConflict {
+			return errSyncConflict(j.first.join(key), j.second.join(key))
+		}
+		if j.opts.conflict == syncConflictKeepBoth && pair.first != nil && pair.second != nil {
+			loser, from := pair.first, j.first
+			if action == syncCopyToSecond {
+				loser, from = pair.second, j.second
+			}
+			if err := j.keepConflict(ctx, key, loser, from); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch action {
+	case syncRecord:
+		j.state.set(key, syncStateEntry{First: syncVersion(pair.first), Second: syncVersion(pair.second)})
+	case syncForget:
+		j.state.remove(key)
+	case syncCopyToSecond:
+		st, err := j.copy(ctx, pair.first, j.first, key, j.second, key)
+		if err != nil {
+			return err
+		}
+		if st != nil {
+			j.state.set(key, syncStateEntry{First: syncVersion(pair.first), Second: syncVersion(st)})
+		}
+	case syncCopyToFirst:
+		st, err := j.copy(ctx, pair.second, j.second, key, j.first, key)
+		if err != nil {
+			return err
+		}
+		if st != nil {
+			j.state.set(key, syncStateEntry{First: syncVersion(st), Second: syncVersion(pair.second)})
+		}
+	case syncRemoveFromSecond:
+		if err := j.remove(ctx, j.second, key); err != nil {
+			return err
+		}
+		j.state.remove(key)
+	case syncRemoveFromFirst:
+		if err := j.remove(ctx, j.first, key); err != nil {
+			return err
+		}
+		j.state.remove(key)
+	}
+	return nil
+}
+
+// run - syncs all keys, returns the errors of those failing to sync.
+// The state is saved even on failures, keys failing to sync keeping
+// their previous state.
+func (j *syncJob) run(ctx context.Context) (errs []*probe.Error) {
+	pairs, err := j.list(ctx)
+	if err != nil {
+		return []*probe.Error{err}
+	}
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	statusCh := make(chan URLs)
+	parallel := newParallelManager(statusCh, j.opts.workers)
+	j.opts.retry.throttled = parallel.throttled
+	go func() {
+		defer close(statusCh)
+		defer parallel.stopAndWait()
+		for _, key := range keys {
+			if ctx.Err() != nil {
+				return
+			}
+			key, pair := key, pairs[key]
+			parallel.queueTask(func() URLs {
+				return URLs{Error: j.sync(ctx, key, pair)}
+			})
+		}
+	}()
+	for urls := range statusCh {
+		if urls.Error != nil {
+			errs = append(errs, urls.Error)
+		}
+	}
+
+	if !j.opts.isFake {
+		if err := j.state.save(); err != nil {
+			errs = append(errs, err.Trace(j.state.filename))
+		}
+	}
+	return errs
+}
+
+// syncStateURL - returns the URL the state of aliased urlStr is
+// recorded for, absolute for local folders.
+func syncStateURL(urlStr string) string {
+	_, expandedURL, _ := mustExpandAlias(urlStr)
+	if newClientURL(expandedURL).Type != fileSystem {
+		return expandedURL
+	}
+	if absPath, e := filepath.Abs(expandedURL); e == nil {
+		return absPath
+	}
+	return expandedURL
+}
+
+// checkSyncSyntax - validates the arguments of sync.
+func checkSyncSyntax(cliCtx *cli.Context) {
+	if len(cliCtx.Args()) != 2 {
+		cli.ShowCommandHelpAndExit(cliCtx, "sync", 1) // last argument is exit code.
+	}
+	switch cliCtx.String("conflict") {
+	case syncConflictNewer, syncConflictKeepBoth, syncConflictFail:
+	default:
+		fatalIf(errInvalidArgument().Trace(cliCtx.String("conflict")), "`--conflict` should be one of `newer`, `keep-both` or `fail`.")
+	}
+}
+
+// mainSync is the handle for "mc sync" command.
+func mainSync(cliCtx *cli.Context) error {
+	ctx, cancelSync := context.WithCancel(globalContext)
+	defer cancelSync()
+
+	checkSyncSyntax(cliCtx)
+
+	// Additional command specific theme customization.
+	console.SetColor("Sync", color.New(color.FgGreen, color.Bold))
+
+	// Parse encryption keys per command.
+	encKeyDB, err := getEncKeys(cliCtx)
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	first := newSyncSide(cliCtx.Args().Get(0))
+	second := newSyncSide(cliCtx.Args().Get(1))
+
+	// The state is that of the expanded URLs, aliases being renamed.
+	firstURL, secondURL := syncStateURL(first.url), syncStateURL(second.url)
+	filename := cliCtx.String("state")
+	if filename == "" {
+		filename, err = getSyncStateFile(firstURL, secondURL)
+		fatalIf(err, "Unable to determine the sync state file.")
+	}
+	state, err := loadSyncState(filename, firstURL, secondURL)
+	fatalIf(err, "Unable to load the sync state.")
+
+	j := &syncJob{
+		first:  first,
+		second: second,
+		opts: syncOptions{
+			conflict: cliCtx.String("conflict"),
+			isFake:   cliCtx.Bool("fake"),
+			workers:  workerOptionsFromContext(cliCtx),
+			retry:    retryPolicyFromContext(cliCtx),
+			encKeyDB: encKeyDB,
+		},
+		state: state,
+	}
+
+	var retErr error
+	for _, err := range j.run(ctx) {
+		errorIf(err, "Unable to sync.")
+		retErr = exitStatus(globalErrorExitStatus)
+	}
+	return retErr
+}
@@ -0,0 +1,142 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// TestDecideSync - tests changes on each side since the last sync
+// are told apart from conflicts.
+func (s *TestSuite) TestDecideSync(c *C) {
+	v1 := &ClientContent{Size: 1, ETag: "v1"}
+	v2 := &ClientContent{Size: 2, ETag: "v2"}
+	synced := &syncStateEntry{First: syncVersion(v1), Second: syncVersion(v1)}
+
+	testCases := []struct {
+		entry         *syncStateEntry
+		first, second *ClientContent
+		action        syncAction
+	}{
+		{nil, v1, nil, syncCopyToSecond},
+		{nil, nil, v1, syncCopyToFirst},
+		{nil, v1, v1, syncRecord},
+		{nil, v1, v2, syncConflict},
+		{synced, v1, v1, syncNone},
+		{synced, v2, v1, syncCopyToSecond},
+		{synced, v1, v2, syncCopyToFirst},
+		{synced, nil, v1, syncRemoveFromSecond},
+		{synced, v1, nil, syncRemoveFromFirst},
+		{synced, nil, nil, syncForget},
+		{synced, v2, v2, syncConflict},
+		{synced, nil, v2, syncConflict},
+	}
+	for i, testCase := range testCases {
+		c.Assert(decideSync(testCase.entry, testCase.first, testCase.second), Equals, testCase.action, Commentf("Test %d", i+1))
+	}
+}
+
+// TestSyncJob - tests changes are synced both ways and conflicts are
+// resolved by policy.
+func (s *TestSuite) TestSyncJob(c *C) {
+	ctx := context.Background()
+	for _, urlStr := range []string{"mem://sync-first", "mem://sync-second"} {
+		clnt, err := newClient(urlStr)
+		c.Assert(err, IsNil)
+		c.Assert(clnt.MakeBucket(ctx, "", false, false), IsNil)
+	}
+	memPut(c, "mem://sync-first/created-first", "one")
+	memPut(c, "mem://sync-second/dir/created-second", "two")
+
+	filename := filepath.Join(c.MkDir(), "state.json")
+	j := &syncJob{
+		first:  newSyncSide("mem://sync-first"),
+		second: newSyncSide("mem://sync-second"),
+		opts:   syncOptions{conflict: syncConflictFail},
+		state:  newSyncState(filename, "mem://sync-first/", "mem://sync-second/"),
+	}
+	c.Assert(j.run(ctx), HasLen, 0)
+
+	content, err := memStat(c, "mem://sync-second/created-first")
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(3))
+	_, err = memStat(c, "mem://sync-first/dir/created-second")
+	c.Assert(err, IsNil)
+
+	// The state is read back as saved.
+	state, err := loadSyncState(filename, "mem://sync-first/", "mem://sync-second/")
+	c.Assert(err, IsNil)
+	c.Assert(state.Entries, HasLen, 2)
+	_, err = loadSyncState(filename, "mem://sync-first/", "mem://sync-other/")
+	c.Assert(err, NotNil)
+	j.state = state
+
+	// Modified in first, removed from second.
+	memPut(c, "mem://sync-first/created-first", "modified")
+	rmClnt, err := newClient("mem://sync-second/dir/created-second")
+	c.Assert(err, IsNil)
+	contentCh := make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: rmClnt.GetURL()}
+	close(contentCh)
+	for err := range rmClnt.Remove(ctx, false, false, false, contentCh) {
+		c.Assert(err, IsNil)
+	}
+	c.Assert(j.run(ctx), HasLen, 0)
+
+	content, err = memStat(c, "mem://sync-second/created-first")
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(len("modified")))
+	_, err = memStat(c, "mem://sync-first/dir/created-second")
+	c.Assert(err, NotNil)
+	c.Assert(j.state.Entries, HasLen, 1)
+
+	// Modified on both sides, second last.
+	memPut(c, "mem://sync-first/created-first", "first side")
+	time.Sleep(10 * time.Millisecond)
+	memPut(c, "mem://sync-second/created-first", "second side!")
+	c.Assert(j.run(ctx), HasLen, 1)
+
+	j.opts.conflict = syncConflictKeepBoth
+	c.Assert(j.run(ctx), HasLen, 0)
+	for _, urlStr := range []string{"mem://sync-first/created-first", "mem://sync-second/created-first"} {
+		content, err = memStat(c, urlStr)
+		c.Assert(err, IsNil)
+		c.Assert(content.Size, Equals, int64(len("second side!")))
+	}
+	contents := memList(c, "mem://sync-first/", ListOptions{Recursive: true})
+	c.Assert(contents, HasLen, 2)
+	conflictKey := contents[1].URL.String()
+	c.Assert(conflictKey, Matches, "mem://sync-first/created-first.sync-conflict-.*")
+	c.Assert(contents[1].Size, Equals, int64(len("first side")))
+	c.Assert(memList(c, "mem://sync-second/", ListOptions{Recursive: true}), HasLen, 2)
+
+	// Nothing left to sync.
+	c.Assert(j.run(ctx), HasLen, 0)
+	c.Assert(j.state.Entries, HasLen, 2)
+}
+
+// TestSyncConflictKey - tests the names versions losing conflicts are
+// kept as.
+func (s *TestSuite) TestSyncConflictKey(c *C) {
+	modTime := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	c.Assert(syncConflictKey("dir/report.pdf", modTime), Equals, "dir/report.sync-conflict-20210304-050607.pdf")
+	c.Assert(syncConflictKey("dir.d/report", modTime), Equals, "dir.d/report.sync-conflict-20210304-050607")
+}
@@ -0,0 +1,241 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/minio/mc/pkg/probe"
+)
+
+const syncStateVersion = "1"
+
+// syncStateEntry - the versions of a key on both sides when it was
+// last synced.
+type syncStateEntry struct {
+	First  string `json:"first"`
+	Second string `json:"second"`
+}
+
+// syncState - the database of the last synced version of each key of
+// two folders, written when a sync ends.
+type syncState struct {
+	Version string                    `json:"version"`
+	First   string                    `json:"first"`
+	Second  string                    `json:"second"`
+	Entries map[string]syncStateEntry `json:"entries"`
+
+	mutex    sync.Mutex
+	filename string
+}
+
+// getSyncStateFile - returns the state file of the sync of firstURL
+// and secondURL in the mc configuration folder.
+func getSyncStateFile(firstURL, secondURL string) (string, *probe.Error) {
+	configDir, err := getMcConfigDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+	return filepath.Join(configDir, globalSyncDir, getHash("sync", []string{firstURL, secondURL})+".json"), nil
+}
+
+// newSyncState - returns an empty state, saved to filename.
+func newSyncState(filename, firstURL, secondURL string) *syncState {
+	return &syncState{
+		Version:  syncStateVersion,
+		First:    firstURL,
+		Second:   secondURL,
+		Entries:  make(map[string]syncStateEntry),
+		filename: filename,
+	}
+}
+
+// loadSyncState - reads the state of filename, an empty one when the
+// two folders were never synced.
+func loadSyncState(filename, firstURL, secondURL string) (*syncState, *probe.Error) {
+	var jsoniter = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	data, e := ioutil.ReadFile(filename)
+	if os.IsNotExist(e) {
+		return newSyncState(filename, firstURL, secondURL), nil
+	}
+	if e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	s := newSyncState(filename, firstURL, secondURL)
+	if e = jsoniter.Unmarshal(data, s); e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	if s.Version != syncStateVersion {
+		return nil, probe.NewError(fmt.Errorf("unsupported sync state version `%s`", s.Version)).Trace(filename)
+	}
+	if s.First != firstURL || s.Second != secondURL {
+		return nil, probe.NewError(fmt.Errorf("sync state of `%s` and `%s`", s.First, s.Second)).Trace(filename)
+	}
+	if s.Entries == nil {
+		s.Entries = make(map[string]syncStateEntry)
+	}
+	return s, nil
+}
+
+// get - returns the entry of key, false when it was never synced.
+func (s *syncState) get(key string) (syncStateEntry, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entry, ok := s.Entries[key]
+	return entry, ok
+}
+
+// set - records the versions key was synced at.
+func (s *syncState) set(key string, entry syncStateEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Entries[key] = entry
+}
+
+// remove - forgets key, removed from both sides.
+func (s *syncState) remove(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.Entries, key)
+}
+
+// keys - returns the keys of the state.
+func (s *syncState) keys() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	keys := make([]string, 0, len(s.Entries))
+	for key := range s.Entries {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// save - writes the state to its file, replacing the previous one
+// only once complete.
+func (s *syncState) save() *probe.Error {
+	if s.filename == "" {
+		return nil
+	}
+	var jsoniter = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	s.mutex.Lock()
+	data, e := jsoniter.Marshal(s)
+	s.mutex.Unlock()
+	if e != nil {
+		return probe.NewError(e)
+	}
+
+	dir := filepath.Dir(s.filename)
+	if e = os.MkdirAll(dir, 0700); e != nil {
+		return probe.NewError(e).Trace(dir)
+	}
+	tmp, e := ioutil.TempFile(dir, filepath.Base(s.filename)+".tmp-")
+	if e != nil {
+		return probe.NewError(e).Trace(dir)
+	}
+	defer os.Remove(tmp.Name())
+	if _, e = tmp.Write(data); e != nil {
+		tmp.Close()
+		return probe.NewError(e).Trace(tmp.Name())
+	}
+	if e = tmp.Close(); e != nil {
+		return probe.NewError(e).Trace(tmp.Name())
+	}
+	if e = os.Rename(tmp.Name(), s.filename); e != nil {
+		return probe.NewError(e).Trace(s.filename)
+	}
+	return nil
+}
+
+// syncVersion - returns what identifies the version of content, its
+// ETag along with its size, or its modification time for local files
+// without one.
+func syncVersion(content *ClientContent) string {
+	if content.ETag != "" {
+		return fmt.Sprintf("%d:%s", content.Size, content.ETag)
+	}
+	return fmt.Sprintf("%d:%d", content.Size, content.Time.UnixNano())
+}
+
+// syncIdentical - returns true if first and second, never synced,
+// are taken to be the same. Without ETags on both sides, the same
+// size is enough.
+func syncIdentical(first, second *ClientContent) bool {
+	if first.Size != second.Size {
+		return false
+	}
+	return first.ETag == "" || second.ETag == "" || first.ETag == second.ETag
+}
+
+// syncAction - what syncing a key does.
+type syncAction int
+
+const (
+	syncNone             syncAction = iota // in sync
+	syncRecord                             // same on both sides, recorded in the state
+	syncForget                             // removed from both sides, forgotten
+	syncCopyToSecond                       // created or modified in first
+	syncCopyToFirst                        // created or modified in second
+	syncRemoveFromSecond                   // removed from first
+	syncRemoveFromFirst                    // removed from second
+	syncConflict                           // changed on both sides
+)
+
+// decideSync - returns the action syncing a key, known to entry when
+// it was synced before, takes. first and second are its contents on
+// both sides, nil when it is missing.
+func decideSync(entry *syncStateEntry, first, second *ClientContent) syncAction {
+	if entry == nil {
+		switch {
+		case first == nil && second == nil:
+			return syncNone
+		case second == nil:
+			return syncCopyToSecond
+		case first == nil:
+			return syncCopyToFirst
+		case syncIdentical(first, second):
+			return syncRecord
+		}
+		return syncConflict
+	}
+
+	firstChanged := first == nil || syncVersion(first) != entry.First
+	secondChanged := second == nil || syncVersion(second) != entry.Second
+	switch {
+	case !firstChanged && !secondChanged:
+		return syncNone
+	case firstChanged && !secondChanged:
+		if first == nil {
+			return syncRemoveFromSecond
+		}
+		return syncCopyToSecond
+	case !firstChanged && secondChanged:
+		if second == nil {
+			return syncRemoveFromFirst
+		}
+		return syncCopyToFirst
+	case first == nil && second == nil:
+		return syncForget
+	}
+	return syncConflict
+}
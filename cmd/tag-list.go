@@ -188,7 +188,7 @@ func mainListTag(cliCtx *cli.Context) error {
 		timeRef = time.Now().UTC()
 	}
 
-	clnt, err := newClient(targetURL)
+	clnt, err := newClientWithCapability(targetURL, capTagging)
 	fatalIf(err, "Unable to initialize target "+targetURL)
 
 	if timeRef.IsZero() && !withVersions {
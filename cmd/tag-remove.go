@@ -150,7 +150,7 @@ func mainRemoveTag(cliCtx *cli.Context) error {
 		timeRef = time.Now().UTC()
 	}
 
-	clnt, pErr := newClient(targetURL)
+	clnt, pErr := newClientWithCapability(targetURL, capTagging)
 	fatalIf(pErr, "Unable to initialize target "+targetURL)
 
 	if timeRef.IsZero() && !withVersions {
@@ -150,7 +150,7 @@ func mainSetTag(cliCtx *cli.Context) error {
 		timeRef = time.Now().UTC()
 	}
 
-	clnt, err := newClient(targetURL)
+	clnt, err := newClientWithCapability(targetURL, capTagging)
 	fatalIf(err.Trace(cliCtx.Args()...), "Unable to initialize target "+targetURL)
 
 	if timeRef.IsZero() && !withVersions {
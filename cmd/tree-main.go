@@ -284,7 +284,7 @@ func mainTree(cliCtx *cli.Context) error {
 			}
 			clnt, err := newClientFromAlias(targetAlias, targetURL)
 			fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
-			if e := doList(ctx, clnt, true, false, false, timeRef, false); e != nil {
+			if e := doList(ctx, clnt, true, false, false, timeRef, false, "", nil); e != nil {
 				cErr = e
 			}
 		}
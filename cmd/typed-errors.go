@@ -125,3 +125,31 @@ var errConflictSSE = func(sseServer, sseKeys string) *probe.Error {
 	err := fmt.Errorf("SSE alias '%s' overlaps with SSE-C aliases '%s'", sseServer, sseKeys)
 	return probe.NewError(conflictSSEErr(err)).Untrace()
 }
+
+type invalidArchiveTargetErr error
+
+var errInvalidArchiveTarget = func(URL string) *probe.Error {
+	msg := "Target `" + URL + "` should end with one of `" + strings.Join(archiveWriteExtensions(), "`, `") + "` to be written as an archive."
+	return probe.NewError(invalidArchiveTargetErr(errors.New(msg))).Untrace()
+}
+
+type archiveEntrySizeErr error
+
+var errArchiveEntrySize = func(entry string) *probe.Error {
+	msg := "Size of `" + entry + "` should be known to be added to an archive."
+	return probe.NewError(archiveEntrySizeErr(errors.New(msg))).Untrace()
+}
+
+type removalAbortedErr error
+
+var errRemovalAborted = func(count int, reason string) *probe.Error {
+	msg := fmt.Sprintf("Removal of %d extraneous object(s) aborted, %s.", count, reason)
+	return probe.NewError(removalAbortedErr(errors.New(msg))).Untrace()
+}
+
+type syncConflictErr error
+
+var errSyncConflict = func(first, second string) *probe.Error {
+	msg := "`" + first + "` and `" + second + "` both changed since the last sync, use `--conflict newer` or `--conflict keep-both` to resolve."
+	return probe.NewError(syncConflictErr(errors.New(msg))).Untrace()
+}
@@ -17,13 +17,18 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/minio/cli"
 	json "github.com/minio/mc/pkg/colorjson"
 	"github.com/minio/mc/pkg/probe"
@@ -49,6 +54,34 @@ var (
 			Name:  "dry-run",
 			Usage: "fake an undo operation",
 		},
+		cli.StringFlag{
+			Name:  "since",
+			Usage: "undo the changes made at or after a date or a duration ago",
+		},
+		cli.StringFlag{
+			Name:  "until",
+			Usage: "undo the changes made at or before a date or a duration ago",
+		},
+		cli.BoolFlag{
+			Name:  "only-deletes",
+			Usage: "undo removals only, removing their delete markers",
+		},
+		cli.BoolFlag{
+			Name:  "only-overwrites",
+			Usage: "undo overwrites only, removing the versions which replaced another one",
+		},
+		cli.StringFlag{
+			Name:  "revert-to",
+			Usage: "revert objects to their state at a date or a duration ago, writing new versions",
+		},
+		cli.StringFlag{
+			Name:  "plan",
+			Usage: "save the changes of a dry run to a file, to apply them later with --apply",
+		},
+		cli.StringFlag{
+			Name:  "apply",
+			Usage: "apply the changes saved by --dry-run --plan",
+		},
 	}
 )
 
@@ -75,12 +108,36 @@ EXAMPLES:
 
   2. Undo the last upload/removal change of all objects under a prefix
      {{.Prompt}} {{.HelpName}} --recursive --force s3/backups/prefix/
+
+  3. Undo every upload and removal made under a prefix during the last two hours
+     {{.Prompt}} {{.HelpName}} --recursive --force --since 2h s3/backups/prefix/
+
+  4. Undo the removals made under a prefix on a given day
+     {{.Prompt}} {{.HelpName}} --recursive --force --only-deletes --since 2021.03.01 --until 2021.03.01T23:59:59 s3/backups/prefix/
+
+  5. Undo the last overwrite of a particular object
+     {{.Prompt}} {{.HelpName}} --only-overwrites s3/backups/file.zip
+
+  6. Revert all objects under a prefix to their state at a given time, keeping their history
+     {{.Prompt}} {{.HelpName}} --recursive --force --revert-to 2021.03.01T10:00 s3/backups/prefix/
+
+  7. Save the changes of an undo to a file, review and apply them later
+     {{.Prompt}} {{.HelpName}} --recursive --force --since 2h --dry-run --plan undo.jsonl s3/backups/prefix/
+     {{.Prompt}} {{.HelpName}} --apply undo.jsonl
 `,
 }
 
+// Actions of an undo.
+const (
+	undoActionRemove  = "remove"  // removes a version or a delete marker
+	undoActionRestore = "restore" // copies a version over the latest one
+	undoActionDelete  = "delete"  // adds a delete marker
+)
+
 // undoMessage container for undo message structure.
 type undoMessage struct {
 	Status         string `json:"status"`
+	Action         string `json:"action,omitempty"`
 	URL            string `json:"url,omitempty"`
 	Key            string `json:"key,omitempty"`
 	VersionID      string `json:"versionId,omitempty"`
@@ -92,9 +149,14 @@ func (c undoMessage) String() string {
 	var msg string
 	fmt.Print(color.GreenString("\u2713 "))
 	yellow := color.New(color.FgYellow).SprintFunc()
-	if c.IsDeleteMarker {
+	switch {
+	case c.Action == undoActionRestore:
+		msg += "`" + yellow(c.Key) + "` is " + color.BlueString("restored") + " to vid=" + c.VersionID
+	case c.Action == undoActionDelete:
+		msg += "`" + yellow(c.Key) + "` is " + color.RedString("deleted")
+	case c.IsDeleteMarker:
 		msg += "Last " + color.RedString("delete") + " of `" + yellow(c.Key) + "` is reverted"
-	} else {
+	default:
 		msg += "Last " + color.BlueString("upload") + " of `" + yellow(c.Key) + "` (vid=" + c.VersionID + ") is reverted"
 	}
 	msg += "."
@@ -110,37 +172,200 @@ func (c undoMessage) JSON() string {
 	return string(jsonMessageBytes)
 }
 
+// undoOptions - the changes undone and how.
+type undoOptions struct {
+	last           int // undo the last N changes of each object, all of them when 0
+	recursive      bool
+	dryRun         bool
+	since, until   time.Time
+	onlyDeletes    bool
+	onlyOverwrites bool
+	revertTo       time.Time
+	plan           *undoPlan
+}
+
 // parseUndoSyntax performs command-line input validation for cat command.
-func parseUndoSyntax(ctx *cli.Context) (targetAliasedURL string, last int, recursive, dryRun bool) {
+func parseUndoSyntax(ctx *cli.Context) (targetAliasedURL string, opts undoOptions) {
 	targetAliasedURL = ctx.Args().Get(0)
 	if targetAliasedURL == "" {
 		fatalIf(errInvalidArgument().Trace(), "The argument should not be empty")
 	}
 
-	last = ctx.Int("last")
-	if last < 1 {
+	opts.last = ctx.Int("last")
+	if opts.last < 1 {
 		fatalIf(errInvalidArgument().Trace(), "--last value should be a positive integer")
 	}
 
-	recursive = ctx.Bool("recursive")
+	opts.recursive = ctx.Bool("recursive")
 	force := ctx.Bool("force")
-	if recursive && !force {
+	if opts.recursive && !force {
 		fatalIf(errInvalidArgument().Trace(), "This is a dangerous operation, you need to provide --force flag as well")
 	}
 
-	dryRun = ctx.Bool("dry-run")
+	opts.dryRun = ctx.Bool("dry-run")
+	if ctx.String("plan") != "" && !opts.dryRun {
+		fatalIf(errInvalidArgument().Trace(), "--plan saves the changes of a dry run, you need to provide --dry-run flag as well")
+	}
+
+	opts.since = parseRewindFlag(ctx.String("since"))
+	opts.until = parseRewindFlag(ctx.String("until"))
+	if !opts.since.IsZero() && !opts.until.IsZero() && opts.since.After(opts.until) {
+		fatalIf(errInvalidArgument().Trace(ctx.String("since"), ctx.String("until")), "--since should not be later than --until")
+	}
+	// All the changes of a time window are undone, unless --last is set.
+	if (!opts.since.IsZero() || !opts.until.IsZero()) && !ctx.IsSet("last") {
+		opts.last = 0
+	}
+
+	opts.onlyDeletes = ctx.Bool("only-deletes")
+	opts.onlyOverwrites = ctx.Bool("only-overwrites")
+	if opts.onlyDeletes && opts.onlyOverwrites {
+		fatalIf(errInvalidArgument().Trace(), "--only-deletes and --only-overwrites cannot be used together")
+	}
+
+	opts.revertTo = parseRewindFlag(ctx.String("revert-to"))
+	if !opts.revertTo.IsZero() {
+		for _, flag := range []string{"last", "since", "until", "only-deletes", "only-overwrites"} {
+			if ctx.IsSet(flag) {
+				fatalIf(errInvalidArgument().Trace(), "--revert-to cannot be used with --"+flag)
+			}
+		}
+	}
 	return
 }
 
-func undoLastNOperations(ctx context.Context, clnt Client, objectVersions []*ClientContent, last int, dryRun bool) (exitErr error) {
-	if last == 0 {
+// undoPlanEntry - a change of a dry run saved to a plan.
+type undoPlanEntry struct {
+	Action         string `json:"action"`
+	URL            string `json:"url"`
+	VersionID      string `json:"versionId,omitempty"`
+	IsDeleteMarker bool   `json:"isDeleteMarker,omitempty"`
+}
+
+// undoPlan - the changes of a dry run, saved as JSON lines.
+type undoPlan struct {
+	file *os.File
+	w    *bufio.Writer
+}
+
+// createUndoPlan - creates the plan filename, replacing any existing
+// one.
+func createUndoPlan(filename string) (*undoPlan, *probe.Error) {
+	file, e := os.Create(filename)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	return &undoPlan{file: file, w: bufio.NewWriter(file)}, nil
+}
+
+// add - saves a change, plans are optional.
+func (p *undoPlan) add(entry undoPlanEntry) *probe.Error {
+	if p == nil {
+		return nil
+	}
+	var jsoniter = jsoniter.ConfigCompatibleWithStandardLibrary
+	data, e := jsoniter.Marshal(entry)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	p.w.Write(data)
+	if e = p.w.WriteByte('\n'); e != nil {
+		return probe.NewError(e).Trace(p.file.Name())
+	}
+	return nil
+}
+
+// Close - writes the changes saved and closes the plan.
+func (p *undoPlan) Close() *probe.Error {
+	if p == nil {
+		return nil
+	}
+	if e := p.w.Flush(); e != nil {
+		p.file.Close()
+		return probe.NewError(e).Trace(p.file.Name())
+	}
+	if e := p.file.Close(); e != nil {
+		return probe.NewError(e).Trace(p.file.Name())
+	}
+	return nil
+}
+
+// readUndoPlan - reads the changes saved to the plan filename.
+func readUndoPlan(filename string) ([]undoPlanEntry, *probe.Error) {
+	var jsoniter = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	file, e := os.Open(filename)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	defer file.Close()
+
+	var entries []undoPlanEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry undoPlanEntry
+		if e = jsoniter.Unmarshal(scanner.Bytes(), &entry); e != nil {
+			return nil, probe.NewError(e).Trace(filename)
+		}
+		switch entry.Action {
+		case undoActionRemove, undoActionRestore, undoActionDelete:
+		default:
+			return nil, errInvalidArgument().Trace(filename, entry.Action)
+		}
+		entries = append(entries, entry)
+	}
+	if e = scanner.Err(); e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	return entries, nil
+}
+
+// selectUndoVersions - returns the versions of an object to remove,
+// among its versions sorted newest first.
+func selectUndoVersions(objectVersions []*ClientContent, opts undoOptions) (selected []*ClientContent) {
+	for i, objectVersion := range objectVersions {
+		if !opts.since.IsZero() && objectVersion.Time.Before(opts.since) {
+			continue
+		}
+		if !opts.until.IsZero() && objectVersion.Time.After(opts.until) {
+			continue
+		}
+		if opts.onlyDeletes && !objectVersion.IsDeleteMarker {
+			continue
+		}
+		// An overwrite is an upload replacing an older version.
+		if opts.onlyOverwrites && (objectVersion.IsDeleteMarker || i == len(objectVersions)-1 || objectVersions[i+1].IsDeleteMarker) {
+			continue
+		}
+		selected = append(selected, objectVersion)
+		if len(selected) == opts.last {
+			break
+		}
+	}
+	return selected
+}
+
+func undoLastNOperations(ctx context.Context, clnt Client, alias string, objectVersions []*ClientContent, opts undoOptions) (exitErr error) {
+	if len(objectVersions) == 0 {
 		return
 	}
 
 	sortObjectVersions(objectVersions)
+	objectVersions = selectUndoVersions(objectVersions, opts)
 
-	if len(objectVersions) > last {
-		objectVersions = objectVersions[:last]
+	if opts.dryRun {
+		for _, objectVersion := range objectVersions {
+			err := opts.plan.add(undoPlanEntry{
+				Action:         undoActionRemove,
+				URL:            getAliasedKey(alias, objectVersion),
+				VersionID:      objectVersion.VersionID,
+				IsDeleteMarker: objectVersion.IsDeleteMarker,
+			})
+			fatalIf(err, "Unable to save the undo plan.")
+		}
 	}
 
 	contentCh := make(chan *ClientContent)
@@ -155,7 +380,7 @@ func undoLastNOperations(ctx context.Context, clnt Client, objectVersions []*Cli
 
 	go func() {
 		for _, objectVersion := range objectVersions {
-			if !dryRun {
+			if !opts.dryRun {
 				contentCh <- objectVersion
 			}
 
@@ -186,7 +411,7 @@ func undoLastNOperations(ctx context.Context, clnt Client, objectVersions []*Cli
 	return
 }
 
-func undoURL(ctx context.Context, aliasedURL string, last int, recursive, dryRun bool) (exitErr error) {
+func undoURL(ctx context.Context, aliasedURL string, opts undoOptions) (exitErr error) {
 	clnt, err := newClient(aliasedURL)
 	fatalIf(err.Trace(aliasedURL), "Unable to initialize target `"+aliasedURL+"`.")
 
@@ -199,7 +424,7 @@ func undoURL(ctx context.Context, aliasedURL string, last int, recursive, dryRun
 	)
 
 	for content := range clnt.List(ctx, ListOptions{
-		Recursive:         recursive,
+		Recursive:         opts.recursive,
 		WithOlderVersions: true,
 		WithDeleteMarkers: true,
 		ShowDir:           DirNone,
@@ -212,15 +437,15 @@ func undoURL(ctx context.Context, aliasedURL string, last int, recursive, dryRun
 			continue
 		}
 
-		if !recursive {
-			if alias+getKey(content) != getStandardizedURL(aliasedURL) {
+		if !opts.recursive {
+			if getAliasedKey(alias, content) != getStandardizedURL(aliasedURL) {
 				break
 			}
 		}
 
 		if lastObjectPath != content.URL.Path {
 			// Print any object in the current list before reinitializing it
-			exitErr = undoLastNOperations(ctx, clnt, perObjectVersions, last, dryRun)
+			exitErr = undoLastNOperations(ctx, clnt, alias, perObjectVersions, opts)
 			lastObjectPath = content.URL.Path
 			perObjectVersions = []*ClientContent{}
 		}
@@ -230,7 +455,7 @@ func undoURL(ctx context.Context, aliasedURL string, last int, recursive, dryRun
 	}
 
 	// Undo the remaining versions found if any
-	exitErr = undoLastNOperations(ctx, clnt, perObjectVersions, last, dryRun)
+	exitErr = undoLastNOperations(ctx, clnt, alias, perObjectVersions, opts)
 
 	if !atLeastOneUndoApplied {
 		errorIf(errDummy().Trace(clnt.GetURL().String()), "Unable to find any object version to undo.")
@@ -240,6 +465,136 @@ func undoURL(ctx context.Context, aliasedURL string, last int, recursive, dryRun
 	return
 }
 
+// applyUndoChange - applies a change saved to a plan to its object.
+func applyUndoChange(ctx context.Context, entry undoPlanEntry) *probe.Error {
+	clnt, err := newClient(entry.URL)
+	if err != nil {
+		return err.Trace(entry.URL)
+	}
+
+	if entry.Action == undoActionRestore {
+		content, err := clnt.Stat(ctx, StatOptions{versionID: entry.VersionID})
+		if err != nil {
+			return err.Trace(entry.URL, entry.VersionID)
+		}
+		content.VersionID = entry.VersionID
+		alias, _, _ := mustExpandAlias(entry.URL)
+		urls := uploadSourceToTargetURL(ctx, URLs{
+			SourceAlias:   alias,
+			SourceContent: content,
+			TargetAlias:   alias,
+			TargetContent: &ClientContent{
+				URL:          clnt.GetURL(),
+				Metadata:     map[string]string{},
+				UserMetadata: map[string]string{},
+			},
+		}, nil, nil, false)
+		return urls.Error
+	}
+
+	// Removing the object without a version adds a delete marker.
+	contentCh := make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: clnt.GetURL(), VersionID: entry.VersionID, IsDeleteMarker: entry.IsDeleteMarker}
+	close(contentCh)
+	for err := range clnt.Remove(ctx, false, false, false, contentCh) {
+		if err != nil {
+			return err.Trace(entry.URL)
+		}
+	}
+	return nil
+}
+
+// undoChanges - prints and applies the changes, saving them to the plan
+// instead on a dry run.
+func undoChanges(ctx context.Context, prefixURL string, entries []undoPlanEntry, opts undoOptions) (exitErr error) {
+	for _, entry := range entries {
+		if opts.dryRun {
+			fatalIf(opts.plan.add(entry), "Unable to save the undo plan.")
+		} else if err := applyUndoChange(ctx, entry); err != nil {
+			errorIf(err.Trace(entry.URL), "Unable to undo")
+			exitErr = exitStatus(globalErrorExitStatus) // Set the exit status.
+			continue
+		}
+		printMsg(undoMessage{
+			Status:         "success",
+			Action:         entry.Action,
+			URL:            entry.URL,
+			Key:            strings.TrimPrefix(entry.URL, prefixURL),
+			VersionID:      entry.VersionID,
+			IsDeleteMarker: entry.IsDeleteMarker,
+		})
+	}
+	return exitErr
+}
+
+// revertURL - reverts the objects of aliasedURL to their state at
+// opts.revertTo, the versions of that time are copied over the latest
+// ones and the objects created since are deleted, their history is
+// kept.
+func revertURL(ctx context.Context, aliasedURL string, opts undoOptions) error {
+	clnt, err := newClient(aliasedURL)
+	fatalIf(err.Trace(aliasedURL), "Unable to initialize target `"+aliasedURL+"`.")
+
+	alias, _, _ := mustExpandAlias(aliasedURL)
+
+	// list - returns the latest versions at timeRef.
+	list := func(timeRef time.Time) map[string]*ClientContent {
+		contents := make(map[string]*ClientContent)
+		for content := range clnt.List(ctx, ListOptions{
+			Recursive: opts.recursive,
+			TimeRef:   timeRef,
+			ShowDir:   DirNone,
+		}) {
+			if content.Err != nil {
+				fatalIf(content.Err.Trace(clnt.GetURL().String()), "Unable to list folder.")
+			}
+			if content.StorageClass == s3StorageClassGlacier {
+				continue
+			}
+			key := getAliasedKey(alias, content)
+			if !opts.recursive && key != getStandardizedURL(aliasedURL) {
+				continue
+			}
+			contents[key] = content
+		}
+		return contents
+	}
+	// The versioned listing of now returns the version IDs of the
+	// latest versions.
+	then, now := list(opts.revertTo), list(UTCNow())
+
+	var entries []undoPlanEntry
+	for key, content := range now {
+		switch thenContent, ok := then[key]; {
+		case !ok:
+			entries = append(entries, undoPlanEntry{Action: undoActionDelete, URL: key})
+		case thenContent.VersionID != content.VersionID:
+			entries = append(entries, undoPlanEntry{Action: undoActionRestore, URL: key, VersionID: thenContent.VersionID})
+		}
+	}
+	for key, thenContent := range then {
+		if _, ok := now[key]; !ok {
+			entries = append(entries, undoPlanEntry{Action: undoActionRestore, URL: key, VersionID: thenContent.VersionID})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].URL < entries[j].URL
+	})
+
+	prefixURL := aliasedURL
+	if !strings.HasSuffix(prefixURL, "/") {
+		prefixURL = prefixURL[:strings.LastIndex(prefixURL, "/")+1]
+	}
+	return undoChanges(ctx, prefixURL, entries, opts)
+}
+
+// applyUndoPlan - applies the changes saved to the plan filename.
+func applyUndoPlan(ctx context.Context, filename string) error {
+	entries, err := readUndoPlan(filename)
+	fatalIf(err, "Unable to read the undo plan.")
+	return undoChanges(ctx, "", entries, undoOptions{})
+}
+
 func checkIfBucketIsVersioned(ctx context.Context, aliasedURL string) (versioned bool) {
 	client, err := newClient(aliasedURL)
 	fatalIf(err, "Unable to parse `%s`", aliasedURL)
@@ -265,12 +620,32 @@ func mainUndo(cliCtx *cli.Context) error {
 
 	console.SetColor("Success", color.New(color.FgGreen, color.Bold))
 
+	if planFile := cliCtx.String("apply"); planFile != "" {
+		if cliCtx.Args().Present() {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--apply applies the changes of its plan, no argument is expected")
+		}
+		return applyUndoPlan(ctx, planFile)
+	}
+
 	// check 'undo' cli arguments.
-	targetAliasedURL, last, recursive, dryRun := parseUndoSyntax(cliCtx)
+	targetAliasedURL, opts := parseUndoSyntax(cliCtx)
 
 	if !checkIfBucketIsVersioned(ctx, targetAliasedURL) {
 		fatalIf(errDummy().Trace(), "Undo command works only with S3 versioned-enabled buckets.")
 	}
 
-	return undoURL(ctx, targetAliasedURL, last, recursive, dryRun)
+	if planFile := cliCtx.String("plan"); planFile != "" {
+		plan, err := createUndoPlan(planFile)
+		fatalIf(err, "Unable to create the undo plan.")
+		opts.plan = plan
+	}
+
+	var undoErr error
+	if !opts.revertTo.IsZero() {
+		undoErr = revertURL(ctx, targetAliasedURL, opts)
+	} else {
+		undoErr = undoURL(ctx, targetAliasedURL, opts)
+	}
+	fatalIf(opts.plan.Close(), "Unable to save the undo plan.")
+	return undoErr
 }
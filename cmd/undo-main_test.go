@@ -0,0 +1,82 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// TestMemUndoWindow - tests undoing the changes of a time window, by
+// operation type, reverting to a time and applying a saved plan.
+func (s *TestSuite) TestMemUndoWindow(c *C) {
+	ctx := context.Background()
+	clnt, err := newClient("mem://mem-undo-window")
+	c.Assert(err, IsNil)
+	c.Assert(clnt.MakeBucket(ctx, "", false, false), IsNil)
+	c.Assert(clnt.SetVersion(ctx, "enable"), IsNil)
+
+	memPut(c, "mem://mem-undo-window/a", "1")
+	memPut(c, "mem://mem-undo-window/b", "1")
+	time.Sleep(10 * time.Millisecond)
+	since := UTCNow()
+	memPut(c, "mem://mem-undo-window/a", "22")
+	memPut(c, "mem://mem-undo-window/c", "22")
+	c.Assert(removeSingle("mem://mem-undo-window/b", "", false, false, false, false, nil, nil, retryPolicy{}, nil), IsNil)
+
+	versions := memList(c, "mem://mem-undo-window/a", ListOptions{WithOlderVersions: true, WithDeleteMarkers: true})
+	sortObjectVersions(versions)
+	c.Assert(selectUndoVersions(versions, undoOptions{since: since}), HasLen, 1)
+	c.Assert(selectUndoVersions(versions, undoOptions{until: since}), HasLen, 1)
+	c.Assert(selectUndoVersions(versions, undoOptions{onlyDeletes: true}), HasLen, 0)
+	c.Assert(selectUndoVersions(versions, undoOptions{onlyOverwrites: true, last: 2}), HasLen, 1)
+
+	// Undo the removals of the window only.
+	c.Assert(undoURL(ctx, "mem://mem-undo-window/", undoOptions{recursive: true, since: since, onlyDeletes: true}), IsNil)
+	_, err = memStat(c, "mem://mem-undo-window/b")
+	c.Assert(err, IsNil)
+	st, err := memStat(c, "mem://mem-undo-window/a")
+	c.Assert(err, IsNil)
+	c.Assert(st.Size, Equals, int64(2))
+
+	// Revert to the state before the window through a saved plan.
+	planFile := filepath.Join(c.MkDir(), "undo.jsonl")
+	plan, err := createUndoPlan(planFile)
+	c.Assert(err, IsNil)
+	c.Assert(revertURL(ctx, "mem://mem-undo-window/", undoOptions{recursive: true, dryRun: true, revertTo: since, plan: plan}), IsNil)
+	c.Assert(plan.Close(), IsNil)
+	entries, err := readUndoPlan(planFile)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+	c.Assert(entries[0].Action, Equals, undoActionRestore)
+	c.Assert(entries[1], DeepEquals, undoPlanEntry{Action: undoActionDelete, URL: "mem://mem-undo-window/c"})
+	st, err = memStat(c, "mem://mem-undo-window/a")
+	c.Assert(err, IsNil)
+	c.Assert(st.Size, Equals, int64(2))
+
+	c.Assert(applyUndoPlan(ctx, planFile), IsNil)
+	st, err = memStat(c, "mem://mem-undo-window/a")
+	c.Assert(err, IsNil)
+	c.Assert(st.Size, Equals, int64(1))
+	_, err = memStat(c, "mem://mem-undo-window/c")
+	c.Assert(err, NotNil)
+	// The history is kept.
+	c.Assert(memList(c, "mem://mem-undo-window/a", ListOptions{WithOlderVersions: true}), HasLen, 3)
+}
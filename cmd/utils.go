@@ -202,10 +202,12 @@ func getLookupType(l string) minio.BucketLookupType {
 	return minio.BucketLookupAuto
 }
 
-// struct representing object prefix and sse keys association.
+// struct representing object prefix and sse keys association, pairs
+// with a client key encrypt objects on the client side instead.
 type prefixSSEPair struct {
-	Prefix string
-	SSE    encrypt.ServerSide
+	Prefix    string
+	SSE       encrypt.ServerSide
+	ClientKey []byte
 }
 
 // parse and validate encryption keys entered on command line
@@ -297,7 +299,7 @@ func (p byPrefixLength) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
 // get SSE Key if object prefix matches with given resource.
 func getSSE(resource string, encKeys []prefixSSEPair) encrypt.ServerSide {
 	for _, k := range encKeys {
-		if strings.HasPrefix(resource, k.Prefix) {
+		if k.SSE != nil && strings.HasPrefix(resource, k.Prefix) {
 			return k.SSE
 		}
 	}
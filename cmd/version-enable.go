@@ -89,7 +89,7 @@ func mainVersionEnable(cliCtx *cli.Context) error {
 	args := cliCtx.Args()
 	aliasedURL := args.Get(0)
 	// Create a new Client
-	client, err := newClient(aliasedURL)
+	client, err := newClientWithCapability(aliasedURL, capVersioning)
 	fatalIf(err, "Unable to initialize connection.")
 	fatalIf(client.SetVersion(ctx, "enable"), "Unable to enable versioning")
 	printMsg(versionEnableMessage{
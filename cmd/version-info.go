@@ -97,7 +97,7 @@ func mainVersionInfo(cliCtx *cli.Context) error {
 	args := cliCtx.Args()
 	aliasedURL := args.Get(0)
 	// Create a new Client
-	client, err := newClient(aliasedURL)
+	client, err := newClientWithCapability(aliasedURL, capVersioning)
 	fatalIf(err, "Unable to initialize connection.")
 	vConfig, e := client.GetVersion(ctx)
 	fatalIf(e, "Unable to get versioning info")
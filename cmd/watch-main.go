@@ -148,7 +148,7 @@ func mainWatch(cliCtx *cli.Context) error {
 	events := strings.Split(cliCtx.String("events"), ",")
 	recursive := cliCtx.Bool("recursive")
 
-	s3Client, pErr := newClient(path)
+	s3Client, pErr := newClientWithCapability(path, capWatch)
 	if pErr != nil {
 		fatalIf(pErr.Trace(), "Unable to parse the provided url.")
 	}
@@ -17,8 +17,10 @@ require (
 	github.com/minio/minio v0.0.0-20210216195645-87cce344f6e4
 	github.com/minio/minio-go/v7 v7.0.9-0.20210210235136-83423dddb072
 	github.com/minio/sha256-simd v0.1.1
+	github.com/minio/sio v0.2.1
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/pkg/profile v1.3.0
+	github.com/pkg/sftp v1.11.0
 	github.com/pkg/xattr v0.4.1
 	github.com/posener/complete v1.2.3
 	github.com/rjeczalik/notify v0.9.2
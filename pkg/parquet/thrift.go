@@ -0,0 +1,105 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Types of the thrift compact protocol.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftEncoder - encodes the structures of the Parquet footer and page
+// headers with the thrift compact protocol.
+type thriftEncoder struct {
+	buf       bytes.Buffer
+	lastField int16
+	parents   []int16
+}
+
+func (t *thriftEncoder) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	t.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (t *thriftEncoder) varint(v int64) {
+	t.uvarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (t *thriftEncoder) field(id int16, fieldType byte) {
+	if delta := id - t.lastField; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		t.buf.WriteByte(fieldType)
+		t.varint(int64(id))
+	}
+	t.lastField = id
+}
+
+func (t *thriftEncoder) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftEncoder) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftEncoder) binary(v string) {
+	t.uvarint(uint64(len(v)))
+	t.buf.WriteString(v)
+}
+
+func (t *thriftEncoder) string(id int16, v string) {
+	t.field(id, thriftBinary)
+	t.binary(v)
+}
+
+// list - begins a list of n elements of elemType, the elements follow.
+func (t *thriftEncoder) list(id int16, elemType byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf.WriteByte(byte(n)<<4 | elemType)
+		return
+	}
+	t.buf.WriteByte(0xf0 | elemType)
+	t.uvarint(uint64(n))
+}
+
+// structBegin - begins a struct, the field of a struct or an element
+// of a list when id is 0.
+func (t *thriftEncoder) structBegin(id int16) {
+	if id != 0 {
+		t.field(id, thriftStruct)
+	}
+	t.parents = append(t.parents, t.lastField)
+	t.lastField = 0
+}
+
+func (t *thriftEncoder) structEnd() {
+	t.buf.WriteByte(0)
+	t.lastField = t.parents[len(t.parents)-1]
+	t.parents = t.parents[:len(t.parents)-1]
+}
@@ -0,0 +1,300 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package parquet writes Parquet files of flat records. All columns
+// are required, PLAIN encoded and uncompressed, which every Parquet
+// reader supports.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Type - the type of the values of a column.
+type Type int
+
+// Column types.
+const (
+	String          Type = iota // UTF-8 string
+	Int64                       // 64 bits signed integer
+	TimestampMillis             // time, stored as milliseconds since the Unix epoch
+	Boolean                     // boolean
+)
+
+// Column - a column of the records written.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Parquet enums written.
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	repetitionRequired = 0
+	encodingPlain      = 0
+	encodingRLE        = 3
+	codecUncompressed  = 0
+	pageData           = 0
+)
+
+var magic = []byte("PAR1")
+
+// DefaultRowGroupSize - the number of records of a row group, held in
+// memory until written.
+const DefaultRowGroupSize = 64 * 1024
+
+type columnChunk struct {
+	offset, size int64
+}
+
+type rowGroup struct {
+	chunks  []columnChunk
+	size    int64
+	numRows int64
+}
+
+// Writer - writes records to a Parquet file, in row groups of a fixed
+// number of records.
+type Writer struct {
+	w            io.Writer
+	offset       int64
+	columns      []Column
+	rowGroupSize int
+
+	values    []bytes.Buffer // PLAIN encoded values of the current row group
+	bools     [][]bool
+	rows      int
+	rowGroups []rowGroup
+}
+
+// NewWriter - returns a writer of records of columns to w.
+func NewWriter(w io.Writer, columns []Column, rowGroupSize int) (*Writer, error) {
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("parquet: no columns")
+	}
+	if rowGroupSize <= 0 {
+		rowGroupSize = DefaultRowGroupSize
+	}
+	pw := &Writer{
+		w:            w,
+		columns:      columns,
+		rowGroupSize: rowGroupSize,
+		values:       make([]bytes.Buffer, len(columns)),
+		bools:        make([][]bool, len(columns)),
+	}
+	if err := pw.write(magic); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+func (pw *Writer) write(p []byte) error {
+	n, err := pw.w.Write(p)
+	pw.offset += int64(n)
+	return err
+}
+
+// Write - writes a record, one value for each column: a string,
+// int64, time.Time or bool.
+func (pw *Writer) Write(values ...interface{}) error {
+	if len(values) != len(pw.columns) {
+		return fmt.Errorf("parquet: %d values for %d columns", len(values), len(pw.columns))
+	}
+	// Check every value first, a record is written whole or not at all.
+	for i, column := range pw.columns {
+		var ok bool
+		switch values[i].(type) {
+		case string:
+			ok = column.Type == String
+		case int64:
+			ok = column.Type == Int64
+		case time.Time:
+			ok = column.Type == TimestampMillis
+		case bool:
+			ok = column.Type == Boolean
+		}
+		if !ok {
+			return fmt.Errorf("parquet: unexpected value %T for column %s", values[i], column.Name)
+		}
+	}
+	for i := range pw.columns {
+		buf := &pw.values[i]
+		switch v := values[i].(type) {
+		case string:
+			var size [4]byte
+			binary.LittleEndian.PutUint32(size[:], uint32(len(v)))
+			buf.Write(size[:])
+			buf.WriteString(v)
+		case int64:
+			binary.Write(buf, binary.LittleEndian, v)
+		case time.Time:
+			binary.Write(buf, binary.LittleEndian, v.UnixNano()/int64(time.Millisecond))
+		case bool:
+			pw.bools[i] = append(pw.bools[i], v)
+		}
+	}
+	pw.rows++
+	if pw.rows >= pw.rowGroupSize {
+		return pw.flush()
+	}
+	return nil
+}
+
+// flush - writes the records held as a row group, one data page for
+// each column.
+func (pw *Writer) flush() error {
+	group := rowGroup{numRows: int64(pw.rows)}
+	for i, column := range pw.columns {
+		data := pw.values[i].Bytes()
+		if column.Type == Boolean {
+			// Booleans are bit packed, least significant bit first.
+			data = make([]byte, (len(pw.bools[i])+7)/8)
+			for k, v := range pw.bools[i] {
+				if v {
+					data[k/8] |= 1 << uint(k%8)
+				}
+			}
+		}
+
+		t := &thriftEncoder{}
+		t.structBegin(0)
+		t.i32(1, pageData)
+		t.i32(2, int32(len(data)))
+		t.i32(3, int32(len(data)))
+		t.structBegin(5)
+		t.i32(1, int32(pw.rows))
+		t.i32(2, encodingPlain)
+		t.i32(3, encodingRLE)
+		t.i32(4, encodingRLE)
+		t.structEnd()
+		t.structEnd()
+
+		chunk := columnChunk{offset: pw.offset, size: int64(t.buf.Len() + len(data))}
+		if err := pw.write(t.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := pw.write(data); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+		group.size += chunk.size
+
+		pw.values[i].Reset()
+		pw.bools[i] = pw.bools[i][:0]
+	}
+	pw.rowGroups = append(pw.rowGroups, group)
+	pw.rows = 0
+	return nil
+}
+
+// physicalType - returns the physical and converted types of column,
+// -1 when it has no converted type.
+func physicalType(column Column) (int32, int32) {
+	switch column.Type {
+	case String:
+		return physicalByteArray, convertedUTF8
+	case TimestampMillis:
+		return physicalInt64, convertedTimestampMillis
+	case Boolean:
+		return physicalBoolean, -1
+	}
+	return physicalInt64, -1
+}
+
+// Close - writes the records held and the footer of the file. It does
+// not close the underlying writer.
+func (pw *Writer) Close() error {
+	if pw.rows > 0 {
+		if err := pw.flush(); err != nil {
+			return err
+		}
+	}
+
+	var numRows int64
+	for _, group := range pw.rowGroups {
+		numRows += group.numRows
+	}
+
+	t := &thriftEncoder{}
+	t.structBegin(0)
+	t.i32(1, 1)
+	t.list(2, thriftStruct, len(pw.columns)+1)
+	t.structBegin(0)
+	t.string(4, "schema")
+	t.i32(5, int32(len(pw.columns)))
+	t.structEnd()
+	for _, column := range pw.columns {
+		physical, converted := physicalType(column)
+		t.structBegin(0)
+		t.i32(1, physical)
+		t.i32(3, repetitionRequired)
+		t.string(4, column.Name)
+		if converted >= 0 {
+			t.i32(6, converted)
+		}
+		t.structEnd()
+	}
+	t.i64(3, numRows)
+	t.list(4, thriftStruct, len(pw.rowGroups))
+	for _, group := range pw.rowGroups {
+		t.structBegin(0)
+		t.list(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			physical, _ := physicalType(pw.columns[i])
+			t.structBegin(0)
+			t.i64(2, chunk.offset)
+			t.structBegin(3)
+			t.i32(1, physical)
+			t.list(2, thriftI32, 2)
+			t.varint(encodingPlain)
+			t.varint(encodingRLE)
+			t.list(3, thriftBinary, 1)
+			t.binary(pw.columns[i].Name)
+			t.i32(4, codecUncompressed)
+			t.i64(5, group.numRows)
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.structEnd()
+			t.structEnd()
+		}
+		t.i64(2, group.size)
+		t.i64(3, group.numRows)
+		t.structEnd()
+	}
+	t.string(6, "mc")
+	t.structEnd()
+
+	if err := pw.write(t.buf.Bytes()); err != nil {
+		return err
+	}
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(t.buf.Len()))
+	if err := pw.write(size[:]); err != nil {
+		return err
+	}
+	return pw.write(magic)
+}
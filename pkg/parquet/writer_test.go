@@ -0,0 +1,135 @@
+/*
+ * MinIO Client (C) 2021 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// thriftDecoder - decodes the compact protocol structs written by
+// thriftEncoder, into maps of field ids to values.
+type thriftDecoder struct {
+	r *bytes.Reader
+}
+
+func (d *thriftDecoder) value(valueType byte) interface{} {
+	switch valueType {
+	case thriftI32, thriftI64:
+		v, _ := binary.ReadVarint(d.r)
+		return v
+	case thriftBinary:
+		n, _ := binary.ReadUvarint(d.r)
+		b := make([]byte, n)
+		d.r.Read(b)
+		return string(b)
+	case thriftList:
+		header, _ := d.r.ReadByte()
+		n := int(header >> 4)
+		if n == 15 {
+			size, _ := binary.ReadUvarint(d.r)
+			n = int(size)
+		}
+		var list []interface{}
+		for i := 0; i < n; i++ {
+			list = append(list, d.value(header&0x0f))
+		}
+		return list
+	case thriftStruct:
+		fields := map[int16]interface{}{}
+		var id int16
+		for {
+			header, _ := d.r.ReadByte()
+			if header == 0 {
+				return fields
+			}
+			if delta := int16(header >> 4); delta != 0 {
+				id += delta
+			} else {
+				v, _ := binary.ReadVarint(d.r)
+				id = int16(v)
+			}
+			fields[id] = d.value(header & 0x0f)
+		}
+	}
+	return nil
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	columns := []Column{
+		{"key", String},
+		{"size", Int64},
+		{"last_modified", TimestampMillis},
+		{"is_latest", Boolean},
+	}
+	w, err := NewWriter(&buf, columns, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, key := range []string{"a", "b", "c"} {
+		if err = w.Write(key, int64(len(key)), modTime, key != "b"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Write("d", int64(1), modTime, "true"); err == nil {
+		t.Fatal("expected an error for a mistyped value")
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data, magic) || !bytes.HasSuffix(data, magic) {
+		t.Fatal("missing magic")
+	}
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := data[len(data)-8-size : len(data)-8]
+	d := &thriftDecoder{r: bytes.NewReader(footer)}
+	meta := d.value(thriftStruct).(map[int16]interface{})
+
+	if numRows := meta[3].(int64); numRows != 3 {
+		t.Fatalf("expected 3 rows, got %d", numRows)
+	}
+	schema := meta[2].([]interface{})
+	if len(schema) != len(columns)+1 {
+		t.Fatalf("expected %d schema elements, got %d", len(columns)+1, len(schema))
+	}
+	for i, column := range columns {
+		if name := schema[i+1].(map[int16]interface{})[4].(string); name != column.Name {
+			t.Fatalf("expected column %s, got %s", column.Name, name)
+		}
+	}
+	rowGroups := meta[4].([]interface{})
+	if len(rowGroups) != 2 {
+		t.Fatalf("expected 2 row groups, got %d", len(rowGroups))
+	}
+
+	// The key column of the second row group holds "c" alone.
+	chunks := rowGroups[1].(map[int16]interface{})[1].([]interface{})
+	offset := chunks[0].(map[int16]interface{})[2].(int64)
+	d = &thriftDecoder{r: bytes.NewReader(data[offset:])}
+	page := d.value(thriftStruct).(map[int16]interface{})
+	values := make([]byte, page[2].(int64))
+	d.r.Read(values)
+	if !bytes.Equal(values, []byte{1, 0, 0, 0, 'c'}) {
+		t.Fatalf("unexpected values %v", values)
+	}
+}